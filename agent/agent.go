@@ -17,6 +17,7 @@ const timeLayout = "2006-01-02 (Monday)"
 // Agent builds a system prompt for a thread run.
 type Agent struct {
 	Name      string
+	Profile   string // provider profile from the template front matter, empty = default
 	workspace string
 	vars      map[string]any // lazy placeholder overrides, applied at Build time
 }
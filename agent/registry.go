@@ -7,7 +7,9 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/linanwx/nagobot/internal/watch"
 	"github.com/linanwx/nagobot/logger"
 )
 
@@ -16,6 +18,7 @@ type AgentDef struct {
 	Name        string // Callable name used by spawn_thread.agent
 	Description string // Short description shown in system prompt context
 	Path        string // Full path to the template file
+	Profile     string // Provider profile the agent runs on, empty = default
 }
 
 // AgentRegistry loads agent templates from workspace/agents.
@@ -23,6 +26,7 @@ type AgentRegistry struct {
 	workspace string
 	agentsDir string
 	agents    map[string]*AgentDef
+	watching  atomic.Bool // a watcher reloads on change, so New skips the disk
 	mu        sync.RWMutex
 }
 
@@ -59,7 +63,7 @@ func (r *AgentRegistry) load() {
 			continue
 		}
 
-		meta, _, _, parseErr := parseTemplate(string(raw))
+		meta, body, _, parseErr := parseTemplate(string(raw))
 		if parseErr != nil {
 			logger.Warn("invalid agent template front matter", "path", path, "err", parseErr)
 		}
@@ -75,10 +79,15 @@ func (r *AgentRegistry) load() {
 			continue
 		}
 
+		description := strings.TrimSpace(meta.Description)
+		if description == "" {
+			description = firstLine(body)
+		}
 		next[key] = &AgentDef{
 			Name:        name,
-			Description: strings.TrimSpace(meta.Description),
+			Description: description,
 			Path:        path,
+			Profile:     strings.TrimSpace(meta.Profile),
 		}
 	}
 
@@ -87,8 +96,26 @@ func (r *AgentRegistry) load() {
 	r.mu.Unlock()
 }
 
+// Watch reloads templates when files in the agents directory change, instead
+// of on every New call. The returned function stops watching.
+func (r *AgentRegistry) Watch() (func(), error) {
+	stop, err := watch.Dir(r.agentsDir, watch.DefaultDebounce, func() {
+		r.load()
+		logger.Info("agents reloaded", "dir", r.agentsDir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.load()
+	r.watching.Store(true)
+	return func() {
+		stop()
+		r.watching.Store(false)
+	}, nil
+}
+
 // New creates an agent by name. Defaults to "soul" if name is empty.
-// Reloads templates from disk before resolving. Returns an error if an
+// Reloads templates from disk before resolving unless Watch is active. Returns an error if an
 // explicit name is provided but not found in the registry.
 func (r *AgentRegistry) New(name string) (*Agent, error) {
 	explicit := strings.TrimSpace(name)
@@ -100,17 +127,62 @@ func (r *AgentRegistry) New(name string) (*Agent, error) {
 		return newAgent(explicit, ""), nil
 	}
 
-	r.load()
+	r.refresh()
 
 	r.mu.RLock()
-	_, found := r.agents[normalizeAgentName(explicit)]
+	def, found := r.agents[normalizeAgentName(explicit)]
 	r.mu.RUnlock()
 
 	if !found && strings.TrimSpace(name) != "" {
 		return nil, fmt.Errorf("agent %q not found", explicit)
 	}
 
-	return newAgent(explicit, r.workspace), nil
+	a := newAgent(explicit, r.workspace)
+	if found {
+		a.Profile = def.Profile
+	}
+	return a, nil
+}
+
+// List returns the agent definitions sorted by name, reloading templates
+// from disk unless Watch is active.
+func (r *AgentRegistry) List() []AgentDef {
+	r.refresh()
+	r.mu.RLock()
+	defs := make([]AgentDef, 0, len(r.agents))
+	for _, def := range r.agents {
+		defs = append(defs, *def)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool {
+		return strings.ToLower(defs[i].Name) < strings.ToLower(defs[j].Name)
+	})
+	return defs
+}
+
+// Prompt returns the named agent's definition and its template body without
+// front matter. Placeholders such as {{TASK}} are left unexpanded.
+func (r *AgentRegistry) Prompt(name string) (AgentDef, string, error) {
+	r.refresh()
+	r.mu.RLock()
+	def, ok := r.agents[normalizeAgentName(name)]
+	r.mu.RUnlock()
+	if !ok {
+		return AgentDef{}, "", fmt.Errorf("agent %q not found", strings.TrimSpace(name))
+	}
+
+	raw, err := os.ReadFile(def.Path)
+	if err != nil {
+		return *def, "", fmt.Errorf("failed to read agent template: %w", err)
+	}
+	return *def, strings.TrimSpace(stripFrontMatter(string(raw))), nil
+}
+
+func (r *AgentRegistry) refresh() {
+	if !r.watching.Load() {
+		r.load()
+	}
 }
 
 // BuildPromptSection renders a concise list of callable agents.
@@ -142,6 +214,17 @@ func (r *AgentRegistry) BuildPromptSection() string {
 	return strings.TrimSpace(sb.String())
 }
 
+// firstLine returns the first non-empty line of a template body, without
+// Markdown heading markers.
+func firstLine(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#")); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 func normalizeAgentName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
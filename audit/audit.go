@@ -0,0 +1,117 @@
+// Package audit persists a JSON-lines trail of what the agent did.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+// bufferSize is how many events may wait for the writer before new ones are
+// dropped.
+const bufferSize = 256
+
+// Event is one line of the audit log.
+type Event struct {
+	Time   time.Time      `json:"ts"`
+	Type   string         `json:"type"`
+	Source string         `json:"source,omitempty"` // session key or component that produced the event
+	Data   map[string]any `json:"data,omitempty"`
+}
+
+// Log appends events to a rotating file from a single writer goroutine, so
+// Record never blocks the caller on disk I/O.
+type Log struct {
+	w       io.WriteCloser
+	events  chan Event
+	done    chan struct{}
+	dropped atomic.Int64
+
+	closeOnce sync.Once
+	mu        sync.RWMutex // guards closed against concurrent Record and Close
+	closed    bool
+}
+
+// Open creates the file's directory and starts the writer. maxSizeMB 0 never
+// rotates; maxBackups 0 keeps every rotated file.
+func Open(path string, maxSizeMB, maxBackups int) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("audit: create dir: %w", err)
+	}
+	w, err := logger.OpenRotatingFile(path, int64(maxSizeMB)*1024*1024, maxBackups, false)
+	if err != nil {
+		return nil, fmt.Errorf("audit: open %s: %w", path, err)
+	}
+	l := &Log{w: w, events: make(chan Event, bufferSize), done: make(chan struct{})}
+	go l.run()
+	return l, nil
+}
+
+// Record queues ev for writing, stamping it with the current time if unset.
+// When the buffer is full the event is dropped and counted. Safe on a nil Log.
+func (l *Log) Record(ev Event) {
+	if l == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.events <- ev:
+	default:
+		if l.dropped.Add(1) == 1 {
+			logger.Warn("audit buffer full, dropping events")
+		}
+	}
+}
+
+// Dropped returns how many events were discarded because the buffer was full.
+func (l *Log) Dropped() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.dropped.Load()
+}
+
+// Close flushes queued events and closes the file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	var err error
+	l.closeOnce.Do(func() {
+		l.mu.Lock()
+		l.closed = true
+		close(l.events)
+		l.mu.Unlock()
+		<-l.done
+		err = l.w.Close()
+	})
+	return err
+}
+
+func (l *Log) run() {
+	defer close(l.done)
+	for ev := range l.events {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			logger.Warn("audit event not serializable", "type", ev.Type, "err", err)
+			continue
+		}
+		if _, err := l.w.Write(append(line, '\n')); err != nil {
+			logger.Warn("audit write failed", "err", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	l, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	l.Record(Event{Type: "tool_called", Source: "main", Data: map[string]any{"tool": "exec"}})
+	l.Record(Event{Type: "tool_completed", Source: "main", Data: map[string]any{"tool": "exec", "result": "ok"}})
+	l.Record(Event{Type: "agent_error", Source: "telegram:1"})
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	l.Record(Event{Type: "after_close"}) // ignored, must not panic
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	want := []string{"tool_called", "tool_completed", "agent_error"}
+	for i, ev := range events {
+		if ev.Type != want[i] || ev.Time.IsZero() {
+			t.Fatalf("event %d = %+v, want type %s with a timestamp", i, ev, want[i])
+		}
+	}
+	if events[1].Data["result"] != "ok" || events[2].Source != "telegram:1" {
+		t.Fatalf("fields not preserved: %+v", events)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/linanwx/nagobot/config"
 	"github.com/linanwx/nagobot/logger"
 )
 
@@ -20,13 +21,26 @@ type Message struct {
 	Text      string            // Message text
 	ReplyTo   string            // ID of message being replied to (if any)
 	Metadata  map[string]string // Channel-specific metadata
+	Images    []Image           // Downloaded image attachments, if any
+
+	// Done, if set, is called once the turn handling this message is over,
+	// with the turn's error. The cron channel uses it to track job runs.
+	Done func(err error)
+}
+
+// Image is an image attachment downloaded from a channel.
+type Image struct {
+	MimeType string // e.g. image/png
+	Data     []byte
 }
 
 // Response represents a response to send back.
 type Response struct {
-	Text     string            // Response text
-	ReplyTo  string            // Message ID to reply to
-	Metadata map[string]string // Channel-specific options
+	Text             string            // Response text
+	ReplyTo          string            // Message ID to reply to
+	ReplyToMessageID string            // Optional triggering message to quote; empty sends a plain message
+	MessageID        string            // Triggering message this is the reply to; keys per-message channel state such as placeholders
+	Metadata         map[string]string // Channel-specific options
 }
 
 // Channel is the interface for messaging channels.
@@ -47,6 +61,20 @@ type Channel interface {
 	Messages() <-chan *Message
 }
 
+// FileSender is implemented by channels that can deliver files.
+type FileSender interface {
+	// SendFile uploads the local file at path to replyTo with an optional caption.
+	SendFile(ctx context.Context, path, caption, replyTo string) error
+}
+
+// TurnFinisher is implemented by channels that hold per-message state, such as
+// a placeholder, until the reply to that message is sent.
+type TurnFinisher interface {
+	// FinishTurn releases the state kept for messageID in replyTo once its
+	// turn is over, whether or not a reply was sent.
+	FinishTurn(replyTo, messageID string)
+}
+
 // Manager manages multiple channels as a pure registry.
 type Manager struct {
 	channels map[string]Channel
@@ -76,11 +104,51 @@ func (m *Manager) Get(name string) (Channel, bool) {
 
 // SendTo sends a text message to a named channel.
 func (m *Manager) SendTo(ctx context.Context, channelName, text, replyTo string) error {
+	return m.SendResponse(ctx, channelName, &Response{Text: text, ReplyTo: replyTo})
+}
+
+// SendResponse sends a prepared response to a named channel.
+func (m *Manager) SendResponse(ctx context.Context, channelName string, resp *Response) error {
+	ch, ok := m.channels[channelName]
+	if !ok {
+		return fmt.Errorf("channel not found: %s", channelName)
+	}
+	return ch.Send(ctx, resp)
+}
+
+// FinishTurn tells a named channel that the turn for messageID is over.
+// Channels that do not implement TurnFinisher are left alone.
+func (m *Manager) FinishTurn(channelName, replyTo, messageID string) {
+	if tf, ok := m.channels[channelName].(TurnFinisher); ok {
+		tf.FinishTurn(replyTo, messageID)
+	}
+}
+
+// SendFile sends a local file to a named channel. Channels that do not
+// implement FileSender return an error.
+func (m *Manager) SendFile(ctx context.Context, channelName, path, caption, replyTo string) error {
 	ch, ok := m.channels[channelName]
 	if !ok {
 		return fmt.Errorf("channel not found: %s", channelName)
 	}
-	return ch.Send(ctx, &Response{Text: text, ReplyTo: replyTo})
+	fs, ok := ch.(FileSender)
+	if !ok {
+		return fmt.Errorf("channel %s does not support sending files", channelName)
+	}
+	return fs.SendFile(ctx, path, caption, replyTo)
+}
+
+// ShouldThreadReply reports whether a reply in a chat of chatType should quote
+// the triggering message under the given config.ReplyThreading* mode.
+func ShouldThreadReply(mode, chatType string) bool {
+	switch mode {
+	case config.ReplyThreadingAlways:
+		return true
+	case config.ReplyThreadingOff:
+		return false
+	default:
+		return chatType == "group" || chatType == "supergroup"
+	}
 }
 
 // StartAll starts all registered channels.
@@ -135,6 +203,17 @@ func (m *Manager) StopAll() error {
 	return nil
 }
 
+// DroppedMessages returns dropped inbound message counts for channels that track them.
+func (m *Manager) DroppedMessages() map[string]int64 {
+	counts := make(map[string]int64)
+	for name, ch := range m.channels {
+		if dc, ok := ch.(DropCounter); ok {
+			counts[name] = dc.DroppedMessages()
+		}
+	}
+	return counts
+}
+
 // Each iterates over all registered channels.
 func (m *Manager) Each(fn func(Channel)) {
 	for _, ch := range m.channels {
@@ -1,25 +1,36 @@
 package channel
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/chzyer/readline"
+	"github.com/linanwx/nagobot/config"
 	"github.com/linanwx/nagobot/logger"
 )
 
 const (
 	cliMessageBufferSize = 10
 	cliStopWaitTimeout   = 500 * time.Millisecond
+	cliHistoryFileName   = "cli_history"
+	cliHistoryLimit      = 1000
+	cliContinuePrompt    = "...> "
+	cliFence             = `"""`
 )
 
-// CLIChannel implements the Channel interface for interactive CLI.
+// CLIChannel implements the Channel interface for interactive CLI. Input has
+// line editing and arrow-key history, persisted under the config dir.
 type CLIChannel struct {
 	prompt       string
+	historyFile  string
+	rl           *readline.Instance
 	messages     chan *Message
 	done         chan struct{}
 	responseDone chan struct{}
@@ -31,8 +42,13 @@ type CLIChannel struct {
 
 // NewCLIChannel creates a new CLI channel.
 func NewCLIChannel() Channel {
+	historyFile := ""
+	if dir, err := config.ConfigDir(); err == nil {
+		historyFile = filepath.Join(dir, cliHistoryFileName)
+	}
 	return &CLIChannel{
 		prompt:       "nagobot> ",
+		historyFile:  historyFile,
 		messages:     make(chan *Message, cliMessageBufferSize),
 		done:         make(chan struct{}),
 		responseDone: make(chan struct{}, 1),
@@ -46,6 +62,22 @@ func (c *CLIChannel) Name() string {
 
 // Start begins reading from stdin.
 func (c *CLIChannel) Start(ctx context.Context) error {
+	if c.historyFile != "" {
+		if err := os.MkdirAll(filepath.Dir(c.historyFile), 0755); err != nil {
+			logger.Warn("cli history unavailable", "err", err)
+			c.historyFile = ""
+		}
+	}
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:                 c.prompt,
+		HistoryFile:            c.historyFile,
+		HistoryLimit:           cliHistoryLimit,
+		DisableAutoSaveHistory: true,
+	})
+	if err != nil {
+		return fmt.Errorf("cli channel: %w", err)
+	}
+	c.rl = rl
 	logger.Info("cli channel started")
 
 	c.wg.Add(1)
@@ -61,6 +93,9 @@ func (c *CLIChannel) Stop() error {
 	default:
 		close(c.done)
 	}
+	if c.rl != nil {
+		_ = c.rl.Close()
+	}
 
 	waitDone := make(chan struct{})
 	go func() {
@@ -92,9 +127,9 @@ func (c *CLIChannel) Send(ctx context.Context, resp *Response) error {
 		case c.responseDone <- struct{}{}:
 		default:
 		}
-	} else {
+	} else if c.rl != nil {
 		// Preserve a visible prompt for out-of-band notifications.
-		fmt.Print(c.prompt)
+		c.rl.Refresh()
 	}
 
 	return nil
@@ -105,12 +140,11 @@ func (c *CLIChannel) Messages() <-chan *Message {
 	return c.messages
 }
 
-// readInput reads lines from stdin.
+// readInput reads messages from stdin, joining multi-line input.
 func (c *CLIChannel) readInput(ctx context.Context) {
 	defer c.wg.Done()
 
-	scanner := bufio.NewScanner(os.Stdin)
-
+	var input cliInput
 	for {
 		select {
 		case <-ctx.Done():
@@ -118,17 +152,42 @@ func (c *CLIChannel) readInput(ctx context.Context) {
 		case <-c.done:
 			return
 		default:
-			fmt.Print(c.prompt)
+			if input.Pending() {
+				c.rl.SetPrompt(cliContinuePrompt)
+			} else {
+				c.rl.SetPrompt(c.prompt)
+			}
 
-			if !scanner.Scan() {
-				// EOF or error
+			line, err := c.rl.Readline()
+			if errors.Is(err, readline.ErrInterrupt) {
+				// Ctrl-C discards the message being typed. On an empty prompt
+				// it is the usual shutdown signal, which raw mode swallowed.
+				if input.Pending() || line != "" {
+					input.Reset()
+					continue
+				}
+				interruptSelf()
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					logger.Warn("cli read error", "err", err)
+				}
 				return
 			}
 
-			text := strings.TrimSpace(scanner.Text())
+			raw, done := input.Add(line)
+			if !done {
+				continue
+			}
+			text := strings.TrimSpace(raw)
 			if text == "" {
 				continue
 			}
+			// The history file is line-based, so only single-line messages are recalled.
+			if !strings.Contains(text, "\n") {
+				_ = c.rl.SaveHistory(text)
+			}
 
 			// Check for exit commands
 			if text == "exit" || text == "quit" || text == "/exit" || text == "/quit" {
@@ -191,3 +250,71 @@ func (c *CLIChannel) completeWaitingResponse() bool {
 	c.waitingResp = false
 	return true
 }
+
+// interruptSelf delivers the SIGINT that a terminal in raw mode did not send.
+func interruptSelf() {
+	if p, err := os.FindProcess(os.Getpid()); err == nil {
+		if err := p.Signal(os.Interrupt); err != nil {
+			logger.Warn("cli interrupt not delivered", "err", err)
+		}
+	}
+}
+
+// cliInput accumulates multi-line messages. A line ending in a backslash
+// continues on the next line, and a line starting with """ opens a block that
+// runs until a line ending with """.
+type cliInput struct {
+	lines  []string
+	fenced bool
+}
+
+// Pending reports whether a message is partially entered.
+func (in *cliInput) Pending() bool {
+	return len(in.lines) > 0 || in.fenced
+}
+
+// Reset discards the partial message.
+func (in *cliInput) Reset() {
+	in.lines = nil
+	in.fenced = false
+}
+
+// Add consumes one input line. It returns the complete message and true once
+// the message is finished, or "" and false while more lines are expected.
+func (in *cliInput) Add(line string) (string, bool) {
+	if in.fenced {
+		if body, ok := strings.CutSuffix(strings.TrimRight(line, " \t"), cliFence); ok {
+			if body != "" {
+				in.lines = append(in.lines, body)
+			}
+			return in.finish()
+		}
+		in.lines = append(in.lines, line)
+		return "", false
+	}
+
+	if rest, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), cliFence); ok {
+		if body, ok := strings.CutSuffix(strings.TrimRight(rest, " \t"), cliFence); ok {
+			in.lines = append(in.lines, body)
+			return in.finish()
+		}
+		in.fenced = true
+		if strings.TrimSpace(rest) != "" {
+			in.lines = append(in.lines, rest)
+		}
+		return "", false
+	}
+
+	if body, ok := strings.CutSuffix(line, "\\"); ok {
+		in.lines = append(in.lines, body)
+		return "", false
+	}
+	in.lines = append(in.lines, line)
+	return in.finish()
+}
+
+func (in *cliInput) finish() (string, bool) {
+	text := strings.Join(in.lines, "\n")
+	in.Reset()
+	return text, true
+}
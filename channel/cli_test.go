@@ -0,0 +1,49 @@
+package channel
+
+import "testing"
+
+func TestCLIInputMultiLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"single line", []string{"hello"}, "hello"},
+		{"backslash continuation", []string{`first \`, `second \`, "third"}, "first \nsecond \nthird"},
+		{"fence block", []string{`"""`, "a", "", "  b", `"""`}, "a\n\n  b"},
+		{"fence with inline open and close", []string{`"""start`, `end"""`}, "start\nend"},
+		{"fence on one line", []string{`"""inline"""`}, "inline"},
+		{"backslash inside fence is literal", []string{`"""`, `path\`, `"""`}, "path\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in cliInput
+			for i, line := range tt.lines {
+				got, done := in.Add(line)
+				last := i == len(tt.lines)-1
+				if done != last {
+					t.Fatalf("line %d (%q): done = %t, want %t", i, line, done, last)
+				}
+				if !last && !in.Pending() {
+					t.Fatalf("line %d: Pending() = false mid-message", i)
+				}
+				if last && got != tt.want {
+					t.Fatalf("message = %q, want %q", got, tt.want)
+				}
+			}
+			if in.Pending() {
+				t.Fatal("Pending() = true after the message completed")
+			}
+		})
+	}
+}
+
+func TestCLIInputReset(t *testing.T) {
+	var in cliInput
+	in.Add(`"""`)
+	in.Add("discarded")
+	in.Reset()
+	if got, done := in.Add("fresh"); !done || got != "fresh" {
+		t.Fatalf("Add after Reset = %q, %t; want fresh, true", got, done)
+	}
+}
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -14,6 +15,9 @@ import (
 	"github.com/linanwx/nagobot/thread"
 )
 
+// errCronStopped ends a run whose turn did not finish before the channel stopped.
+var errCronStopped = errors.New("cron channel stopped before the run finished")
+
 // CronChannel wraps a cron.Scheduler as a Channel. Each fired job produces
 // a Message on the Messages() channel. Send is a no-op — responses are
 // delivered via thread sinks.
@@ -35,22 +39,49 @@ func NewCronChannel(cfg *config.Config) Channel {
 		messages:  make(chan *Message, 64),
 		done:      make(chan struct{}),
 	}
+	sch, err := cronpkg.NewScheduler(ch.storePath, ch.runJob)
+	if err != nil {
+		logger.Warn("cron channel: failed to create scheduler", "err", err)
+	} else {
+		maxConcurrent, overlap := cfg.GetCronLimits()
+		sch.SetLimits(cronpkg.Limits{MaxConcurrent: maxConcurrent, Overlap: overlap})
+	}
+	ch.scheduler = sch
 	return ch
 }
 
-func (c *CronChannel) Name() string { return "cron" }
+// Scheduler returns the underlying job scheduler, or nil if it could not be created.
+func (c *CronChannel) Scheduler() *cronpkg.Scheduler {
+	return c.scheduler
+}
 
-func (c *CronChannel) Start(ctx context.Context) error {
-	factory := func(job *cronpkg.Job) (string, error) {
-		c.messages <- c.buildMessage(job)
-		return "", nil // fire-and-forget
+// runJob is the scheduler's factory. A run lasts until the agent turn it
+// starts is over, so the scheduler's overlap and concurrency limits bound
+// agent runs, not just the hand-off to the dispatcher, and it fails when the
+// turn fails.
+func (c *CronChannel) runJob(job *cronpkg.Job) (string, error) {
+	finished := make(chan error, 1)
+	msg := c.buildMessage(job)
+	msg.Done = func(err error) { finished <- err }
+	select {
+	case c.messages <- msg:
+	case <-c.done:
+		return "", errCronStopped
 	}
+	select {
+	case err := <-finished:
+		return "", err // a failed turn is retried per the job's policy
+	case <-c.done:
+		return "", errCronStopped
+	}
+}
 
-	sch, err := cronpkg.NewScheduler(c.storePath, factory)
-	if err != nil {
-		return fmt.Errorf("failed to create cron scheduler: %w", err)
+func (c *CronChannel) Name() string { return "cron" }
+
+func (c *CronChannel) Start(ctx context.Context) error {
+	if c.scheduler == nil {
+		return fmt.Errorf("cron scheduler is not initialized")
 	}
-	c.scheduler = sch
 	if err := c.scheduler.Load(); err != nil {
 		return fmt.Errorf("failed to load cron jobs: %w", err)
 	}
@@ -146,10 +177,11 @@ func buildCronStartMessage(job *cronpkg.Job) string {
 	}
 
 	return fmt.Sprintf(
-		"[Cron wake notice]\nReason: scheduled cron task triggered.\nRaw job config:\n- id: %s\n- kind: %s\n- expr: %s\n- at_time: %s\n- task: %s\n- agent: %s\n- wake_session: %s\n- silent: %t\n- created_at: %s",
+		"[Cron wake notice]\nReason: scheduled cron task triggered.\nRaw job config:\n- id: %s\n- kind: %s\n- expr: %s\n- timezone: %s\n- at_time: %s\n- task: %s\n- agent: %s\n- wake_session: %s\n- silent: %t\n- created_at: %s",
 		strings.TrimSpace(job.ID),
 		strings.TrimSpace(job.Kind),
 		strings.TrimSpace(job.Expr),
+		strings.TrimSpace(job.Timezone),
 		atTime,
 		strings.TrimSpace(job.Task),
 		strings.TrimSpace(job.Agent),
@@ -0,0 +1,62 @@
+package channel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cronpkg "github.com/linanwx/nagobot/cron"
+)
+
+func TestCronRunLastsUntilTurnFinishes(t *testing.T) {
+	ch := &CronChannel{messages: make(chan *Message, 1), done: make(chan struct{})}
+
+	returned := make(chan error, 1)
+	go func() {
+		_, err := ch.runJob(&cronpkg.Job{ID: "daily", Task: "report"})
+		returned <- err
+	}()
+	msg := <-ch.Messages()
+	if msg.Done == nil {
+		t.Fatal("cron message has no Done callback")
+	}
+	select {
+	case <-returned:
+		t.Fatal("runJob() returned before the turn finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	msg.Done(nil)
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Fatalf("runJob() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runJob() did not return after the turn finished")
+	}
+
+	// A failed turn fails the run, so the scheduler can retry it.
+	go func() {
+		_, err := ch.runJob(&cronpkg.Job{ID: "daily", Task: "report"})
+		returned <- err
+	}()
+	turnErr := errors.New("provider unavailable")
+	(<-ch.Messages()).Done(turnErr)
+	if err := <-returned; err != turnErr {
+		t.Fatalf("runJob() error = %v, want the turn error", err)
+	}
+
+	// A turn still running when the channel stops ends the run.
+	go func() {
+		_, err := ch.runJob(&cronpkg.Job{ID: "daily", Task: "report"})
+		returned <- err
+	}()
+	<-ch.Messages()
+	if err := ch.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := <-returned; err != errCronStopped {
+		t.Fatalf("runJob() after Stop error = %v, want errCronStopped", err)
+	}
+}
@@ -0,0 +1,237 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/logger"
+)
+
+const (
+	discordMessageBufferSize = 100
+	discordAPIBase           = "https://discord.com/api/v10"
+	discordHTTPTimeout       = 30 * time.Second
+	discordMaxRetryAfter     = 30 * time.Second
+	DiscordMaxMessageLength  = 2000
+)
+
+// DiscordChannel implements the Channel interface for Discord. Messages are
+// received over the gateway websocket and sent through the REST API.
+type DiscordChannel struct {
+	token      string
+	allowedIDs map[string]bool // Allowed user/guild/channel IDs (empty = allow all)
+	queue      *inboundQueue
+	done       chan struct{}
+	wg         sync.WaitGroup
+
+	apiBase string
+	client  *http.Client
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	botUserID string
+	sessionID string // gateway session for resuming; empty forces a fresh identify
+	resumeURL string
+	seq       *int64 // last dispatch sequence number, nil before the first event
+}
+
+// NewDiscordChannel creates a new Discord channel from config.
+// Returns nil if no token is configured.
+func NewDiscordChannel(cfg *config.Config) Channel {
+	token := cfg.GetDiscordToken()
+	if token == "" {
+		logger.Warn("Discord token not configured, skipping Discord channel")
+		return nil
+	}
+
+	allowedIDs := make(map[string]bool)
+	for _, id := range cfg.GetDiscordAllowedIDs() {
+		if id = strings.TrimSpace(id); id != "" {
+			allowedIDs[id] = true
+		}
+	}
+
+	ch := &DiscordChannel{
+		token:      token,
+		allowedIDs: allowedIDs,
+		queue:      newInboundQueue("discord", cfg, discordMessageBufferSize),
+		done:       make(chan struct{}),
+		apiBase:    discordAPIBase,
+		client:     &http.Client{Timeout: discordHTTPTimeout},
+	}
+	ch.queue.onDrop = busyNotifier(ch)
+	return ch
+}
+
+// Name returns the channel name.
+func (d *DiscordChannel) Name() string {
+	return "discord"
+}
+
+// DroppedMessages returns how many inbound messages were dropped on a full buffer.
+func (d *DiscordChannel) DroppedMessages() int64 {
+	return d.queue.Dropped()
+}
+
+// Start connects to the gateway and begins receiving messages.
+func (d *DiscordChannel) Start(ctx context.Context) error {
+	var gateway struct {
+		URL string `json:"url"`
+	}
+	if err := d.api(ctx, http.MethodGet, "/gateway/bot", nil, &gateway); err != nil {
+		return fmt.Errorf("discord connection failed: %w", err)
+	}
+	if gateway.URL == "" {
+		return fmt.Errorf("discord connection failed: empty gateway url")
+	}
+
+	d.wg.Add(1)
+	go d.runGateway(ctx, gateway.URL)
+
+	logger.Info("discord channel started")
+	return nil
+}
+
+// Stop gracefully shuts down the channel.
+func (d *DiscordChannel) Stop() error {
+	select {
+	case <-d.done:
+	default:
+		close(d.done)
+	}
+
+	d.mu.Lock()
+	if d.conn != nil {
+		_ = d.conn.Close(websocket.StatusNormalClosure, "shutdown")
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+	close(d.queue.messages)
+	logger.Info("discord channel stopped")
+	return nil
+}
+
+// Send sends a response message.
+// resp.ReplyTo is a Discord channel ID, or "user:{userID}" to open a DM.
+func (d *DiscordChannel) Send(ctx context.Context, resp *Response) error {
+	channelID := strings.TrimSpace(resp.ReplyTo)
+	if userID, ok := strings.CutPrefix(channelID, "user:"); ok {
+		dm, err := d.openDM(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("discord send error: %w", err)
+		}
+		channelID = dm
+	}
+	if channelID == "" {
+		return fmt.Errorf("discord send error: missing channel ID")
+	}
+
+	for i, chunk := range SplitMessage(resp.Text, DiscordMaxMessageLength) {
+		payload := discordOutboundMessage{Content: chunk}
+		// Only the first chunk replies to the triggering message.
+		if i == 0 && resp.ReplyToMessageID != "" {
+			payload.MessageReference = &discordMessageReference{
+				MessageID:       resp.ReplyToMessageID,
+				FailIfNotExists: false,
+			}
+		}
+		if err := d.api(ctx, http.MethodPost, "/channels/"+channelID+"/messages", payload, nil); err != nil {
+			return fmt.Errorf("discord send error: %w", err)
+		}
+	}
+	return nil
+}
+
+// Messages returns the incoming message channel.
+func (d *DiscordChannel) Messages() <-chan *Message {
+	return d.queue.messages
+}
+
+type discordOutboundMessage struct {
+	Content          string                   `json:"content"`
+	MessageReference *discordMessageReference `json:"message_reference,omitempty"`
+}
+
+type discordMessageReference struct {
+	MessageID       string `json:"message_id"`
+	FailIfNotExists bool   `json:"fail_if_not_exists"`
+}
+
+// openDM returns the DM channel ID for a user.
+func (d *DiscordChannel) openDM(ctx context.Context, userID string) (string, error) {
+	var dm struct {
+		ID string `json:"id"`
+	}
+	body := map[string]string{"recipient_id": userID}
+	if err := d.api(ctx, http.MethodPost, "/users/@me/channels", body, &dm); err != nil {
+		return "", err
+	}
+	return dm.ID, nil
+}
+
+// api performs a REST call. On a 429 it waits for retry_after (bounded) and
+// retries once.
+func (d *DiscordChannel) api(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, d.apiBase+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bot "+d.token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt == 0 {
+			var limited struct {
+				RetryAfter float64 `json:"retry_after"`
+			}
+			_ = json.Unmarshal(data, &limited)
+			wait := time.Duration(limited.RetryAfter * float64(time.Second))
+			if wait > 0 && wait <= discordMaxRetryAfter {
+				logger.Debug("discord rate limited, retrying", "path", path, "retryAfter", wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+				continue
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, out)
+	}
+}
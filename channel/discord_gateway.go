@@ -0,0 +1,361 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/linanwx/nagobot/logger"
+)
+
+const (
+	discordGatewayQuery     = "?v=10&encoding=json"
+	discordGatewayReadLimit = 8 << 20
+	discordReconnectMin     = 1 * time.Second
+	discordReconnectMax     = 60 * time.Second
+
+	// GUILDS | GUILD_MESSAGES | DIRECT_MESSAGES | MESSAGE_CONTENT
+	discordIntents = 1<<0 | 1<<9 | 1<<12 | 1<<15
+)
+
+// Gateway opcodes.
+const (
+	discordOpDispatch       = 0
+	discordOpHeartbeat      = 1
+	discordOpIdentify       = 2
+	discordOpResume         = 6
+	discordOpReconnect      = 7
+	discordOpInvalidSession = 9
+	discordOpHello          = 10
+	discordOpHeartbeatAck   = 11
+)
+
+// errDiscordReconnect asks runGateway to open a new connection.
+var errDiscordReconnect = errors.New("gateway requested reconnect")
+
+type discordPayload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+type discordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Bot      bool   `json:"bot"`
+}
+
+type discordAttachment struct {
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+}
+
+type discordMessage struct {
+	ID               string              `json:"id"`
+	ChannelID        string              `json:"channel_id"`
+	GuildID          string              `json:"guild_id"`
+	Author           discordUser         `json:"author"`
+	Content          string              `json:"content"`
+	Mentions         []discordUser       `json:"mentions"`
+	Attachments      []discordAttachment `json:"attachments"`
+	MessageReference *struct {
+		MessageID string `json:"message_id"`
+	} `json:"message_reference"`
+}
+
+// runGateway keeps a gateway connection open, reconnecting with backoff and
+// resuming the previous session when Discord allows it.
+func (d *DiscordChannel) runGateway(ctx context.Context, gatewayURL string) {
+	defer d.wg.Done()
+
+	backoff := discordReconnectMin
+	for {
+		ready, err := d.connectGateway(ctx, gatewayURL)
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.done:
+			return
+		default:
+		}
+		if ready {
+			backoff = discordReconnectMin
+		}
+		logger.Warn("discord gateway disconnected, reconnecting", "err", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.done:
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > discordReconnectMax {
+			backoff = discordReconnectMax
+		}
+	}
+}
+
+// connectGateway runs one gateway connection until it fails. ready reports
+// whether the session got as far as READY or RESUMED.
+func (d *DiscordChannel) connectGateway(ctx context.Context, gatewayURL string) (ready bool, err error) {
+	d.mu.Lock()
+	resume := d.sessionID != ""
+	url := gatewayURL
+	if resume && d.resumeURL != "" {
+		url = d.resumeURL
+	}
+	d.mu.Unlock()
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(connCtx, url+discordGatewayQuery, nil)
+	if err != nil {
+		return false, err
+	}
+	conn.SetReadLimit(discordGatewayReadLimit)
+	d.mu.Lock()
+	d.conn = conn
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.conn = nil
+		d.mu.Unlock()
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+	}()
+
+	var hello discordPayload
+	if err := wsjson.Read(connCtx, conn, &hello); err != nil {
+		return false, err
+	}
+	if hello.Op != discordOpHello {
+		return false, fmt.Errorf("expected hello, got op %d", hello.Op)
+	}
+	var helloData struct {
+		HeartbeatInterval int `json:"heartbeat_interval"`
+	}
+	if err := json.Unmarshal(hello.D, &helloData); err != nil || helloData.HeartbeatInterval <= 0 {
+		return false, fmt.Errorf("invalid hello payload")
+	}
+	go d.heartbeat(connCtx, conn, time.Duration(helloData.HeartbeatInterval)*time.Millisecond)
+
+	if resume {
+		err = d.sendResume(connCtx, conn)
+	} else {
+		err = d.sendIdentify(connCtx, conn)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		var p discordPayload
+		if err := wsjson.Read(connCtx, conn, &p); err != nil {
+			return ready, err
+		}
+		if p.S != nil {
+			d.mu.Lock()
+			seq := *p.S
+			d.seq = &seq
+			d.mu.Unlock()
+		}
+
+		switch p.Op {
+		case discordOpDispatch:
+			if p.T == "READY" || p.T == "RESUMED" {
+				ready = true
+			}
+			d.handleDispatch(p.T, p.D)
+		case discordOpHeartbeat:
+			if err := d.sendHeartbeat(connCtx, conn); err != nil {
+				return ready, err
+			}
+		case discordOpReconnect:
+			return ready, errDiscordReconnect
+		case discordOpInvalidSession:
+			var resumable bool
+			_ = json.Unmarshal(p.D, &resumable)
+			if !resumable {
+				d.mu.Lock()
+				d.sessionID, d.resumeURL, d.seq = "", "", nil
+				d.mu.Unlock()
+			}
+			return ready, fmt.Errorf("invalid session (resumable=%v)", resumable)
+		case discordOpHeartbeatAck:
+		}
+	}
+}
+
+func (d *DiscordChannel) heartbeat(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.sendHeartbeat(ctx, conn); err != nil {
+				logger.Debug("discord heartbeat failed", "err", err)
+				return
+			}
+		}
+	}
+}
+
+func (d *DiscordChannel) sendHeartbeat(ctx context.Context, conn *websocket.Conn) error {
+	d.mu.Lock()
+	seq := d.seq
+	d.mu.Unlock()
+	return wsjson.Write(ctx, conn, map[string]any{"op": discordOpHeartbeat, "d": seq})
+}
+
+func (d *DiscordChannel) sendIdentify(ctx context.Context, conn *websocket.Conn) error {
+	return wsjson.Write(ctx, conn, map[string]any{
+		"op": discordOpIdentify,
+		"d": map[string]any{
+			"token":   d.token,
+			"intents": discordIntents,
+			"properties": map[string]string{
+				"os":      "linux",
+				"browser": "nagobot",
+				"device":  "nagobot",
+			},
+		},
+	})
+}
+
+func (d *DiscordChannel) sendResume(ctx context.Context, conn *websocket.Conn) error {
+	d.mu.Lock()
+	data := map[string]any{"token": d.token, "session_id": d.sessionID, "seq": d.seq}
+	d.mu.Unlock()
+	return wsjson.Write(ctx, conn, map[string]any{"op": discordOpResume, "d": data})
+}
+
+// handleDispatch processes a gateway dispatch event.
+func (d *DiscordChannel) handleDispatch(event string, data json.RawMessage) {
+	switch event {
+	case "READY":
+		var ready struct {
+			User             discordUser `json:"user"`
+			SessionID        string      `json:"session_id"`
+			ResumeGatewayURL string      `json:"resume_gateway_url"`
+		}
+		if err := json.Unmarshal(data, &ready); err != nil {
+			logger.Warn("discord: invalid READY payload", "err", err)
+			return
+		}
+		d.mu.Lock()
+		d.botUserID = ready.User.ID
+		d.sessionID = ready.SessionID
+		d.resumeURL = ready.ResumeGatewayURL
+		d.mu.Unlock()
+		logger.Info("discord bot connected", "username", ready.User.Username)
+	case "MESSAGE_CREATE":
+		var msg discordMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.Warn("discord: invalid MESSAGE_CREATE payload", "err", err)
+			return
+		}
+		d.processMessage(&msg)
+	}
+}
+
+// processMessage converts a Discord message into a channel Message. In guild
+// channels the bot only answers messages that mention it.
+func (d *DiscordChannel) processMessage(msg *discordMessage) {
+	d.mu.Lock()
+	botUserID := d.botUserID
+	d.mu.Unlock()
+
+	if msg.Author.Bot || msg.Author.ID == "" || msg.Author.ID == botUserID {
+		return
+	}
+
+	if len(d.allowedIDs) > 0 {
+		if !d.allowedIDs[msg.Author.ID] && !d.allowedIDs[msg.GuildID] && !d.allowedIDs[msg.ChannelID] {
+			logger.Warn("discord message from unauthorized user",
+				"userID", msg.Author.ID,
+				"guildID", msg.GuildID,
+				"channelID", msg.ChannelID,
+				"username", msg.Author.Username,
+			)
+			return
+		}
+	}
+
+	text := msg.Content
+	chatType := "private"
+	if msg.GuildID != "" {
+		chatType = "group"
+		if !discordMentions(msg, botUserID) {
+			return
+		}
+		text = stripDiscordMention(text, botUserID)
+	}
+	text = strings.TrimSpace(text)
+
+	metadata := map[string]string{
+		"chat_id":    msg.ChannelID,
+		"chat_type":  chatType,
+		"message_id": msg.ID,
+	}
+	if msg.GuildID != "" {
+		metadata["guild_id"] = msg.GuildID
+	}
+	if len(msg.Attachments) > 0 {
+		att := msg.Attachments[0]
+		metadata["media_summary"] = MediaSummary("attachment",
+			"file_url", att.URL,
+			"file_name", att.Filename,
+			"mime_type", att.ContentType)
+		if text == "" {
+			text = "[Attachment received]"
+		}
+	}
+
+	if text == "" {
+		return
+	}
+
+	channelMsg := &Message{
+		ID:        msg.ID,
+		ChannelID: "discord:" + msg.ChannelID,
+		UserID:    msg.Author.ID,
+		Username:  msg.Author.Username,
+		Text:      text,
+		Metadata:  metadata,
+	}
+	if msg.MessageReference != nil {
+		channelMsg.ReplyTo = msg.MessageReference.MessageID
+	}
+
+	d.queue.push(channelMsg, d.done)
+}
+
+func discordMentions(msg *discordMessage, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	for _, u := range msg.Mentions {
+		if u.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func stripDiscordMention(text, userID string) string {
+	if userID == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, "<@"+userID+">", "")
+	return strings.ReplaceAll(text, "<@!"+userID+">", "")
+}
@@ -0,0 +1,183 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+func newTestDiscordChannel(apiBase string, allowed ...string) *DiscordChannel {
+	allowedIDs := make(map[string]bool)
+	for _, id := range allowed {
+		allowedIDs[id] = true
+	}
+	return &DiscordChannel{
+		token:      "token",
+		allowedIDs: allowedIDs,
+		queue:      newInboundQueue("discord", nil, 10),
+		done:       make(chan struct{}),
+		apiBase:    apiBase,
+		client:     http.DefaultClient,
+	}
+}
+
+func TestDiscordGatewayDeliversMessages(t *testing.T) {
+	identified := make(chan map[string]any, 1)
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/gateway/bot", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"url": "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"})
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+
+		_ = wsjson.Write(ctx, conn, map[string]any{"op": 10, "d": map[string]any{"heartbeat_interval": 45000}})
+		var identify map[string]any
+		if err := wsjson.Read(ctx, conn, &identify); err != nil {
+			return
+		}
+		identified <- identify
+
+		_ = wsjson.Write(ctx, conn, map[string]any{"op": 0, "s": 1, "t": "READY", "d": map[string]any{
+			"user": map[string]any{"id": "bot1", "username": "nagobot"}, "session_id": "sess",
+		}})
+		dispatch := func(s int, msg map[string]any) {
+			_ = wsjson.Write(ctx, conn, map[string]any{"op": 0, "s": s, "t": "MESSAGE_CREATE", "d": msg})
+		}
+		// Guild message without a mention: ignored.
+		dispatch(2, map[string]any{"id": "m1", "channel_id": "c1", "guild_id": "g1",
+			"author": map[string]any{"id": "u1", "username": "alice"}, "content": "chatter"})
+		// Guild message mentioning the bot: delivered with the mention stripped.
+		dispatch(3, map[string]any{"id": "m2", "channel_id": "c1", "guild_id": "g1",
+			"author": map[string]any{"id": "u1", "username": "alice"}, "content": "<@bot1> hello",
+			"mentions": []map[string]any{{"id": "bot1"}}})
+		// Other bots are ignored.
+		dispatch(4, map[string]any{"id": "m3", "channel_id": "c2",
+			"author": map[string]any{"id": "u9", "bot": true}, "content": "beep"})
+		// DM: delivered without a mention.
+		dispatch(5, map[string]any{"id": "m4", "channel_id": "dm1",
+			"author": map[string]any{"id": "u2", "username": "bob"}, "content": "hi there"})
+		// Keep reading so the client's close handshake completes.
+		for {
+			if _, _, err := conn.Read(ctx); err != nil {
+				return
+			}
+		}
+	})
+
+	ch := newTestDiscordChannel(srv.URL)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer ch.Stop()
+
+	select {
+	case identify := <-identified:
+		if identify["op"] != float64(discordOpIdentify) {
+			t.Fatalf("first client payload op = %v, want identify", identify["op"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("gateway never received identify")
+	}
+
+	var got []*Message
+	for len(got) < 2 {
+		select {
+		case msg := <-ch.Messages():
+			got = append(got, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d messages, want 2", len(got))
+		}
+	}
+
+	if got[0].Text != "hello" || got[0].ChannelID != "discord:c1" || got[0].Metadata["chat_id"] != "c1" ||
+		got[0].Metadata["chat_type"] != "group" || got[0].Metadata["message_id"] != "m2" {
+		t.Fatalf("guild message = %+v", got[0])
+	}
+	if got[1].Text != "hi there" || got[1].UserID != "u2" || got[1].Metadata["chat_type"] != "private" {
+		t.Fatalf("dm message = %+v", got[1])
+	}
+}
+
+func TestDiscordAllowlist(t *testing.T) {
+	ch := newTestDiscordChannel("", "g1", "u2")
+	ch.botUserID = "bot1"
+
+	ch.processMessage(&discordMessage{ID: "1", ChannelID: "c9", Author: discordUser{ID: "u9"}, Content: "stranger"})
+	ch.processMessage(&discordMessage{ID: "2", ChannelID: "c9", Author: discordUser{ID: "u2"}, Content: "allowed user"})
+	ch.processMessage(&discordMessage{ID: "3", ChannelID: "c1", GuildID: "g1", Author: discordUser{ID: "u9"},
+		Content: "<@!bot1> allowed guild", Mentions: []discordUser{{ID: "bot1"}}})
+
+	var texts []string
+	for len(ch.queue.messages) > 0 {
+		texts = append(texts, (<-ch.queue.messages).Text)
+	}
+	if len(texts) != 2 || texts[0] != "allowed user" || texts[1] != "allowed guild" {
+		t.Fatalf("delivered %v, want [allowed user, allowed guild]", texts)
+	}
+}
+
+func TestDiscordSend(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		bodies   []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+		bodies = append(bodies, body)
+		mu.Unlock()
+		if r.URL.Path == "/users/@me/channels" {
+			_, _ = w.Write([]byte(`{"id":"dm7"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"out"}`))
+	}))
+	defer srv.Close()
+
+	ch := newTestDiscordChannel(srv.URL)
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "c1", ReplyToMessageID: "m2", Text: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "user:u2", Text: "dm"}); err != nil {
+		t.Fatalf("Send() to user error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"POST /channels/c1/messages Bot token",
+		"POST /users/@me/channels Bot token",
+		"POST /channels/dm7/messages Bot token",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	ref, _ := bodies[0]["message_reference"].(map[string]any)
+	if ref["message_id"] != "m2" {
+		t.Fatalf("message_reference = %v, want message_id m2", bodies[0]["message_reference"])
+	}
+	if _, ok := bodies[2]["message_reference"]; ok || bodies[2]["content"] != "dm" {
+		t.Fatalf("dm body = %v", bodies[2])
+	}
+}
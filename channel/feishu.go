@@ -7,6 +7,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +23,7 @@ const (
 	feishuMaxMessageLength  = 4000
 	feishuMaxBodySize       = 1 << 20 // 1MB
 	feishuDedupTTL          = 5 * time.Minute
+	feishuMaxUploadSize     = 30 << 20 // im/v1/files limit
 )
 
 // FeishuChannel implements the Channel interface for Feishu (Lark).
@@ -32,10 +35,11 @@ type FeishuChannel struct {
 	allowedOpenIDs    map[string]bool // nil or empty = allow all
 	bot               *lark.Bot
 	server            *http.Server
-	messages          chan *Message
+	queue             *inboundQueue
 	done              chan struct{}
 	wg                sync.WaitGroup
 	encryptedKey      []byte // precomputed from encryptKey
+	mediaDir          string // downloaded attachments; empty disables saving files
 
 	// Event dedup: Feishu retries delivery, so we track seen event IDs.
 	seenMu sync.Mutex
@@ -57,6 +61,11 @@ func NewFeishuChannel(cfg *config.Config) Channel {
 		allowedOpenIDs[id] = true
 	}
 
+	mediaDir := ""
+	if workspace, err := cfg.WorkspacePath(); err == nil {
+		mediaDir = filepath.Join(workspace, feishuMediaDirName)
+	}
+
 	ch := &FeishuChannel{
 		appID:             appID,
 		appSecret:         appSecret,
@@ -64,14 +73,16 @@ func NewFeishuChannel(cfg *config.Config) Channel {
 		encryptKey:        cfg.GetFeishuEncryptKey(),
 		webhookAddr:       cfg.GetFeishuWebhookAddr(),
 		allowedOpenIDs:    allowedOpenIDs,
-		messages:          make(chan *Message, feishuMessageBufferSize),
+		queue:             newInboundQueue("feishu", cfg, feishuMessageBufferSize),
 		done:              make(chan struct{}),
 		seen:              make(map[string]time.Time),
+		mediaDir:          mediaDir,
 	}
 
 	if ch.encryptKey != "" {
 		ch.encryptedKey = lark.EncryptKey(ch.encryptKey)
 	}
+	ch.queue.onDrop = busyNotifier(ch)
 	return ch
 }
 
@@ -80,6 +91,11 @@ func (f *FeishuChannel) Name() string {
 	return "feishu"
 }
 
+// DroppedMessages returns how many inbound messages were dropped on a full buffer.
+func (f *FeishuChannel) DroppedMessages() int64 {
+	return f.queue.Dropped()
+}
+
 // Start initializes the Feishu bot and begins listening for webhook events.
 func (f *FeishuChannel) Start(ctx context.Context) error {
 	bot := lark.NewChatBot(f.appID, f.appSecret)
@@ -111,7 +127,7 @@ func (f *FeishuChannel) Start(ctx context.Context) error {
 		}
 	}()
 
-	// Periodic dedup cache cleanup.
+	// Periodic dedup cache and downloaded media cleanup.
 	f.wg.Add(1)
 	go func() {
 		defer f.wg.Done()
@@ -123,6 +139,7 @@ func (f *FeishuChannel) Start(ctx context.Context) error {
 				return
 			case <-ticker.C:
 				f.cleanupSeen()
+				f.cleanupMedia()
 			}
 		}
 	}()
@@ -145,7 +162,7 @@ func (f *FeishuChannel) Stop() error {
 		}
 	}
 	f.wg.Wait()
-	close(f.messages)
+	close(f.queue.messages)
 	logger.Info("feishu channel stopped")
 	return nil
 }
@@ -158,28 +175,89 @@ func (f *FeishuChannel) Send(ctx context.Context, resp *Response) error {
 	}
 
 	chunks := SplitMessage(resp.Text, feishuMaxMessageLength)
-	for _, chunk := range chunks {
-		var uid *lark.OptionalUserID
-		replyTo := resp.ReplyTo
-		if strings.HasPrefix(replyTo, "p2p:") {
-			uid = lark.WithOpenID(strings.TrimPrefix(replyTo, "p2p:"))
-		} else if strings.HasPrefix(replyTo, "group:") {
-			uid = lark.WithChatID(strings.TrimPrefix(replyTo, "group:"))
-		} else {
-			// Fallback: treat as open_id.
-			uid = lark.WithOpenID(replyTo)
+	for i, chunk := range chunks {
+		mb := lark.NewMsgBuffer(lark.MsgText)
+		bindFeishuTarget(mb, resp.ReplyTo)
+		// Only the first chunk replies to the triggering message.
+		if i == 0 && resp.ReplyToMessageID != "" {
+			mb.BindReply(resp.ReplyToMessageID)
 		}
 
-		if _, err := f.bot.PostText(chunk, uid); err != nil {
+		if _, err := f.bot.PostMessage(mb.Text(chunk).Build()); err != nil {
 			return fmt.Errorf("feishu send error: %w", err)
 		}
 	}
 	return nil
 }
 
+// SendFile uploads a local file and sends it as a file message. Feishu file
+// messages carry no caption, so a non-empty caption follows as text.
+func (f *FeishuChannel) SendFile(ctx context.Context, path, caption, replyTo string) error {
+	if f.bot == nil {
+		return fmt.Errorf("feishu bot not started")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > feishuMaxUploadSize {
+		return fmt.Errorf("file exceeds the feishu upload limit of %d bytes", feishuMaxUploadSize)
+	}
+
+	upload, err := f.bot.UploadFile(lark.UploadFileRequest{
+		FileType: feishuFileType(path),
+		FileName: filepath.Base(path),
+		Path:     path,
+	})
+	if err != nil {
+		return fmt.Errorf("feishu upload error: %w", err)
+	}
+	if upload.Code != 0 {
+		return fmt.Errorf("feishu upload error: code %d: %s", upload.Code, upload.Msg)
+	}
+
+	mb := lark.NewMsgBuffer(lark.MsgFile)
+	bindFeishuTarget(mb, replyTo)
+	if _, err := f.bot.PostMessage(mb.File(upload.Data.FileKey).Build()); err != nil {
+		return fmt.Errorf("feishu send error: %w", err)
+	}
+	if strings.TrimSpace(caption) != "" {
+		return f.Send(ctx, &Response{Text: caption, ReplyTo: replyTo})
+	}
+	return nil
+}
+
+// feishuFileType maps a file name to the upload file_type Feishu expects.
+func feishuFileType(path string) string {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "opus", "mp4", "pdf":
+		return ext
+	case "doc", "docx":
+		return "doc"
+	case "xls", "xlsx", "csv":
+		return "xls"
+	case "ppt", "pptx":
+		return "ppt"
+	default:
+		return "stream"
+	}
+}
+
+// bindFeishuTarget addresses mb to "p2p:{openID}", "group:{chatID}" or a bare open_id.
+func bindFeishuTarget(mb *lark.MsgBuffer, replyTo string) {
+	if strings.HasPrefix(replyTo, "p2p:") {
+		mb.BindOpenID(strings.TrimPrefix(replyTo, "p2p:"))
+	} else if strings.HasPrefix(replyTo, "group:") {
+		mb.BindChatID(strings.TrimPrefix(replyTo, "group:"))
+	} else {
+		// Fallback: treat as open_id.
+		mb.BindOpenID(replyTo)
+	}
+}
+
 // Messages returns the incoming message channel.
 func (f *FeishuChannel) Messages() <-chan *Message {
-	return f.messages
+	return f.queue.messages
 }
 
 // handleEvent processes incoming Feishu webhook events.
@@ -300,6 +378,7 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 	}
 
 	var text string
+	var media *feishuMedia
 	metadata := map[string]string{}
 
 	switch received.Message.MessageType {
@@ -316,7 +395,8 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 			logger.Error("feishu image content parse error", "err", err)
 			return
 		}
-		metadata["media_summary"] = MediaSummary("image", "image_key", content.ImageKey)
+		media = &feishuMedia{kind: "image", resourceType: "image", key: content.ImageKey,
+			summary: []string{"image_key", content.ImageKey}}
 		text = "[Image received]"
 	case "file":
 		var content feishuFileContent
@@ -324,8 +404,8 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 			logger.Error("feishu file content parse error", "err", err)
 			return
 		}
-		metadata["media_summary"] = MediaSummary("file",
-			"file_key", content.FileKey, "file_name", content.FileName)
+		media = &feishuMedia{kind: "file", resourceType: "file", key: content.FileKey, fileName: content.FileName,
+			summary: []string{"file_key", content.FileKey, "file_name", content.FileName}}
 		if content.FileName != "" {
 			text = fmt.Sprintf("[File: %s]", content.FileName)
 		} else {
@@ -337,9 +417,9 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 			logger.Error("feishu media content parse error", "err", err)
 			return
 		}
-		metadata["media_summary"] = MediaSummary("video",
-			"file_key", content.FileKey, "file_name", content.FileName,
-			"duration", fmtSeconds(content.Duration))
+		media = &feishuMedia{kind: "video", resourceType: "file", key: content.FileKey, fileName: content.FileName,
+			summary: []string{"file_key", content.FileKey, "file_name", content.FileName,
+				"duration", fmtSeconds(content.Duration)}}
 		text = "[Video received]"
 	case "audio":
 		var content feishuAudioContent
@@ -347,8 +427,8 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 			logger.Error("feishu audio content parse error", "err", err)
 			return
 		}
-		metadata["media_summary"] = MediaSummary("audio",
-			"file_key", content.FileKey, "duration", fmtSeconds(content.Duration))
+		media = &feishuMedia{kind: "audio", resourceType: "file", key: content.FileKey,
+			summary: []string{"file_key", content.FileKey, "duration", fmtSeconds(content.Duration)}}
 		text = "[Audio received]"
 	case "sticker":
 		var content feishuStickerContent
@@ -366,6 +446,9 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 	if text == "" {
 		return
 	}
+	if media != nil {
+		metadata["media_summary"] = MediaSummary(media.kind, media.summary...)
+	}
 
 	openID := received.Sender.SenderID.OpenID
 
@@ -401,12 +484,18 @@ func (f *FeishuChannel) processMessageEvent(event *lark.EventV2) {
 		Metadata:  metadata,
 	}
 
-	select {
-	case f.messages <- msg:
-	case <-f.done:
-	default:
-		logger.Warn("feishu message buffer full, dropping message")
+	// Stickers are not available through the message resource API.
+	if media == nil || media.key == "" {
+		f.queue.push(msg, f.done)
+		return
 	}
+	// Download off the webhook handler so Feishu gets its response promptly.
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.attachMedia(msg, media)
+		f.queue.push(msg, f.done)
+	}()
 }
 
 // markSeen returns true if the eventID is new (first time seen), false if duplicate.
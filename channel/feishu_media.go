@@ -0,0 +1,169 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+const (
+	feishuMediaDirName       = "media/feishu"
+	feishuMaxMediaSize       = 20 << 20 // 20MB
+	feishuMediaTimeout       = 30 * time.Second
+	feishuMediaRetention     = 24 * time.Hour
+	feishuMaxInlineImageSize = 10 << 20 // 10MB, larger images are saved but not sent to the model
+)
+
+// feishuMedia describes a message resource to download.
+type feishuMedia struct {
+	kind         string   // MediaSummary type, e.g. "file"
+	resourceType string   // message resource API type: "image" or "file"
+	key          string   // image_key or file_key
+	fileName     string   // original file name, if known
+	summary      []string // MediaSummary key/value pairs
+}
+
+// attachMedia downloads m into the media directory and records its local path
+// in msg.Metadata. Images small enough are also attached inline. Failures are
+// logged and leave the message with its plain media summary.
+func (f *FeishuChannel) attachMedia(msg *Message, m *feishuMedia) {
+	data, contentType, err := f.downloadResource(msg.ID, m.key, m.resourceType)
+	if err != nil {
+		logger.Warn("feishu media download failed, sending summary only", "messageID", msg.ID, "type", m.kind, "err", err)
+		return
+	}
+	mimeType := feishuMimeType(contentType, m.fileName, data)
+
+	if m.resourceType == "image" && strings.HasPrefix(mimeType, "image/") && len(data) <= feishuMaxInlineImageSize {
+		msg.Images = []Image{{MimeType: mimeType, Data: data}}
+	}
+
+	path, err := f.saveMedia(msg.ID, m, mimeType, data)
+	if err != nil {
+		logger.Warn("feishu media save failed", "messageID", msg.ID, "type", m.kind, "err", err)
+		return
+	}
+	msg.Metadata["file_path"] = path
+	msg.Metadata["mime_type"] = mimeType
+	if m.fileName != "" {
+		msg.Metadata["file_name"] = m.fileName
+	}
+	msg.Metadata["media_summary"] = MediaSummary(m.kind, append(m.summary, "file_path", path, "mime_type", mimeType)...)
+}
+
+// downloadResource fetches a message resource (image or file) via the message
+// resource API. It returns the body and the response Content-Type.
+func (f *FeishuChannel) downloadResource(messageID, key, resourceType string) ([]byte, string, error) {
+	if f.bot == nil {
+		return nil, "", fmt.Errorf("feishu bot not started")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), feishuMediaTimeout)
+	defer cancel()
+
+	url := f.bot.ExpandURL(fmt.Sprintf("/open-apis/im/v1/messages/%s/resources/%s?type=%s", messageID, key, resourceType))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+f.bot.TenantAccessToken())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > feishuMaxMediaSize {
+		return nil, "", fmt.Errorf("resource exceeds %d bytes", feishuMaxMediaSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, feishuMaxMediaSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > feishuMaxMediaSize {
+		return nil, "", fmt.Errorf("resource exceeds %d bytes", feishuMaxMediaSize)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// saveMedia writes data under the media directory as "{messageID}_{name}".
+func (f *FeishuChannel) saveMedia(messageID string, m *feishuMedia, mimeType string, data []byte) (string, error) {
+	if f.mediaDir == "" {
+		return "", fmt.Errorf("media directory not configured")
+	}
+	if err := os.MkdirAll(f.mediaDir, 0o755); err != nil {
+		return "", err
+	}
+	name := safeFileName(m.fileName)
+	if name == "" {
+		name = safeFileName(m.key)
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	path := filepath.Join(f.mediaDir, safeFileName(messageID)+"_"+name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// cleanupMedia removes downloaded files older than feishuMediaRetention.
+func (f *FeishuChannel) cleanupMedia() {
+	if f.mediaDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(f.mediaDir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-feishuMediaRetention)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(f.mediaDir, entry.Name())); err != nil {
+			logger.Warn("feishu media cleanup failed", "file", entry.Name(), "err", err)
+		}
+	}
+}
+
+// feishuMimeType prefers a specific Content-Type header, then the file
+// extension, then content sniffing.
+func feishuMimeType(contentType, fileName string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	if byExt := mime.TypeByExtension(filepath.Ext(fileName)); byExt != "" {
+		if mediaType, _, err := mime.ParseMediaType(byExt); err == nil {
+			return mediaType
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+// safeFileName reduces name to a single path element without separators.
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,211 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-lark/lark"
+)
+
+func TestFeishuSendRepliesToTriggeringMessage(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"message_id": "om_reply"}})
+	}))
+	defer srv.Close()
+
+	bot := lark.NewChatBot("app", "secret")
+	bot.SetDomain(srv.URL)
+	f := &FeishuChannel{bot: bot}
+
+	if err := f.Send(context.Background(), &Response{ReplyTo: "group:oc_1", ReplyToMessageID: "om_origin", Text: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := f.Send(context.Background(), &Response{ReplyTo: "group:oc_1", Text: "plain"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"/open-apis/im/v1/messages/om_origin/reply", "/open-apis/im/v1/messages"}
+	if len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] {
+		t.Fatalf("request paths = %v, want %v", paths, want)
+	}
+}
+
+func TestFeishuSendFileUploadsThenPosts(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		paths    []string
+		fileType string
+		msgTypes []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/open-apis/im/v1/files" {
+			_ = r.ParseMultipartForm(1 << 20)
+			fileType = r.FormValue("file_type")
+			_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"file_key": "file_v2_up"}})
+			return
+		}
+		var body struct {
+			MsgType string `json:"msg_type"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		msgTypes = append(msgTypes, body.MsgType)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"message_id": "om_file"}})
+	}))
+	defer srv.Close()
+
+	bot := lark.NewChatBot("app", "secret")
+	bot.SetDomain(srv.URL)
+	f := &FeishuChannel{bot: bot}
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(file, []byte("%PDF-1.4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.SendFile(context.Background(), file, "here you go", "group:oc_1"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"/open-apis/im/v1/files", "/open-apis/im/v1/messages", "/open-apis/im/v1/messages"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("request paths = %v, want %v", paths, want)
+	}
+	if fileType != "pdf" {
+		t.Errorf("file_type = %q, want pdf", fileType)
+	}
+	if strings.Join(msgTypes, ",") != "file,text" {
+		t.Errorf("message types = %v, want [file text]", msgTypes)
+	}
+}
+
+func newFeishuResourceServer(t *testing.T, contentType string, body []byte) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestFeishuAttachMediaSavesFile(t *testing.T) {
+	srv, requests := newFeishuResourceServer(t, "application/octet-stream", []byte("%PDF-1.4 report"))
+	bot := lark.NewChatBot("app", "secret")
+	bot.SetDomain(srv.URL)
+	f := &FeishuChannel{bot: bot, mediaDir: filepath.Join(t.TempDir(), "media")}
+
+	msg := &Message{ID: "om_1", Metadata: map[string]string{}}
+	f.attachMedia(msg, &feishuMedia{kind: "file", resourceType: "file", key: "file_v2_abc", fileName: "report.pdf",
+		summary: []string{"file_key", "file_v2_abc", "file_name", "report.pdf"}})
+
+	if want := "/open-apis/im/v1/messages/om_1/resources/file_v2_abc?type=file"; len(*requests) != 1 || (*requests)[0] != want {
+		t.Fatalf("requests = %v, want [%s]", *requests, want)
+	}
+	path := msg.Metadata["file_path"]
+	if path != filepath.Join(f.mediaDir, "om_1_report.pdf") {
+		t.Fatalf("file_path = %q", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "%PDF-1.4 report" {
+		t.Fatalf("saved file = %q, %v", data, err)
+	}
+	if got := msg.Metadata["mime_type"]; got != "application/pdf" {
+		t.Errorf("mime_type = %q, want application/pdf", got)
+	}
+	if got := msg.Metadata["file_name"]; got != "report.pdf" {
+		t.Errorf("file_name = %q, want report.pdf", got)
+	}
+	if summary := msg.Metadata["media_summary"]; !strings.Contains(summary, "file_path: "+path) {
+		t.Errorf("media_summary missing file path:\n%s", summary)
+	}
+	if len(msg.Images) != 0 {
+		t.Errorf("files must not be attached inline, got %d images", len(msg.Images))
+	}
+}
+
+func TestFeishuAttachMediaInlinesImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	srv, _ := newFeishuResourceServer(t, "", png)
+	bot := lark.NewChatBot("app", "secret")
+	bot.SetDomain(srv.URL)
+	f := &FeishuChannel{bot: bot, mediaDir: t.TempDir()}
+
+	msg := &Message{ID: "om_2", Metadata: map[string]string{}}
+	f.attachMedia(msg, &feishuMedia{kind: "image", resourceType: "image", key: "img_v2_xyz",
+		summary: []string{"image_key", "img_v2_xyz"}})
+
+	if got := msg.Metadata["file_path"]; got != filepath.Join(f.mediaDir, "om_2_img_v2_xyz.png") {
+		t.Errorf("file_path = %q", got)
+	}
+	if len(msg.Images) != 1 || msg.Images[0].MimeType != "image/png" {
+		t.Fatalf("images = %+v, want one image/png", msg.Images)
+	}
+}
+
+func TestFeishuAttachMediaRejectsOversizedResource(t *testing.T) {
+	srv, _ := newFeishuResourceServer(t, "application/zip", make([]byte, feishuMaxMediaSize+1))
+	bot := lark.NewChatBot("app", "secret")
+	bot.SetDomain(srv.URL)
+	f := &FeishuChannel{bot: bot, mediaDir: t.TempDir()}
+
+	msg := &Message{ID: "om_3", Metadata: map[string]string{}}
+	f.attachMedia(msg, &feishuMedia{kind: "file", resourceType: "file", key: "file_big", fileName: "big.zip"})
+
+	if _, ok := msg.Metadata["file_path"]; ok {
+		t.Fatal("oversized resource should not be saved")
+	}
+	if entries, _ := os.ReadDir(f.mediaDir); len(entries) != 0 {
+		t.Fatalf("media dir has %d entries, want 0", len(entries))
+	}
+}
+
+func TestFeishuCleanupMediaRemovesExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	f := &FeishuChannel{mediaDir: dir}
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	for _, p := range []string{oldPath, newPath} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-feishuMediaRetention - time.Minute)
+	if err := os.Chtimes(oldPath, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	f.cleanupMedia()
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expired file still present: %v", err)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("recent file removed: %v", err)
+	}
+}
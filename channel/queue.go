@@ -0,0 +1,151 @@
+package channel
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/logger"
+)
+
+// DropCounter is implemented by channels that may drop inbound messages
+// when their buffer is full.
+type DropCounter interface {
+	DroppedMessages() int64
+}
+
+const (
+	busyNotice        = "System busy, please resend your message in a moment."
+	busyNoticeTimeout = 10 * time.Second
+	// busyNoticeInterval is the least time between two busy notices to the
+	// same sender; drops in between are only counted.
+	busyNoticeInterval = time.Minute
+	// busyNoticeMaxInFlight bounds the notices being sent at once. Drops past
+	// it go unannounced rather than queueing more network calls.
+	busyNoticeMaxInFlight = 4
+)
+
+// inboundQueue buffers incoming messages for a channel and applies the
+// configured overflow policy: drop immediately, or block up to a timeout
+// (backpressure) before dropping.
+type inboundQueue struct {
+	channel      string
+	messages     chan *Message
+	blockTimeout time.Duration
+	dropped      atomic.Int64
+	onDrop       func(*Message) // called in the background when a message is dropped on a full buffer; may be nil
+
+	noticeMu    sync.Mutex
+	lastNotice  map[string]time.Time // sender → when they were last told about a drop
+	noticeSlots chan struct{}        // semaphore bounding in-flight onDrop calls
+}
+
+func newInboundQueue(channelName string, cfg *config.Config, defaultSize int) *inboundQueue {
+	size := cfg.GetChannelMessageBufferSize()
+	if size <= 0 {
+		size = defaultSize
+	}
+	return &inboundQueue{
+		channel:      channelName,
+		messages:     make(chan *Message, size),
+		blockTimeout: time.Duration(cfg.GetChannelBackpressureTimeoutMs()) * time.Millisecond,
+		lastNotice:   make(map[string]time.Time),
+		noticeSlots:  make(chan struct{}, busyNoticeMaxInFlight),
+	}
+}
+
+// push enqueues msg. It returns false if the message was dropped because the
+// buffer stayed full, or if done was closed first.
+func (q *inboundQueue) push(msg *Message, done <-chan struct{}) bool {
+	select {
+	case q.messages <- msg:
+		return true
+	case <-done:
+		return false
+	default:
+	}
+
+	if q.blockTimeout > 0 {
+		timer := time.NewTimer(q.blockTimeout)
+		defer timer.Stop()
+		select {
+		case q.messages <- msg:
+			return true
+		case <-done:
+			return false
+		case <-timer.C:
+		}
+	}
+
+	total := q.dropped.Add(1)
+	logger.Warn(
+		q.channel+" message buffer full, dropping message",
+		"channel", q.channel,
+		"messageID", msg.ID,
+		"bufferSize", cap(q.messages),
+		"droppedTotal", total,
+	)
+	q.announceDrop(msg)
+	return false
+}
+
+// announceDrop runs onDrop for msg in the background, so the receive path
+// never waits on the network. Each sender hears at most once per
+// busyNoticeInterval, and only busyNoticeMaxInFlight notices run at once.
+func (q *inboundQueue) announceDrop(msg *Message) {
+	if q.onDrop == nil {
+		return
+	}
+	sender := strings.TrimSpace(msg.UserID)
+	if sender == "" {
+		sender = strings.TrimSpace(msg.Metadata["chat_id"])
+	}
+
+	now := time.Now()
+	q.noticeMu.Lock()
+	for key, at := range q.lastNotice {
+		if now.Sub(at) >= busyNoticeInterval {
+			delete(q.lastNotice, key)
+		}
+	}
+	if _, recent := q.lastNotice[sender]; recent {
+		q.noticeMu.Unlock()
+		return
+	}
+	select {
+	case q.noticeSlots <- struct{}{}:
+		q.lastNotice[sender] = now
+		q.noticeMu.Unlock()
+	default:
+		q.noticeMu.Unlock()
+		return
+	}
+	go func() {
+		defer func() { <-q.noticeSlots }()
+		q.onDrop(msg)
+	}()
+}
+
+// busyNotifier returns an onDrop hook that tells the sender their message was
+// not accepted, replying to the chat recorded in its chat_id metadata.
+func busyNotifier(ch Channel) func(*Message) {
+	return func(msg *Message) {
+		chatID := strings.TrimSpace(msg.Metadata["chat_id"])
+		if chatID == "" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), busyNoticeTimeout)
+		defer cancel()
+		if err := ch.Send(ctx, &Response{Text: busyNotice, ReplyTo: chatID}); err != nil {
+			logger.Warn("failed to send busy notice", "channel", ch.Name(), "chatID", chatID, "err", err)
+		}
+	}
+}
+
+// Dropped returns the number of messages dropped so far.
+func (q *inboundQueue) Dropped() int64 {
+	return q.dropped.Load()
+}
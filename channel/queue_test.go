@@ -0,0 +1,196 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+// droppingChannel is a Channel stub that reports a fixed drop count.
+type droppingChannel struct {
+	recordingChannel
+	name    string
+	dropped int64
+}
+
+func (d *droppingChannel) Name() string           { return d.name }
+func (d *droppingChannel) DroppedMessages() int64 { return d.dropped }
+
+// recordingChannel is a Channel stub that records sent responses.
+type recordingChannel struct {
+	mu   sync.Mutex
+	sent []*Response
+}
+
+func (r *recordingChannel) Name() string                { return "recording" }
+func (r *recordingChannel) Start(context.Context) error { return nil }
+func (r *recordingChannel) Stop() error                 { return nil }
+func (r *recordingChannel) Messages() <-chan *Message   { return nil }
+func (r *recordingChannel) Send(_ context.Context, resp *Response) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, resp)
+	return nil
+}
+
+// waitSent waits until n responses were sent and returns them.
+func (r *recordingChannel) waitSent(t *testing.T, n int) []*Response {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		sent := append([]*Response(nil), r.sent...)
+		r.mu.Unlock()
+		if len(sent) >= n || time.Now().After(deadline) {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInboundQueueBusyNoticeOnDrop(t *testing.T) {
+	ch := &recordingChannel{}
+	q := newInboundQueue("recording", nil, 1)
+	q.blockTimeout = 10 * time.Millisecond
+	q.onDrop = busyNotifier(ch)
+	done := make(chan struct{})
+
+	if !q.push(&Message{ID: "1", Metadata: map[string]string{"chat_id": "c1"}}, done) {
+		t.Fatal("first push dropped, want accepted")
+	}
+	start := time.Now()
+	if q.push(&Message{ID: "2", Metadata: map[string]string{"chat_id": "c2"}}, done) {
+		t.Fatal("second push accepted on a full buffer")
+	}
+	if waited := time.Since(start); waited < q.blockTimeout {
+		t.Fatalf("push gave up after %v, want it to block for %v", waited, q.blockTimeout)
+	}
+
+	if sent := ch.waitSent(t, 1); len(sent) != 1 || sent[0].ReplyTo != "c2" || sent[0].Text != busyNotice {
+		t.Fatalf("sent = %+v, want one busy notice to c2", sent)
+	}
+	if q.Dropped() != 1 {
+		t.Fatalf("Dropped() = %d, want 1", q.Dropped())
+	}
+}
+
+func TestInboundQueueBusyNoticesDoNotBlockAndAreThrottled(t *testing.T) {
+	cfg := &config.Config{Channels: &config.ChannelsConfig{BackpressureTimeoutMs: -1}}
+	q := newInboundQueue("test", cfg, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var notified []string
+	q.onDrop = func(msg *Message) {
+		<-release // a send stuck on the network
+		mu.Lock()
+		notified = append(notified, msg.UserID)
+		mu.Unlock()
+	}
+	done := make(chan struct{})
+	q.push(&Message{ID: "fill"}, done)
+
+	start := time.Now()
+	for _, user := range []string{"a", "a", "b", "a", "c", "d", "e", "f"} {
+		q.push(&Message{ID: "extra", UserID: user}, done)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Fatalf("pushes took %v while notices were stuck, want no waiting", waited)
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(notified)
+		mu.Unlock()
+		if n >= busyNoticeMaxInFlight || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	// a, b, c and d fill the in-flight slots; a's repeats are throttled and
+	// e and f find no free slot.
+	if len(notified) != busyNoticeMaxInFlight {
+		t.Fatalf("notified %v, want one notice for each of the first %d senders", notified, busyNoticeMaxInFlight)
+	}
+	if q.Dropped() != 8 {
+		t.Fatalf("Dropped() = %d, want every drop counted", q.Dropped())
+	}
+}
+
+func TestInboundQueueSizeAndTimeoutFromConfig(t *testing.T) {
+	q := newInboundQueue("test", nil, 7)
+	if cap(q.messages) != 7 || q.blockTimeout != 2*time.Second {
+		t.Fatalf("defaults: size %d, timeout %v; want 7 and 2s", cap(q.messages), q.blockTimeout)
+	}
+
+	cfg := &config.Config{Channels: &config.ChannelsConfig{MessageBufferSize: 3, BackpressureTimeoutMs: -1}}
+	q = newInboundQueue("test", cfg, 7)
+	if cap(q.messages) != 3 || q.blockTimeout != 0 {
+		t.Fatalf("configured: size %d, timeout %v; want 3 and no blocking", cap(q.messages), q.blockTimeout)
+	}
+}
+
+func TestInboundQueueDropsImmediatelyWithoutBackpressure(t *testing.T) {
+	cfg := &config.Config{Channels: &config.ChannelsConfig{BackpressureTimeoutMs: -1}}
+	q := newInboundQueue("test", cfg, 1)
+	done := make(chan struct{})
+
+	q.push(&Message{ID: "1"}, done)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if q.push(&Message{ID: "extra"}, done) {
+			t.Fatal("push accepted on a full buffer")
+		}
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Fatalf("pushes took %v, want an immediate drop", waited)
+	}
+	if q.Dropped() != 3 {
+		t.Fatalf("Dropped() = %d, want 3", q.Dropped())
+	}
+}
+
+func TestInboundQueueBackpressureWaitsForRoom(t *testing.T) {
+	q := newInboundQueue("test", nil, 1)
+	q.blockTimeout = 5 * time.Second
+	done := make(chan struct{})
+	q.push(&Message{ID: "1"}, done)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-q.messages
+	}()
+	if !q.push(&Message{ID: "2"}, done) {
+		t.Fatal("push dropped although the consumer made room")
+	}
+	if q.Dropped() != 0 {
+		t.Fatalf("Dropped() = %d, want 0", q.Dropped())
+	}
+
+	close(done)
+	if q.push(&Message{ID: "3"}, done) {
+		t.Fatal("push accepted after done was closed")
+	}
+	if q.Dropped() != 0 {
+		t.Fatalf("Dropped() = %d after shutdown, want it not counted as a drop", q.Dropped())
+	}
+}
+
+func TestManagerReportsDroppedMessagesPerChannel(t *testing.T) {
+	m := NewManager()
+	m.Register(&droppingChannel{name: "telegram", dropped: 4})
+	m.Register(&droppingChannel{name: "web"})
+	m.Register(&recordingChannel{})
+
+	got := m.DroppedMessages()
+	if len(got) != 2 || got["telegram"] != 4 || got["web"] != 0 {
+		t.Fatalf("DroppedMessages() = %v, want telegram 4 and web 0 only", got)
+	}
+}
@@ -0,0 +1,428 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/logger"
+)
+
+const (
+	slackMessageBufferSize = 100
+	slackMaxMessageLength  = 4000
+	slackMaxBodySize       = 1 << 20 // 1MB
+	slackDedupTTL          = 5 * time.Minute
+	slackMaxClockSkew      = 5 * time.Minute
+	slackAPIBase           = "https://slack.com/api"
+	slackHTTPTimeout       = 30 * time.Second
+)
+
+// SlackChannel implements the Channel interface for Slack via the Events API.
+type SlackChannel struct {
+	botToken      string
+	signingSecret string
+	webhookAddr   string
+	allowedIDs    map[string]bool // Allowed user/channel IDs (empty = allow all)
+	apiBase       string
+	client        *http.Client
+	botUserID     string
+	server        *http.Server
+	queue         *inboundQueue
+	done          chan struct{}
+	wg            sync.WaitGroup
+
+	// Event dedup: Slack retries delivery, so we track seen event IDs.
+	seenMu sync.Mutex
+	seen   map[string]time.Time
+}
+
+// NewSlackChannel creates a new Slack channel from config.
+// Returns nil if the bot token or signing secret is not configured.
+func NewSlackChannel(cfg *config.Config) Channel {
+	botToken := cfg.GetSlackBotToken()
+	signingSecret := cfg.GetSlackSigningSecret()
+	if botToken == "" || signingSecret == "" {
+		logger.Warn("Slack botToken/signingSecret not configured, skipping Slack channel")
+		return nil
+	}
+
+	allowedIDs := make(map[string]bool)
+	for _, id := range cfg.GetSlackAllowedIDs() {
+		if id = strings.TrimSpace(id); id != "" {
+			allowedIDs[id] = true
+		}
+	}
+
+	ch := &SlackChannel{
+		botToken:      botToken,
+		signingSecret: signingSecret,
+		webhookAddr:   cfg.GetSlackWebhookAddr(),
+		allowedIDs:    allowedIDs,
+		apiBase:       slackAPIBase,
+		client:        &http.Client{Timeout: slackHTTPTimeout},
+		queue:         newInboundQueue("slack", cfg, slackMessageBufferSize),
+		done:          make(chan struct{}),
+		seen:          make(map[string]time.Time),
+	}
+	ch.queue.onDrop = busyNotifier(ch)
+	return ch
+}
+
+// Name returns the channel name.
+func (s *SlackChannel) Name() string {
+	return "slack"
+}
+
+// DroppedMessages returns how many inbound messages were dropped on a full buffer.
+func (s *SlackChannel) DroppedMessages() int64 {
+	return s.queue.Dropped()
+}
+
+// Start verifies the bot token and begins listening for Events API requests.
+func (s *SlackChannel) Start(ctx context.Context) error {
+	var auth struct {
+		UserID string `json:"user_id"`
+		User   string `json:"user"`
+		Team   string `json:"team"`
+	}
+	if err := s.api(ctx, "auth.test", nil, &auth); err != nil {
+		return fmt.Errorf("slack connection failed: %w", err)
+	}
+	s.botUserID = auth.UserID
+	logger.Info("slack bot connected", "user", auth.User, "team", auth.Team)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slack/events", s.handleEvent)
+
+	s.server = &http.Server{
+		Addr:              s.webhookAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		logger.Info("slack events listening", "addr", s.webhookAddr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("slack events server error", "err", err)
+		}
+	}()
+
+	// Periodic dedup cache cleanup.
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.cleanupSeen()
+			}
+		}
+	}()
+
+	logger.Info("slack channel started")
+	return nil
+}
+
+// Stop gracefully shuts down the channel.
+func (s *SlackChannel) Stop() error {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	if s.server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("slack server shutdown error", "err", err)
+		}
+	}
+	s.wg.Wait()
+	close(s.queue.messages)
+	logger.Info("slack channel stopped")
+	return nil
+}
+
+// Send posts a response via chat.postMessage.
+// resp.ReplyTo is a Slack channel ID, or a user ID to post in the app DM.
+// resp.ReplyToMessageID is the thread_ts to reply in.
+func (s *SlackChannel) Send(ctx context.Context, resp *Response) error {
+	channelID := strings.TrimSpace(resp.ReplyTo)
+	if channelID == "" {
+		return fmt.Errorf("slack send error: missing channel ID")
+	}
+
+	for _, chunk := range SplitMessage(resp.Text, slackMaxMessageLength) {
+		payload := map[string]string{"channel": channelID, "text": chunk}
+		if resp.ReplyToMessageID != "" {
+			payload["thread_ts"] = resp.ReplyToMessageID
+		}
+		if err := s.api(ctx, "chat.postMessage", payload, nil); err != nil {
+			return fmt.Errorf("slack send error: %w", err)
+		}
+	}
+	return nil
+}
+
+// Messages returns the incoming message channel.
+func (s *SlackChannel) Messages() <-chan *Message {
+	return s.queue.messages
+}
+
+// api calls a Slack Web API method and decodes the result into out.
+func (s *SlackChannel) api(ctx context.Context, method string, body, out any) error {
+	payload := []byte("{}")
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiBase+"/"+method, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.botToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, slackMaxBodySize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", method, resp.Status)
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("%s: %s", method, result.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// slackEnvelope is the outer Events API request body.
+type slackEnvelope struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// slackMessageEvent covers the message and app_mention event types.
+type slackMessageEvent struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+}
+
+// handleEvent processes incoming Events API requests.
+func (s *SlackChannel) handleEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBodySize))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if !s.verifySignature(r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var envelope slackEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		logger.Error("slack event parse error", "err", err)
+		http.Error(w, "parse error", http.StatusBadRequest)
+		return
+	}
+
+	// URL verification challenge.
+	if envelope.Type == "url_verification" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"challenge": envelope.Challenge}); err != nil {
+			logger.Warn("slack challenge response write error", "err", err)
+		}
+		return
+	}
+
+	// Respond 200 immediately (Slack retries if no response within 3s).
+	w.WriteHeader(http.StatusOK)
+
+	if envelope.Type != "event_callback" {
+		return
+	}
+	// Dedup: skip already-seen events.
+	if envelope.EventID != "" && !s.markSeen(envelope.EventID) {
+		return
+	}
+
+	var event slackMessageEvent
+	if err := json.Unmarshal(envelope.Event, &event); err != nil {
+		logger.Warn("slack event payload parse error", "err", err)
+		return
+	}
+	s.processMessageEvent(&event)
+}
+
+// verifySignature checks X-Slack-Signature against the signing secret and
+// rejects stale timestamps to prevent replays.
+func (s *SlackChannel) verifySignature(header http.Header, body []byte, now time.Time) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sig := header.Get("X-Slack-Signature")
+	if ts == "" || sig == "" {
+		return false
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxClockSkew || skew < -slackMaxClockSkew {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(slackSignature(s.signingSecret, ts, body)))
+}
+
+// slackSignature computes the v0 request signature.
+func slackSignature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// processMessageEvent converts a Slack event into a channel Message. Direct
+// messages arrive as "message" events; in channels the bot answers
+// "app_mention" events only, so plain channel chatter is ignored.
+func (s *SlackChannel) processMessageEvent(event *slackMessageEvent) {
+	switch event.Type {
+	case "app_mention":
+	case "message":
+		if event.ChannelType != "im" {
+			return
+		}
+	default:
+		return
+	}
+	if event.Subtype != "" || event.BotID != "" || event.User == "" || event.User == s.botUserID {
+		return
+	}
+
+	if len(s.allowedIDs) > 0 && !s.allowedIDs[event.User] && !s.allowedIDs[event.Channel] {
+		logger.Warn("slack message from unauthorized user", "userID", event.User, "channel", event.Channel)
+		return
+	}
+
+	text := event.Text
+	if s.botUserID != "" {
+		text = strings.ReplaceAll(text, "<@"+s.botUserID+">", "")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	chatType := "group"
+	if event.ChannelType == "im" {
+		chatType = "private"
+	}
+
+	// Replies go into the message's thread, starting one if needed.
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+	metadata := map[string]string{
+		"chat_id":    event.Channel,
+		"chat_type":  chatType,
+		"message_id": threadTS,
+		"ts":         event.TS,
+	}
+	if event.ThreadTS != "" {
+		metadata["thread_ts"] = event.ThreadTS
+	}
+
+	msg := &Message{
+		ID:        event.TS,
+		ChannelID: "slack:" + event.Channel,
+		UserID:    event.User,
+		Username:  event.User, // Events don't carry display names; use the user ID.
+		Text:      text,
+		Metadata:  metadata,
+	}
+	if event.ThreadTS != "" {
+		msg.ReplyTo = event.ThreadTS
+	}
+
+	s.queue.push(msg, s.done)
+}
+
+// markSeen returns true if the eventID is new (first time seen), false if duplicate.
+func (s *SlackChannel) markSeen(eventID string) bool {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	if _, exists := s.seen[eventID]; exists {
+		return false
+	}
+	s.seen[eventID] = time.Now()
+	return true
+}
+
+// cleanupSeen removes expired entries from the dedup cache.
+func (s *SlackChannel) cleanupSeen() {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	cutoff := time.Now().Add(-slackDedupTTL)
+	for id, t := range s.seen {
+		if t.Before(cutoff) {
+			delete(s.seen, id)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSlackChannel(apiBase string) *SlackChannel {
+	return &SlackChannel{
+		botToken:      "xoxb-test",
+		signingSecret: "secret",
+		allowedIDs:    map[string]bool{},
+		apiBase:       apiBase,
+		client:        http.DefaultClient,
+		botUserID:     "UBOT",
+		queue:         newInboundQueue("slack", nil, 10),
+		done:          make(chan struct{}),
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// postSlackEvent signs body like Slack does and sends it to the handler.
+func postSlackEvent(s *SlackChannel, body string, sign bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	if sign {
+		req.Header.Set("X-Slack-Signature", slackSignature(s.signingSecret, ts, []byte(body)))
+	} else {
+		req.Header.Set("X-Slack-Signature", "v0=deadbeef")
+	}
+	rec := httptest.NewRecorder()
+	s.handleEvent(rec, req)
+	return rec
+}
+
+func TestSlackURLVerificationAndSignature(t *testing.T) {
+	s := newTestSlackChannel("")
+	body := `{"type":"url_verification","challenge":"abc123"}`
+
+	if rec := postSlackEvent(s, body, false); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request status = %d, want 401", rec.Code)
+	}
+
+	rec := postSlackEvent(s, body, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp["challenge"] != "abc123" {
+		t.Fatalf("challenge response = %q, want abc123", rec.Body.String())
+	}
+}
+
+func TestSlackEventsDedupedAndMapped(t *testing.T) {
+	s := newTestSlackChannel("")
+	mention := `{"type":"event_callback","event_id":"Ev1","event":{"type":"app_mention","user":"U1",` +
+		`"text":"<@UBOT> deploy status?","channel":"C1","channel_type":"channel","ts":"1700.01","thread_ts":"1700.00"}}`
+	chatter := `{"type":"event_callback","event_id":"Ev2","event":{"type":"message","user":"U1",` +
+		`"text":"just chatting","channel":"C1","channel_type":"channel","ts":"1700.02"}}`
+	dm := `{"type":"event_callback","event_id":"Ev3","event":{"type":"message","user":"U2",` +
+		`"text":"hello","channel":"D1","channel_type":"im","ts":"1700.03"}}`
+
+	for _, body := range []string{mention, mention, chatter, dm} {
+		if rec := postSlackEvent(s, body, true); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+	}
+
+	var got []*Message
+	for len(s.queue.messages) > 0 {
+		got = append(got, <-s.queue.messages)
+	}
+	if len(got) != 2 {
+		t.Fatalf("delivered %d messages, want 2 (retry deduped, chatter ignored)", len(got))
+	}
+	if got[0].Text != "deploy status?" || got[0].Metadata["chat_id"] != "C1" ||
+		got[0].Metadata["thread_ts"] != "1700.00" || got[0].Metadata["message_id"] != "1700.00" {
+		t.Fatalf("mention message = %+v", got[0])
+	}
+	if got[1].ChannelID != "slack:D1" || got[1].Metadata["chat_type"] != "private" {
+		t.Fatalf("dm message = %+v", got[1])
+	}
+}
+
+func TestSlackSendPostsInThread(t *testing.T) {
+	var body map[string]string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" {
+			t.Errorf("path = %s, want /chat.postMessage", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	s := newTestSlackChannel(srv.URL)
+	if err := s.Send(context.Background(), &Response{ReplyTo: "C1", ReplyToMessageID: "1700.00", Text: "all green"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if auth != "Bearer xoxb-test" || body["channel"] != "C1" || body["thread_ts"] != "1700.00" || body["text"] != "all green" {
+		t.Fatalf("postMessage auth=%q body=%v", auth, body)
+	}
+}
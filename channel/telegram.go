@@ -3,8 +3,11 @@ package channel
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/linanwx/nagobot/config"
@@ -16,18 +19,27 @@ const (
 	telegramMessageBufferSize    = 100
 	telegramUpdateTimeoutSeconds = 30
 	TelegramMaxMessageLength     = 4096
+	telegramMaxCaptionLength     = 1024
+	telegramMaxUploadSize        = 50 << 20 // Bot API limit for sendDocument
 )
 
 // TelegramChannel implements the Channel interface for Telegram.
 type TelegramChannel struct {
 	token      string
 	allowedIDs map[int64]bool // Allowed user/chat IDs (nil = allow all)
-	messages   chan *Message
+	queue      *inboundQueue
 	done       chan struct{}
 	wg         sync.WaitGroup
 
-	bot    *tgbotapi.BotAPI
-	offset int
+	bot        *tgbotapi.BotAPI
+	offset     int
+	offsetPath string // persisted polling offset; empty disables persistence
+
+	parseMode      string // Bot API parse mode for replies; empty sends plain text
+	replyThreading string // config.ReplyThreading* mode for quoting the triggering message
+	placeholder    string // text sent while the agent works; empty disables edit-in-place
+	placeholderMu  sync.Mutex
+	placeholders   map[telegramTurn]int // triggering message → pending placeholder message ID
 }
 
 // NewTelegramChannel creates a new Telegram channel from config.
@@ -44,12 +56,25 @@ func NewTelegramChannel(cfg *config.Config) Channel {
 		allowedIDs[id] = true
 	}
 
-	return &TelegramChannel{
+	offsetPath := ""
+	if workspace, err := cfg.WorkspacePath(); err == nil {
+		offsetPath = filepath.Join(workspace, telegramOffsetFileName)
+	}
+
+	ch := &TelegramChannel{
 		token:      token,
 		allowedIDs: allowedIDs,
-		messages:   make(chan *Message, telegramMessageBufferSize),
+		queue:      newInboundQueue("telegram", cfg, telegramMessageBufferSize),
 		done:       make(chan struct{}),
+		offsetPath: offsetPath,
+
+		parseMode:      cfg.GetTelegramParseMode(),
+		replyThreading: cfg.GetReplyThreading(),
+		placeholder:    cfg.GetTelegramPlaceholder(),
+		placeholders:   make(map[telegramTurn]int),
 	}
+	ch.queue.onDrop = ch.notifyBusy
+	return ch
 }
 
 // Name returns the channel name.
@@ -57,6 +82,11 @@ func (t *TelegramChannel) Name() string {
 	return "telegram"
 }
 
+// DroppedMessages returns how many inbound messages were dropped on a full buffer.
+func (t *TelegramChannel) DroppedMessages() int64 {
+	return t.queue.Dropped()
+}
+
 // Start begins polling for updates.
 func (t *TelegramChannel) Start(ctx context.Context) error {
 	bot, err := tgbotapi.NewBotAPI(t.token)
@@ -71,7 +101,9 @@ func (t *TelegramChannel) Start(ctx context.Context) error {
 
 	t.bot = bot
 	logger.Info("telegram bot connected", "username", me.UserName)
-	logger.Info("telegram channel started")
+
+	t.restoreOffset()
+	logger.Info("telegram channel started", "offset", t.offset)
 
 	u := tgbotapi.NewUpdate(t.offset)
 	u.Timeout = telegramUpdateTimeoutSeconds
@@ -83,6 +115,32 @@ func (t *TelegramChannel) Start(ctx context.Context) error {
 	return nil
 }
 
+// restoreOffset loads the persisted polling offset so updates confirmed before
+// a restart are not delivered again.
+func (t *TelegramChannel) restoreOffset() {
+	offset, err := loadTelegramOffset(t.offsetPath)
+	if err != nil {
+		logger.Warn("failed to load telegram offset, starting from pending updates", "path", t.offsetPath, "err", err)
+		return
+	}
+	if offset > t.offset {
+		t.offset = offset
+	}
+}
+
+// ResetOffset clears the persisted polling offset. The next Start resumes
+// from whatever updates Telegram still holds. Call it while the channel is stopped.
+func (t *TelegramChannel) ResetOffset() error {
+	t.offset = 0
+	if t.offsetPath == "" {
+		return nil
+	}
+	if err := os.Remove(t.offsetPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // Stop gracefully shuts down the channel.
 func (t *TelegramChannel) Stop() error {
 	close(t.done)
@@ -90,7 +148,7 @@ func (t *TelegramChannel) Stop() error {
 		t.bot.StopReceivingUpdates()
 	}
 	t.wg.Wait()
-	close(t.messages)
+	close(t.queue.messages)
 	logger.Info("telegram channel stopped")
 	return nil
 }
@@ -109,14 +167,36 @@ func (t *TelegramChannel) Send(ctx context.Context, resp *Response) error {
 	// Split long messages
 	messages := SplitMessage(resp.Text, TelegramMaxMessageLength)
 
-	for _, chunk := range messages {
-		htmlChunk := tgmd.Convert(chunk)
-		msg := tgbotapi.NewMessage(chatID, htmlChunk)
-		msg.ParseMode = tgbotapi.ModeHTML
+	// Replace the placeholder in place when the reply fits one message;
+	// otherwise remove it and send the chunks normally.
+	if placeholderID, ok := t.takePlaceholder(chatID, resp.MessageID); ok {
+		if len(messages) == 1 {
+			err := t.editMessage(chatID, placeholderID, messages[0])
+			if err == nil {
+				return nil
+			}
+			logger.Warn("telegram edit failed, sending a new message", "chatID", chatID, "err", err)
+		}
+		t.deleteMessage(chatID, placeholderID)
+	}
+
+	// Only the first chunk quotes the triggering message.
+	replyTo, _ := strconv.Atoi(resp.ReplyToMessageID)
+	for i, chunk := range messages {
+		if i > 0 {
+			replyTo = 0
+		}
+		msg := tgbotapi.NewMessage(chatID, t.formatText(chunk))
+		msg.ParseMode = t.parseMode
+		setTelegramReply(&msg, replyTo)
 
 		if _, err := t.bot.Send(msg); err != nil {
+			if t.parseMode == "" {
+				return fmt.Errorf("telegram send error: %w", err)
+			}
 			// Retry without formatting using the original markdown text.
 			plainMsg := tgbotapi.NewMessage(chatID, chunk)
+			setTelegramReply(&plainMsg, replyTo)
 			if _, retryErr := t.bot.Send(plainMsg); retryErr != nil {
 				return fmt.Errorf("telegram send error: %w", retryErr)
 			}
@@ -126,15 +206,74 @@ func (t *TelegramChannel) Send(ctx context.Context, resp *Response) error {
 	return nil
 }
 
+// SendFile sends a local file as a document. Captions too long for Telegram
+// are sent as a separate message after the document.
+func (t *TelegramChannel) SendFile(ctx context.Context, path, caption, replyTo string) error {
+	if t.bot == nil {
+		return fmt.Errorf("telegram bot not started")
+	}
+	chatID, err := strconv.ParseInt(replyTo, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > telegramMaxUploadSize {
+		return fmt.Errorf("file exceeds the telegram upload limit of %d bytes", telegramMaxUploadSize)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(path))
+	separateCaption := utf8.RuneCountInString(caption) > telegramMaxCaptionLength
+	if !separateCaption {
+		doc.Caption = caption
+	}
+	if _, err := t.bot.Send(doc); err != nil {
+		return fmt.Errorf("telegram send document error: %w", err)
+	}
+	if separateCaption {
+		return t.Send(ctx, &Response{Text: caption, ReplyTo: replyTo})
+	}
+	return nil
+}
+
+// formatText renders a reply chunk for the configured parse mode.
+func (t *TelegramChannel) formatText(text string) string {
+	switch t.parseMode {
+	case tgbotapi.ModeHTML:
+		return tgmd.Convert(text)
+	case tgbotapi.ModeMarkdownV2:
+		return tgmd.EscapeMarkdownV2(text)
+	default:
+		return text
+	}
+}
+
 // Messages returns the incoming message channel.
 func (t *TelegramChannel) Messages() <-chan *Message {
-	return t.messages
+	return t.queue.messages
 }
 
 // pollUpdates continuously polls for new messages.
 func (t *TelegramChannel) pollUpdates(ctx context.Context, updates tgbotapi.UpdatesChannel) {
 	defer t.wg.Done()
 
+	// The offset is saved once per batch: when no further update is waiting,
+	// and on the way out.
+	saved := t.offset
+	saveOffset := func() {
+		if t.offset == saved {
+			return
+		}
+		if err := saveTelegramOffset(t.offsetPath, t.offset); err != nil {
+			logger.Warn("failed to persist telegram offset", "path", t.offsetPath, "err", err)
+			return
+		}
+		saved = t.offset
+	}
+	defer saveOffset()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -145,10 +284,15 @@ func (t *TelegramChannel) pollUpdates(ctx context.Context, updates tgbotapi.Upda
 			if !ok {
 				return
 			}
-			if update.UpdateID >= t.offset {
-				t.offset = update.UpdateID + 1
+			if update.UpdateID < t.offset {
+				// Already confirmed before a restart.
+				continue
 			}
 			t.processUpdate(update)
+			t.offset = update.UpdateID + 1
+			if len(updates) == 0 {
+				saveOffset()
+			}
 		}
 	}
 }
@@ -0,0 +1,153 @@
+package channel
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/linanwx/nagobot/logger"
+)
+
+// telegramMaxRetryAfter caps how long an edit waits on a 429 before giving up.
+const telegramMaxRetryAfter = 30 * time.Second
+
+// telegramTurn identifies the inbound message a placeholder stands in for.
+type telegramTurn struct {
+	chatID    int64
+	messageID int
+}
+
+// sendPlaceholder posts the placeholder for messageID in chatID and remembers
+// it so the reply to that message can later replace it in place. A non-zero
+// replyTo quotes the triggering message, which the edited reply inherits.
+func (t *TelegramChannel) sendPlaceholder(chatID int64, messageID, replyTo int) {
+	if t.bot == nil || t.placeholder == "" {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, t.placeholder)
+	setTelegramReply(&msg, replyTo)
+	sent, err := t.bot.Send(msg)
+	if err != nil {
+		logger.Warn("failed to send telegram placeholder", "chatID", chatID, "err", err)
+		return
+	}
+
+	t.placeholderMu.Lock()
+	t.placeholders[telegramTurn{chatID, messageID}] = sent.MessageID
+	t.placeholderMu.Unlock()
+}
+
+// takePlaceholder removes and returns the pending placeholder for the
+// triggering message messageID. Replies without one never take a placeholder.
+func (t *TelegramChannel) takePlaceholder(chatID int64, messageID string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimSpace(messageID))
+	if err != nil {
+		return 0, false
+	}
+	key := telegramTurn{chatID, id}
+	t.placeholderMu.Lock()
+	defer t.placeholderMu.Unlock()
+	placeholderID, ok := t.placeholders[key]
+	if ok {
+		delete(t.placeholders, key)
+	}
+	return placeholderID, ok
+}
+
+// FinishTurn deletes the placeholder of a turn that ended without a reply.
+func (t *TelegramChannel) FinishTurn(replyTo, messageID string) {
+	chatID, err := strconv.ParseInt(replyTo, 10, 64)
+	if err != nil || t.bot == nil {
+		return
+	}
+	if placeholderID, ok := t.takePlaceholder(chatID, messageID); ok {
+		t.deleteMessage(chatID, placeholderID)
+	}
+}
+
+// editMessage replaces the text of an existing message. It honours Telegram's
+// retry_after on rate limiting (once, aborted on shutdown) and falls back to plain
+// text when the formatted version is rejected.
+func (t *TelegramChannel) editMessage(chatID int64, messageID int, text string) error {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, t.formatText(text))
+	edit.ParseMode = t.parseMode
+
+	err := t.requestWithRetryAfter(edit)
+	if err == nil || isTelegramNotModified(err) {
+		return nil
+	}
+	if t.parseMode == "" || telegramRetryAfter(err) > 0 {
+		return err
+	}
+
+	plain := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	if err := t.requestWithRetryAfter(plain); err != nil && !isTelegramNotModified(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteMessage removes a message, ignoring failures (best effort).
+func (t *TelegramChannel) deleteMessage(chatID int64, messageID int) {
+	if _, err := t.bot.Request(tgbotapi.NewDeleteMessage(chatID, messageID)); err != nil {
+		logger.Debug("failed to delete telegram placeholder", "chatID", chatID, "messageID", messageID, "err", err)
+	}
+}
+
+func (t *TelegramChannel) requestWithRetryAfter(c tgbotapi.Chattable) error {
+	_, err := t.bot.Request(c)
+	wait := telegramRetryAfter(err)
+	if wait <= 0 {
+		return err
+	}
+	if wait > telegramMaxRetryAfter {
+		return err
+	}
+
+	logger.Debug("telegram rate limited, retrying edit", "retryAfter", wait)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-t.done:
+		return err
+	case <-timer.C:
+	}
+	_, err = t.bot.Request(c)
+	return err
+}
+
+// notifyBusy tells the sender their message was dropped. It posts directly
+// rather than through Send so pending placeholders of accepted messages in the
+// same chat are left alone.
+func (t *TelegramChannel) notifyBusy(msg *Message) {
+	chatID, err := strconv.ParseInt(msg.Metadata["chat_id"], 10, 64)
+	if err != nil || t.bot == nil {
+		return
+	}
+	if _, err := t.bot.Send(tgbotapi.NewMessage(chatID, busyNotice)); err != nil {
+		logger.Warn("failed to send busy notice", "channel", t.Name(), "chatID", chatID, "err", err)
+	}
+}
+
+// setTelegramReply quotes messageID when it is set. Sending still succeeds if
+// the quoted message has been deleted meanwhile.
+func setTelegramReply(msg *tgbotapi.MessageConfig, messageID int) {
+	if messageID > 0 {
+		msg.ReplyToMessageID = messageID
+		msg.AllowSendingWithoutReply = true
+	}
+}
+
+func telegramRetryAfter(err error) time.Duration {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second
+	}
+	return 0
+}
+
+func isTelegramNotModified(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message is not modified")
+}
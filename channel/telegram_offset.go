@@ -0,0 +1,77 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const telegramOffsetFileName = "telegram_offset.json"
+
+type telegramOffsetState struct {
+	Offset int `json:"offset"`
+}
+
+// loadTelegramOffset reads the persisted polling offset. A missing file yields 0.
+func loadTelegramOffset(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var state telegramOffsetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, err
+	}
+	return state.Offset, nil
+}
+
+// saveTelegramOffset writes the polling offset atomically: a synced temp file
+// in the same directory is renamed over the old one.
+func saveTelegramOffset(path string, offset int) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(telegramOffsetState{Offset: offset})
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".telegram-offset-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0o644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package channel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newTestTelegramChannel(offsetPath string) *TelegramChannel {
+	return &TelegramChannel{
+		queue:      newInboundQueue("telegram", nil, 10),
+		done:       make(chan struct{}),
+		offsetPath: offsetPath,
+	}
+}
+
+func testTelegramUpdate(id int, text string) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: id,
+		Message: &tgbotapi.Message{
+			MessageID: id,
+			Text:      text,
+			Chat:      &tgbotapi.Chat{ID: 42, Type: "private"},
+			From:      &tgbotapi.User{ID: 42, UserName: "tester"},
+		},
+	}
+}
+
+// deliver runs pollUpdates over the given updates and returns the texts queued.
+func deliver(t *testing.T, ch *TelegramChannel, updates ...tgbotapi.Update) []string {
+	t.Helper()
+	src := make(chan tgbotapi.Update, len(updates))
+	for _, u := range updates {
+		src <- u
+	}
+	close(src)
+
+	ch.wg.Add(1)
+	ch.pollUpdates(context.Background(), src)
+
+	var texts []string
+	for len(ch.queue.messages) > 0 {
+		texts = append(texts, (<-ch.queue.messages).Text)
+	}
+	return texts
+}
+
+func TestTelegramOffsetSurvivesRestart(t *testing.T) {
+	offsetPath := filepath.Join(t.TempDir(), telegramOffsetFileName)
+
+	first := newTestTelegramChannel(offsetPath)
+	first.restoreOffset()
+	if got := deliver(t, first, testTelegramUpdate(5, "a"), testTelegramUpdate(6, "b")); len(got) != 2 {
+		t.Fatalf("first run delivered %v, want 2 messages", got)
+	}
+
+	saved, err := loadTelegramOffset(offsetPath)
+	if err != nil {
+		t.Fatalf("loadTelegramOffset() error = %v", err)
+	}
+	if saved != 7 {
+		t.Fatalf("persisted offset = %d, want 7", saved)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(offsetPath)); len(entries) != 1 {
+		t.Fatalf("offset dir holds %d entries, want only the offset file", len(entries))
+	}
+
+	// After a restart Telegram may hand back already-confirmed updates.
+	second := newTestTelegramChannel(offsetPath)
+	second.restoreOffset()
+	if second.offset != 7 {
+		t.Fatalf("restored offset = %d, want 7", second.offset)
+	}
+	got := deliver(t, second, testTelegramUpdate(5, "a"), testTelegramUpdate(6, "b"), testTelegramUpdate(7, "c"))
+	if len(got) != 1 || got[0] != "c" {
+		t.Fatalf("second run delivered %v, want only [c]", got)
+	}
+
+	if err := second.ResetOffset(); err != nil {
+		t.Fatalf("ResetOffset() error = %v", err)
+	}
+	if offset, _ := loadTelegramOffset(offsetPath); offset != 0 || second.offset != 0 {
+		t.Fatalf("offset after reset = file %d, memory %d; want 0", offset, second.offset)
+	}
+}
+
+func TestTelegramOffsetSavedAfterEachBatch(t *testing.T) {
+	offsetPath := filepath.Join(t.TempDir(), telegramOffsetFileName)
+	ch := newTestTelegramChannel(offsetPath)
+	src := make(chan tgbotapi.Update, 3)
+	src <- testTelegramUpdate(3, "a")
+	src <- testTelegramUpdate(4, "b")
+
+	ch.wg.Add(1)
+	go ch.pollUpdates(context.Background(), src)
+	defer func() {
+		close(ch.done)
+		ch.wg.Wait()
+	}()
+
+	// Polling is still running: the batch is saved once it is drained.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if offset, _ := loadTelegramOffset(offsetPath); offset == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("offset not saved after the batch was drained")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// fakeTelegramAPI records Bot API calls and answers them successfully.
+type fakeTelegramAPI struct {
+	mu    sync.Mutex
+	calls []string     // "method message_id"
+	forms []url.Values // request parameters, parallel to calls
+}
+
+func (f *fakeTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseMultipartForm(1 << 20) // also parses plain forms
+	method := path.Base(r.URL.Path)
+	f.mu.Lock()
+	f.calls = append(f.calls, strings.TrimSpace(method+" "+r.FormValue("message_id")))
+	f.forms = append(f.forms, r.Form)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch method {
+	case "getMe":
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"username":"testbot"}}`))
+	case "deleteMessage":
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	default:
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":77,"chat":{"id":42,"type":"private"}}}`))
+	}
+}
+
+func (f *fakeTelegramAPI) methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// newFakeTelegramBot points a TelegramChannel at a fake Bot API server.
+func newFakeTelegramBot(t *testing.T, ch *TelegramChannel) *fakeTelegramAPI {
+	t.Helper()
+	api := &fakeTelegramAPI{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("NewBotAPIWithAPIEndpoint() error = %v", err)
+	}
+	ch.bot = bot
+	return api
+}
+
+func TestTelegramSendEditsPlaceholder(t *testing.T) {
+	ch := newTestTelegramChannel("")
+	api := newFakeTelegramBot(t, ch)
+	ch.placeholder = "typing…"
+	ch.placeholders = make(map[telegramTurn]int)
+
+	if got := deliver(t, ch, testTelegramUpdate(1, "hi")); len(got) != 1 {
+		t.Fatalf("delivered %v, want 1 message", got)
+	}
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "42", MessageID: "1", Text: "hello"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// Single chunk: the placeholder is edited, not followed by a new message.
+	want := []string{"getMe", "sendMessage", "editMessageText 77"}
+	if got := api.methods(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("API calls = %v, want %v", got, want)
+	}
+
+	// Multi-chunk: the placeholder is deleted and chunks are sent normally.
+	deliver(t, ch, testTelegramUpdate(2, "more"))
+	long := strings.Repeat("word ", TelegramMaxMessageLength/2)
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "42", MessageID: "2", Text: long}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got := api.methods()[len(want):]
+	if len(got) < 4 || got[0] != "sendMessage" || got[1] != "deleteMessage 77" || got[2] != "sendMessage" {
+		t.Fatalf("API calls for long reply = %v, want placeholder, delete, then chunks", got)
+	}
+}
+
+func TestTelegramPlaceholderBelongsToItsTurn(t *testing.T) {
+	ch := newTestTelegramChannel("")
+	api := newFakeTelegramBot(t, ch)
+	ch.placeholder = "typing…"
+	ch.placeholders = make(map[telegramTurn]int)
+
+	deliver(t, ch, testTelegramUpdate(1, "hi"))
+	// A message for the same chat that is not the turn's reply (a cron
+	// notice, another turn) is sent as new and leaves the placeholder.
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "42", Text: "reminder"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "42", MessageID: "5", Text: "other"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	want := []string{"getMe", "sendMessage", "sendMessage", "sendMessage"}
+	if got := api.methods(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("API calls = %v, want %v", got, want)
+	}
+
+	// The turn ends without a reply: its placeholder is removed.
+	ch.FinishTurn("42", "1")
+	ch.FinishTurn("42", "1")
+	want = append(want, "deleteMessage 77")
+	if got := api.methods(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("API calls after FinishTurn = %v, want %v", got, want)
+	}
+}
+
+func TestTelegramSendQuotesTriggeringMessage(t *testing.T) {
+	ch := newTestTelegramChannel("")
+	api := newFakeTelegramBot(t, ch)
+
+	long := strings.Repeat("word ", TelegramMaxMessageLength/2)
+	if err := ch.Send(context.Background(), &Response{ReplyTo: "42", ReplyToMessageID: "9", Text: long}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	api.mu.Lock()
+	forms := api.forms[1:] // skip getMe
+	api.mu.Unlock()
+	if len(forms) < 2 {
+		t.Fatalf("sent %d messages, want at least 2 chunks", len(forms))
+	}
+	if got := forms[0].Get("reply_to_message_id"); got != "9" {
+		t.Fatalf("first chunk reply_to_message_id = %q, want 9", got)
+	}
+	if got := forms[1].Get("reply_to_message_id"); got != "" {
+		t.Fatalf("second chunk reply_to_message_id = %q, want none", got)
+	}
+}
+
+func TestTelegramSendFileUploadsDocument(t *testing.T) {
+	ch := newTestTelegramChannel("")
+	api := newFakeTelegramBot(t, ch)
+	file := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(file, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ch.SendFile(context.Background(), file, "weekly report", "42"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+	long := strings.Repeat("x", telegramMaxCaptionLength+1)
+	if err := ch.SendFile(context.Background(), file, long, "42"); err != nil {
+		t.Fatalf("SendFile() error = %v", err)
+	}
+
+	want := []string{"getMe", "sendDocument", "sendDocument", "sendMessage"}
+	if got := api.methods(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("API calls = %v, want %v", got, want)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if got := api.forms[1].Get("caption"); got != "weekly report" {
+		t.Errorf("caption = %q, want weekly report", got)
+	}
+	if got := api.forms[2].Get("caption"); got != "" {
+		t.Errorf("oversized caption sent with document: %d chars", len(got))
+	}
+	if got := api.forms[3].Get("text"); got != long {
+		t.Errorf("separate caption message has %d chars, want %d", len(got), len(long))
+	}
+}
+
+func TestShouldThreadReply(t *testing.T) {
+	cases := []struct {
+		mode, chatType string
+		want           bool
+	}{
+		{"group", "supergroup", true},
+		{"group", "group", true},
+		{"group", "private", false},
+		{"group", "p2p", false},
+		{"always", "private", true},
+		{"off", "group", false},
+	}
+	for _, tc := range cases {
+		if got := ShouldThreadReply(tc.mode, tc.chatType); got != tc.want {
+			t.Errorf("ShouldThreadReply(%q, %q) = %v, want %v", tc.mode, tc.chatType, got, tc.want)
+		}
+	}
+}
+
+func TestTelegramFullBufferSendsBusyNotice(t *testing.T) {
+	ch := newTestTelegramChannel("")
+	api := newFakeTelegramBot(t, ch)
+	ch.queue = newInboundQueue("telegram", nil, 1)
+	ch.queue.blockTimeout = 10 * time.Millisecond
+	ch.queue.onDrop = ch.notifyBusy
+
+	got := deliver(t, ch, testTelegramUpdate(1, "first"), testTelegramUpdate(2, "second"))
+	if len(got) != 1 || got[0] != "first" {
+		t.Fatalf("delivered %v, want only [first]", got)
+	}
+	if ch.DroppedMessages() != 1 {
+		t.Fatalf("DroppedMessages() = %d, want 1", ch.DroppedMessages())
+	}
+
+	// The notice goes out in the background.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		api.mu.Lock()
+		last := api.forms[len(api.forms)-1]
+		method := api.calls[len(api.calls)-1]
+		api.mu.Unlock()
+		if method == "sendMessage" && last.Get("text") == busyNotice && last.Get("chat_id") == "42" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("last API call = %s %v, want busy notice to chat 42", method, last)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
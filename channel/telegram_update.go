@@ -48,6 +48,7 @@ func (t *TelegramChannel) processUpdate(update tgbotapi.Update) {
 	metadata := map[string]string{
 		"chat_id":    strconv.FormatInt(chat.ID, 10),
 		"chat_type":  chat.Type,
+		"message_id": strconv.Itoa(msg.MessageID),
 		"first_name": firstName,
 		"last_name":  lastName,
 	}
@@ -159,10 +160,12 @@ func (t *TelegramChannel) processUpdate(update tgbotapi.Update) {
 		channelMsg.ReplyTo = strconv.Itoa(msg.ReplyToMessage.MessageID)
 	}
 
-	select {
-	case t.messages <- channelMsg:
-	default:
-		logger.Warn("telegram message buffer full, dropping message")
+	if t.queue.push(channelMsg, t.done) {
+		replyTo := 0
+		if ShouldThreadReply(t.replyThreading, chat.Type) {
+			replyTo = msg.MessageID
+		}
+		t.sendPlaceholder(chat.ID, msg.MessageID, replyTo)
 	}
 }
 
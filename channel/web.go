@@ -9,8 +9,7 @@ import (
 	"io/fs"
 	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,15 +18,22 @@ import (
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
 	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/internal/health"
 	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/session"
 )
 
+// WebStreamDelta is the Response.Metadata["stream"] value marking a partial
+// chunk; the web client appends deltas until the final "response" arrives.
+const WebStreamDelta = "delta"
+
 const (
 	webMainSessionID     = "main"
 	webMessageBufferSize = 100
 	webDefaultAddr       = "127.0.0.1:8080"
 	webShutdownTimeout   = 5 * time.Second
-	sessionsDirName      = "sessions"
+	webPingInterval      = 30 * time.Second
+	webIdleTimeout       = 90 * time.Second
 )
 
 //go:embed web/dist/*
@@ -35,12 +41,18 @@ var rawFrontendFS embed.FS
 
 // WebChannel implements the Channel interface for browser chat.
 type WebChannel struct {
-	addr      string
-	workspace string
-	messages  chan *Message
-	done      chan struct{}
-	wg        sync.WaitGroup
-	server    *http.Server
+	addr        string
+	sessionsDir string        // reported in health output
+	sessions    session.Store // the thread manager's store, for history and listing
+	messages    chan *Message
+	done        chan struct{}
+	wg          sync.WaitGroup
+	server      *http.Server
+
+	pingInterval   time.Duration // 0 disables keepalive
+	idleTimeout    time.Duration
+	allowedOrigins []string // cross-origin callers; "*" allows any
+	healthOpts     health.Options
 
 	mu      sync.RWMutex
 	clients map[string]*wsClient
@@ -66,8 +78,9 @@ type webOutboundMessage struct {
 	Error string `json:"error,omitempty"`
 }
 
-// NewWebChannel creates a new web channel from config.
-func NewWebChannel(cfg *config.Config) Channel {
+// NewWebChannel creates a new web channel from config. sessions backs the
+// history and session list endpoints; it may be nil when sessions are disabled.
+func NewWebChannel(cfg *config.Config, sessions session.Store) Channel {
 	addr := cfg.GetWebAddr()
 	if addr == "" {
 		addr = webDefaultAddr
@@ -77,14 +90,56 @@ func NewWebChannel(cfg *config.Config) Channel {
 		logger.Warn("web channel: failed to get workspace path", "err", err)
 	}
 
+	pingInterval, idleTimeout := webKeepalive(cfg)
+	sessionsDir, _ := cfg.SessionsDir()
+
 	return &WebChannel{
-		addr:      addr,
-		workspace: workspace,
-		messages:  make(chan *Message, webMessageBufferSize),
-		done:      make(chan struct{}),
-		clients:   make(map[string]*wsClient),
-		peers:     make(map[*wsClient]struct{}),
+		addr:           addr,
+		sessionsDir:    sessionsDir,
+		sessions:       sessions,
+		messages:       make(chan *Message, webBufferSize(cfg)),
+		done:           make(chan struct{}),
+		clients:        make(map[string]*wsClient),
+		peers:          make(map[*wsClient]struct{}),
+		pingInterval:   pingInterval,
+		idleTimeout:    idleTimeout,
+		allowedOrigins: cfg.GetWebAllowedOrigins(),
+		healthOpts: health.Options{
+			Workspace:    workspace,
+			SessionsRoot: sessionsDir,
+			Provider:     cfg.Thread.Provider,
+			Model:        cfg.GetModelName(),
+		},
+	}
+}
+
+// webKeepalive resolves the ping interval and idle timeout. A negative ping
+// interval disables both; the idle timeout is never shorter than two pings.
+func webKeepalive(cfg *config.Config) (time.Duration, time.Duration) {
+	pingSec, idleSec := cfg.GetWebKeepalive()
+	if pingSec < 0 {
+		return 0, 0
+	}
+	ping, idle := webPingInterval, webIdleTimeout
+	if pingSec > 0 {
+		ping = time.Duration(pingSec) * time.Second
 	}
+	if idleSec > 0 {
+		idle = time.Duration(idleSec) * time.Second
+	}
+	if idle < 2*ping {
+		idle = 2 * ping
+	}
+	return ping, idle
+}
+
+// webBufferSize returns the configured inbound buffer size. The web channel
+// always applies backpressure: each connection blocks its own reader when full.
+func webBufferSize(cfg *config.Config) int {
+	if size := cfg.GetChannelMessageBufferSize(); size > 0 {
+		return size
+	}
+	return webMessageBufferSize
 }
 
 // Name returns the channel name.
@@ -99,7 +154,9 @@ func (w *WebChannel) Start(ctx context.Context) error {
 
 	mux := http.NewServeMux()
 	mux.Handle("/ws", http.HandlerFunc(w.handleWS))
-	mux.Handle("/api/history", http.HandlerFunc(w.handleHistory))
+	mux.Handle("/api/history", w.withCORS(w.handleHistory))
+	mux.Handle("/api/sessions", w.withCORS(w.handleSessions))
+	mux.Handle("/api/health", w.withCORS(w.handleHealth))
 	mux.Handle("/", http.FileServer(http.FS(frontendFS)))
 
 	w.server = &http.Server{
@@ -114,6 +171,10 @@ func (w *WebChannel) Start(ctx context.Context) error {
 
 	bindAddr := ln.Addr().String()
 	logger.Info("web channel started", "addr", bindAddr, "url", webURLHintFromAddr(bindAddr))
+	if !webLoopbackAddr(bindAddr) {
+		logger.Warn("web channel is reachable from the network and has no authentication; anyone who can reach this address can chat with the agent",
+			"addr", bindAddr)
+	}
 
 	w.wg.Add(1)
 	go func() {
@@ -183,6 +244,9 @@ func (w *WebChannel) Send(ctx context.Context, resp *Response) error {
 		Type: "response",
 		Text: resp.Text,
 	}
+	if resp.Metadata["stream"] == WebStreamDelta {
+		payload.Type = WebStreamDelta
+	}
 
 	client.mu.Lock()
 	defer client.mu.Unlock()
@@ -196,28 +260,49 @@ func (w *WebChannel) Send(ctx context.Context, resp *Response) error {
 func (w *WebChannel) Messages() <-chan *Message { return w.messages }
 
 func (w *WebChannel) handleWS(rw http.ResponseWriter, r *http.Request) {
-	conn, err := websocket.Accept(rw, r, nil)
+	conn, err := websocket.Accept(rw, r, w.acceptOptions())
 	if err != nil {
 		return
 	}
 
+	// The connection starts on ?session= (default main) and follows the
+	// session_id of each inbound message.
+	sessionID := sanitizeSessionID(r.URL.Query().Get("session"))
+	if sessionID == "" {
+		sessionID = webMainSessionID
+	}
+
+	remoteAddr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+
 	client := &wsClient{conn: conn}
 	w.registerPeer(client)
-	w.bindClient(webMainSessionID, client)
+	w.bindClient(sessionID, client)
 
 	w.wg.Add(1)
 	defer w.wg.Done()
 	defer func() {
 		w.unregisterPeer(client)
-		w.unbindClient(webMainSessionID, client)
+		w.unbindClient(sessionID, client)
 		_ = conn.Close(websocket.StatusNormalClosure, "")
 	}()
 
+	var lastActive atomic.Int64
+	lastActive.Store(time.Now().UnixNano())
+	if w.pingInterval > 0 {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go w.keepalive(ctx, conn, &lastActive)
+	}
+
 	for {
 		var req webInboundMessage
 		if err := wsjson.Read(r.Context(), conn, &req); err != nil {
 			return
 		}
+		lastActive.Store(time.Now().UnixNano())
 
 		reqType := strings.TrimSpace(req.Type)
 		if reqType == "" {
@@ -228,6 +313,19 @@ func (w *WebChannel) handleWS(rw http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		if raw := strings.TrimSpace(req.SessionID); raw != "" {
+			requested := sanitizeSessionID(raw)
+			if requested == "" {
+				_ = wsjson.Write(r.Context(), conn, webOutboundMessage{Type: "error", Error: "invalid session id"})
+				continue
+			}
+			if requested != sessionID {
+				w.unbindClient(sessionID, client)
+				sessionID = requested
+				w.bindClient(sessionID, client)
+			}
+		}
+
 		text := strings.TrimSpace(req.Text)
 		if text == "" {
 			continue
@@ -235,12 +333,13 @@ func (w *WebChannel) handleWS(rw http.ResponseWriter, r *http.Request) {
 
 		msg := &Message{
 			ID:        fmt.Sprintf("web-%d", atomic.AddInt64(&w.msgID, 1)),
-			ChannelID: "web:main",
-			UserID:    webMainSessionID,
+			ChannelID: "web:" + sessionID,
+			UserID:    sessionID,
 			Username:  "web-user",
 			Text:      text,
 			Metadata: map[string]string{
-				"chat_id": webMainSessionID,
+				"chat_id":     sessionID,
+				"remote_addr": remoteAddr,
 			},
 		}
 
@@ -254,6 +353,34 @@ func (w *WebChannel) handleWS(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// keepalive pings the connection every pingInterval. A pong or an inbound
+// message counts as activity; after idleTimeout without either the connection
+// is closed, which ends the read loop in handleWS and unregisters the client.
+func (w *WebChannel) keepalive(ctx context.Context, conn *websocket.Conn, lastActive *atomic.Int64) {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, w.pingInterval)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			lastActive.Store(time.Now().UnixNano())
+		}
+		if idle := time.Since(time.Unix(0, lastActive.Load())); idle >= w.idleTimeout {
+			// The peer is unresponsive, so skip the close handshake.
+			logger.Info("web channel closing idle connection", "idle", idle.Round(time.Second))
+			_ = conn.CloseNow()
+			return
+		}
+	}
+}
+
 func (w *WebChannel) registerPeer(client *wsClient) {
 	w.mu.Lock()
 	w.peers[client] = struct{}{}
@@ -286,6 +413,25 @@ func (w *WebChannel) unbindClient(sessionID string, client *wsClient) {
 	}
 }
 
+// handleHealth returns the process health snapshot plus the number of
+// connected websocket clients.
+func (w *WebChannel) handleHealth(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.mu.RLock()
+	clients := len(w.peers)
+	w.mu.RUnlock()
+
+	opts := w.healthOpts
+	opts.Channels = &health.ChannelsInfo{Web: &health.WebInfo{Addr: w.addr, Clients: clients}}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(health.Collect(opts)); err != nil {
+		logger.Warn("web health encode error", "err", err)
+	}
+}
+
 func sanitizeSessionID(raw string) string {
 	s := strings.TrimSpace(raw)
 	if s == "" || len(s) > 128 {
@@ -322,6 +468,65 @@ func webURLHintFromAddr(addr string) string {
 	return fmt.Sprintf("http://%s:%s", host, port)
 }
 
+// webLoopbackAddr reports whether a listen address only accepts local
+// connections. Wildcard hosts, which webURLHintFromAddr maps to 127.0.0.1,
+// are not loopback.
+func webLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(addr))
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowsOrigin reports whether origin may call the web channel cross-origin.
+func (w *WebChannel) allowsOrigin(origin string) bool {
+	for _, allowed := range w.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCORS adds CORS headers for allowed origins and answers preflight
+// requests. Other origins get no CORS headers, so browsers block them.
+func (w *WebChannel) withCORS(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && w.allowsOrigin(origin) {
+			h := rw.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Content-Type")
+			h.Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(rw, r)
+	}
+}
+
+// acceptOptions lets allowed origins open the WebSocket too; same-host
+// connections are always accepted.
+func (w *WebChannel) acceptOptions() *websocket.AcceptOptions {
+	if len(w.allowedOrigins) == 0 {
+		return nil
+	}
+	for _, origin := range w.allowedOrigins {
+		if origin == "*" {
+			return &websocket.AcceptOptions{InsecureSkipVerify: true}
+		}
+	}
+	return &websocket.AcceptOptions{OriginPatterns: w.allowedOrigins}
+}
+
 type webHistoryEnvelope struct {
 	SessionID  string              `json:"session_id"`
 	SessionKey string              `json:"session_key"`
@@ -333,17 +538,21 @@ type webHistoryMessage struct {
 	Content string `json:"content"`
 }
 
-type sessionFileEnvelope struct {
-	Messages []sessionFileMessage `json:"messages"`
-}
-
-type sessionFileMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+type webSessionsEnvelope struct {
+	Sessions []string `json:"sessions"`
+	Default  string   `json:"default"`
 }
 
 func (w *WebChannel) handleHistory(rw http.ResponseWriter, r *http.Request) {
-	history, err := w.loadHistory()
+	sessionID := webMainSessionID
+	if raw := strings.TrimSpace(r.URL.Query().Get("session")); raw != "" {
+		if sessionID = sanitizeSessionID(raw); sessionID == "" {
+			http.Error(rw, "invalid session id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	history, err := w.loadHistory(sessionID)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("failed to load history: %v", err), http.StatusInternalServerError)
 		return
@@ -351,30 +560,72 @@ func (w *WebChannel) handleHistory(rw http.ResponseWriter, r *http.Request) {
 
 	rw.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(rw).Encode(webHistoryEnvelope{
-		SessionID:  webMainSessionID,
-		SessionKey: webMainSessionID,
+		SessionID:  sessionID,
+		SessionKey: WebSessionKey(sessionID),
 		Messages:   history,
 	})
 }
 
-func (w *WebChannel) loadHistory() ([]webHistoryMessage, error) {
-	if w.workspace == "" {
-		return nil, fmt.Errorf("workspace is not configured")
+func (w *WebChannel) handleSessions(rw http.ResponseWriter, r *http.Request) {
+	sessions, err := w.listSessions()
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to list sessions: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	path := filepath.Join(w.workspace, sessionsDirName, "main", "session.json")
-	data, err := os.ReadFile(path)
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(webSessionsEnvelope{
+		Sessions: sessions,
+		Default:  webMainSessionID,
+	})
+}
+
+// listSessions returns the web-addressable session IDs: main, plus every
+// stored web:<id> session.
+func (w *WebChannel) listSessions() ([]string, error) {
+	if w.sessions == nil {
+		return nil, fmt.Errorf("session store is not configured")
+	}
+	keys, err := w.sessions.List()
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return []webHistoryMessage{}, nil
-		}
 		return nil, err
 	}
 
-	var src sessionFileEnvelope
-	if err := json.Unmarshal(data, &src); err != nil {
+	sessions := []string{webMainSessionID}
+	for _, key := range keys {
+		raw, ok := strings.CutPrefix(key, "web:")
+		if !ok {
+			continue
+		}
+		if id := sanitizeSessionID(raw); id == raw && id != "" && id != webMainSessionID {
+			sessions = append(sessions, id)
+		}
+	}
+	sort.Strings(sessions[1:])
+	return sessions, nil
+}
+
+// WebSessionKey maps a web session ID to its thread session key. The main
+// web session shares the admin "main" session.
+func WebSessionKey(sessionID string) string {
+	if sessionID == "" || sessionID == webMainSessionID {
+		return webMainSessionID
+	}
+	return "web:" + sessionID
+}
+
+func (w *WebChannel) loadHistory(sessionID string) ([]webHistoryMessage, error) {
+	if w.sessions == nil {
+		return nil, fmt.Errorf("session store is not configured")
+	}
+
+	src, found, err := w.sessions.Load(WebSessionKey(sessionID))
+	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return []webHistoryMessage{}, nil
+	}
 
 	out := make([]webHistoryMessage, 0, len(src.Messages))
 	for _, m := range src.Messages {
@@ -0,0 +1,354 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/linanwx/nagobot/internal/health"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+func newTestWebChannel(sessions session.Store) *WebChannel {
+	return &WebChannel{
+		sessions: sessions,
+		messages: make(chan *Message, 10),
+		done:     make(chan struct{}),
+		clients:  make(map[string]*wsClient),
+		peers:    make(map[*wsClient]struct{}),
+	}
+}
+
+func writeTestSession(t *testing.T, sessionsDir string, parts ...string) {
+	t.Helper()
+	dir := filepath.Join(append([]string{sessionsDir}, parts...)...)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "session.json"), []byte(`{"messages":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebListSessions(t *testing.T) {
+	sessionsDir := t.TempDir()
+	writeTestSession(t, sessionsDir, "main")
+	writeTestSession(t, sessionsDir, "web", "work")
+	writeTestSession(t, sessionsDir, "web", "notes")
+	writeTestSession(t, sessionsDir, "telegram", "42")
+	if err := os.MkdirAll(filepath.Join(sessionsDir, "web", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := session.NewFileStore(sessionsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	newTestWebChannel(store).handleSessions(rec, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var got webSessionsEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Join(got.Sessions, ",") != "main,notes,work" || got.Default != "main" {
+		t.Fatalf("sessions = %+v, want [main notes work] with default main", got)
+	}
+}
+
+func TestWebRoutesMessageToNamedSession(t *testing.T) {
+	w := newTestWebChannel(nil)
+	srv := httptest.NewServer(http.HandlerFunc(w.handleWS))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	if err := wsjson.Write(ctx, conn, webInboundMessage{Type: "message", SessionID: "work", Text: "hello"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var msg *Message
+	select {
+	case msg = <-w.Messages():
+	case <-ctx.Done():
+		t.Fatal("no inbound message")
+	}
+	if msg.ChannelID != "web:work" || msg.Metadata["chat_id"] != "work" {
+		t.Fatalf("message routed to %q (chat_id %q), want web:work", msg.ChannelID, msg.Metadata["chat_id"])
+	}
+
+	if err := w.Send(ctx, &Response{ReplyTo: msg.Metadata["chat_id"], Text: "reply"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	var out webOutboundMessage
+	if err := wsjson.Read(ctx, conn, &out); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if out.Type != "response" || out.Text != "reply" {
+		t.Fatalf("client received %+v, want the reply", out)
+	}
+
+	// The connection moved off main.
+	if err := w.Send(ctx, &Response{ReplyTo: "main", Text: "x"}); err == nil {
+		t.Fatal("Send() to main succeeded, want not connected")
+	}
+}
+
+func TestWebSendStreamsDeltas(t *testing.T) {
+	w := newTestWebChannel(nil)
+	srv := httptest.NewServer(http.HandlerFunc(w.handleWS))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	if err := wsjson.Write(ctx, conn, webInboundMessage{Type: "message", Text: "hello"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case <-w.Messages():
+	case <-ctx.Done():
+		t.Fatal("no inbound message")
+	}
+
+	delta := map[string]string{"stream": WebStreamDelta}
+	for _, resp := range []*Response{
+		{ReplyTo: "main", Text: "par", Metadata: delta},
+		{ReplyTo: "main", Text: "tial", Metadata: delta},
+		{ReplyTo: "main", Text: "partial"},
+	} {
+		if err := w.Send(ctx, resp); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	want := []webOutboundMessage{{Type: "delta", Text: "par"}, {Type: "delta", Text: "tial"}, {Type: "response", Text: "partial"}}
+	for i, exp := range want {
+		var out webOutboundMessage
+		if err := wsjson.Read(ctx, conn, &out); err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		if out != exp {
+			t.Fatalf("frame %d = %+v, want %+v", i, out, exp)
+		}
+	}
+}
+
+func webPeerCount(w *WebChannel) int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.peers)
+}
+
+func TestWebClosesIdleConnection(t *testing.T) {
+	w := newTestWebChannel(nil)
+	w.pingInterval = 20 * time.Millisecond
+	w.idleTimeout = 100 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(w.handleWS))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	// A client that reads answers pings and stays connected.
+	live, _, err := websocket.Dial(ctx, url+"?session=live", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer live.CloseNow()
+	live.CloseRead(ctx)
+
+	// A client that never reads cannot pong and goes idle.
+	idle, _, err := websocket.Dial(ctx, url+"?session=idle", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer idle.CloseNow()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for webPeerCount(w) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("peers = %d, want the idle connection unregistered", webPeerCount(w))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w.mu.RLock()
+	_, idleBound := w.clients["idle"]
+	_, liveBound := w.clients["live"]
+	w.mu.RUnlock()
+	if idleBound || !liveBound {
+		t.Fatalf("bindings idle=%t live=%t, want only live", idleBound, liveBound)
+	}
+}
+
+func TestWebCORSAllowsConfiguredOrigins(t *testing.T) {
+	store, err := session.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWebChannel(store)
+	w.allowedOrigins = []string{"http://192.168.1.5:3000"}
+	handler := w.withCORS(w.handleSessions)
+
+	tests := []struct {
+		origin string
+		method string
+		want   string
+		status int
+	}{
+		{"http://192.168.1.5:3000", http.MethodGet, "http://192.168.1.5:3000", http.StatusOK},
+		{"http://192.168.1.5:3000", http.MethodOptions, "http://192.168.1.5:3000", http.StatusNoContent},
+		{"http://evil.example", http.MethodGet, "", http.StatusOK},
+		{"http://evil.example", http.MethodOptions, "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/sessions", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != tt.status {
+			t.Fatalf("%s %s: status = %d, want %d", tt.method, tt.origin, rec.Code, tt.status)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+			t.Fatalf("%s %s: Access-Control-Allow-Origin = %q, want %q", tt.method, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestWebLoopbackAddr(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"localhost:8080": true,
+		"0.0.0.0:8080":   false,
+		"[::]:8080":      false,
+		":8080":          false,
+		"10.0.0.2:8080":  false,
+	}
+	for addr, want := range tests {
+		if got := webLoopbackAddr(addr); got != want {
+			t.Errorf("webLoopbackAddr(%q) = %t, want %t", addr, got, want)
+		}
+	}
+}
+
+func TestWebHealthReportsConnectedClients(t *testing.T) {
+	w := newTestWebChannel(nil)
+	w.registerPeer(&wsClient{})
+	w.registerPeer(&wsClient{})
+
+	rec := httptest.NewRecorder()
+	w.handleHealth(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var snap health.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if snap.Status != "healthy" || snap.Channels == nil || snap.Channels.Web == nil || snap.Channels.Web.Clients != 2 {
+		t.Fatalf("snapshot = %+v, want healthy with 2 web clients", snap)
+	}
+}
+
+func TestWebHistoryReturnsSavedConversation(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testWebHistory(t, sessions)
+}
+
+func TestWebHistoryAndSessionsWithSQLiteStore(t *testing.T) {
+	store, err := session.NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testWebHistory(t, session.NewManagerWithStore(t.TempDir(), store))
+
+	rec := httptest.NewRecorder()
+	newTestWebChannel(store).handleSessions(rec, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	var got webSessionsEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Join(got.Sessions, ",") != "main,work" {
+		t.Fatalf("sessions = %v, want [main work]", got.Sessions)
+	}
+}
+
+// testWebHistory saves a turn per web session through sessions and checks
+// the history endpoint returns it.
+func testWebHistory(t *testing.T, sessions *session.Manager) {
+	t.Helper()
+	w := newTestWebChannel(sessions.Store())
+	srv := httptest.NewServer(http.HandlerFunc(w.handleWS))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	for _, sessionID := range []string{"main", "work"} {
+		text := "hello " + sessionID
+		if err := wsjson.Write(ctx, conn, webInboundMessage{Type: "message", SessionID: sessionID, Text: text}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		var msg *Message
+		select {
+		case msg = <-w.Messages():
+		case <-ctx.Done():
+			t.Fatal("no inbound message")
+		}
+
+		// Save the turn the way the thread does, under the routed key.
+		s, err := sessions.Get(WebSessionKey(strings.TrimPrefix(msg.ChannelID, "web:")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Messages = append(s.Messages, provider.UserMessage(msg.Text), provider.AssistantMessage("reply"))
+		if err := sessions.Save(s); err != nil {
+			t.Fatal(err)
+		}
+
+		rec := httptest.NewRecorder()
+		w.handleHistory(rec, httptest.NewRequest(http.MethodGet, "/api/history?session="+sessionID, nil))
+		var got webHistoryEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(got.Messages) != 2 || got.Messages[0].Content != text || got.Messages[1].Content != "reply" {
+			t.Fatalf("history for %s = %+v, want the saved turn", sessionID, got.Messages)
+		}
+	}
+}
@@ -64,10 +64,11 @@ func runAgent(cmd *cobra.Command, args []string) error {
 
 	applyAgentOverrides(cfg)
 
-	mgr, err := buildThreadManager(cfg, false)
+	mgr, auditLog, err := buildThreadManager(cfg, false)
 	if err != nil {
 		return err
 	}
+	defer auditLog.Close()
 	t, err := mgr.NewThread("agent", "")
 	if err != nil {
 		return fmt.Errorf("failed to create agent thread: %w", err)
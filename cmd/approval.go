@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/thread"
+)
+
+const approvalArgsMaxChars = 500
+
+// adminApprover asks the admin to confirm sensitive tool calls through the
+// "main" session sink and waits for a yes/no reply. Requests are serialized so
+// a reply always answers the single outstanding question.
+type adminApprover struct {
+	sinkFor func(sessionKey string) thread.Sink
+	timeout time.Duration
+
+	askMu   sync.Mutex // held for the whole ask/await cycle
+	mu      sync.Mutex
+	pending chan bool
+}
+
+func newAdminApprover(sinkFor func(string) thread.Sink, timeout time.Duration) *adminApprover {
+	return &adminApprover{sinkFor: sinkFor, timeout: timeout}
+}
+
+// Approve implements tools.ApprovalFunc. It denies when the admin cannot be
+// reached or does not answer before the timeout.
+func (a *adminApprover) Approve(toolName string, args json.RawMessage) bool {
+	a.askMu.Lock()
+	defer a.askMu.Unlock()
+
+	sink := a.sinkFor("main")
+	if sink.IsZero() || sink.Send == nil {
+		logger.Warn("tool approval denied: no admin channel available", "tool", toolName)
+		return false
+	}
+
+	reply := make(chan bool, 1)
+	a.mu.Lock()
+	a.pending = reply
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.pending = nil
+		a.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	prompt := fmt.Sprintf(
+		"[Approval required] The agent wants to run %s:\n%s\n\nReply \"yes\" to allow or \"no\" to deny within %s.",
+		toolName, clipApprovalArgs(args), a.timeout,
+	)
+	if err := sink.Send(ctx, prompt); err != nil {
+		logger.Warn("tool approval denied: failed to ask admin", "tool", toolName, "err", err)
+		return false
+	}
+
+	select {
+	case ok := <-reply:
+		logger.Info("tool approval answered", "tool", toolName, "approved", ok)
+		return ok
+	case <-ctx.Done():
+		logger.Warn("tool approval timed out", "tool", toolName, "timeout", a.timeout)
+		_ = sink.Send(context.Background(), fmt.Sprintf("[Approval timed out] %s was denied.", toolName))
+		return false
+	}
+}
+
+// Resolve answers the outstanding approval with an admin reply. It returns
+// false, leaving the message for normal dispatch, when nothing is pending or
+// the text is not a yes/no answer.
+func (a *adminApprover) Resolve(text string) bool {
+	var approved bool
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "y", "yes", "approve", "allow":
+		approved = true
+	case "n", "no", "deny", "reject":
+		approved = false
+	default:
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pending == nil {
+		return false
+	}
+	select {
+	case a.pending <- approved:
+	default:
+	}
+	a.pending = nil
+	return true
+}
+
+// clipApprovalArgs shortens long tool arguments for the admin prompt, cutting
+// on a rune boundary.
+func clipApprovalArgs(args json.RawMessage) string {
+	s := strings.TrimSpace(string(args))
+	if len(s) <= approvalArgsMaxChars {
+		return s
+	}
+	n := approvalArgsMaxChars
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/channel"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/thread"
+)
+
+func TestClipApprovalArgsKeepsRunesWhole(t *testing.T) {
+	short := json.RawMessage(` {"command":"ls"} `)
+	if got := clipApprovalArgs(short); got != `{"command":"ls"}` {
+		t.Fatalf("clipApprovalArgs(short) = %q", got)
+	}
+
+	args, _ := json.Marshal(map[string]string{"content": strings.Repeat("日本語", approvalArgsMaxChars)})
+	got := clipApprovalArgs(args)
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "…") {
+		t.Fatalf("clipApprovalArgs(long) = %q, want valid UTF-8 ending in an ellipsis", got)
+	}
+	if n := len(strings.TrimSuffix(got, "…")); n > approvalArgsMaxChars || n < approvalArgsMaxChars-3 {
+		t.Fatalf("clipped to %d bytes, want just under %d", n, approvalArgsMaxChars)
+	}
+}
+
+func TestOnlyAdminResolvesApprovalOnMainSession(t *testing.T) {
+	asked := make(chan string, 1)
+	approver := newAdminApprover(func(string) thread.Sink {
+		return thread.Sink{Send: func(_ context.Context, text string) error {
+			asked <- text
+			return nil
+		}}
+	}, 5*time.Second)
+	d := &Dispatcher{
+		cfg:      &config.Config{Channels: &config.ChannelsConfig{AdminUserID: "42"}},
+		threads:  thread.NewManager(&thread.ThreadConfig{}),
+		approver: approver,
+	}
+
+	result := make(chan bool, 1)
+	go func() { result <- approver.Approve("exec", json.RawMessage(`{"command":"rm -rf build"}`)) }()
+	<-asked
+
+	// The web main session routes to "main" but proves nothing about the sender.
+	web := &recordingChannel{name: "web"}
+	d.dispatch(context.Background(), web, &channel.Message{ChannelID: "web:main", UserID: "main", Text: "yes"})
+	select {
+	case ok := <-result:
+		t.Fatalf("web message settled the approval (approved=%v)", ok)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tg := &recordingChannel{name: "telegram"}
+	d.dispatch(context.Background(), tg, &channel.Message{ChannelID: "telegram:42", UserID: "42", Text: "no"})
+	select {
+	case ok := <-result:
+		if ok {
+			t.Fatal("admin answered no, approval granted")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("admin reply did not settle the approval")
+	}
+	if len(tg.replies) != 0 {
+		t.Fatalf("admin answer was dispatched as a message: %+v", tg.replies)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/linanwx/nagobot/channel"
+	"github.com/linanwx/nagobot/logger"
+)
+
+// slashCommand is a chat command answered by the dispatcher without waking
+// the agent.
+type slashCommand struct {
+	name string
+	help string
+	run  func(d *Dispatcher, ch channel.Channel, msg *channel.Message, sessionKey string) string
+}
+
+// slashCommands are matched before a message reaches its thread. Unknown
+// slash commands pass through to the agent. Filled in init because /help
+// lists them.
+var slashCommands []slashCommand
+
+func init() {
+	slashCommands = []slashCommand{
+		{name: "help", help: "list commands", run: (*Dispatcher).cmdHelp},
+		{name: "reset", help: "clear this conversation", run: (*Dispatcher).cmdReset},
+		{name: "model", help: "show the active provider, model and token usage", run: (*Dispatcher).cmdModel},
+		{name: "whoami", help: "show your user ID and session", run: (*Dispatcher).cmdWhoami},
+	}
+}
+
+// parseSlashCommand splits "/name args" into a lowercase name and its
+// arguments. A Telegram-style "@botname" suffix on the name is dropped.
+func parseSlashCommand(text string) (name, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	rest, found := strings.CutPrefix(text, "/")
+	if !found {
+		return "", "", false
+	}
+	name, args, _ = strings.Cut(rest, " ")
+	name, _, _ = strings.Cut(name, "@")
+	if name == "" {
+		return "", "", false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(name), strings.TrimSpace(args), true
+}
+
+func findSlashCommand(name string) (slashCommand, bool) {
+	for _, c := range slashCommands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return slashCommand{}, false
+}
+
+// handleCommand answers msg if it is a known slash command and reports
+// whether it did. The reply goes straight back through the channel.
+func (d *Dispatcher) handleCommand(ctx context.Context, ch channel.Channel, msg *channel.Message, sessionKey string) bool {
+	if ch.Name() == "cron" {
+		return false
+	}
+	name, _, ok := parseSlashCommand(msg.Text)
+	if !ok {
+		return false
+	}
+	cmd, ok := findSlashCommand(name)
+	if !ok {
+		return false
+	}
+
+	reply := cmd.run(d, ch, msg, sessionKey)
+	if err := ch.Send(ctx, &channel.Response{Text: reply, ReplyTo: replyTarget(msg)}); err != nil {
+		logger.Warn("command reply failed", "channel", ch.Name(), "command", name, "err", err)
+	}
+	return true
+}
+
+func (d *Dispatcher) cmdHelp(channel.Channel, *channel.Message, string) string {
+	var sb strings.Builder
+	sb.WriteString("Commands:")
+	for _, c := range slashCommands {
+		fmt.Fprintf(&sb, "\n/%s - %s", c.name, c.help)
+	}
+	return sb.String()
+}
+
+func (d *Dispatcher) cmdReset(_ channel.Channel, _ *channel.Message, sessionKey string) string {
+	if err := d.threads.ResetSession(sessionKey); err != nil {
+		return fmt.Sprintf("Reset failed: %v", err)
+	}
+	return "Conversation cleared."
+}
+
+func (d *Dispatcher) cmdModel(_ channel.Channel, _ *channel.Message, sessionKey string) string {
+	providerName, modelName := d.threads.SessionModel(sessionKey)
+	prompt, completion, total := d.threads.SessionUsage(sessionKey)
+	return fmt.Sprintf("Provider: %s\nModel: %s\nTokens used: %d (prompt %d, completion %d)",
+		providerName, modelName, total, prompt, completion)
+}
+
+func (d *Dispatcher) cmdWhoami(ch channel.Channel, msg *channel.Message, sessionKey string) string {
+	return fmt.Sprintf("Channel: %s\nUser: %s\nSession: %s", ch.Name(), strings.TrimSpace(msg.UserID), sessionKey)
+}
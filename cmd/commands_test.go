@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/channel"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/thread"
+)
+
+type recordingChannel struct {
+	name    string
+	replies []*channel.Response
+}
+
+func (c *recordingChannel) Name() string                      { return c.name }
+func (c *recordingChannel) Start(context.Context) error       { return nil }
+func (c *recordingChannel) Stop() error                       { return nil }
+func (c *recordingChannel) Messages() <-chan *channel.Message { return nil }
+func (c *recordingChannel) Send(_ context.Context, resp *channel.Response) error {
+	c.replies = append(c.replies, resp)
+	return nil
+}
+
+func TestParseSlashCommand(t *testing.T) {
+	tests := []struct {
+		in         string
+		name, args string
+		ok         bool
+	}{
+		{"/reset", "reset", "", true},
+		{"  /Model  ", "model", "", true},
+		{"/help me please", "help", "me please", true},
+		{"/whoami@nagobot_bot", "whoami", "", true},
+		{"reset", "", "", false},
+		{"/", "", "", false},
+		{"/tmp/file.txt is big", "", "", false},
+		{"// comment", "", "", false},
+	}
+	for _, tt := range tests {
+		name, args, ok := parseSlashCommand(tt.in)
+		if name != tt.name || args != tt.args || ok != tt.ok {
+			t.Errorf("parseSlashCommand(%q) = %q, %q, %t; want %q, %q, %t", tt.in, name, args, ok, tt.name, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestResetCommandClearsSession(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := sessions.Get("telegram:42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Messages = append(s.Messages, provider.UserMessage("hi"), provider.AssistantMessage("hello"))
+	if err := sessions.Save(s); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Dispatcher{
+		cfg:     &config.Config{},
+		threads: thread.NewManager(&thread.ThreadConfig{Sessions: sessions, ProviderName: "mock", ModelName: "mock"}),
+	}
+	ch := &recordingChannel{name: "telegram"}
+	msg := &channel.Message{ChannelID: "telegram:42", UserID: "42", Text: "/reset", Metadata: map[string]string{"chat_id": "42"}}
+	d.dispatch(context.Background(), ch, msg)
+
+	if len(ch.replies) != 1 || ch.replies[0].Text != "Conversation cleared." || ch.replies[0].ReplyTo != "42" {
+		t.Fatalf("replies = %+v, want one confirmation to chat 42", ch.replies)
+	}
+	reloaded, err := sessions.Reload("telegram:42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Messages) != 0 {
+		t.Fatalf("session still has %d messages after /reset", len(reloaded.Messages))
+	}
+
+	msg.Text = "/model"
+	d.dispatch(context.Background(), ch, msg)
+	if got := ch.replies[1].Text; !strings.Contains(got, "Provider: mock") {
+		t.Fatalf("/model reply = %q", got)
+	}
+}
+
+func TestModelCommandReportsSessionUsage(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := sessions.Get("telegram:42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Usage = provider.Usage{PromptTokens: 1200, CompletionTokens: 34, TotalTokens: 1234}
+	if err := sessions.Save(s); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Dispatcher{
+		cfg:     &config.Config{},
+		threads: thread.NewManager(&thread.ThreadConfig{Sessions: sessions, ProviderName: "mock", ModelName: "mock-1"}),
+	}
+	ch := &recordingChannel{name: "telegram"}
+	msg := &channel.Message{ChannelID: "telegram:42", UserID: "42", Text: "/model", Metadata: map[string]string{"chat_id": "42"}}
+	d.dispatch(context.Background(), ch, msg)
+
+	want := "Provider: mock\nModel: mock-1\nTokens used: 1234 (prompt 1200, completion 34)"
+	if len(ch.replies) != 1 || ch.replies[0].Text != want {
+		t.Fatalf("replies = %+v, want %q", ch.replies, want)
+	}
+}
@@ -8,22 +8,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/linanwx/nagobot/config"
 	"github.com/linanwx/nagobot/provider"
 	"github.com/linanwx/nagobot/session"
 	"github.com/spf13/cobra"
 )
 
 var compressSessionCmd = &cobra.Command{
-	Use:   "compress-session <session-file> <input-file>",
+	Use:   "compress-session <session-key> <input-file>",
 	Short: "Replace session messages with a compressed summary",
 	Long: `Replace session messages with compressed context from an input text file.
-The original is backed up to <session_dir>/history/.
+The original is backed up to <sessions_dir>/<session key path>/history/.
 
 The input file should contain plain text (not JSON). It will be stored as a
 single assistant message in the session. The input file is deleted after use.
 
 Example:
-  nagobot compress-session /path/to/session.json /path/to/compressed.txt`,
+  nagobot compress-session telegram:123456 /path/to/compressed.txt`,
 	Args:    cobra.ExactArgs(2),
 	GroupID: "internal",
 	RunE:    runCompressSession,
@@ -34,7 +35,7 @@ func init() {
 }
 
 func runCompressSession(_ *cobra.Command, args []string) error {
-	sessionFile := args[0]
+	sessionKey := strings.TrimSpace(args[0])
 	inputFile := args[1]
 
 	// 1. Read compressed context.
@@ -47,49 +48,68 @@ func runCompressSession(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("input file is empty")
 	}
 
-	// 2. Read original session.
-	origData, err := os.ReadFile(sessionFile)
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to read session file: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	var orig session.Session
-	if err := json.Unmarshal(origData, &orig); err != nil {
-		return fmt.Errorf("failed to parse session file: %w", err)
-	}
-	origCount := len(orig.Messages)
-
-	// 3. Backup original.
-	sessionDir := filepath.Dir(sessionFile)
-	historyDir := filepath.Join(sessionDir, "history")
-	if err := os.MkdirAll(historyDir, 0755); err != nil {
-		return fmt.Errorf("failed to create history directory: %w", err)
+	sessionsDir, err := cfg.SessionsDir()
+	if err != nil {
+		return fmt.Errorf("failed to get sessions directory: %w", err)
 	}
-	now := time.Now()
-	timestamp := fmt.Sprintf("%d_%s", now.Unix(), now.Format("20060102T150405-0700"))
-	backupPath := filepath.Join(historyDir, timestamp+".json")
-	if err := os.WriteFile(backupPath, origData, 0644); err != nil {
-		return fmt.Errorf("failed to write backup: %w", err)
+	sessions, err := openSessionManager(cfg, cfg.GetStorageBackend())
+	if err != nil {
+		return err
 	}
+	defer sessions.Store().Close()
 
-	// 4. Replace messages with a single assistant message and write directly.
-	orig.Messages = []provider.Message{
-		{Role: "assistant", Content: content},
-	}
-	orig.UpdatedAt = now
-	newData, err := json.MarshalIndent(&orig, "", "  ")
+	origCount, backupPath, err := compressSession(sessions, sessionsDir, sessionKey, content)
 	if err != nil {
-		return fmt.Errorf("failed to marshal new session: %w", err)
-	}
-	if err := os.WriteFile(sessionFile, newData, 0644); err != nil {
-		return fmt.Errorf("failed to write session file: %w", err)
+		return err
 	}
 
-	// 5. Cleanup input file.
+	// Cleanup input file.
 	_ = os.Remove(inputFile)
 
-	// 6. Summary.
 	fmt.Printf("Session compressed: %d → 1 messages\n", origCount)
 	fmt.Printf("Backup: %s\n", backupPath)
-	fmt.Printf("Session: %s\n", sessionFile)
+	fmt.Printf("Session: %s\n", sessionKey)
 	return nil
 }
+
+// compressSession backs up the stored session for key under sessionsDir and
+// replaces its messages with a single assistant message holding content. It
+// goes through the session store, so it works for every storage backend.
+func compressSession(sessions *session.Manager, sessionsDir, key, content string) (origCount int, backupPath string, err error) {
+	if _, found, err := sessions.Store().Load(key); err != nil {
+		return 0, "", fmt.Errorf("failed to read session: %w", err)
+	} else if !found {
+		return 0, "", fmt.Errorf("session not found: %s", key)
+	}
+
+	_, err = sessions.Update(key, func(s *session.Session) error {
+		origData, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal session: %w", err)
+		}
+		historyDir := filepath.Join(filepath.Dir(session.FilePath(sessionsDir, key)), "history")
+		if err := os.MkdirAll(historyDir, 0755); err != nil {
+			return fmt.Errorf("failed to create history directory: %w", err)
+		}
+		now := time.Now()
+		timestamp := fmt.Sprintf("%d_%s", now.Unix(), now.Format("20060102T150405-0700"))
+		backupPath = filepath.Join(historyDir, timestamp+".json")
+		if err := os.WriteFile(backupPath, origData, 0644); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+
+		origCount = len(s.Messages)
+		s.Messages = []provider.Message{
+			{Role: "assistant", Content: content},
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return origCount, backupPath, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+func TestCompressSessionWithSQLiteStore(t *testing.T) {
+	sessionsDir := t.TempDir()
+	store, err := session.NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer store.Close()
+	sessions := session.NewManagerWithStore(sessionsDir, store)
+	if err := sessions.Save(&session.Session{Key: "telegram:1", Messages: []provider.Message{
+		provider.UserMessage("hello"),
+		provider.AssistantMessage("hi"),
+	}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	count, backupPath, err := compressSession(sessions, sessionsDir, "telegram:1", "summary")
+	if err != nil {
+		t.Fatalf("compressSession() error = %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("original count = %d, want 2", count)
+	}
+	if _, err := os.Stat(backupPath); err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	got, _, err := store.Load("telegram:1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Content != "summary" {
+		t.Fatalf("stored messages = %+v, want the summary only", got.Messages)
+	}
+
+	if _, _, err := compressSession(sessions, sessionsDir, "telegram:missing", "summary"); err == nil {
+		t.Fatal("compressSession() on a missing session succeeded")
+	}
+}
@@ -83,7 +83,7 @@ var (
 
 func init() {
 	setAtCmd.Flags().StringVar(&setAtID, "id", "", "Unique job ID (required)")
-	setAtCmd.Flags().StringVar(&setAtTime, "at", "", "Execution time in RFC3339 (required)")
+	setAtCmd.Flags().StringVar(&setAtTime, "at", "", "Execution time in RFC3339, or wall-clock time in --timezone (required)")
 	setAtCmd.Flags().StringVar(&setAtTask, "task", "", "Task prompt for the job (required)")
 	_ = setAtCmd.MarkFlagRequired("id")
 	_ = setAtCmd.MarkFlagRequired("at")
@@ -93,9 +93,9 @@ func init() {
 }
 
 func runSetAt(_ *cobra.Command, _ []string) error {
-	t, err := time.Parse(time.RFC3339, strings.TrimSpace(setAtTime))
+	t, err := cronsvc.ParseAtTime(setAtTime, commonTimezone)
 	if err != nil {
-		return fmt.Errorf("invalid --at time %q: %w", setAtTime, err)
+		return fmt.Errorf("invalid --at: %w", err)
 	}
 	job := cronsvc.Job{
 		ID:     setAtID,
@@ -201,8 +201,18 @@ func runCronList(_ *cobra.Command, _ []string) error {
 		schedule := job.Expr
 		if job.Kind == cronsvc.JobKindAt {
 			schedule = job.AtTime.Format(time.RFC3339)
+		} else if job.Timezone != "" {
+			schedule += " (" + job.Timezone + ")"
 		}
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", job.ID, job.Kind, schedule, job.Agent, job.Task)
+		next := "-"
+		if t := cronsvc.Normalize(job).NextRun(); t.After(time.Now()) {
+			next = t.Format(time.RFC3339)
+		}
+		kind := job.Kind
+		if job.Disabled {
+			kind += ",paused"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n", job.ID, kind, schedule, next, job.Agent, job.Task)
 	}
 	return nil
 }
@@ -219,18 +229,21 @@ var (
 	commonAgent             string
 	commonWakeSession string
 	commonSilent            bool
+	commonTimezone          string
 )
 
 func addCommonJobFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&commonAgent, "agent", "", "Agent template name")
 	cmd.Flags().StringVar(&commonWakeSession, "wake-session", "", "Session to inject task into and wake for execution (default: main)")
 	cmd.Flags().BoolVar(&commonSilent, "silent", false, "Suppress result delivery")
+	cmd.Flags().StringVar(&commonTimezone, "timezone", "", "IANA timezone for the schedule, e.g. Asia/Shanghai (default: server local time)")
 }
 
 func applyCommonJobFlags(job *cronsvc.Job) {
 	job.Agent = strings.TrimSpace(commonAgent)
 	job.WakeSession = strings.TrimSpace(commonWakeSession)
 	job.Silent = commonSilent
+	job.Timezone = strings.TrimSpace(commonTimezone)
 }
 
 func cronStorePath() (string, error) {
@@ -252,6 +265,11 @@ func upsertJob(job cronsvc.Job) (updated bool, err error) {
 	if !ok {
 		return false, fmt.Errorf("invalid job: check id, task, and schedule fields")
 	}
+	if job.Timezone != "" {
+		if _, err := time.LoadLocation(job.Timezone); err != nil {
+			return false, fmt.Errorf("invalid --timezone %q: %w", job.Timezone, err)
+		}
+	}
 
 	storePath, err := cronStorePath()
 	if err != nil {
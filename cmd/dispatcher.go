@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/linanwx/nagobot/channel"
 	"github.com/linanwx/nagobot/config"
 	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
 	"github.com/linanwx/nagobot/thread"
 )
 
@@ -18,6 +20,8 @@ type Dispatcher struct {
 	channels *channel.Manager
 	threads  *thread.Manager
 	cfg      *config.Config
+	approver *adminApprover // optional; consumes admin yes/no replies to pending tool approvals
+	limiter  *rateLimiter   // optional; throttles non-admin users
 }
 
 // NewDispatcher creates a new dispatcher.
@@ -30,6 +34,7 @@ func NewDispatcher(
 		channels: channels,
 		threads:  threads,
 		cfg:      cfg,
+		limiter:  newRateLimiter(cfg),
 	}
 }
 
@@ -56,7 +61,7 @@ func (d *Dispatcher) processChannel(ctx context.Context, ch channel.Channel) {
 	}
 }
 
-func (d *Dispatcher) dispatch(_ context.Context, ch channel.Channel, msg *channel.Message) {
+func (d *Dispatcher) dispatch(ctx context.Context, ch channel.Channel, msg *channel.Message) {
 	logger.Debug("dispatching message",
 		"channel", ch.Name(),
 		"channelID", msg.ChannelID,
@@ -65,8 +70,21 @@ func (d *Dispatcher) dispatch(_ context.Context, ch channel.Channel, msg *channe
 	)
 
 	sessionKey := d.route(msg)
+	origin := d.buildOrigin(ch, msg)
+	// Web users can reach "main" without proving who they are, so only the
+	// admin or the local CLI may answer a pending approval.
+	if d.approver != nil && sessionKey == "main" && origin != nil && (origin.IsAdmin || ch.Name() == "cli") &&
+		d.approver.Resolve(msg.Text) {
+		return
+	}
+	if !d.allowMessage(ctx, ch, msg, origin) {
+		return
+	}
+	if d.handleCommand(ctx, ch, msg, sessionKey) {
+		return
+	}
 	sink := d.buildSink(ch, msg)
-	agentName, vars := d.resolveAgentName(msg)
+	agentName, vars := d.resolveAgentName(ch, msg)
 	userMessage := d.preprocessMessage(msg)
 	source := d.wakeSource(ch)
 
@@ -76,19 +94,118 @@ func (d *Dispatcher) dispatch(_ context.Context, ch channel.Channel, msg *channe
 		Sink:      sink,
 		AgentName: agentName,
 		Vars:      vars,
+		Origin:    origin,
+		Images:    imageParts(msg.Images),
 	})
 }
 
+// allowMessage applies the per-user rate limit. The local CLI user, admins
+// and messages without a user origin (cron) are exempt. A throttled user gets
+// one reply asking them to slow down.
+func (d *Dispatcher) allowMessage(ctx context.Context, ch channel.Channel, msg *channel.Message, origin *thread.Origin) bool {
+	if d.limiter == nil || origin == nil || origin.IsAdmin || ch.Name() == "cli" {
+		return true
+	}
+	key := rateLimitKey(ch, msg, origin)
+	allowed, notify := d.limiter.Allow(ch.Name(), key)
+	if allowed {
+		return true
+	}
+	logger.Info("message rate limited", "channel", ch.Name(), "user", key)
+	if notify {
+		if err := ch.Send(ctx, &channel.Response{Text: rateLimitReply, ReplyTo: replyTarget(msg)}); err != nil {
+			logger.Warn("rate limit reply failed", "channel", ch.Name(), "err", err)
+		}
+	}
+	return false
+}
+
+// rateLimitKey picks the bucket a message is charged to. Web clients choose
+// their own session ID, so web traffic is keyed on the remote address, and
+// shares one channel-wide bucket when the address is unknown.
+func rateLimitKey(ch channel.Channel, msg *channel.Message, origin *thread.Origin) string {
+	if ch.Name() != "web" {
+		return origin.UserID
+	}
+	if addr := strings.TrimSpace(msg.Metadata["remote_addr"]); addr != "" {
+		return addr
+	}
+	return "*"
+}
+
+// imageParts converts downloaded channel images into provider image parts.
+func imageParts(images []channel.Image) []provider.ImagePart {
+	if len(images) == 0 {
+		return nil
+	}
+	parts := make([]provider.ImagePart, 0, len(images))
+	for _, img := range images {
+		parts = append(parts, provider.ImagePart{
+			MimeType: img.MimeType,
+			Data:     base64.StdEncoding.EncodeToString(img.Data),
+		})
+	}
+	return parts
+}
+
+// buildOrigin describes who sent a message. Cron messages have no user origin.
+func (d *Dispatcher) buildOrigin(ch channel.Channel, msg *channel.Message) *thread.Origin {
+	if msg == nil || ch.Name() == "cron" {
+		return nil
+	}
+
+	userID := strings.TrimSpace(msg.UserID)
+	isAdmin := false
+	if userID != "" {
+		switch ch.Name() {
+		case "telegram":
+			isAdmin = userID == strings.TrimSpace(d.cfg.GetAdminUserID())
+		case "feishu":
+			isAdmin = userID == strings.TrimSpace(d.cfg.GetFeishuAdminOpenID())
+		case "discord":
+			isAdmin = userID == strings.TrimSpace(d.cfg.GetDiscordAdminUserID())
+		case "slack":
+			isAdmin = userID == strings.TrimSpace(d.cfg.GetSlackAdminUserID())
+		case "web":
+			// The web user ID is the session ID the client picked, so it
+			// proves nothing about who is connected.
+		default:
+			isAdmin = userID == strings.TrimSpace(d.cfg.GetAdminUserID())
+		}
+	}
+
+	return &thread.Origin{
+		Channel:  ch.Name(),
+		UserID:   userID,
+		Username: strings.TrimSpace(msg.Username),
+		IsAdmin:  isAdmin,
+		ReplyTo:  replyTarget(msg),
+	}
+}
+
+// replyTarget returns the channel-specific address replies to msg go to.
+func replyTarget(msg *channel.Message) string {
+	if replyTo := strings.TrimSpace(msg.Metadata["chat_id"]); replyTo != "" {
+		return replyTo
+	}
+	return strings.TrimSpace(msg.ReplyTo)
+}
+
 // route determines the session key for a message.
 func (d *Dispatcher) route(msg *channel.Message) string {
 	if msg == nil {
 		return "main"
 	}
 
-	if msg.ChannelID == "cli:local" || strings.HasPrefix(msg.ChannelID, "web:") {
+	if msg.ChannelID == "cli:local" {
 		return "main"
 	}
 
+	// Web sessions share the key scheme the web channel reads history from.
+	if sessionID, ok := strings.CutPrefix(msg.ChannelID, "web:"); ok {
+		return channel.WebSessionKey(sessionID)
+	}
+
 	if strings.HasPrefix(msg.ChannelID, "telegram:") {
 		userID := strings.TrimSpace(msg.UserID)
 		adminID := strings.TrimSpace(d.cfg.GetAdminUserID())
@@ -113,6 +230,30 @@ func (d *Dispatcher) route(msg *channel.Message) string {
 		return msg.ChannelID
 	}
 
+	if strings.HasPrefix(msg.ChannelID, "discord:") {
+		userID := strings.TrimSpace(msg.UserID)
+		adminID := strings.TrimSpace(d.cfg.GetDiscordAdminUserID())
+		if userID != "" && adminID != "" && userID == adminID {
+			return "main"
+		}
+		if userID != "" {
+			return "discord:" + userID
+		}
+		return msg.ChannelID
+	}
+
+	if strings.HasPrefix(msg.ChannelID, "slack:") {
+		userID := strings.TrimSpace(msg.UserID)
+		adminID := strings.TrimSpace(d.cfg.GetSlackAdminUserID())
+		if userID != "" && adminID != "" && userID == adminID {
+			return "main"
+		}
+		if userID != "" {
+			return "slack:" + userID
+		}
+		return msg.ChannelID
+	}
+
 	if strings.HasPrefix(msg.ChannelID, "cron:") {
 		jobID := strings.TrimSpace(msg.Metadata["job_id"])
 		if jobID == "" {
@@ -146,20 +287,44 @@ func (d *Dispatcher) buildSink(ch channel.Channel, msg *channel.Message) thread.
 	}
 
 	channelName := ch.Name()
-	replyTo := strings.TrimSpace(msg.Metadata["chat_id"])
-	if replyTo == "" {
-		replyTo = strings.TrimSpace(msg.ReplyTo)
+	replyTo := replyTarget(msg)
+	quoteID := ""
+	if channel.ShouldThreadReply(d.cfg.GetReplyThreading(), msg.Metadata["chat_type"]) {
+		quoteID = strings.TrimSpace(msg.Metadata["message_id"])
 	}
 
-	return thread.Sink{
+	sink := thread.Sink{
 		Label: "your response will be sent to the user via " + channelName,
 		Send: func(ctx context.Context, response string) error {
 			if strings.TrimSpace(response) == "" {
 				return nil
 			}
-			return manager.SendTo(ctx, channelName, response, replyTo)
+			return manager.SendResponse(ctx, channelName, &channel.Response{
+				Text:             response,
+				ReplyTo:          replyTo,
+				ReplyToMessageID: quoteID,
+				MessageID:        msg.ID,
+			})
+		},
+		// Progress lines are not the reply, so they leave the placeholder alone.
+		Progress: func(ctx context.Context, line string) error {
+			return manager.SendTo(ctx, channelName, line, replyTo)
+		},
+		Done: func(context.Context, error) {
+			manager.FinishTurn(channelName, replyTo, msg.ID)
 		},
 	}
+	// The web client renders partial text as it arrives.
+	if channelName == "web" {
+		sink.Stream = func(ctx context.Context, delta string) error {
+			return manager.SendResponse(ctx, channelName, &channel.Response{
+				Text:     delta,
+				ReplyTo:  replyTo,
+				Metadata: map[string]string{"stream": channel.WebStreamDelta},
+			})
+		}
+	}
+	return sink
 }
 
 // buildCronSink creates a sink for cron jobs that wakes the creator thread
@@ -176,8 +341,13 @@ func (d *Dispatcher) buildCronSink(msg *channel.Message) thread.Sink {
 	}
 	jobID := strings.TrimSpace(msg.Metadata["job_id"])
 
+	// Done reports the end of the run back to the cron scheduler.
+	var done func(context.Context, error)
+	if msg.Done != nil {
+		done = func(_ context.Context, err error) { msg.Done(err) }
+	}
 	if silent {
-		return thread.Sink{Label: "cron silent, result will not be delivered"}
+		return thread.Sink{Label: "cron silent, result will not be delivered", Done: done}
 	}
 
 	return thread.Sink{
@@ -197,19 +367,20 @@ func (d *Dispatcher) buildCronSink(msg *channel.Message) thread.Sink {
 			})
 			return nil
 		},
+		Done: done,
 	}
 }
 
 // resolveAgentName returns the agent name and vars for a message.
 // Empty name means use the default (soul) agent.
-func (d *Dispatcher) resolveAgentName(msg *channel.Message) (string, map[string]string) {
+func (d *Dispatcher) resolveAgentName(ch channel.Channel, msg *channel.Message) (string, map[string]string) {
 	if msg == nil {
 		return "", nil
 	}
 
 	agentName := strings.TrimSpace(msg.Metadata["agent"])
-	if agentName == "" && msg.UserID != "" && d.cfg.Channels != nil {
-		agentName = d.cfg.Channels.UserAgents[msg.UserID]
+	if agentName == "" {
+		agentName = d.cfg.GetUserAgent(ch.Name(), msg.UserID)
 	}
 	if agentName == "" {
 		return "", nil
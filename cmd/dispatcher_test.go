@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/channel"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/thread"
+)
+
+func TestRouteWebMessagesToHistorySessions(t *testing.T) {
+	d := &Dispatcher{cfg: &config.Config{}}
+	for _, sessionID := range []string{"main", "work"} {
+		msg := &channel.Message{ChannelID: "web:" + sessionID, UserID: sessionID}
+		if got, want := d.route(msg), channel.WebSessionKey(sessionID); got != want {
+			t.Fatalf("route(web:%s) = %q, want %q", sessionID, got, want)
+		}
+	}
+	if got := d.route(&channel.Message{ChannelID: "web:main"}); got != "main" {
+		t.Fatalf("route(web:main) = %q, want main", got)
+	}
+}
+
+func TestAssignedAgentPromptsMappedUser(t *testing.T) {
+	workspace := t.TempDir()
+	agentsDir := filepath.Join(workspace, "agents")
+	if err := os.MkdirAll(agentsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentsDir, "ops.md"), []byte("---\nname: ops\n---\nYou are the ops agent."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := provider.NewMockProvider()
+	cfg := &config.Config{Channels: &config.ChannelsConfig{UserAgents: map[string]string{
+		"feishu:ou_admin": "ops",
+		"ou_admin":        "missing", // the channel-qualified entry wins
+	}}}
+	d := &Dispatcher{
+		cfg: cfg,
+		threads: thread.NewManager(&thread.ThreadConfig{
+			DefaultProvider: mock,
+			Agents:          agent.NewRegistry(workspace),
+		}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.threads.Run(ctx)
+
+	ch := &recordingChannel{name: "feishu"}
+	systemPrompt := func(userID string) string {
+		t.Helper()
+		before := len(mock.Requests())
+		d.dispatch(ctx, ch, &channel.Message{ChannelID: "feishu:" + userID, UserID: userID, Text: "hi"})
+		deadline := time.Now().Add(5 * time.Second)
+		for len(mock.Requests()) == before {
+			if time.Now().After(deadline) {
+				t.Fatalf("no provider request for %s", userID)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		req := mock.Requests()[before]
+		if len(req.Messages) == 0 || req.Messages[0].Role != "system" {
+			t.Fatalf("request for %s has no system prompt", userID)
+		}
+		return req.Messages[0].Content
+	}
+
+	if got := systemPrompt("ou_admin"); !strings.Contains(got, "You are the ops agent.") {
+		t.Fatalf("mapped user's system prompt does not use the ops agent:\n%s", got)
+	}
+	if got := systemPrompt("ou_member"); strings.Contains(got, "You are the ops agent.") {
+		t.Fatal("unmapped user got the ops agent")
+	}
+}
+
+// finishingChannel records FinishTurn calls on top of recordingChannel.
+type finishingChannel struct {
+	recordingChannel
+	finished []string
+}
+
+func (c *finishingChannel) FinishTurn(replyTo, messageID string) {
+	c.finished = append(c.finished, replyTo+"/"+messageID)
+}
+
+func TestSinkSeparatesProgressAndFinishesTurn(t *testing.T) {
+	ch := &finishingChannel{recordingChannel: recordingChannel{name: "telegram"}}
+	channels := channel.NewManager()
+	channels.Register(ch)
+	d := &Dispatcher{cfg: &config.Config{}, channels: channels}
+
+	msg := &channel.Message{ID: "7", ChannelID: "telegram:42", Metadata: map[string]string{"chat_id": "42"}}
+	sink := d.buildSink(ch, msg)
+	if err := sink.Progress(context.Background(), "[exec] 50%"); err != nil {
+		t.Fatalf("Progress() error = %v", err)
+	}
+	if err := sink.Send(context.Background(), "hello"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	sink.Done(context.Background(), nil)
+
+	if len(ch.replies) != 2 || ch.replies[0].MessageID != "" || ch.replies[1].MessageID != "7" {
+		t.Fatalf("replies = %+v, want untagged progress then the tagged reply", ch.replies)
+	}
+	if strings.Join(ch.finished, ",") != "42/7" {
+		t.Fatalf("finished = %v, want the turn for 42/7", ch.finished)
+	}
+}
+
+func TestWebUserIDNeverGrantsAdmin(t *testing.T) {
+	cfg := &config.Config{Channels: &config.ChannelsConfig{AdminUserID: "12345"}}
+	d := &Dispatcher{cfg: cfg}
+
+	web := d.buildOrigin(&recordingChannel{name: "web"}, &channel.Message{ChannelID: "web:12345", UserID: "12345"})
+	if web.IsAdmin {
+		t.Fatal("web session named after the admin ID was treated as admin")
+	}
+	tg := d.buildOrigin(&recordingChannel{name: "telegram"}, &channel.Message{ChannelID: "telegram:12345", UserID: "12345"})
+	if !tg.IsAdmin {
+		t.Fatal("telegram admin was not recognised")
+	}
+}
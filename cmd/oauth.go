@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
@@ -12,8 +13,10 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,7 +29,9 @@ import (
 )
 
 const (
-	oauthCallbackAddr = "127.0.0.1:14155"
+	oauthCallbackHost = "127.0.0.1"
+	oauthCallbackPort = 14155
+	oauthPortEnv      = "NAGOBOT_OAUTH_PORT"
 	oauthCallbackPath = "/auth/callback"
 	oauthTimeout      = 5 * time.Minute
 	oauthHTTPTimeout  = 30 * time.Second
@@ -54,21 +59,29 @@ var oauthProviders = map[string]oauthProvider{
 		Name:     "anthropic",
 		AuthURL:  "https://claude.ai/oauth/authorize",
 		TokenURL: "https://console.anthropic.com/v1/oauth/token",
-		ClientID: "", // not yet available
+		ClientID: "", // no public client; set via --client-id, env or config
 		Scopes:   []string{"user:inference", "user:profile"},
 	},
 }
 
+var (
+	oauthNoBrowser bool
+	oauthPortFlag  int
+	oauthClientID  string
+)
+
 var oauthCmd = &cobra.Command{
 	Use:   "oauth",
 	Short: "Manage OAuth authentication for LLM providers",
 	Long: `Authenticate with OpenAI or Anthropic using OAuth.
 
 Examples:
-  nagobot oauth openai         # Login with OpenAI account
-  nagobot oauth anthropic      # Login with Anthropic account (coming soon)
-  nagobot oauth status         # Show current OAuth status
-  nagobot oauth logout openai  # Remove OpenAI OAuth token`,
+  nagobot oauth openai                      # Login with OpenAI account
+  nagobot oauth openai --no-browser         # Paste the redirect URL (headless servers)
+  nagobot oauth openai --port 0             # Use any free callback port
+  nagobot oauth anthropic --client-id <id>  # Login with Anthropic using your OAuth app
+  nagobot oauth status                      # Show current OAuth status
+  nagobot oauth logout openai               # Remove OpenAI OAuth token`,
 }
 
 var oauthStatusCmd = &cobra.Command{
@@ -94,13 +107,16 @@ var oauthOpenAICmd = &cobra.Command{
 
 var oauthAnthropicCmd = &cobra.Command{
 	Use:   "anthropic",
-	Short: "Login with Anthropic account (coming soon)",
+	Short: "Login with Anthropic account (requires an OAuth client ID)",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runOAuthLogin("anthropic")
 	},
 }
 
 func init() {
+	oauthCmd.PersistentFlags().IntVar(&oauthPortFlag, "port", -1, "Local callback port (default 14155, or $NAGOBOT_OAUTH_PORT; 0 picks a free port)")
+	oauthCmd.PersistentFlags().StringVar(&oauthClientID, "client-id", "", "OAuth client ID (or $NAGOBOT_<PROVIDER>_OAUTH_CLIENT_ID, or providers.<provider>OAuth.clientId)")
+	oauthCmd.PersistentFlags().BoolVar(&oauthNoBrowser, "no-browser", false, "Print the authorization URL and paste the redirect URL back instead of using a local callback")
 	oauthCmd.AddCommand(oauthStatusCmd)
 	oauthCmd.AddCommand(oauthLogoutCmd)
 	oauthCmd.AddCommand(oauthOpenAICmd)
@@ -116,14 +132,15 @@ func runOAuthLogin(providerName string) error {
 	if !ok {
 		return fmt.Errorf("unsupported OAuth provider: %s (supported: openai, anthropic)", providerName)
 	}
-	if prov.ClientID == "" {
-		return fmt.Errorf("%s OAuth is not yet available (client_id not configured)", providerName)
-	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	prov.ClientID = resolveOAuthClientID(cfg, prov, oauthClientID)
+	if prov.ClientID == "" {
+		return fmt.Errorf("%s OAuth client ID not configured: pass --client-id or set %s", providerName, oauthClientIDEnv(providerName))
+	}
 
 	// Generate PKCE verifier + challenge.
 	verifier, err := generateCodeVerifier()
@@ -138,21 +155,71 @@ func runOAuthLogin(providerName string) error {
 		return fmt.Errorf("failed to generate state: %w", err)
 	}
 
-	redirectURI := "http://" + oauthCallbackAddr + oauthCallbackPath
+	// Receive the code via the local callback, or have the user paste it when
+	// that is disabled or no callback port can be bound. The redirect URI
+	// follows the port actually bound so the auth URL and exchange agree.
+	port, err := oauthPort()
+	if err != nil {
+		return err
+	}
+	redirectURI := callbackRedirectURI(net.JoinHostPort(oauthCallbackHost, strconv.Itoa(port)))
+	var listener net.Listener
+	if !oauthNoBrowser {
+		if listener, err = listenOAuthCallback(port); err != nil {
+			fmt.Printf("Could not start callback server: %v\n", err)
+		} else {
+			redirectURI = callbackRedirectURI(listener.Addr().String())
+		}
+	}
 
 	// Build authorization URL.
 	authURL := buildAuthURL(prov, redirectURI, challenge, state)
 
-	// Start local callback server.
-	codeCh := make(chan string, 1)
-	errCh := make(chan error, 1)
-	var callbackOnce sync.Once
+	var code string
+	if listener == nil {
+		code, err = promptForAuthCode(providerName, authURL, state)
+	} else {
+		code, err = waitForAuthCallback(listener, providerName, authURL, state)
+	}
+	if err != nil {
+		return err
+	}
 
-	listener, err := net.Listen("tcp", oauthCallbackAddr)
+	// Exchange code for token.
+	fmt.Println("Exchanging authorization code for token...")
+	token, err := exchangeCodeForToken(prov, code, verifier, redirectURI)
 	if err != nil {
-		return fmt.Errorf("failed to start callback server on %s: %w", oauthCallbackAddr, err)
+		return fmt.Errorf("token exchange failed: %w", err)
 	}
 
+	// Store token in config, remembering a custom client ID for refreshes.
+	if prov.ClientID != oauthProviders[providerName].ClientID {
+		token.ClientID = prov.ClientID
+	}
+	cfg.SetOAuthToken(providerName, token)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Successfully authenticated with %s!\n", providerName)
+	if token.ExpiresAt > 0 {
+		fmt.Printf("Token expires: %s\n", time.Unix(token.ExpiresAt, 0).Local().Format(time.RFC3339))
+	}
+	if token.RefreshToken != "" {
+		fmt.Println("Refresh token saved (auto-refresh enabled).")
+	}
+
+	return nil
+}
+
+// waitForAuthCallback opens the browser at authURL and waits for the
+// authorization code to arrive at the local callback server on listener.
+func waitForAuthCallback(listener net.Listener, providerName, authURL, state string) (string, error) {
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	var callbackOnce sync.Once
+
 	mux := http.NewServeMux()
 	mux.HandleFunc(oauthCallbackPath, func(w http.ResponseWriter, r *http.Request) {
 		// Only handle the first valid callback.
@@ -207,39 +274,142 @@ func runOAuthLogin(providerName string) error {
 	fmt.Println("Waiting for authorization...")
 
 	// Wait for callback or timeout.
-	var code string
 	select {
-	case code = <-codeCh:
-		// success
+	case code := <-codeCh:
+		return code, nil
 	case err := <-errCh:
-		return err
+		return "", err
 	case <-time.After(oauthTimeout):
-		return fmt.Errorf("OAuth timeout: no callback received within %s", oauthTimeout)
+		return "", fmt.Errorf("OAuth timeout: no callback received within %s", oauthTimeout)
 	}
+}
 
-	// Exchange code for token.
-	fmt.Println("Exchanging authorization code for token...")
-	token, err := exchangeCodeForToken(prov, code, verifier, redirectURI)
-	if err != nil {
-		return fmt.Errorf("token exchange failed: %w", err)
+// oauthPort returns the callback port from --port, then NAGOBOT_OAUTH_PORT,
+// then the default. Port 0 asks the OS for a free port.
+func oauthPort() (int, error) {
+	if oauthPortFlag >= 0 {
+		return oauthPortFlag, nil
 	}
+	if v := strings.TrimSpace(os.Getenv(oauthPortEnv)); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 0 || port > 65535 {
+			return 0, fmt.Errorf("invalid %s %q", oauthPortEnv, v)
+		}
+		return port, nil
+	}
+	return oauthCallbackPort, nil
+}
 
-	// Store token in config.
-	cfg.SetOAuthToken(providerName, token)
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+// listenOAuthCallback binds the callback server on port, falling back to an
+// OS-assigned free port when that one is unavailable.
+func listenOAuthCallback(port int) (net.Listener, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(oauthCallbackHost, strconv.Itoa(port)))
+	if err == nil || port == 0 {
+		return listener, err
 	}
+	fallback, fallbackErr := net.Listen("tcp", net.JoinHostPort(oauthCallbackHost, "0"))
+	if fallbackErr != nil {
+		return nil, err
+	}
+	fmt.Printf("Port %d is unavailable (%v), using %s instead.\n", port, err, fallback.Addr())
+	return fallback, nil
+}
+
+// callbackRedirectURI returns the redirect URI served at addr ("host:port").
+func callbackRedirectURI(addr string) string {
+	return "http://" + addr + oauthCallbackPath
+}
 
+// promptForAuthCode prints authURL and reads the redirect URL or bare code
+// the user pastes back, for machines where the local callback cannot be used.
+func promptForAuthCode(providerName, authURL, state string) (string, error) {
+	fmt.Printf("Open this URL in a browser to authorize %s:\n", providerName)
 	fmt.Println()
-	fmt.Printf("Successfully authenticated with %s!\n", providerName)
-	if token.ExpiresAt > 0 {
-		fmt.Printf("Token expires: %s\n", time.Unix(token.ExpiresAt, 0).Local().Format(time.RFC3339))
+	fmt.Println("  " + authURL)
+	fmt.Println()
+	fmt.Println("After approving, the browser is sent to a local page that may fail to load.")
+	fmt.Print("Paste the full URL from the address bar (or just the code) here: ")
+
+	lineCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && strings.TrimSpace(line) == "" {
+			errCh <- fmt.Errorf("failed to read authorization code: %w", err)
+			return
+		}
+		lineCh <- line
+	}()
+
+	select {
+	case line := <-lineCh:
+		return parseAuthCode(line, state)
+	case err := <-errCh:
+		return "", err
+	case <-time.After(oauthTimeout):
+		return "", fmt.Errorf("OAuth timeout: no code entered within %s", oauthTimeout)
 	}
-	if token.RefreshToken != "" {
-		fmt.Println("Refresh token saved (auto-refresh enabled).")
+}
+
+// parseAuthCode extracts the authorization code from pasted input: a full
+// redirect URL, its query string, "code#state", or the bare code. A state
+// that is present must match.
+func parseAuthCode(input, state string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("no authorization code entered")
 	}
 
-	return nil
+	if strings.Contains(input, "code=") || strings.Contains(input, "error=") {
+		query := input
+		if i := strings.Index(query, "?"); i >= 0 {
+			query = query[i+1:]
+		}
+		query, _, _ = strings.Cut(query, "#")
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", fmt.Errorf("invalid redirect URL: %w", err)
+		}
+		if errMsg := values.Get("error"); errMsg != "" {
+			return "", fmt.Errorf("OAuth error: %s (%s)", errMsg, values.Get("error_description"))
+		}
+		if got := values.Get("state"); got != "" && got != state {
+			return "", fmt.Errorf("OAuth state mismatch")
+		}
+		if code := values.Get("code"); code != "" {
+			return code, nil
+		}
+		return "", fmt.Errorf("no authorization code in pasted URL")
+	}
+
+	code, gotState, hasState := strings.Cut(input, "#")
+	if hasState && gotState != state {
+		return "", fmt.Errorf("OAuth state mismatch")
+	}
+	if strings.ContainsAny(code, " /?") {
+		return "", fmt.Errorf("unrecognized input: paste the redirect URL or the code")
+	}
+	return code, nil
+}
+
+// resolveOAuthClientID picks the OAuth client ID from the flag value, then
+// NAGOBOT_<PROVIDER>_OAUTH_CLIENT_ID, then the stored token config, then the
+// built-in ID for prov.
+func resolveOAuthClientID(cfg *config.Config, prov oauthProvider, flagValue string) string {
+	if v := strings.TrimSpace(flagValue); v != "" {
+		return v
+	}
+	if v := strings.TrimSpace(os.Getenv(oauthClientIDEnv(prov.Name))); v != "" {
+		return v
+	}
+	if token := cfg.GetOAuthToken(prov.Name); token != nil && strings.TrimSpace(token.ClientID) != "" {
+		return strings.TrimSpace(token.ClientID)
+	}
+	return prov.ClientID
+}
+
+func oauthClientIDEnv(providerName string) string {
+	return "NAGOBOT_" + strings.ToUpper(providerName) + "_OAUTH_CLIENT_ID"
 }
 
 func runOAuthStatus(_ *cobra.Command, _ []string) error {
@@ -416,7 +586,11 @@ func RefreshOAuthToken(cfg *config.Config, providerName string) string {
 	}
 
 	prov, ok := oauthProviders[providerName]
-	if !ok || prov.TokenURL == "" || prov.ClientID == "" {
+	if !ok || prov.TokenURL == "" {
+		return ""
+	}
+	prov.ClientID = resolveOAuthClientID(cfg, prov, "")
+	if prov.ClientID == "" {
 		return ""
 	}
 
@@ -463,6 +637,7 @@ func RefreshOAuthToken(cfg *config.Config, providerName string) string {
 		AccessToken:  tokenResp.AccessToken,
 		RefreshToken: tokenResp.RefreshToken,
 		TokenType:    tokenResp.TokenType,
+		ClientID:     token.ClientID,
 	}
 	if newToken.RefreshToken == "" {
 		newToken.RefreshToken = token.RefreshToken // keep old refresh token
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+func TestParseAuthCode(t *testing.T) {
+	const state = "abc123"
+	cases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"redirect URL", "http://127.0.0.1:14155/auth/callback?code=xyz&state=abc123\n", "xyz", false},
+		{"query string", "code=xyz&state=abc123", "xyz", false},
+		{"URL without state", "http://127.0.0.1:14155/auth/callback?code=xyz", "xyz", false},
+		{"code with fragment", "http://127.0.0.1:14155/auth/callback?code=xyz&state=abc123#_", "xyz", false},
+		{"bare code", "  xyz  ", "xyz", false},
+		{"code#state", "xyz#abc123", "xyz", false},
+		{"state mismatch", "http://127.0.0.1:14155/auth/callback?code=xyz&state=other", "", true},
+		{"code#state mismatch", "xyz#other", "", true},
+		{"oauth error", "http://127.0.0.1:14155/auth/callback?error=access_denied&state=abc123", "", true},
+		{"missing code", "http://127.0.0.1:14155/auth/callback?state=abc123&code=", "", true},
+		{"empty", "   ", "", true},
+		{"not a code", "https://example.com/somewhere", "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseAuthCode(tc.input, state)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseAuthCode(%q) = %q, want error", tc.input, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("parseAuthCode(%q) = %q, %v; want %q", tc.input, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestListenOAuthCallbackFreePort(t *testing.T) {
+	listener, err := listenOAuthCallback(0)
+	if err != nil {
+		t.Fatalf("listenOAuthCallback(0) error = %v", err)
+	}
+	defer listener.Close()
+
+	redirect, err := url.Parse(callbackRedirectURI(listener.Addr().String()))
+	if err != nil {
+		t.Fatalf("redirect URI does not parse: %v", err)
+	}
+	if redirect.Scheme != "http" || redirect.Hostname() != oauthCallbackHost || redirect.Path != oauthCallbackPath {
+		t.Fatalf("redirect URI = %s, want http://%s:<port>%s", redirect, oauthCallbackHost, oauthCallbackPath)
+	}
+	if port, _ := strconv.Atoi(redirect.Port()); port == 0 {
+		t.Fatalf("redirect URI = %s, want an assigned port", redirect)
+	}
+
+	// The redirect URI reaches the listener.
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("tcp", redirect.Host)
+	if err != nil {
+		t.Fatalf("dial %s: %v", redirect.Host, err)
+	}
+	conn.Close()
+}
+
+func TestListenOAuthCallbackFallsBackWhenPortBusy(t *testing.T) {
+	busy, err := net.Listen("tcp", net.JoinHostPort(oauthCallbackHost, "0"))
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	listener, err := listenOAuthCallback(busyPort)
+	if err != nil {
+		t.Fatalf("listenOAuthCallback(%d) error = %v", busyPort, err)
+	}
+	defer listener.Close()
+	if port := listener.Addr().(*net.TCPAddr).Port; port == busyPort || port == 0 {
+		t.Fatalf("bound port %d, want a different free port", port)
+	}
+}
+
+func TestOAuthPortPrecedence(t *testing.T) {
+	defer func(prev int) { oauthPortFlag = prev }(oauthPortFlag)
+
+	oauthPortFlag = -1
+	t.Setenv(oauthPortEnv, "")
+	if port, err := oauthPort(); err != nil || port != oauthCallbackPort {
+		t.Fatalf("default oauthPort() = %d, %v; want %d", port, err, oauthCallbackPort)
+	}
+	t.Setenv(oauthPortEnv, "15000")
+	if port, err := oauthPort(); err != nil || port != 15000 {
+		t.Fatalf("env oauthPort() = %d, %v; want 15000", port, err)
+	}
+	oauthPortFlag = 0
+	if port, err := oauthPort(); err != nil || port != 0 {
+		t.Fatalf("flag oauthPort() = %d, %v; want 0", port, err)
+	}
+	oauthPortFlag = -1
+	t.Setenv(oauthPortEnv, "nope")
+	if _, err := oauthPort(); err == nil {
+		t.Fatal("oauthPort() accepted an invalid env value")
+	}
+}
+
+func TestExchangeCodeForToken(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"at","refresh_token":"rt","expires_in":3600,"token_type":"bearer"}`)
+	}))
+	defer srv.Close()
+
+	prov := oauthProvider{Name: "anthropic", TokenURL: srv.URL, ClientID: "my-app"}
+	token, err := exchangeCodeForToken(prov, "the-code", "verifier", "http://127.0.0.1:1/auth/callback")
+	if err != nil {
+		t.Fatalf("exchangeCodeForToken() error = %v", err)
+	}
+	if token.AccessToken != "at" || token.RefreshToken != "rt" || token.ExpiresAt <= time.Now().Unix() {
+		t.Fatalf("token = %+v", token)
+	}
+	for key, want := range map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          "the-code",
+		"client_id":     "my-app",
+		"code_verifier": "verifier",
+		"redirect_uri":  "http://127.0.0.1:1/auth/callback",
+	} {
+		if got := form.Get(key); got != want {
+			t.Errorf("form %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestExchangeCodeForTokenReportsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant","error_description":"code expired"}`)
+	}))
+	defer srv.Close()
+
+	_, err := exchangeCodeForToken(oauthProvider{TokenURL: srv.URL, ClientID: "my-app"}, "c", "v", "r")
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("exchangeCodeForToken() error = %v, want invalid_grant", err)
+	}
+}
+
+func TestResolveOAuthClientID(t *testing.T) {
+	prov := oauthProviders["anthropic"]
+	cfg := &config.Config{}
+	t.Setenv(oauthClientIDEnv("anthropic"), "")
+
+	if got := resolveOAuthClientID(cfg, prov, ""); got != "" {
+		t.Fatalf("unconfigured client ID = %q, want empty", got)
+	}
+	cfg.Providers.AnthropicOAuth = &config.OAuthTokenConfig{ClientID: "from-config"}
+	if got := resolveOAuthClientID(cfg, prov, ""); got != "from-config" {
+		t.Fatalf("config client ID = %q, want from-config", got)
+	}
+	t.Setenv(oauthClientIDEnv("anthropic"), "from-env")
+	if got := resolveOAuthClientID(cfg, prov, ""); got != "from-env" {
+		t.Fatalf("env client ID = %q, want from-env", got)
+	}
+	if got := resolveOAuthClientID(cfg, prov, "from-flag"); got != "from-flag" {
+		t.Fatalf("flag client ID = %q, want from-flag", got)
+	}
+}
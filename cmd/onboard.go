@@ -32,6 +32,7 @@ func init() {
 // providerURLs maps provider names to their API key portal URLs.
 var providerURLs = map[string]string{
 	"deepseek":        "https://platform.deepseek.com",
+	"openai":          "https://platform.openai.com/api-keys",
 	"openrouter":      "https://openrouter.ai/keys",
 	"anthropic":       "https://console.anthropic.com",
 	"moonshot-cn":     "https://platform.moonshot.cn",
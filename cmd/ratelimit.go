@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+const (
+	rateLimitReply      = "You're sending messages too quickly. Please slow down and try again in a moment."
+	rateLimitPruneEvery = 10 * time.Minute
+)
+
+// rateLimiter is a token bucket per channel and user. Limits come from config
+// on each call, so a reloaded config applies to the next message.
+type rateLimiter struct {
+	cfg *config.Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastPrune time.Time
+}
+
+type rateBucket struct {
+	tokens   float64
+	last     time.Time
+	rate     float64 // tokens per second
+	burst    float64
+	notified bool // the user was told to slow down since the last allowed message
+}
+
+// refill adds the tokens earned since the last update, up to burst.
+func (b *rateBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+func newRateLimiter(cfg *config.Config) *rateLimiter {
+	return &rateLimiter{cfg: cfg, now: time.Now, buckets: make(map[string]*rateBucket)}
+}
+
+// Allow reports whether userID may send another message on channelName, and
+// whether a throttled user should be told so. Only the first rejection in a
+// row is notified, so the reply itself cannot flood the chat.
+func (l *rateLimiter) Allow(channelName, userID string) (allowed, notify bool) {
+	perMinute, burst := l.cfg.GetRateLimit(channelName)
+	if perMinute <= 0 || userID == "" {
+		return true, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.pruneLocked(now)
+
+	key := channelName + ":" + userID
+	b := l.buckets[key]
+	if b == nil {
+		b = &rateBucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+	b.rate, b.burst = float64(perMinute)/60, float64(burst)
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.notified = false
+		return true, false
+	}
+	notify = !b.notified
+	b.notified = true
+	return false, notify
+}
+
+// pruneLocked forgets buckets that have refilled completely, since a fresh
+// bucket behaves the same.
+func (l *rateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneEvery {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.refill(now); b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/channel"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/thread"
+)
+
+func rateLimitedConfig(perMinute, burst int) *config.Config {
+	return &config.Config{Channels: &config.ChannelsConfig{
+		AdminUserID: "1",
+		RateLimit: &config.RateLimitConfig{
+			RateLimitRule: config.RateLimitRule{PerMinute: perMinute, Burst: burst},
+			Channels:      map[string]config.RateLimitRule{"web": {}},
+		},
+	}}
+}
+
+func TestRateLimiterThrottlesBursts(t *testing.T) {
+	l := newRateLimiter(rateLimitedConfig(2, 3))
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("telegram", "42"); !ok {
+			t.Fatalf("message %d within burst was throttled", i+1)
+		}
+	}
+	if ok, notify := l.Allow("telegram", "42"); ok || !notify {
+		t.Fatalf("4th message: allowed=%t notify=%t, want throttled with a notice", ok, notify)
+	}
+	if ok, notify := l.Allow("telegram", "42"); ok || notify {
+		t.Fatalf("5th message: allowed=%t notify=%t, want throttled silently", ok, notify)
+	}
+	if ok, _ := l.Allow("telegram", "7"); !ok {
+		t.Fatal("another user was throttled by user 42's bucket")
+	}
+	if ok, _ := l.Allow("web", "42"); !ok {
+		t.Fatal("web override disables the limit but the message was throttled")
+	}
+
+	now = now.Add(30 * time.Second) // one token at 2/min
+	if ok, _ := l.Allow("telegram", "42"); !ok {
+		t.Fatal("message after refill was throttled")
+	}
+	if ok, notify := l.Allow("telegram", "42"); ok || !notify {
+		t.Fatalf("after refill: allowed=%t notify=%t, want a fresh notice", ok, notify)
+	}
+}
+
+func TestDispatchRateLimitExemptsAdmin(t *testing.T) {
+	cfg := rateLimitedConfig(1, 1)
+	d := &Dispatcher{cfg: cfg, limiter: newRateLimiter(cfg), threads: thread.NewManager(&thread.ThreadConfig{})}
+	ch := &recordingChannel{name: "telegram"}
+	send := func(userID string) {
+		d.dispatch(context.Background(), ch, &channel.Message{ChannelID: "telegram:" + userID, UserID: userID, Text: "hi", Metadata: map[string]string{"chat_id": userID}})
+	}
+
+	for i := 0; i < 5; i++ {
+		send("1")
+	}
+	if len(ch.replies) != 0 {
+		t.Fatalf("admin got %d rate limit replies, want none", len(ch.replies))
+	}
+
+	send("42")
+	send("42")
+	send("42")
+	if len(ch.replies) != 1 || ch.replies[0].Text != rateLimitReply || ch.replies[0].ReplyTo != "42" {
+		t.Fatalf("replies = %+v, want one slow-down notice to user 42", ch.replies)
+	}
+}
+
+func TestDispatchRateLimitKeysWebOnRemoteAddr(t *testing.T) {
+	cfg := rateLimitedConfig(1, 1)
+	cfg.Channels.RateLimit.Channels["web"] = config.RateLimitRule{PerMinute: 1, Burst: 1}
+	d := &Dispatcher{cfg: cfg, limiter: newRateLimiter(cfg), threads: thread.NewManager(&thread.ThreadConfig{})}
+	ch := &recordingChannel{name: "web"}
+	send := func(sessionID, addr string) {
+		d.dispatch(context.Background(), ch, &channel.Message{ChannelID: "web:" + sessionID, UserID: sessionID, Text: "hi", Metadata: map[string]string{"chat_id": sessionID, "remote_addr": addr}})
+	}
+
+	send("a", "10.0.0.1")
+	send("b", "10.0.0.1")
+	send("c", "10.0.0.1")
+	if len(ch.replies) != 1 || ch.replies[0].Text != rateLimitReply {
+		t.Fatalf("replies = %+v, want one slow-down notice despite rotating session IDs", ch.replies)
+	}
+
+	send("a", "10.0.0.2")
+	if len(ch.replies) != 1 {
+		t.Fatalf("another address was throttled: %+v", ch.replies)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/thread"
+	"github.com/linanwx/nagobot/tools"
+)
+
+const (
+	selfTestSessionKey = "system:selftest"
+	selfTestProbeFile  = ".nagobot-selftest"
+	selfTestTimeout    = 2 * time.Minute
+)
+
+// runStartupSelfTest runs one agent turn against the configured provider to
+// verify the provider, tool dispatch and session persistence before serving.
+// A probe file holding a random token is written to the workspace; the model
+// must read it with read_file and echo the token back, which proves a tool
+// call round-trip happened. The turn is then saved and reloaded.
+func runStartupSelfTest(ctx context.Context, cfg *config.Config, workspace string) error {
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	token := fmt.Sprintf("selftest-%d", time.Now().UnixNano())
+	probePath := filepath.Join(workspace, selfTestProbeFile)
+	if err := os.WriteFile(probePath, []byte(token+"\n"), 0644); err != nil {
+		return fmt.Errorf("workspace is not writable: %w", err)
+	}
+	defer os.Remove(probePath)
+
+	factory, err := provider.NewFactory(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider factory: %w", err)
+	}
+	prov, err := factory.Create("", "")
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.Register(tools.NewReadFileTool(workspace))
+	runner := thread.NewRunner(prov, toolRegistry)
+
+	userMsg := provider.UserMessage(fmt.Sprintf(
+		"Startup self-test. Call the read_file tool with path %q, then reply with only the file's content.",
+		selfTestProbeFile,
+	))
+	messages := []provider.Message{
+		provider.SystemMessage("You are running an automated health check. Always use the provided tools when asked."),
+		userMsg,
+	}
+	response, err := runner.RunWithMessages(tools.WithRuntimeContext(ctx, tools.RuntimeContext{
+		SessionKey: selfTestSessionKey,
+		Workspace:  workspace,
+	}), messages)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(response, token) {
+		return fmt.Errorf("model did not return the probe token via read_file (response: %q)", truncateSelfTest(response))
+	}
+
+	sessions, err := openSessionManager(cfg, cfg.GetStorageBackend())
+	if err != nil {
+		return fmt.Errorf("failed to open sessions: %w", err)
+	}
+	defer sessions.Store().Close()
+	if err := sessions.Save(&session.Session{
+		Key:      selfTestSessionKey,
+		Messages: []provider.Message{userMsg, provider.AssistantMessage(response)},
+	}); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if _, err := sessions.Reload(selfTestSessionKey); err != nil {
+		return fmt.Errorf("failed to reload session: %w", err)
+	}
+	return nil
+}
+
+// startupSelfTest runs the self-test according to the configured mode and
+// returns an error only when the mode is "fail".
+func startupSelfTest(ctx context.Context, cfg *config.Config, workspace string) error {
+	mode := cfg.GetStartupSelfTest()
+	if mode == config.SelfTestOff {
+		return nil
+	}
+
+	logger.Info("running startup self-test", "mode", mode)
+	start := time.Now()
+	if err := runStartupSelfTest(ctx, cfg, workspace); err != nil {
+		if mode == config.SelfTestFail {
+			return fmt.Errorf("startup self-test failed: %w", err)
+		}
+		logger.Warn("startup self-test failed", "err", err)
+		return nil
+	}
+	logger.Info("startup self-test passed", "latency", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func truncateSelfTest(s string) string {
+	const max = 200
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/linanwx/nagobot/channel"
 	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/internal/health"
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/thread"
 	"github.com/linanwx/nagobot/tools"
@@ -27,13 +29,18 @@ Supported channels:
   - cli: Interactive command line (default)
   - telegram: Telegram bot (requires TELEGRAM_BOT_TOKEN)
   - feishu: Feishu (Lark) bot (requires FEISHU_APP_ID + FEISHU_APP_SECRET)
+  - discord: Discord bot (requires DISCORD_BOT_TOKEN)
+  - slack: Slack app via Events API (requires SLACK_BOT_TOKEN + SLACK_SIGNING_SECRET)
   - web: Browser chat UI (http + websocket)
 
 Examples:
   nagobot serve              # Start all configured channels (default)
   nagobot serve --cli        # Start with CLI channel only
   nagobot serve --telegram   # Start with Telegram bot only
+  nagobot serve --telegram --reset-telegram-offset  # Re-read updates Telegram still holds
   nagobot serve --feishu     # Start with Feishu bot only
+  nagobot serve --discord    # Start with Discord bot only
+  nagobot serve --slack      # Start with Slack app only
   nagobot serve --web        # Start Web chat channel only`,
 	RunE: runServe,
 }
@@ -41,16 +48,23 @@ Examples:
 var (
 	serveTelegram bool
 	serveFeishu   bool
+	serveDiscord  bool
+	serveSlack    bool
 	serveCLI      bool
 	serveWeb      bool
+
+	serveResetTelegramOffset bool
 )
 
 func init() {
 	serveCmd.Flags().BoolVar(&serveTelegram, "telegram", false, "Enable Telegram bot channel")
 	serveCmd.Flags().BoolVar(&serveFeishu, "feishu", false, "Enable Feishu (Lark) bot channel")
+	serveCmd.Flags().BoolVar(&serveDiscord, "discord", false, "Enable Discord bot channel")
+	serveCmd.Flags().BoolVar(&serveSlack, "slack", false, "Enable Slack app channel")
 	serveCmd.Flags().BoolVar(&serveWeb, "web", false, "Enable Web chat channel")
 
 	serveCmd.Flags().BoolVar(&serveCLI, "cli", true, "Enable CLI channel (default: true)")
+	serveCmd.Flags().BoolVar(&serveResetTelegramOffset, "reset-telegram-offset", false, "Forget the saved Telegram polling offset and resume from the updates Telegram still holds")
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -66,40 +80,74 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 	installBinary(workspace)
 
-	threadMgr, err := buildThreadManager(cfg, true)
+	threadMgr, auditLog, err := buildThreadManager(cfg, true)
 	if err != nil {
 		return err
 	}
 	chManager := channel.NewManager()
 
-	finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeWeb, err := resolveServeTargets(cmd)
+	finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeDiscord, finalServeSlack, finalServeWeb, err := resolveServeTargets(cmd)
 	if err != nil {
 		return err
 	}
 
 	if finalServeWeb {
-		chManager.Register(channel.NewWebChannel(cfg))
+		chManager.Register(channel.NewWebChannel(cfg, threadMgr.SessionStore()))
 	}
 	if finalServeCLI {
 		chManager.Register(channel.NewCLIChannel())
 	}
 	if finalServeTelegram {
-		chManager.Register(channel.NewTelegramChannel(cfg))
+		tg := channel.NewTelegramChannel(cfg)
+		if tc, ok := tg.(*channel.TelegramChannel); ok && serveResetTelegramOffset {
+			if err := tc.ResetOffset(); err != nil {
+				return fmt.Errorf("failed to reset telegram offset: %w", err)
+			}
+			logger.Info("telegram polling offset reset")
+		}
+		chManager.Register(tg)
 	}
 	if finalServeFeishu {
 		chManager.Register(channel.NewFeishuChannel(cfg))
 	}
-	chManager.Register(channel.NewCronChannel(cfg))
+	if finalServeDiscord {
+		chManager.Register(channel.NewDiscordChannel(cfg))
+	}
+	if finalServeSlack {
+		chManager.Register(channel.NewSlackChannel(cfg))
+	}
+	cronChannel := channel.NewCronChannel(cfg)
+	chManager.Register(cronChannel)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Set default sink factory: resolves fallback sink per session key.
-	threadMgr.SetDefaultSinkFor(buildDefaultSinkFor(chManager, cfg))
+	defaultSinkFor := buildDefaultSinkFor(chManager, cfg)
+	threadMgr.SetDefaultSinkFor(defaultSinkFor)
+	threadMgr.SetChannelDroppedFn(chManager.DroppedMessages)
+
+	// Sensitive tool calls wait for the admin, who answers in the main session.
+	var approver *adminApprover
+	if cfg.GetToolApprovalEnabled() {
+		approver = newAdminApprover(defaultSinkFor, time.Duration(cfg.GetToolApprovalTimeout())*time.Second)
+		threadMgr.SetToolApprovalFunc(approver.Approve)
+	}
 
 	// Register shared tools.
 	threadMgr.RegisterTool(tools.NewWakeThreadTool(threadMgr))
 	threadMgr.RegisterTool(tools.NewCheckThreadTool(threadMgr))
+	threadMgr.RegisterTool(tools.NewSendFileTool(chManager, workspace, cfg.GetExecRestrictToWorkspace()))
+	if cc, ok := cronChannel.(*channel.CronChannel); ok && cc.Scheduler() != nil {
+		threadMgr.RegisterTool(tools.NewCronTool(cc.Scheduler()))
+	}
+	if len(cfg.Thread.Workspaces) > 0 {
+		threadMgr.RegisterTool(tools.NewSwitchWorkspaceTool(threadMgr))
+	}
+
+	// Turns run on their own context so shutdown can let them finish.
+	turnCtx, cancelTurns := context.WithCancel(context.Background())
+	defer cancelTurns()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -107,33 +155,100 @@ func runServe(cmd *cobra.Command, args []string) error {
 		<-sigChan
 		logger.Info("shutdown signal received")
 		cancel()
+		<-sigChan
+		logger.Info("second shutdown signal, cancelling running turns")
+		cancelTurns()
 	}()
 
+	if err := startupSelfTest(ctx, cfg, workspace); err != nil {
+		return err
+	}
+
 	logger.Info("nagobot is running. Press Ctrl+C to stop.")
 
 	if err := chManager.StartAll(ctx); err != nil {
 		return fmt.Errorf("failed to start channels: %w", err)
 	}
 
+	var healthServer *health.Server
+	if addr := cfg.GetHealthAddr(); addr != "" {
+		healthServer = newHealthServer(cfg, addr, workspace)
+		if err := healthServer.Start(); err != nil {
+			logger.Error("failed to start health server", "err", err)
+			healthServer = nil
+		}
+	}
+
 	// Start thread manager run loop in background.
-	go threadMgr.Run(ctx)
+	go threadMgr.Run(turnCtx)
 
 	// Dispatcher reads from channels and dispatches to threads. Blocks until ctx done.
 	dispatcher := NewDispatcher(chManager, threadMgr, cfg)
+	dispatcher.approver = approver
 	dispatcher.Run(ctx)
 
+	// Replies still go out through the channels, so stop them afterwards.
+	drainThreads(threadMgr, cancelTurns, time.Duration(cfg.GetShutdownGrace())*time.Second)
+	// No more runner events once turns have stopped; flush what is queued.
+	if err := auditLog.Close(); err != nil {
+		logger.Error("error closing audit log", "err", err)
+	}
+
 	if err := chManager.StopAll(); err != nil {
 		logger.Error("error stopping channels", "err", err)
 	}
+	if healthServer != nil {
+		if err := healthServer.Stop(); err != nil {
+			logger.Error("error stopping health server", "err", err)
+		}
+	}
 
 	logger.Info("nagobot service stopped")
 	return nil
 }
 
+// drainSaveTimeout bounds the wait for cancelled turns to save what they have.
+const drainSaveTimeout = 5 * time.Second
+
+// drainThreads gives running turns up to grace to finish, then cancels them
+// and waits briefly for their partial turns to be saved.
+func drainThreads(mgr *thread.Manager, cancelTurns context.CancelFunc, grace time.Duration) {
+	defer cancelTurns()
+	logger.Info("waiting for running turns to finish", "grace", grace)
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := mgr.Drain(ctx); err == nil {
+		return
+	}
+
+	logger.Warn("shutdown grace period over, cancelling running turns")
+	cancelTurns()
+	saveCtx, cancelSave := context.WithTimeout(context.Background(), drainSaveTimeout)
+	defer cancelSave()
+	if err := mgr.Drain(saveCtx); err != nil {
+		logger.Warn("running turns did not stop after cancellation")
+	}
+}
+
+// newHealthServer builds the HTTP probe server reporting on this process.
+func newHealthServer(cfg *config.Config, addr, workspace string) *health.Server {
+	sessionsDir, _ := cfg.SessionsDir()
+	return health.NewServer(addr, func() health.Options {
+		return health.Options{
+			Workspace:    workspace,
+			SessionsRoot: sessionsDir,
+			Provider:     cfg.Thread.Provider,
+			Model:        cfg.GetModelName(),
+		}
+	})
+}
+
 // buildDefaultSinkFor returns a factory that resolves the fallback sink for a given session key.
 func buildDefaultSinkFor(chMgr *channel.Manager, cfg *config.Config) func(string) thread.Sink {
 	adminID := strings.TrimSpace(cfg.GetAdminUserID())
 	feishuAdminID := strings.TrimSpace(cfg.GetFeishuAdminOpenID())
+	discordAdminID := strings.TrimSpace(cfg.GetDiscordAdminUserID())
+	slackAdminID := strings.TrimSpace(cfg.GetSlackAdminUserID())
 
 	return func(sessionKey string) thread.Sink {
 		// telegram:{userID} → send to that user.
@@ -171,7 +286,39 @@ func buildDefaultSinkFor(chMgr *channel.Manager, cfg *config.Config) func(string
 			}
 		}
 
-		// "main" → telegram admin > feishu admin > cli.
+		// discord:{userID} → send to that user via DM.
+		if strings.HasPrefix(sessionKey, "discord:") {
+			userID := strings.TrimPrefix(sessionKey, "discord:")
+			if userID != "" {
+				return thread.Sink{
+					Label: "your response will be sent to discord user " + userID,
+					Send: func(ctx context.Context, response string) error {
+						if strings.TrimSpace(response) == "" {
+							return nil
+						}
+						return chMgr.SendTo(ctx, "discord", response, "user:"+userID)
+					},
+				}
+			}
+		}
+
+		// slack:{userID} → post to the user's app DM.
+		if strings.HasPrefix(sessionKey, "slack:") {
+			userID := strings.TrimPrefix(sessionKey, "slack:")
+			if userID != "" {
+				return thread.Sink{
+					Label: "your response will be sent to slack user " + userID,
+					Send: func(ctx context.Context, response string) error {
+						if strings.TrimSpace(response) == "" {
+							return nil
+						}
+						return chMgr.SendTo(ctx, "slack", response, userID)
+					},
+				}
+			}
+		}
+
+		// "main" → telegram admin > feishu admin > discord admin > slack admin > cli.
 		if sessionKey == "main" {
 			if _, ok := chMgr.Get("telegram"); ok && adminID != "" {
 				return thread.Sink{
@@ -195,6 +342,28 @@ func buildDefaultSinkFor(chMgr *channel.Manager, cfg *config.Config) func(string
 					},
 				}
 			}
+			if _, ok := chMgr.Get("discord"); ok && discordAdminID != "" {
+				return thread.Sink{
+					Label: "your response will be sent to discord admin",
+					Send: func(ctx context.Context, response string) error {
+						if strings.TrimSpace(response) == "" {
+							return nil
+						}
+						return chMgr.SendTo(ctx, "discord", response, "user:"+discordAdminID)
+					},
+				}
+			}
+			if _, ok := chMgr.Get("slack"); ok && slackAdminID != "" {
+				return thread.Sink{
+					Label: "your response will be sent to slack admin",
+					Send: func(ctx context.Context, response string) error {
+						if strings.TrimSpace(response) == "" {
+							return nil
+						}
+						return chMgr.SendTo(ctx, "slack", response, slackAdminID)
+					},
+				}
+			}
 			if _, ok := chMgr.Get("cli"); ok {
 				return thread.Sink{
 					Label: "your response will be printed to cli",
@@ -213,19 +382,21 @@ func buildDefaultSinkFor(chMgr *channel.Manager, cfg *config.Config) func(string
 	}
 }
 
-func resolveServeTargets(cmd *cobra.Command) (finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeWeb bool, err error) {
+func resolveServeTargets(cmd *cobra.Command) (finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeDiscord, finalServeSlack, finalServeWeb bool, err error) {
 	if cmd == nil {
-		return false, false, false, false, fmt.Errorf("serve command is nil")
+		return false, false, false, false, false, false, fmt.Errorf("serve command is nil")
 	}
 	flags := cmd.Flags()
 	cliChanged := flags.Changed("cli")
 	telegramChanged := flags.Changed("telegram")
 	feishuChanged := flags.Changed("feishu")
+	discordChanged := flags.Changed("discord")
+	slackChanged := flags.Changed("slack")
 	webChanged := flags.Changed("web")
 
 	// No explicit channel flags -> default to all channels.
-	if !cliChanged && !telegramChanged && !feishuChanged && !webChanged {
-		return true, true, true, true, nil
+	if !cliChanged && !telegramChanged && !feishuChanged && !discordChanged && !slackChanged && !webChanged {
+		return true, true, true, true, true, true, nil
 	}
 
 	// Any explicit channel flag -> use explicit switches only.
@@ -238,14 +409,20 @@ func resolveServeTargets(cmd *cobra.Command) (finalServeCLI, finalServeTelegram,
 	if feishuChanged {
 		finalServeFeishu = serveFeishu
 	}
+	if discordChanged {
+		finalServeDiscord = serveDiscord
+	}
+	if slackChanged {
+		finalServeSlack = serveSlack
+	}
 	if webChanged {
 		finalServeWeb = serveWeb
 	}
 
-	if !finalServeCLI && !finalServeTelegram && !finalServeFeishu && !finalServeWeb {
-		return false, false, false, false, fmt.Errorf("no channels enabled; use --cli, --telegram, --feishu, or --web")
+	if !finalServeCLI && !finalServeTelegram && !finalServeFeishu && !finalServeDiscord && !finalServeSlack && !finalServeWeb {
+		return false, false, false, false, false, false, fmt.Errorf("no channels enabled; use --cli, --telegram, --feishu, --discord, --slack, or --web")
 	}
-	return finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeWeb, nil
+	return finalServeCLI, finalServeTelegram, finalServeFeishu, finalServeDiscord, finalServeSlack, finalServeWeb, nil
 }
 
 // installBinary copies the running executable to workspace/bin/nagobot.
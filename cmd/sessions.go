@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/skills"
+	"github.com/linanwx/nagobot/thread"
+	"github.com/linanwx/nagobot/tools"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Inspect and debug saved sessions",
+}
+
+var sessionsReplayCmd = &cobra.Command{
+	Use:   "replay <session-key>",
+	Short: "Re-run a saved session's user messages against the current config",
+	Long: `Replay a saved session through the agent and print the responses.
+
+By default only the last user message is re-run, using the history before it
+as context. Use --all to re-run every user message in order. The original
+session is left untouched unless --save is given.
+
+Examples:
+  nagobot sessions replay main
+  nagobot sessions replay telegram:12345 --all
+  nagobot sessions replay main --mock --no-tools`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionsReplay,
+}
+
+var sessionsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy all sessions between storage backends",
+	Long: `Copy every session from one storage backend to another (one-time migration).
+
+The source defaults to the other backend. Existing sessions in the destination
+with the same key are overwritten. Update storage.backend in config.yaml
+afterwards to switch over.
+
+Examples:
+  nagobot sessions migrate --to sqlite
+  nagobot sessions migrate --from sqlite --to file`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsMigrate,
+}
+
+var sessionsUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage per session, highest first",
+	Long: `Show accumulated prompt/completion token usage for saved sessions.
+
+Usage is recorded on each session as turns complete and is reset only when the
+session is deleted.
+
+Examples:
+  nagobot sessions usage
+  nagobot sessions usage --top 5`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsUsage,
+}
+
+var usageTop int
+
+var (
+	migrateFrom string
+	migrateTo   string
+)
+
+var (
+	replayAll     bool
+	replaySave    bool
+	replayMock    bool
+	replayNoTools bool
+)
+
+func init() {
+	sessionsReplayCmd.Flags().BoolVar(&replayAll, "all", false, "Re-run every user message instead of only the last one")
+	sessionsReplayCmd.Flags().BoolVar(&replaySave, "save", false, "Overwrite the session with the replayed conversation")
+	sessionsReplayCmd.Flags().BoolVar(&replayMock, "mock", false, "Use the offline mock provider for deterministic output")
+	sessionsReplayCmd.Flags().BoolVar(&replayNoTools, "no-tools", false, "Run without tools (avoids side effects such as exec)")
+	sessionsCmd.AddCommand(sessionsReplayCmd)
+
+	sessionsMigrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Source backend (file or sqlite); defaults to the opposite of --to")
+	sessionsMigrateCmd.Flags().StringVar(&migrateTo, "to", config.StorageBackendSQLite, "Destination backend (file or sqlite)")
+	sessionsCmd.AddCommand(sessionsMigrateCmd)
+
+	sessionsUsageCmd.Flags().IntVar(&usageTop, "top", 10, "Number of sessions to show (0 = all)")
+	sessionsCmd.AddCommand(sessionsUsageCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+func runSessionsReplay(_ *cobra.Command, args []string) error {
+	key := strings.TrimSpace(args[0])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	workspace, err := cfg.WorkspacePath()
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	sessions, err := openSessionManager(cfg, cfg.GetStorageBackend())
+	if err != nil {
+		return fmt.Errorf("failed to open sessions: %w", err)
+	}
+	defer sessions.Store().Close()
+	if _, found, err := sessions.Store().Load(key); err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	} else if !found {
+		return fmt.Errorf("session %q not found", key)
+	}
+	sess, err := sessions.Reload(key)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	userIdx := make([]int, 0, len(sess.Messages))
+	for i, m := range sess.Messages {
+		if m.Role == "user" {
+			userIdx = append(userIdx, i)
+		}
+	}
+	if len(userIdx) == 0 {
+		return fmt.Errorf("session %q has no user messages to replay", key)
+	}
+	if !replayAll {
+		userIdx = userIdx[len(userIdx)-1:]
+	}
+
+	var prov provider.Provider
+	if replayMock {
+		prov = provider.NewMockProvider()
+	} else {
+		factory, err := provider.NewFactory(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create provider factory: %w", err)
+		}
+		if prov, err = factory.Create("", ""); err != nil {
+			return fmt.Errorf("failed to create provider: %w", err)
+		}
+	}
+
+	toolRegistry, systemPrompt, err := buildReplayRuntime(cfg, workspace, !replayNoTools)
+	if err != nil {
+		return err
+	}
+	runner := thread.NewRunner(prov, toolRegistry)
+	ctx := tools.WithRuntimeContext(context.Background(), tools.RuntimeContext{
+		SessionKey: key,
+		Workspace:  workspace,
+	})
+
+	replayed := append([]provider.Message(nil), sess.Messages[:userIdx[0]]...)
+	for n, idx := range userIdx {
+		userMsg := sess.Messages[idx]
+		messages := append([]provider.Message{provider.SystemMessage(systemPrompt)}, replayed...)
+		messages = append(messages, userMsg)
+
+		fmt.Printf("=== [%d/%d] user (message #%d) ===\n%s\n\n", n+1, len(userIdx), idx+1, userMsg.Content)
+		response, err := runner.RunWithMessages(ctx, messages)
+		if err != nil {
+			return fmt.Errorf("replay of message #%d failed: %w", idx+1, err)
+		}
+		fmt.Printf("=== [%d/%d] assistant ===\n%s\n\n", n+1, len(userIdx), response)
+
+		replayed = append(replayed, userMsg, provider.AssistantMessage(response))
+	}
+
+	if !replaySave {
+		return nil
+	}
+	sess.Messages = replayed
+	if err := sessions.Save(sess); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	fmt.Printf("Session %q saved (%d messages)\n", key, len(replayed))
+	return nil
+}
+
+func runSessionsMigrate(_ *cobra.Command, _ []string) error {
+	to := strings.ToLower(strings.TrimSpace(migrateTo))
+	from := strings.ToLower(strings.TrimSpace(migrateFrom))
+	if from == "" {
+		from = config.StorageBackendFile
+		if to == config.StorageBackendFile {
+			from = config.StorageBackendSQLite
+		}
+	}
+	if from == to {
+		return fmt.Errorf("source and destination backends are both %s", from)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	src, err := openSessionManager(cfg, from)
+	if err != nil {
+		return fmt.Errorf("failed to open %s store: %w", from, err)
+	}
+	defer src.Store().Close()
+	dst, err := openSessionManager(cfg, to)
+	if err != nil {
+		return fmt.Errorf("failed to open %s store: %w", to, err)
+	}
+	defer dst.Store().Close()
+
+	migrated, err := migrateSessions(src.Store(), dst.Store())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Migrated %d sessions from %s to %s\n", migrated, from, to)
+	if cfg.GetStorageBackend() != to {
+		fmt.Printf("Set storage.backend: %s in config.yaml to use the new store.\n", to)
+	}
+	return nil
+}
+
+func runSessionsUsage(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	sessions, err := openSessionManager(cfg, cfg.GetStorageBackend())
+	if err != nil {
+		return fmt.Errorf("failed to open sessions: %w", err)
+	}
+	defer sessions.Store().Close()
+
+	keys, err := sessions.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	type row struct {
+		key   string
+		usage provider.Usage
+	}
+	rows := make([]row, 0, len(keys))
+	for _, key := range keys {
+		sess, found, err := sessions.Store().Load(key)
+		if err != nil || !found {
+			continue
+		}
+		rows = append(rows, row{key: key, usage: sess.Usage})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].usage.TotalTokens > rows[j].usage.TotalTokens
+	})
+	if usageTop > 0 && len(rows) > usageTop {
+		rows = rows[:usageTop]
+	}
+	if len(rows) == 0 {
+		fmt.Println("No sessions found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tPROMPT\tCOMPLETION\tTOTAL")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.key, r.usage.PromptTokens, r.usage.CompletionTokens, r.usage.TotalTokens)
+	}
+	return w.Flush()
+}
+
+// migrateSessions copies every session from src to dst, preserving timestamps.
+func migrateSessions(src, dst session.Store) (int, error) {
+	keys, err := src.List()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	migrated := 0
+	for _, key := range keys {
+		sess, found, err := src.Load(key)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to load session %q: %w", key, err)
+		}
+		if !found {
+			continue
+		}
+		if sess.Key == "" {
+			sess.Key = key
+		}
+		if err := dst.Save(sess); err != nil {
+			return migrated, fmt.Errorf("failed to save session %q: %w", key, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// buildReplayRuntime builds the tool registry and default agent system prompt
+// the same way a serve thread would.
+func buildReplayRuntime(cfg *config.Config, workspace string, withTools bool) (*tools.Registry, string, error) {
+	skillsDir, err := cfg.SkillsDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get skills directory: %w", err)
+	}
+	skillRegistry := skills.NewRegistry()
+	if err := skillRegistry.LoadFromDirectory(skillsDir); err != nil {
+		logger.Warn("failed to load skills", "dir", skillsDir, "err", err)
+	}
+
+	toolRegistry := tools.NewRegistry()
+	if withTools {
+		toolRegistry.SetLogsDir(filepath.Join(workspace, "logs", "tool_calls"))
+		toolRegistry.RegisterDefaultTools(workspace, defaultToolsConfig(cfg, skillRegistry, skillsDir))
+	}
+
+	a, err := agent.NewRegistry(workspace).New("")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build agent: %w", err)
+	}
+	a.Set("TIME", time.Now())
+	a.Set("TOOLS", toolRegistry.Names())
+	a.Set("SKILLS", skillRegistry.BuildPromptSection())
+	systemPrompt := a.Build()
+	if strings.TrimSpace(systemPrompt) == "" {
+		systemPrompt = "You are a helpful AI assistant."
+	}
+	return toolRegistry, systemPrompt, nil
+}
@@ -77,7 +77,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("Settings:")
 	fmt.Printf("  Max Tokens: %d\n", cfg.GetMaxTokens())
 	fmt.Printf("  Temperature: %.1f\n", cfg.GetTemperature())
-	fmt.Printf("  Context Window Tokens: %d\n", cfg.GetContextWindowTokens())
+	fmt.Printf("  Context Window Tokens: %d\n", provider.ContextWindowFor(cfg.GetModelName(), cfg.Thread.ModelContextWindows, cfg.GetContextWindowTokens()))
 	fmt.Printf("  Context Warn Ratio: %.2f\n", cfg.GetContextWarnRatio())
 
 	return nil
@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/audit"
 	"github.com/linanwx/nagobot/config"
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
@@ -14,32 +17,215 @@ import (
 	"github.com/linanwx/nagobot/tools"
 )
 
-func buildThreadManager(cfg *config.Config, enableSessions bool) (*thread.Manager, error) {
+// migrateLegacySessions moves session files left in the old config-dir
+// location into the workspace sessions directory.
+func migrateLegacySessions(sessionsDir string) {
+	legacyDir, err := config.LegacySessionsDir()
+	if err != nil {
+		return
+	}
+	moved, err := session.MigrateDir(legacyDir, sessionsDir)
+	if err != nil {
+		logger.Warn("failed to migrate legacy sessions", "from", legacyDir, "to", sessionsDir, "moved", moved, "err", err)
+		return
+	}
+	if moved > 0 {
+		logger.Info("migrated legacy sessions", "from", legacyDir, "to", sessionsDir, "files", moved)
+	}
+}
+
+// openSessionManager opens the session manager for the given storage backend.
+func openSessionManager(cfg *config.Config, backend string) (*session.Manager, error) {
+	sessionsDir, err := cfg.SessionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions directory: %w", err)
+	}
+
+	switch backend {
+	case config.StorageBackendFile:
+		migrateLegacySessions(sessionsDir)
+		return session.NewManager(sessionsDir)
+	case config.StorageBackendSQLite:
+		dbPath, err := cfg.SQLitePath()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve sqlite path: %w", err)
+		}
+		store, err := session.NewSQLiteStore(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		return session.NewManagerWithStore(sessionsDir, store), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s (expected %s or %s)", backend, config.StorageBackendFile, config.StorageBackendSQLite)
+	}
+}
+
+// defaultToolsConfig converts the built-in tool settings from config.
+func defaultToolsConfig(cfg *config.Config, skillRegistry tools.SkillProvider, skillsDir string) tools.DefaultToolsConfig {
+	deny, denyPatterns, allow := cfg.GetExecPolicy()
+	sanitizeEnv, envAllowlist := cfg.GetExecEnv()
+	return tools.DefaultToolsConfig{
+		ExecTimeout:         cfg.GetExecTimeout(),
+		WebSearchMaxResults: cfg.GetWebSearchMaxResults(),
+		WebSearchAPIKey:     cfg.GetWebSearchAPIKey(),
+		RestrictToWorkspace: cfg.GetExecRestrictToWorkspace(),
+		ExecPolicy:          tools.ExecPolicy{Deny: deny, DenyPatterns: denyPatterns, Allow: allow},
+		ExecSanitizeEnv:     sanitizeEnv,
+		ExecEnvAllowlist:    envAllowlist,
+		Skills:              skillRegistry,
+		SkillsDir:           skillsDir,
+		SkillsStrict:        cfg.GetSkillsStrictRequirements(),
+	}
+}
+
+// buildRouter creates the per-turn model router from config. It returns nil
+// when routing is disabled or no fast tier can be created.
+func buildRouter(cfg *config.Config, factory *provider.Factory, defaultProvider provider.Provider) *thread.Router {
+	routing := cfg.GetRouting()
+	if routing == nil {
+		return nil
+	}
+
+	create := func(m *config.RouteModelConfig) (provider.Provider, string) {
+		if m == nil || strings.TrimSpace(m.ModelType) == "" {
+			return nil, ""
+		}
+		p, err := factory.Create(m.Provider, m.ModelType)
+		if err != nil {
+			logger.Warn("routing model unavailable", "provider", m.Provider, "modelType", m.ModelType, "err", err)
+			return nil, ""
+		}
+		return p, m.ModelType
+	}
+
+	router := &thread.Router{
+		Classifier: &thread.HeuristicClassifier{
+			MaxFastChars:   routing.FastMaxChars,
+			StrongKeywords: routing.StrongKeywords,
+		},
+		Agents: make(map[string]thread.RouteTiers),
+	}
+	router.Default.Fast, router.Default.FastModel = create(routing.Fast)
+	router.Default.Strong, router.Default.StrongModel = create(routing.Strong)
+	if router.Default.Strong == nil {
+		router.Default.Strong, router.Default.StrongModel = defaultProvider, cfg.GetModelType()
+	}
+	for name, tiers := range routing.Agents {
+		if tiers == nil {
+			continue
+		}
+		var t thread.RouteTiers
+		t.Fast, t.FastModel = create(tiers.Fast)
+		t.Strong, t.StrongModel = create(tiers.Strong)
+		router.Agents[strings.TrimSpace(name)] = t
+	}
+
+	if router.Default.Fast == nil && len(router.Agents) == 0 {
+		logger.Warn("model routing enabled but no fast tier is configured; using the default model")
+		return nil
+	}
+	return router
+}
+
+// auditRecorder returns the thread hook that writes runner events to the audit
+// log, and the log itself so the caller can flush it on exit. Both are nil
+// when auditing is disabled or the log cannot be opened.
+func auditRecorder(cfg *config.Config) (func(string, thread.RunnerEvent), *audit.Log) {
+	if !cfg.Audit.Enabled {
+		return nil, nil
+	}
+	path, err := cfg.AuditPath()
+	if err != nil {
+		logger.Warn("audit log unavailable", "err", err)
+		return nil, nil
+	}
+	log, err := audit.Open(path, cfg.Audit.MaxSizeMB, cfg.Audit.MaxBackups)
+	if err != nil {
+		logger.Warn("audit log unavailable", "path", path, "err", err)
+		return nil, nil
+	}
+	return func(sessionKey string, ev thread.RunnerEvent) {
+		log.Record(runnerAuditEvent(sessionKey, ev))
+	}, log
+}
+
+func runnerAuditEvent(sessionKey string, ev thread.RunnerEvent) audit.Event {
+	data := map[string]any{}
+	if ev.Tool != "" {
+		data["tool"] = ev.Tool
+		data["call_id"] = ev.CallID
+		data["args"] = ev.Arguments
+	}
+	if ev.Kind == thread.EventToolCompleted {
+		data["result"] = ev.Result
+		data["is_error"] = ev.IsError
+		data["duration_ms"] = ev.Duration.Milliseconds()
+	}
+	if ev.Err != nil {
+		data["error"] = ev.Err.Error()
+	}
+	return audit.Event{Type: ev.Kind, Source: sessionKey, Data: data}
+}
+
+// profileResolver returns the thread hook that resolves agent provider
+// profiles. Each profile's provider is created on first use and shared by
+// later threads.
+func profileResolver(cfg *config.Config, factory *provider.Factory) func(string) (*thread.ProviderProfile, error) {
+	var mu sync.Mutex
+	cache := make(map[string]*thread.ProviderProfile)
+	return func(name string) (*thread.ProviderProfile, error) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		mu.Lock()
+		defer mu.Unlock()
+		if p, ok := cache[key]; ok {
+			return p, nil
+		}
+
+		p, err := factory.CreateProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		profile := &thread.ProviderProfile{Provider: p, ProviderName: cfg.GetProvider(), ModelName: cfg.GetModelName()}
+		if pc := cfg.GetProviderProfile(name); pc != nil && key != provider.DefaultProfile {
+			profile.ProviderName = strings.TrimSpace(pc.Provider)
+			profile.ModelName = strings.TrimSpace(pc.ModelName)
+			if profile.ModelName == "" {
+				profile.ModelName = strings.TrimSpace(pc.ModelType)
+			}
+		}
+		cache[key] = profile
+		return profile, nil
+	}
+}
+
+// buildThreadManager wires the thread manager from config. The returned audit
+// log, nil when auditing is off, must be closed once turns have stopped.
+func buildThreadManager(cfg *config.Config, enableSessions bool) (*thread.Manager, *audit.Log, error) {
 	if cfg == nil {
-		return nil, fmt.Errorf("config is nil")
+		return nil, nil, fmt.Errorf("config is nil")
 	}
 	workspace, err := cfg.WorkspacePath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workspace: %w", err)
+		return nil, nil, fmt.Errorf("failed to get workspace: %w", err)
 	}
 
 	providerFactory, err := provider.NewFactory(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create provider factory: %w", err)
+		return nil, nil, fmt.Errorf("failed to create provider factory: %w", err)
 	}
 
 	defaultProvider, err := providerFactory.Create("", "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create default provider: %w", err)
+		return nil, nil, fmt.Errorf("failed to create default provider: %w", err)
 	}
 
 	skillsDir, err := cfg.SkillsDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get skills directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to get skills directory: %w", err)
 	}
 	sessionsDir, err := cfg.SessionsDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sessions directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to get sessions directory: %w", err)
 	}
 
 	skillRegistry := skills.NewRegistry()
@@ -49,20 +235,33 @@ func buildThreadManager(cfg *config.Config, enableSessions bool) (*thread.Manage
 
 	toolRegistry := tools.NewRegistry()
 	toolRegistry.SetLogsDir(filepath.Join(workspace, "logs", "tool_calls"))
-	toolRegistry.RegisterDefaultTools(workspace, tools.DefaultToolsConfig{
-		ExecTimeout:         cfg.GetExecTimeout(),
-		WebSearchMaxResults: cfg.GetWebSearchMaxResults(),
-		RestrictToWorkspace: cfg.GetExecRestrictToWorkspace(),
-		Skills:              skillRegistry,
-	})
+	toolRegistry.RegisterDefaultTools(workspace, defaultToolsConfig(cfg, skillRegistry, skillsDir))
 
 	agentRegistry := agent.NewRegistry(workspace)
+	toolRegistry.Register(tools.NewAgentsTool(agentRegistry))
+
+	// Watchers live as long as the process; without one, registries fall
+	// back to re-reading their directory on each turn.
+	if _, err := skillRegistry.Watch(skillsDir); err != nil {
+		logger.Warn("skills hot reload unavailable, reloading per turn", "dir", skillsDir, "err", err)
+	}
+	if _, err := agentRegistry.Watch(); err != nil {
+		logger.Debug("agents hot reload unavailable, reloading per call", "err", err)
+	}
+
+	workspaces, err := cfg.NamedWorkspaces()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve workspaces: %w", err)
+	}
 
 	var sessions *session.Manager
 	if enableSessions {
-		sessions, err = session.NewManager(sessionsDir)
+		sessions, err = openSessionManager(cfg, cfg.GetStorageBackend())
 		if err != nil {
 			logger.Warn("session manager unavailable", "err", err)
+		} else {
+			toolRegistry.Register(tools.NewForkSessionTool(sessions))
+			toolRegistry.Register(tools.NewSessionTool(sessions))
 		}
 	}
 
@@ -85,6 +284,7 @@ func buildThreadManager(cfg *config.Config, enableSessions bool) (*thread.Manage
 		}
 	}
 
+	onRunnerEvent, auditLog := auditRecorder(cfg)
 	return thread.NewManager(&thread.ThreadConfig{
 		DefaultProvider:     defaultProvider,
 		ProviderName:        cfg.Thread.Provider,
@@ -93,11 +293,22 @@ func buildThreadManager(cfg *config.Config, enableSessions bool) (*thread.Manage
 		Skills:              skillRegistry,
 		Agents:              agentRegistry,
 		Workspace:           workspace,
+		Workspaces:          workspaces,
 		SkillsDir:           skillsDir,
 		SessionsDir:         sessionsDir,
 		ContextWindowTokens: cfg.GetContextWindowTokens(),
+		ModelContextWindows: cfg.Thread.ModelContextWindows,
 		ContextWarnRatio:    cfg.GetContextWarnRatio(),
+		HistoryTokenRatio:   cfg.GetHistoryTokenRatio(),
+		AutoCompact:         cfg.Thread.AutoCompact,
+		CompactKeepRecent:   cfg.Thread.CompactKeepRecent,
+		MaxActiveChildren:   cfg.Thread.MaxActiveChildren,
+		MaxSpawnedChildren:  cfg.Thread.MaxSpawnedChildren,
+		ToolProgress:        cfg.Tools.Exec.StreamProgress,
 		Sessions:            sessions,
 		HealthChannels:      healthChannels,
-	}), nil
+		Router:              buildRouter(cfg, providerFactory, defaultProvider),
+		Profiles:            profileResolver(cfg, providerFactory),
+		OnRunnerEvent:       onRunnerEvent,
+	}), auditLog, nil
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/config"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/thread"
+)
+
+func TestOpenSessionManagerUsesWorkspaceSessions(t *testing.T) {
+	root := t.TempDir()
+	config.SetConfigDir(filepath.Join(root, "config"))
+	t.Cleanup(func() { config.SetConfigDir("") })
+	cfg := &config.Config{Thread: config.ThreadConfig{Workspace: filepath.Join(root, "workspace")}}
+
+	legacy := filepath.Join(root, "config", "sessions", "telegram", "42", "session.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"key":"telegram:42","messages":[{"role":"user","content":"hi"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := openSessionManager(cfg, config.StorageBackendFile)
+	if err != nil {
+		t.Fatalf("openSessionManager() error = %v", err)
+	}
+	sess, err := sessions.Get("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess.Messages = []provider.Message{provider.UserMessage("hello")}
+	if err := sessions.Save(sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "workspace", "sessions", "main", "session.json")); err != nil {
+		t.Fatalf("saved session not under the workspace: %v", err)
+	}
+	migrated, err := sessions.Reload("telegram:42")
+	if err != nil || len(migrated.Messages) != 1 {
+		t.Fatalf("legacy session after open = %+v, %v; want its message", migrated, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("legacy session file still present (err %v)", err)
+	}
+}
+
+func TestAuditRecorderLogFlushesOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := &config.Config{Audit: config.AuditConfig{Enabled: true, File: path}}
+
+	record, log := auditRecorder(cfg)
+	if record == nil || log == nil {
+		t.Fatal("auditRecorder() = nil, want a hook and its log")
+	}
+	record("telegram:1", thread.RunnerEvent{Kind: thread.EventToolCalled, Tool: "exec", CallID: "c1"})
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"tool":"exec"`) {
+		t.Fatalf("audit log = %q, want the recorded event", data)
+	}
+
+	if record, log := auditRecorder(&config.Config{}); record != nil || log != nil {
+		t.Fatal("auditRecorder() with auditing off returned a hook")
+	}
+}
@@ -17,27 +17,89 @@ func SetConfigDir(dir string) {
 
 // Config is the root configuration structure.
 type Config struct {
-	Thread    ThreadConfig    `json:"thread" yaml:"thread"`
-	Providers ProvidersConfig `json:"providers" yaml:"providers"`
-	Tools     ToolsConfig     `json:"tools,omitempty" yaml:"tools,omitempty"`
-	Channels  *ChannelsConfig `json:"channels" yaml:"channels"`
-	Logging   LoggingConfig   `json:"logging,omitempty" yaml:"logging,omitempty"`
+	SchemaVersion int             `json:"schemaVersion,omitempty" yaml:"schemaVersion,omitempty"` // bumped by migrations on load
+	Thread        ThreadConfig    `json:"thread" yaml:"thread"`
+	Providers     ProvidersConfig `json:"providers" yaml:"providers"`
+	Tools         ToolsConfig     `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Channels      *ChannelsConfig `json:"channels" yaml:"channels"`
+	Logging       LoggingConfig   `json:"logging,omitempty" yaml:"logging,omitempty"`
+	Storage       StorageConfig   `json:"storage,omitempty" yaml:"storage,omitempty"`
+	Health        HealthConfig    `json:"health,omitempty" yaml:"health,omitempty"`
+	Cron          CronConfig      `json:"cron,omitempty" yaml:"cron,omitempty"`
+	Audit         AuditConfig     `json:"audit,omitempty" yaml:"audit,omitempty"`
+}
+
+// AuditConfig enables a JSON-lines trail of tool calls and agent errors.
+type AuditConfig struct {
+	Enabled    bool   `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	File       string `json:"file,omitempty" yaml:"file,omitempty"`             // defaults to audit/audit.jsonl under the config dir
+	MaxSizeMB  int    `json:"maxSizeMB,omitempty" yaml:"maxSizeMB,omitempty"`   // rotate past this size, 0 = never
+	MaxBackups int    `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"` // rotated files to keep, 0 = keep all
+}
+
+// CronConfig limits how scheduled jobs run. A run lasts until the agent turn
+// it starts has finished.
+type CronConfig struct {
+	MaxConcurrent int    `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"` // jobs running at once, 0 = unlimited
+	Overlap       string `json:"overlap,omitempty" yaml:"overlap,omitempty"`             // skip (default), queue or allow a run while the previous one is in flight
+}
+
+// HealthConfig controls the HTTP health probe server started by serve.
+type HealthConfig struct {
+	Addr string `json:"addr,omitempty" yaml:"addr,omitempty"` // e.g. 127.0.0.1:9090; empty disables /healthz and /livez
 }
 
 // ThreadConfig contains thread runtime defaults.
 type ThreadConfig struct {
-	Provider            string  `json:"provider" yaml:"provider"` // openrouter, anthropic, deepseek, moonshot-cn, moonshot-global
-	ModelType           string  `json:"modelType" yaml:"modelType"`
-	ModelName           string  `json:"modelName,omitempty" yaml:"modelName,omitempty"`                     // optional, defaults to modelType
-	Workspace           string  `json:"workspace,omitempty" yaml:"workspace,omitempty"`                     // defaults to ~/.nagobot/workspace
-	MaxTokens           int     `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`                     // defaults to 8192
-	Temperature         float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`                 // defaults to 0.95
-	ContextWindowTokens int     `json:"contextWindowTokens,omitempty" yaml:"contextWindowTokens,omitempty"` // defaults to 128000
-	ContextWarnRatio    float64 `json:"contextWarnRatio,omitempty" yaml:"contextWarnRatio,omitempty"`       // defaults to 0.8
+	Provider            string            `json:"provider" yaml:"provider"` // openrouter, anthropic, deepseek, moonshot-cn, moonshot-global
+	ModelType           string            `json:"modelType" yaml:"modelType"`
+	ModelName           string            `json:"modelName,omitempty" yaml:"modelName,omitempty"`                     // optional, defaults to modelType
+	Workspace           string            `json:"workspace,omitempty" yaml:"workspace,omitempty"`                     // defaults to ~/.nagobot/workspace
+	Workspaces          map[string]string `json:"workspaces,omitempty" yaml:"workspaces,omitempty"`                   // extra named workspaces (name → path) for switch_workspace
+	MaxTokens           int               `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`                     // defaults to 8192
+	Temperature         float64           `json:"temperature,omitempty" yaml:"temperature,omitempty"`                 // defaults to 0.95
+	ContextWindowTokens int               `json:"contextWindowTokens,omitempty" yaml:"contextWindowTokens,omitempty"` // defaults to 128000
+	ContextWarnRatio    float64           `json:"contextWarnRatio,omitempty" yaml:"contextWarnRatio,omitempty"`       // defaults to 0.8
+	ModelContextWindows map[string]int    `json:"modelContextWindows,omitempty" yaml:"modelContextWindows,omitempty"` // model → context window tokens, overrides the built-in table
+	HistoryTokenRatio   float64           `json:"historyTokenRatio,omitempty" yaml:"historyTokenRatio,omitempty"`     // drop oldest history beyond this share of contextWindowTokens, 0 disables
+	AutoCompact         bool              `json:"autoCompact,omitempty" yaml:"autoCompact,omitempty"`                 // summarize older turns when contextWarnRatio is reached instead of asking the model to
+	CompactKeepRecent   int               `json:"compactKeepRecent,omitempty" yaml:"compactKeepRecent,omitempty"`     // user turns kept verbatim by auto-compaction, defaults to 4
+	ProviderTimeout     int               `json:"providerTimeout,omitempty" yaml:"providerTimeout,omitempty"`         // seconds per provider request attempt, defaults to 300
+	ProviderMaxAttempts int               `json:"providerMaxAttempts,omitempty" yaml:"providerMaxAttempts,omitempty"` // attempts per provider call on 429/5xx/529, defaults to 3
+	ProviderRetryBaseMs int               `json:"providerRetryBaseMs,omitempty" yaml:"providerRetryBaseMs,omitempty"` // first retry backoff in ms, doubled per attempt, defaults to 1000
+	StartupSelfTest     string            `json:"startupSelfTest,omitempty" yaml:"startupSelfTest,omitempty"`         // serve startup check: off (default), warn, or fail
+	MaxActiveChildren   int               `json:"maxActiveChildren,omitempty" yaml:"maxActiveChildren,omitempty"`     // unfinished child threads one thread may have, defaults to 8
+	MaxSpawnedChildren  int               `json:"maxSpawnedChildren,omitempty" yaml:"maxSpawnedChildren,omitempty"`   // child threads one thread may spawn in its lifetime, defaults to 100
+	ShutdownGrace       int               `json:"shutdownGrace,omitempty" yaml:"shutdownGrace,omitempty"`             // seconds running turns get to finish on shutdown, defaults to 30
+	Routing             *RoutingConfig    `json:"routing,omitempty" yaml:"routing,omitempty"`                         // optional fast/strong model routing per turn
+}
+
+// RoutingConfig routes each turn to a fast (cheap) or strong model tier.
+// Agents pinned to a provider profile are not routed.
+type RoutingConfig struct {
+	Enabled        bool                         `json:"enabled" yaml:"enabled"`
+	Fast           *RouteModelConfig            `json:"fast,omitempty" yaml:"fast,omitempty"`                     // cheap tier for trivial turns
+	Strong         *RouteModelConfig            `json:"strong,omitempty" yaml:"strong,omitempty"`                 // defaults to thread provider/modelType
+	Agents         map[string]*RouteTiersConfig `json:"agents,omitempty" yaml:"agents,omitempty"`                 // agent name → tier overrides
+	FastMaxChars   int                          `json:"fastMaxChars,omitempty" yaml:"fastMaxChars,omitempty"`     // messages up to this length may use fast, defaults to 80
+	StrongKeywords []string                     `json:"strongKeywords,omitempty" yaml:"strongKeywords,omitempty"` // any match (case-insensitive) forces strong
+}
+
+// RouteTiersConfig overrides the fast/strong models for one agent.
+type RouteTiersConfig struct {
+	Fast   *RouteModelConfig `json:"fast,omitempty" yaml:"fast,omitempty"`
+	Strong *RouteModelConfig `json:"strong,omitempty" yaml:"strong,omitempty"`
+}
+
+// RouteModelConfig selects a provider model for a routing tier.
+type RouteModelConfig struct {
+	Provider  string `json:"provider,omitempty" yaml:"provider,omitempty"` // defaults to thread provider
+	ModelType string `json:"modelType" yaml:"modelType"`
 }
 
 // ProvidersConfig contains provider API configurations.
 type ProvidersConfig struct {
+	OpenAI         *ProviderConfig   `json:"openai,omitempty" yaml:"openai,omitempty"`
 	OpenRouter     *ProviderConfig   `json:"openrouter,omitempty" yaml:"openrouter,omitempty"`
 	Anthropic      *ProviderConfig   `json:"anthropic,omitempty" yaml:"anthropic,omitempty"`
 	DeepSeek       *ProviderConfig   `json:"deepseek,omitempty" yaml:"deepseek,omitempty"`
@@ -45,6 +107,18 @@ type ProvidersConfig struct {
 	MoonshotGlobal *ProviderConfig   `json:"moonshotGlobal,omitempty" yaml:"moonshotGlobal,omitempty"`
 	OpenAIOAuth    *OAuthTokenConfig `json:"openaiOAuth,omitempty" yaml:"openaiOAuth,omitempty"`
 	AnthropicOAuth *OAuthTokenConfig `json:"anthropicOAuth,omitempty" yaml:"anthropicOAuth,omitempty"`
+
+	Profiles map[string]*ProviderProfileConfig `json:"profiles,omitempty" yaml:"profiles,omitempty"` // named provider/model presets selectable by agents
+}
+
+// ProviderProfileConfig is a named provider/model preset. Empty key and base
+// fall back to the provider's own config.
+type ProviderProfileConfig struct {
+	Provider  string `json:"provider" yaml:"provider"`
+	ModelType string `json:"modelType" yaml:"modelType"`
+	ModelName string `json:"modelName,omitempty" yaml:"modelName,omitempty"` // optional, defaults to modelType
+	APIKey    string `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	APIBase   string `json:"apiBase,omitempty" yaml:"apiBase,omitempty"`
 }
 
 // OAuthTokenConfig stores an OAuth token with optional refresh capability.
@@ -53,6 +127,7 @@ type OAuthTokenConfig struct {
 	RefreshToken string `json:"refreshToken,omitempty" yaml:"refreshToken,omitempty"`
 	ExpiresAt    int64  `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"` // unix timestamp, 0 = no expiry
 	TokenType    string `json:"tokenType,omitempty" yaml:"tokenType,omitempty"` // "bearer"
+	ClientID     string `json:"clientId,omitempty" yaml:"clientId,omitempty"`   // OAuth app client ID, overrides the built-in one
 }
 
 // ProviderConfig contains API credentials for a provider.
@@ -63,8 +138,22 @@ type ProviderConfig struct {
 
 // ToolsConfig contains tool-related configuration.
 type ToolsConfig struct {
-	Web  WebToolsConfig  `json:"web,omitempty" yaml:"web,omitempty"`
-	Exec ExecToolsConfig `json:"exec,omitempty" yaml:"exec,omitempty"`
+	Web      WebToolsConfig     `json:"web,omitempty" yaml:"web,omitempty"`
+	Exec     ExecToolsConfig    `json:"exec,omitempty" yaml:"exec,omitempty"`
+	Approval ToolApprovalConfig `json:"approval,omitempty" yaml:"approval,omitempty"`
+	Skills   SkillToolsConfig   `json:"skills,omitempty" yaml:"skills,omitempty"`
+}
+
+// SkillToolsConfig contains use_skill configuration.
+type SkillToolsConfig struct {
+	StrictRequirements bool `json:"strictRequirements,omitempty" yaml:"strictRequirements,omitempty"` // refuse to load skills whose declared requirements are missing, instead of warning
+}
+
+// ToolApprovalConfig makes sensitive tool calls (exec, file writes outside the
+// workspace) wait for a yes/no reply from the admin in serve mode.
+type ToolApprovalConfig struct {
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Timeout int  `json:"timeout,omitempty" yaml:"timeout,omitempty"` // seconds to wait for the admin, defaults to 120; no reply denies
 }
 
 // LoggingConfig contains logging configuration.
@@ -73,6 +162,17 @@ type LoggingConfig struct {
 	Level   string `json:"level,omitempty" yaml:"level,omitempty"`   // debug, info, warn, error
 	Stdout  bool   `json:"stdout,omitempty" yaml:"stdout,omitempty"` // log to stdout
 	File    string `json:"file,omitempty" yaml:"file,omitempty"`     // log file path
+	Format  string `json:"format,omitempty" yaml:"format,omitempty"` // text (default) or json
+
+	MaxSizeMB  int  `json:"maxSizeMB,omitempty" yaml:"maxSizeMB,omitempty"`   // rotate the log file past this size, 0 = never
+	MaxBackups int  `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"` // rotated files to keep, 0 = keep all
+	Compress   bool `json:"compress,omitempty" yaml:"compress,omitempty"`     // gzip rotated files
+}
+
+// StorageConfig selects the session persistence backend.
+type StorageConfig struct {
+	Backend string `json:"backend,omitempty" yaml:"backend,omitempty"` // file (default) or sqlite
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`       // sqlite database path, defaults to {workspace}/sessions.db
 }
 
 // WebToolsConfig contains web tool configuration.
@@ -88,23 +188,53 @@ type SearchConfig struct {
 
 // ExecToolsConfig contains exec tool configuration.
 type ExecToolsConfig struct {
-	Timeout             int  `json:"timeout,omitempty" yaml:"timeout,omitempty"`                         // seconds
-	RestrictToWorkspace bool `json:"restrictToWorkspace,omitempty" yaml:"restrictToWorkspace,omitempty"` // restrict to workspace
+	Timeout             int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`                         // seconds
+	RestrictToWorkspace bool     `json:"restrictToWorkspace,omitempty" yaml:"restrictToWorkspace,omitempty"` // keep exec and file tools inside the workspace
+	DenyCommands        []string `json:"denyCommands,omitempty" yaml:"denyCommands,omitempty"`               // command prefixes rejected in any pipeline segment, e.g. "rm -rf"
+	DenyPatterns        []string `json:"denyPatterns,omitempty" yaml:"denyPatterns,omitempty"`               // regexes rejected anywhere in the command, e.g. curl.*\|\s*sh
+	AllowCommands       []string `json:"allowCommands,omitempty" yaml:"allowCommands,omitempty"`             // when set, only these binaries may run
+	SanitizeEnv         bool     `json:"sanitizeEnv,omitempty" yaml:"sanitizeEnv,omitempty"`                 // pass only allowlisted host env vars to commands
+	EnvAllowlist        []string `json:"envAllowlist,omitempty" yaml:"envAllowlist,omitempty"`               // host env vars kept when sanitizeEnv is on; empty uses PATH, HOME, LANG, etc.
+	StreamProgress      bool     `json:"streamProgress,omitempty" yaml:"streamProgress,omitempty"`           // send the latest output line of long commands to the chat while they run
 }
 
 // ChannelsConfig contains channel configurations.
 type ChannelsConfig struct {
 	AdminUserID string                 `json:"adminUserID" yaml:"adminUserID"`                   // Cross-channel admin user id for shared "main" session
-	UserAgents  map[string]string      `json:"userAgents,omitempty" yaml:"userAgents,omitempty"` // userID → default agent name
+	UserAgents  map[string]string      `json:"userAgents,omitempty" yaml:"userAgents,omitempty"` // userID or channel:userID → agent name; unlisted users get the default agent
 	Telegram    *TelegramChannelConfig `json:"telegram" yaml:"telegram"`
 	Feishu      *FeishuChannelConfig   `json:"feishu,omitempty" yaml:"feishu,omitempty"`
+	Discord     *DiscordChannelConfig  `json:"discord,omitempty" yaml:"discord,omitempty"`
+	Slack       *SlackChannelConfig    `json:"slack,omitempty" yaml:"slack,omitempty"`
 	Web         *WebChannelConfig      `json:"web,omitempty" yaml:"web,omitempty"`
+
+	MessageBufferSize     int `json:"messageBufferSize,omitempty" yaml:"messageBufferSize,omitempty"`         // inbound buffer per channel, 0 = channel default
+	BackpressureTimeoutMs int `json:"backpressureTimeoutMs,omitempty" yaml:"backpressureTimeoutMs,omitempty"` // block up to N ms on a full buffer before dropping, 0 = default (2000), negative = drop immediately
+
+	ReplyThreading string `json:"replyThreading,omitempty" yaml:"replyThreading,omitempty"` // quote the triggering message: group (default), always, or off
+
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+}
+
+// RateLimitRule caps how fast one user may send messages.
+type RateLimitRule struct {
+	PerMinute int `json:"perMinute,omitempty" yaml:"perMinute,omitempty"` // sustained messages per minute, 0 = unlimited
+	Burst     int `json:"burst,omitempty" yaml:"burst,omitempty"`         // messages allowed at once, defaults to perMinute
+}
+
+// RateLimitConfig applies a per-user limit to every channel, with optional
+// per-channel overrides. Admins are exempt.
+type RateLimitConfig struct {
+	RateLimitRule `json:",inline" yaml:",inline"`
+	Channels      map[string]RateLimitRule `json:"channels,omitempty" yaml:"channels,omitempty"` // channel name → rule replacing the global one
 }
 
 // TelegramChannelConfig contains Telegram bot configuration.
 type TelegramChannelConfig struct {
-	Token      string  `json:"token" yaml:"token"`           // Bot token from BotFather
-	AllowedIDs []int64 `json:"allowedIds" yaml:"allowedIds"` // Allowed user/chat IDs
+	Token       string  `json:"token" yaml:"token"`                                 // Bot token from BotFather
+	AllowedIDs  []int64 `json:"allowedIds" yaml:"allowedIds"`                       // Allowed user/chat IDs
+	Placeholder string  `json:"placeholder,omitempty" yaml:"placeholder,omitempty"` // sent while working, then edited into the reply; "off" disables, defaults to "typing…"
+	ParseMode   string  `json:"parseMode,omitempty" yaml:"parseMode,omitempty"`     // html (default, Markdown converted to HTML), markdownv2 (escaped literal text), or none
 }
 
 // FeishuChannelConfig contains Feishu (Lark) bot configuration.
@@ -118,7 +248,27 @@ type FeishuChannelConfig struct {
 	AllowedOpenIDs    []string `json:"allowedOpenIds,omitempty" yaml:"allowedOpenIds,omitempty"` // empty = allow all
 }
 
+// DiscordChannelConfig contains Discord bot configuration.
+type DiscordChannelConfig struct {
+	Token       string   `json:"token" yaml:"token"`                                 // Bot token from the Discord developer portal
+	AdminUserID string   `json:"adminUserId,omitempty" yaml:"adminUserId,omitempty"` // Discord user routed to the shared "main" session
+	AllowedIDs  []string `json:"allowedIds,omitempty" yaml:"allowedIds,omitempty"`   // Allowed user/guild/channel IDs, empty = allow all
+}
+
+// SlackChannelConfig contains Slack app configuration (Events API).
+type SlackChannelConfig struct {
+	BotToken      string   `json:"botToken" yaml:"botToken"`                           // xoxb- bot token
+	SigningSecret string   `json:"signingSecret" yaml:"signingSecret"`                 // verifies Events API requests
+	WebhookAddr   string   `json:"webhookAddr,omitempty" yaml:"webhookAddr,omitempty"` // default: 127.0.0.1:9091
+	AdminUserID   string   `json:"adminUserId,omitempty" yaml:"adminUserId,omitempty"` // Slack user routed to the shared "main" session
+	AllowedIDs    []string `json:"allowedIds,omitempty" yaml:"allowedIds,omitempty"`   // Allowed user/channel IDs, empty = allow all
+}
+
 // WebChannelConfig contains Web chat configuration.
 type WebChannelConfig struct {
-	Addr string `json:"addr,omitempty" yaml:"addr,omitempty"` // default: 127.0.0.1:8080
+	Addr         string `json:"addr,omitempty" yaml:"addr,omitempty"`                 // default: 127.0.0.1:8080
+	PingInterval int    `json:"pingInterval,omitempty" yaml:"pingInterval,omitempty"` // seconds between server pings, default 30, negative disables keepalive
+	IdleTimeout  int    `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`   // close a connection with no message or pong for this many seconds, default 90
+
+	AllowedOrigins []string `json:"allowedOrigins,omitempty" yaml:"allowedOrigins,omitempty"` // cross-origin callers, e.g. http://192.168.1.5:3000; "*" allows any
 }
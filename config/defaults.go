@@ -7,13 +7,22 @@ const (
 	defaultTemperature         = 0.95
 	defaultContextWindowTokens = 128000
 	defaultContextWarnRatio    = 0.8
+	defaultProviderTimeout     = 300
+	defaultProviderMaxAttempts = 3
+	defaultProviderRetryBaseMs = 1000
+	defaultShutdownGrace       = 30
 	defaultWebAddr             = "127.0.0.1:8080"
+	defaultTelegramPlaceholder = "typing…"
+	defaultBackpressureMs      = 2000
+	defaultApprovalTimeout     = 120
+	defaultCronOverlap         = "skip"
 )
 
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() *Config {
 	logDefaults := defaultLoggingConfig()
 	return &Config{
+		SchemaVersion: currentSchemaVersion,
 		Thread: ThreadConfig{
 			Provider:            defaultProvider,
 			ModelType:           defaultModelType,
@@ -21,6 +30,9 @@ func DefaultConfig() *Config {
 			Temperature:         defaultTemperature,
 			ContextWindowTokens: defaultContextWindowTokens,
 			ContextWarnRatio:    defaultContextWarnRatio,
+			ProviderTimeout:     defaultProviderTimeout,
+			ProviderMaxAttempts: defaultProviderMaxAttempts,
+			ProviderRetryBaseMs: defaultProviderRetryBaseMs,
 		},
 		Providers: ProvidersConfig{
 			DeepSeek: &ProviderConfig{
@@ -70,6 +82,9 @@ func (c *Config) applyDefaults() {
 	if c.Thread.ContextWarnRatio <= 0 || c.Thread.ContextWarnRatio >= 1 {
 		c.Thread.ContextWarnRatio = defaultContextWarnRatio
 	}
+	if c.Thread.ProviderTimeout <= 0 {
+		c.Thread.ProviderTimeout = defaultProviderTimeout
+	}
 
 	if c.Channels == nil {
 		c.Channels = &ChannelsConfig{}
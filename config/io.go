@@ -2,10 +2,12 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
+	"github.com/linanwx/nagobot/logger"
 	"gopkg.in/yaml.v3"
 )
 
@@ -32,7 +34,14 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	migrated := cfg.migrate()
 	cfg.applyDefaults()
+	if migrated {
+		// Rewrite once so the file records the new version and defaults.
+		if err := cfg.saveMigrated(data); err != nil {
+			logger.Warn("failed to save migrated config", "path", path, "schemaVersion", cfg.SchemaVersion, "err", err)
+		}
+	}
 	return &cfg, nil
 }
 
@@ -59,3 +68,68 @@ func (c *Config) Save() error {
 
 	return os.WriteFile(path, data, 0600)
 }
+
+// saveMigrated writes c over the config file it was loaded from, given as
+// original, keeping that file's comments and key order: existing values are
+// updated in place and new keys appended to their section.
+func (c *Config) saveMigrated(original []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil {
+		return err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return c.Save()
+	}
+	var updated yaml.Node
+	if err := updated.Encode(c); err != nil {
+		return err
+	}
+	if updated.Kind != yaml.MappingNode {
+		return fmt.Errorf("config encoded as yaml kind %d, want a mapping", updated.Kind)
+	}
+	mergeYAMLMapping(doc.Content[0], &updated)
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// mergeYAMLMapping copies the keys of src into dst. Nested mappings are merged
+// recursively; other values replace dst's but keep its comments. Keys only in
+// dst are left as they are.
+func mergeYAMLMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		j := yamlMappingIndex(dst, key.Value)
+		if j < 0 {
+			dst.Content = append(dst.Content, key, value)
+			continue
+		}
+		old := dst.Content[j+1]
+		if old.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeYAMLMapping(old, value)
+			continue
+		}
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		dst.Content[j+1] = value
+	}
+}
+
+// yamlMappingIndex returns the index of key's key node in mapping, or -1.
+func yamlMappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package config
+
+// migrations upgrade a config one schema version at a time: migrations[i]
+// moves a config from version i to i+1. Each step only fills fields that are
+// still zero, so values the user set are preserved. Append a step (never
+// edit an old one) whenever a new field needs a default written to disk.
+var migrations = []func(*Config){
+	migrateV1,
+}
+
+// currentSchemaVersion is the schema version written by this build.
+var currentSchemaVersion = len(migrations)
+
+// migrate upgrades c to currentSchemaVersion and reports whether anything
+// ran. Configs from newer builds are left untouched.
+func (c *Config) migrate() bool {
+	if c.SchemaVersion >= currentSchemaVersion {
+		return false
+	}
+	for v := max(c.SchemaVersion, 0); v < currentSchemaVersion; v++ {
+		migrations[v](c)
+	}
+	c.SchemaVersion = currentSchemaVersion
+	return true
+}
+
+// migrateV1 backfills fields added before configs were versioned, so old
+// files show the values they have been running with.
+func migrateV1(c *Config) {
+	if c.Thread.ContextWindowTokens <= 0 {
+		c.Thread.ContextWindowTokens = defaultContextWindowTokens
+	}
+	if c.Thread.ContextWarnRatio <= 0 || c.Thread.ContextWarnRatio >= 1 {
+		c.Thread.ContextWarnRatio = defaultContextWarnRatio
+	}
+	if c.Thread.ProviderTimeout <= 0 {
+		c.Thread.ProviderTimeout = defaultProviderTimeout
+	}
+	if c.Thread.ProviderMaxAttempts <= 0 {
+		c.Thread.ProviderMaxAttempts = defaultProviderMaxAttempts
+	}
+	if c.Thread.ProviderRetryBaseMs <= 0 {
+		c.Thread.ProviderRetryBaseMs = defaultProviderRetryBaseMs
+	}
+	if c.Tools.Approval.Timeout <= 0 {
+		c.Tools.Approval.Timeout = defaultApprovalTimeout
+	}
+	if c.Cron.Overlap == "" {
+		c.Cron.Overlap = defaultCronOverlap
+	}
+}
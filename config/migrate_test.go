@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const oldShapedConfig = `thread:
+  provider: anthropic
+  modelType: claude-sonnet-4-5
+  providerTimeout: 60
+  contextWarnRatio: 0.5
+providers:
+  anthropic:
+    apiKey: sk-ant-xxx
+tools:
+  approval:
+    enabled: true
+cron:
+  overlap: queue
+channels:
+  adminUserID: "42"
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetConfigDir(dir)
+	t.Cleanup(func() { SetConfigDir("") })
+	path := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadMigratesOldConfig(t *testing.T) {
+	path := writeTestConfig(t, oldShapedConfig)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.SchemaVersion != currentSchemaVersion {
+		t.Fatalf("SchemaVersion = %d, want %d", cfg.SchemaVersion, currentSchemaVersion)
+	}
+
+	// Newly introduced fields are backfilled.
+	if cfg.Thread.ProviderMaxAttempts != defaultProviderMaxAttempts || cfg.Thread.ProviderRetryBaseMs != defaultProviderRetryBaseMs {
+		t.Fatalf("provider retry = %d/%d, want defaults", cfg.Thread.ProviderMaxAttempts, cfg.Thread.ProviderRetryBaseMs)
+	}
+	if cfg.Thread.ContextWindowTokens != defaultContextWindowTokens {
+		t.Fatalf("ContextWindowTokens = %d, want %d", cfg.Thread.ContextWindowTokens, defaultContextWindowTokens)
+	}
+	if cfg.Tools.Approval.Timeout != defaultApprovalTimeout {
+		t.Fatalf("Approval.Timeout = %d, want %d", cfg.Tools.Approval.Timeout, defaultApprovalTimeout)
+	}
+
+	// User-set values are preserved.
+	if cfg.Thread.ProviderTimeout != 60 || cfg.Thread.ContextWarnRatio != 0.5 {
+		t.Fatalf("thread = timeout %d, warn %v; want 60 and 0.5", cfg.Thread.ProviderTimeout, cfg.Thread.ContextWarnRatio)
+	}
+	if cfg.Cron.Overlap != "queue" || !cfg.Tools.Approval.Enabled || cfg.GetAdminUserID() != "42" {
+		t.Fatalf("user values clobbered: overlap %q, approval %v, admin %q", cfg.Cron.Overlap, cfg.Tools.Approval.Enabled, cfg.GetAdminUserID())
+	}
+	if cfg.Providers.Anthropic == nil || cfg.Providers.Anthropic.APIKey != "sk-ant-xxx" {
+		t.Fatalf("anthropic provider = %+v, want api key kept", cfg.Providers.Anthropic)
+	}
+
+	// The file is rewritten with the new version, and only once.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if !strings.Contains(string(data), "schemaVersion:") || !strings.Contains(string(data), "providerMaxAttempts: 3") {
+		t.Fatalf("config not rewritten:\n%s", data)
+	}
+	info, _ := os.Stat(path)
+	if _, err := Load(); err != nil {
+		t.Fatalf("second Load() error = %v", err)
+	}
+	after, _ := os.Stat(path)
+	if !after.ModTime().Equal(info.ModTime()) {
+		t.Fatal("current config was rewritten on load")
+	}
+}
+
+func TestMigrateLeavesNewerConfigAlone(t *testing.T) {
+	cfg := &Config{SchemaVersion: currentSchemaVersion + 1}
+	if cfg.migrate() {
+		t.Fatal("migrate() ran on a config from a newer build")
+	}
+	if cfg.Thread.ProviderMaxAttempts != 0 {
+		t.Fatalf("ProviderMaxAttempts = %d, want untouched", cfg.Thread.ProviderMaxAttempts)
+	}
+}
+
+func TestLoadMigrationKeepsComments(t *testing.T) {
+	path := writeTestConfig(t, `# nagobot config, edited by hand
+thread:
+  provider: anthropic # the main provider
+  # seconds before a provider call is abandoned
+  providerTimeout: 60
+providers:
+  anthropic:
+    apiKey: sk-ant-xxx
+# keep cron runs from piling up
+cron:
+  overlap: queue
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Thread.ProviderTimeout != 60 || cfg.Thread.ProviderMaxAttempts != defaultProviderMaxAttempts {
+		t.Fatalf("thread = timeout %d, attempts %d; want 60 and the default", cfg.Thread.ProviderTimeout, cfg.Thread.ProviderMaxAttempts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"# nagobot config, edited by hand",
+		"provider: anthropic # the main provider",
+		"# seconds before a provider call is abandoned",
+		"providerTimeout: 60",
+		"# keep cron runs from piling up",
+		"providerMaxAttempts: 3",
+		fmt.Sprintf("schemaVersion: %d", currentSchemaVersion),
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("rewritten config lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "thread:") > strings.Index(out, "cron:") {
+		t.Fatalf("rewritten config reordered sections:\n%s", out)
+	}
+
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("second Load() error = %v", err)
+	}
+	if reloaded.Thread.ProviderTimeout != 60 || reloaded.Providers.Anthropic == nil || reloaded.Providers.Anthropic.APIKey != "sk-ant-xxx" {
+		t.Fatalf("reloaded config = %+v, want user values kept", reloaded.Thread)
+	}
+}
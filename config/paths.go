@@ -68,6 +68,38 @@ func (c *Config) WorkspacePath() (string, error) {
 	return ws, nil
 }
 
+// DefaultWorkspaceName is the name of the primary workspace (thread.workspace).
+const DefaultWorkspaceName = "default"
+
+// NamedWorkspaces returns all switchable workspaces (name → absolute path),
+// including the primary workspace under DefaultWorkspaceName.
+func (c *Config) NamedWorkspaces() (map[string]string, error) {
+	primary, err := c.WorkspacePath()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{DefaultWorkspaceName: primary}
+	for name, path := range c.Thread.Workspaces {
+		name = strings.TrimSpace(name)
+		path = strings.TrimSpace(path)
+		if name == "" || path == "" || name == DefaultWorkspaceName {
+			continue
+		}
+		if path[0] == '~' {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(home, path[1:])
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		out[name] = path
+	}
+	return out, nil
+}
+
 // EnsureWorkspace creates the workspace directory if it doesn't exist.
 func (c *Config) EnsureWorkspace() error {
 	ws, err := c.WorkspacePath()
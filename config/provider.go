@@ -12,6 +12,14 @@ import (
 const (
 	sessionsDirName = "sessions"
 	skillsDirName   = "skills"
+	sqliteFileName  = "sessions.db"
+	auditFileName   = "audit/audit.jsonl"
+)
+
+// Session storage backends.
+const (
+	StorageBackendFile   = "file"
+	StorageBackendSQLite = "sqlite"
 )
 
 // SessionsDir returns the full path to the sessions directory.
@@ -23,6 +31,16 @@ func (c *Config) SessionsDir() (string, error) {
 	return filepath.Join(ws, sessionsDirName), nil
 }
 
+// LegacySessionsDir returns the sessions directory used before sessions moved
+// into the workspace. Serve migrates files found there into SessionsDir.
+func LegacySessionsDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionsDirName), nil
+}
+
 // SkillsDir returns the full path to the skills directory.
 func (c *Config) SkillsDir() (string, error) {
 	ws, err := c.WorkspacePath()
@@ -32,6 +50,108 @@ func (c *Config) SkillsDir() (string, error) {
 	return filepath.Join(ws, skillsDirName), nil
 }
 
+// Startup self-test modes.
+const (
+	SelfTestOff  = "off"
+	SelfTestWarn = "warn"
+	SelfTestFail = "fail"
+)
+
+// GetStartupSelfTest returns the serve startup self-test mode ("off", "warn" or "fail").
+// Unknown values are treated as "warn".
+func (c *Config) GetStartupSelfTest() string {
+	if c == nil {
+		return SelfTestOff
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(c.Thread.StartupSelfTest)); mode {
+	case "", SelfTestOff:
+		return SelfTestOff
+	case SelfTestFail:
+		return SelfTestFail
+	default:
+		return SelfTestWarn
+	}
+}
+
+// GetRouting returns the model routing config, or nil when routing is disabled.
+func (c *Config) GetRouting() *RoutingConfig {
+	if c == nil || c.Thread.Routing == nil || !c.Thread.Routing.Enabled {
+		return nil
+	}
+	return c.Thread.Routing
+}
+
+// GetHealthAddr returns the health probe listen address, or "" when disabled.
+func (c *Config) GetHealthAddr() string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Health.Addr)
+}
+
+// GetCronLimits returns the max concurrent cron runs (0 = unlimited) and the
+// overlap policy, defaulting to "skip".
+func (c *Config) GetCronLimits() (maxConcurrent int, overlap string) {
+	if c == nil {
+		return 0, defaultCronOverlap
+	}
+	overlap = strings.ToLower(strings.TrimSpace(c.Cron.Overlap))
+	if overlap == "" {
+		overlap = defaultCronOverlap
+	}
+	return max(c.Cron.MaxConcurrent, 0), overlap
+}
+
+// GetStorageBackend returns the session storage backend ("file" or "sqlite").
+func (c *Config) GetStorageBackend() string {
+	if c == nil || strings.TrimSpace(c.Storage.Backend) == "" {
+		return StorageBackendFile
+	}
+	return strings.ToLower(strings.TrimSpace(c.Storage.Backend))
+}
+
+// SQLitePath returns the SQLite database path, resolved relative to the workspace.
+func (c *Config) SQLitePath() (string, error) {
+	ws, err := c.WorkspacePath()
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(c.Storage.Path)
+	if path == "" {
+		return filepath.Join(ws, sqliteFileName), nil
+	}
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ws, path)
+	}
+	return path, nil
+}
+
+// AuditPath returns the audit log path, resolved relative to the config dir.
+func (c *Config) AuditPath() (string, error) {
+	path := strings.TrimSpace(c.Audit.File)
+	if path == "" {
+		path = auditFileName
+	}
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		dir, err := ConfigDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
 // GetProvider returns the configured default thread provider.
 func (c *Config) GetProvider() string {
 	if c == nil {
@@ -91,6 +211,59 @@ func (c *Config) GetContextWarnRatio() float64 {
 	return c.Thread.ContextWarnRatio
 }
 
+// GetHistoryTokenRatio returns the share of the context window session history may fill (0 = unlimited).
+func (c *Config) GetHistoryTokenRatio() float64 {
+	if c == nil || c.Thread.HistoryTokenRatio <= 0 || c.Thread.HistoryTokenRatio > 1 {
+		return 0
+	}
+	return c.Thread.HistoryTokenRatio
+}
+
+// GetProviderTimeout returns the per-attempt provider request timeout in seconds.
+func (c *Config) GetProviderTimeout() int {
+	if c == nil || c.Thread.ProviderTimeout <= 0 {
+		return defaultProviderTimeout
+	}
+	return c.Thread.ProviderTimeout
+}
+
+// GetProviderMaxAttempts returns how many times a provider call is attempted on transient errors.
+func (c *Config) GetProviderMaxAttempts() int {
+	if c == nil || c.Thread.ProviderMaxAttempts <= 0 {
+		return defaultProviderMaxAttempts
+	}
+	return c.Thread.ProviderMaxAttempts
+}
+
+// GetProviderRetryBaseMs returns the initial provider retry backoff in milliseconds.
+func (c *Config) GetProviderRetryBaseMs() int {
+	if c == nil || c.Thread.ProviderRetryBaseMs <= 0 {
+		return defaultProviderRetryBaseMs
+	}
+	return c.Thread.ProviderRetryBaseMs
+}
+
+// GetShutdownGrace returns how many seconds serve waits for running turns on shutdown.
+func (c *Config) GetShutdownGrace() int {
+	if c == nil || c.Thread.ShutdownGrace <= 0 {
+		return defaultShutdownGrace
+	}
+	return c.Thread.ShutdownGrace
+}
+
+// GetUserAgent returns the agent assigned to a user, or "" for the default
+// agent. A channel-qualified key ("feishu:ou_123") wins over the bare user ID.
+func (c *Config) GetUserAgent(channelName, userID string) string {
+	userID = strings.TrimSpace(userID)
+	if c == nil || c.Channels == nil || userID == "" {
+		return ""
+	}
+	if name := strings.TrimSpace(c.Channels.UserAgents[channelName+":"+userID]); name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.Channels.UserAgents[userID])
+}
+
 // GetAdminUserID returns the cross-channel admin user ID.
 func (c *Config) GetAdminUserID() string {
 	if c == nil || c.Channels == nil {
@@ -99,6 +272,45 @@ func (c *Config) GetAdminUserID() string {
 	return c.Channels.AdminUserID
 }
 
+// GetChannelMessageBufferSize returns the configured inbound buffer size (0 = channel default).
+func (c *Config) GetChannelMessageBufferSize() int {
+	if c == nil || c.Channels == nil || c.Channels.MessageBufferSize < 0 {
+		return 0
+	}
+	return c.Channels.MessageBufferSize
+}
+
+// GetChannelBackpressureTimeoutMs returns how long channels block on a full buffer before dropping.
+// Unset means 2s; a negative value disables blocking.
+func (c *Config) GetChannelBackpressureTimeoutMs() int {
+	if c == nil || c.Channels == nil || c.Channels.BackpressureTimeoutMs == 0 {
+		return defaultBackpressureMs
+	}
+	if c.Channels.BackpressureTimeoutMs < 0 {
+		return 0
+	}
+	return c.Channels.BackpressureTimeoutMs
+}
+
+// GetRateLimit returns the per-user message limit for a channel. A zero
+// perMinute means unlimited; burst defaults to perMinute.
+func (c *Config) GetRateLimit(channelName string) (perMinute, burst int) {
+	if c == nil || c.Channels == nil || c.Channels.RateLimit == nil {
+		return 0, 0
+	}
+	rule := c.Channels.RateLimit.RateLimitRule
+	if override, ok := c.Channels.RateLimit.Channels[channelName]; ok {
+		rule = override
+	}
+	if rule.PerMinute <= 0 {
+		return 0, 0
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.PerMinute
+	}
+	return rule.PerMinute, rule.Burst
+}
+
 // GetWebAddr returns the configured web channel listen address.
 func (c *Config) GetWebAddr() string {
 	if c == nil || c.Channels == nil || c.Channels.Web == nil {
@@ -107,6 +319,29 @@ func (c *Config) GetWebAddr() string {
 	return strings.TrimSpace(c.Channels.Web.Addr)
 }
 
+// GetWebKeepalive returns the configured web ping interval and idle timeout
+// in seconds (0 = channel default).
+func (c *Config) GetWebKeepalive() (pingInterval, idleTimeout int) {
+	if c == nil || c.Channels == nil || c.Channels.Web == nil {
+		return 0, 0
+	}
+	return c.Channels.Web.PingInterval, c.Channels.Web.IdleTimeout
+}
+
+// GetWebAllowedOrigins returns the origins allowed to call the web channel cross-origin.
+func (c *Config) GetWebAllowedOrigins() []string {
+	if c == nil || c.Channels == nil || c.Channels.Web == nil {
+		return nil
+	}
+	var origins []string
+	for _, origin := range c.Channels.Web.AllowedOrigins {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
 // GetTelegramToken returns the Telegram bot token (env overrides config).
 func (c *Config) GetTelegramToken() string {
 	if v := strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN")); v != "" {
@@ -126,6 +361,67 @@ func (c *Config) GetTelegramAllowedIDs() []int64 {
 	return c.Channels.Telegram.AllowedIDs
 }
 
+// GetTelegramPlaceholder returns the placeholder text sent before a reply, or
+// "" when edit-in-place is disabled.
+func (c *Config) GetTelegramPlaceholder() string {
+	if c == nil || c.Channels == nil || c.Channels.Telegram == nil {
+		return defaultTelegramPlaceholder
+	}
+	switch p := strings.TrimSpace(c.Channels.Telegram.Placeholder); strings.ToLower(p) {
+	case "":
+		return defaultTelegramPlaceholder
+	case "off", "none":
+		return ""
+	default:
+		return p
+	}
+}
+
+// Reply threading modes for channel responses.
+const (
+	ReplyThreadingOff    = "off"
+	ReplyThreadingGroup  = "group"
+	ReplyThreadingAlways = "always"
+)
+
+// GetReplyThreading returns when channel replies quote the triggering message.
+// Unknown values fall back to "group".
+func (c *Config) GetReplyThreading() string {
+	if c == nil || c.Channels == nil {
+		return ReplyThreadingGroup
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(c.Channels.ReplyThreading)); mode {
+	case ReplyThreadingOff, ReplyThreadingAlways:
+		return mode
+	default:
+		return ReplyThreadingGroup
+	}
+}
+
+// Telegram parse modes, named as the Bot API expects them. An empty mode sends
+// plain text.
+const (
+	TelegramParseModeHTML       = "HTML"
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+	TelegramParseModeNone       = ""
+)
+
+// GetTelegramParseMode returns the Bot API parse mode for outgoing messages.
+// Unknown values fall back to HTML.
+func (c *Config) GetTelegramParseMode() string {
+	if c == nil || c.Channels == nil || c.Channels.Telegram == nil {
+		return TelegramParseModeHTML
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Channels.Telegram.ParseMode)) {
+	case "markdownv2":
+		return TelegramParseModeMarkdownV2
+	case "none", "plain", "off":
+		return TelegramParseModeNone
+	default:
+		return TelegramParseModeHTML
+	}
+}
+
 // GetFeishuAppID returns the Feishu app ID (env overrides config).
 func (c *Config) GetFeishuAppID() string {
 	if v := strings.TrimSpace(os.Getenv("FEISHU_APP_ID")); v != "" {
@@ -191,6 +487,100 @@ func (c *Config) GetFeishuAllowedOpenIDs() []string {
 	return c.Channels.Feishu.AllowedOpenIDs
 }
 
+// GetDiscordToken returns the Discord bot token (env overrides config).
+func (c *Config) GetDiscordToken() string {
+	if v := strings.TrimSpace(os.Getenv("DISCORD_BOT_TOKEN")); v != "" {
+		return v
+	}
+	if c == nil || c.Channels == nil || c.Channels.Discord == nil {
+		return ""
+	}
+	return c.Channels.Discord.Token
+}
+
+// GetDiscordAdminUserID returns the Discord admin user ID.
+func (c *Config) GetDiscordAdminUserID() string {
+	if c == nil || c.Channels == nil || c.Channels.Discord == nil {
+		return ""
+	}
+	return c.Channels.Discord.AdminUserID
+}
+
+// GetDiscordAllowedIDs returns the Discord allowed user/guild/channel IDs.
+func (c *Config) GetDiscordAllowedIDs() []string {
+	if c == nil || c.Channels == nil || c.Channels.Discord == nil {
+		return nil
+	}
+	return c.Channels.Discord.AllowedIDs
+}
+
+// GetSlackBotToken returns the Slack bot token (env overrides config).
+func (c *Config) GetSlackBotToken() string {
+	if v := strings.TrimSpace(os.Getenv("SLACK_BOT_TOKEN")); v != "" {
+		return v
+	}
+	if c == nil || c.Channels == nil || c.Channels.Slack == nil {
+		return ""
+	}
+	return c.Channels.Slack.BotToken
+}
+
+// GetSlackSigningSecret returns the Slack signing secret (env overrides config).
+func (c *Config) GetSlackSigningSecret() string {
+	if v := strings.TrimSpace(os.Getenv("SLACK_SIGNING_SECRET")); v != "" {
+		return v
+	}
+	if c == nil || c.Channels == nil || c.Channels.Slack == nil {
+		return ""
+	}
+	return c.Channels.Slack.SigningSecret
+}
+
+// GetSlackWebhookAddr returns the Slack events listen address (default 127.0.0.1:9091).
+func (c *Config) GetSlackWebhookAddr() string {
+	if c == nil || c.Channels == nil || c.Channels.Slack == nil {
+		return "127.0.0.1:9091"
+	}
+	if v := strings.TrimSpace(c.Channels.Slack.WebhookAddr); v != "" {
+		return v
+	}
+	return "127.0.0.1:9091"
+}
+
+// GetSlackAdminUserID returns the Slack admin user ID.
+func (c *Config) GetSlackAdminUserID() string {
+	if c == nil || c.Channels == nil || c.Channels.Slack == nil {
+		return ""
+	}
+	return c.Channels.Slack.AdminUserID
+}
+
+// GetSlackAllowedIDs returns the Slack allowed user/channel IDs.
+func (c *Config) GetSlackAllowedIDs() []string {
+	if c == nil || c.Channels == nil || c.Channels.Slack == nil {
+		return nil
+	}
+	return c.Channels.Slack.AllowedIDs
+}
+
+// GetProviderProfile returns the named provider profile, or nil if it is not
+// configured. Names are case-insensitive.
+func (c *Config) GetProviderProfile(name string) *ProviderProfileConfig {
+	if c == nil {
+		return nil
+	}
+	name = strings.TrimSpace(name)
+	if p, ok := c.Providers.Profiles[name]; ok {
+		return p
+	}
+	for key, p := range c.Providers.Profiles {
+		if strings.EqualFold(key, name) {
+			return p
+		}
+	}
+	return nil
+}
+
 // GetOAuthToken returns the OAuth token config for the given provider name.
 func (c *Config) GetOAuthToken(providerName string) *OAuthTokenConfig {
 	if c == nil {
@@ -224,6 +614,11 @@ func (c *Config) ClearOAuthToken(providerName string) {
 // provider, creating it if nil.
 func (c *Config) ensureProviderConfig() *ProviderConfig {
 	switch c.GetProvider() {
+	case "openai":
+		if c.Providers.OpenAI == nil {
+			c.Providers.OpenAI = &ProviderConfig{}
+		}
+		return c.Providers.OpenAI
 	case "openrouter":
 		if c.Providers.OpenRouter == nil {
 			c.Providers.OpenRouter = &ProviderConfig{}
@@ -280,6 +675,47 @@ func (c *Config) GetExecRestrictToWorkspace() bool {
 	return c.Tools.Exec.RestrictToWorkspace
 }
 
+// GetExecPolicy returns the exec tool deny prefixes, deny patterns, and allowlist.
+func (c *Config) GetExecPolicy() (deny, denyPatterns, allow []string) {
+	if c == nil {
+		return nil, nil, nil
+	}
+	return c.Tools.Exec.DenyCommands, c.Tools.Exec.DenyPatterns, c.Tools.Exec.AllowCommands
+}
+
+// GetExecEnv returns whether exec runs with a sanitized environment and which
+// host variables it keeps.
+func (c *Config) GetExecEnv() (sanitize bool, allowlist []string) {
+	if c == nil {
+		return false, nil
+	}
+	return c.Tools.Exec.SanitizeEnv, c.Tools.Exec.EnvAllowlist
+}
+
+// GetToolApprovalEnabled returns whether sensitive tool calls need admin approval.
+func (c *Config) GetToolApprovalEnabled() bool {
+	if c == nil {
+		return false
+	}
+	return c.Tools.Approval.Enabled
+}
+
+// GetToolApprovalTimeout returns how long to wait for an admin approval reply, in seconds.
+func (c *Config) GetToolApprovalTimeout() int {
+	if c == nil || c.Tools.Approval.Timeout <= 0 {
+		return defaultApprovalTimeout
+	}
+	return c.Tools.Approval.Timeout
+}
+
+// GetSkillsStrictRequirements returns whether use_skill refuses skills with unmet requirements.
+func (c *Config) GetSkillsStrictRequirements() bool {
+	if c == nil {
+		return false
+	}
+	return c.Tools.Skills.StrictRequirements
+}
+
 // GetWebSearchMaxResults returns the web search max results.
 func (c *Config) GetWebSearchMaxResults() int {
 	if c == nil {
@@ -288,6 +724,14 @@ func (c *Config) GetWebSearchMaxResults() int {
 	return c.Tools.Web.Search.MaxResults
 }
 
+// GetWebSearchAPIKey returns the Brave Search API key, if configured.
+func (c *Config) GetWebSearchAPIKey() string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Tools.Web.Search.APIKey)
+}
+
 // BuildLoggerConfig returns a logger.Config ready for logger.Init().
 func (c *Config) BuildLoggerConfig() logger.Config {
 	enabled := true
@@ -299,6 +743,11 @@ func (c *Config) BuildLoggerConfig() logger.Config {
 		Level:   c.Logging.Level,
 		Stdout:  c.Logging.Stdout,
 		File:    c.Logging.File,
+		Format:  c.Logging.Format,
+
+		MaxSizeMB:  c.Logging.MaxSizeMB,
+		MaxBackups: c.Logging.MaxBackups,
+		Compress:   c.Logging.Compress,
 	}
 }
 
@@ -354,6 +803,8 @@ func (c *Config) GetAPIBase() string {
 
 func (c *Config) providerConfigEnv() (*ProviderConfig, string, string, error) {
 	switch c.GetProvider() {
+	case "openai":
+		return c.Providers.OpenAI, "OPENAI_API_KEY", "OPENAI_API_BASE", nil
 	case "openrouter":
 		return c.Providers.OpenRouter, "OPENROUTER_API_KEY", "OPENROUTER_API_BASE", nil
 	case "anthropic":
@@ -0,0 +1,206 @@
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	robfigcron "github.com/robfig/cron/v3"
+)
+
+// JobInfo is a scheduled job with its computed next activation.
+type JobInfo struct {
+	Job
+	NextRun time.Time  `json:"next_run"`           // zero when the job will not fire again
+	LastRun *RunStatus `json:"last_run,omitempty"` // nil until it runs after the scheduler started
+}
+
+// List returns the scheduled jobs sorted by ID.
+func (s *Scheduler) List() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	list := make([]JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		info := JobInfo{Job: job, NextRun: job.NextRunAfter(now)}
+		if status, ok := s.LastRun(job.ID); ok {
+			info.LastRun = &status
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Get returns the job with the given ID.
+func (s *Scheduler) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// Add schedules and persists a recurring job, replacing any job with the same
+// ID. It reports whether an existing job was replaced.
+func (s *Scheduler) Add(job Job) (bool, error) {
+	job.Kind = JobKindCron
+	job.AtTime = time.Time{}
+	return s.upsert(job)
+}
+
+// AddAt schedules and persists a one-time job, replacing any job with the same
+// ID. It reports whether an existing job was replaced.
+func (s *Scheduler) AddAt(job Job) (bool, error) {
+	job.Kind = JobKindAt
+	job.Expr = ""
+	return s.upsert(job)
+}
+
+// Remove unschedules and deletes a job. It reports whether the job existed.
+func (s *Scheduler) Remove(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[id]; !ok {
+		return false, nil
+	}
+	s.unscheduleLocked(id)
+	delete(s.jobs, id)
+	if err := s.saveLocked(); err != nil {
+		return true, fmt.Errorf("failed to save cron store: %w", err)
+	}
+	return true, nil
+}
+
+// SetEnabled pauses or resumes a job. A disabled job stays in the store but is
+// not scheduled until it is enabled again.
+func (s *Scheduler) SetEnabled(id string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if job.Disabled == !enabled {
+		return nil
+	}
+
+	if enabled {
+		cancel, err := s.scheduleLocked(job)
+		if err != nil {
+			return err
+		}
+		s.cancels[id] = cancel
+	} else {
+		s.unscheduleLocked(id)
+	}
+	job.Disabled = !enabled
+	s.jobs[id] = job
+	if err := s.saveLocked(); err != nil {
+		return fmt.Errorf("failed to save cron store: %w", err)
+	}
+	return nil
+}
+
+func (s *Scheduler) upsert(job Job) (bool, error) {
+	job = Normalize(job)
+	ok, expired := ValidateStored(job, time.Now())
+	if expired {
+		return false, fmt.Errorf("at time %s is in the past", job.AtTime.Format(time.RFC3339))
+	}
+	if !ok {
+		return false, fmt.Errorf("invalid job: check id, task, and schedule fields")
+	}
+	if job.Retry != nil && (job.Retry.MaxAttempts > maxRetryAttempts || job.Retry.BackoffSeconds < 0) {
+		return false, fmt.Errorf("invalid retry policy: max attempts must be at most %d and backoff non-negative", maxRetryAttempts)
+	}
+	if job.Timezone != "" {
+		if _, err := time.LoadLocation(job.Timezone); err != nil {
+			return false, fmt.Errorf("invalid timezone %q: %w", job.Timezone, err)
+		}
+	}
+	if job.Kind == JobKindCron {
+		if _, err := robfigcron.ParseStandard(job.cronSpec()); err != nil {
+			return false, fmt.Errorf("invalid cron expression %q: %w", job.Expr, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.jobs[job.ID]
+	s.unscheduleLocked(job.ID)
+	cancel, err := s.scheduleLocked(job)
+	if err != nil {
+		if existed {
+			if restore, restoreErr := s.scheduleLocked(previous); restoreErr == nil {
+				s.cancels[job.ID] = restore
+			}
+		}
+		return false, err
+	}
+	s.jobs[job.ID] = job
+	s.cancels[job.ID] = cancel
+	if err := s.saveLocked(); err != nil {
+		return existed, fmt.Errorf("failed to save cron store: %w", err)
+	}
+	return existed, nil
+}
+
+// NextRun returns when the job fires next, or the zero time if it will not.
+func (j Job) NextRun() time.Time {
+	return j.NextRunAfter(time.Now())
+}
+
+// NextRunAfter returns the first activation after now. Cron expressions use
+// the same standard 5-field parser the scheduler accepts, evaluated in the
+// job's timezone; at jobs return AtTime. Invalid and disabled jobs return the
+// zero time.
+func (j Job) NextRunAfter(now time.Time) time.Time {
+	if j.Disabled {
+		return time.Time{}
+	}
+	switch j.Kind {
+	case JobKindCron:
+		schedule, err := robfigcron.ParseStandard(j.cronSpec())
+		if err != nil {
+			return time.Time{}
+		}
+		return schedule.Next(now)
+	case JobKindAt:
+		return j.AtTime
+	}
+	return time.Time{}
+}
+
+// cronSpec returns Expr with a CRON_TZ prefix when the job has a timezone, so
+// the expression is evaluated in that zone instead of the server's.
+func (j Job) cronSpec() string {
+	if j.Timezone == "" {
+		return j.Expr
+	}
+	return "CRON_TZ=" + j.Timezone + " " + j.Expr
+}
+
+// ParseAtTime parses an at job time. RFC3339 values with an offset are used
+// as is; wall-clock values without one ("2006-01-02T15:04:05" or
+// "2006-01-02 15:04") are interpreted in timezone, or server local time when
+// timezone is empty.
+func ParseAtTime(value, timezone string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	loc := time.Local
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339 or a wall-clock time like 2006-01-02T15:04:05", value)
+}
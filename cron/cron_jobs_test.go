@@ -0,0 +1,189 @@
+package cron
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextRunAfterCronExpression(t *testing.T) {
+	job := Job{Kind: JobKindCron, Expr: "*/15 * * * *"}
+	now := time.Date(2026, 3, 1, 10, 7, 30, 0, time.UTC)
+
+	if got, want := job.NextRunAfter(now), time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("NextRunAfter(%s) = %s, want %s", now, got, want)
+	}
+	onBoundary := time.Date(2026, 3, 1, 10, 45, 0, 0, time.UTC)
+	if got, want := job.NextRunAfter(onBoundary), time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("NextRunAfter(%s) = %s, want %s", onBoundary, got, want)
+	}
+	if got := (Job{Kind: JobKindCron, Expr: "not a schedule"}).NextRunAfter(now); !got.IsZero() {
+		t.Fatalf("invalid expression NextRunAfter = %s, want zero", got)
+	}
+}
+
+func TestListIncludesNextRun(t *testing.T) {
+	sch, err := NewScheduler(filepath.Join(t.TempDir(), "cron.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	defer sch.Stop()
+
+	at := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	if _, err := sch.AddAt(Job{ID: "later", AtTime: at, Task: "remind"}); err != nil {
+		t.Fatalf("AddAt() error = %v", err)
+	}
+	if _, err := sch.Add(Job{ID: "quarter", Expr: "*/15 * * * *", Task: "poll"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	list := sch.List()
+	if len(list) != 2 || list[0].ID != "later" || list[1].ID != "quarter" {
+		t.Fatalf("List() = %+v, want later and quarter", list)
+	}
+	if !list[0].NextRun.Equal(at) {
+		t.Errorf("at job NextRun = %s, want %s", list[0].NextRun, at)
+	}
+	next := list[1].NextRun
+	if !next.After(time.Now()) || next.After(time.Now().Add(15*time.Minute)) || next.Minute()%15 != 0 || next.Second() != 0 {
+		t.Errorf("cron job NextRun = %s, want the next quarter hour", next)
+	}
+}
+
+func TestTimezoneCronJobFiresAtZoneInstant(t *testing.T) {
+	job := Job{Kind: JobKindCron, Expr: "0 9 * * *", Timezone: "Asia/Shanghai"}
+	now := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC) // 10:00 in Shanghai
+
+	// 09:00 Shanghai (UTC+8) on the next day is 01:00 UTC.
+	if got, want := job.NextRunAfter(now), time.Date(2026, 3, 2, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("NextRunAfter(%s) = %s, want %s", now, got, want)
+	}
+
+	sch, err := NewScheduler(filepath.Join(t.TempDir(), "cron.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	defer sch.Stop()
+	job.ID, job.Task = "shanghai", "briefing"
+	if _, err := sch.Add(job); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	sch.Start()
+
+	var scheduled time.Time
+	for _, j := range sch.cron.Jobs() {
+		if j.Name() == "shanghai" {
+			if scheduled, err = j.NextRun(); err != nil {
+				t.Fatalf("NextRun() error = %v", err)
+			}
+		}
+	}
+	if want := job.NextRun(); !scheduled.Equal(want) {
+		t.Fatalf("scheduler fires at %s, want %s", scheduled.UTC(), want.UTC())
+	}
+	if local := scheduled.In(time.FixedZone("CST", 8*3600)); local.Hour() != 9 || local.Minute() != 0 {
+		t.Fatalf("scheduler fires at %s Shanghai time, want 09:00", local)
+	}
+}
+
+func TestAddRejectsUnknownTimezone(t *testing.T) {
+	sch, err := NewScheduler("", nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	defer sch.Stop()
+	if _, err := sch.Add(Job{ID: "x", Expr: "0 9 * * *", Task: "t", Timezone: "Mars/Olympus"}); err == nil {
+		t.Fatal("Add() accepted an unknown timezone")
+	}
+	if len(sch.List()) != 0 {
+		t.Fatal("rejected job was scheduled")
+	}
+}
+
+func TestParseAtTimeUsesTimezoneForWallClock(t *testing.T) {
+	got, err := ParseAtTime("2026-03-01T09:00:00", "Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("ParseAtTime() error = %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("ParseAtTime() = %s, want %s", got.UTC(), want)
+	}
+
+	// An explicit offset wins over the timezone.
+	got, err = ParseAtTime("2026-03-01T09:00:00Z", "Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("ParseAtTime() error = %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("ParseAtTime() = %s, want %s", got.UTC(), want)
+	}
+
+	if _, err := ParseAtTime("2026-03-01T09:00:00", "Nowhere/Zone"); err == nil {
+		t.Fatal("ParseAtTime() accepted an unknown timezone")
+	}
+}
+
+func TestSetEnabledPausesAndResumesJob(t *testing.T) {
+	cronWithSeconds = true
+	defer func() { cronWithSeconds = false }()
+
+	var runs atomic.Int32
+	storePath := filepath.Join(t.TempDir(), "cron.jsonl")
+	if err := WriteJobs(storePath, []Job{{ID: "noisy", Kind: JobKindCron, Expr: "* * * * * *", Task: "tick"}}); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := NewScheduler(storePath, func(*Job) (string, error) {
+		runs.Add(1)
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	defer sch.Stop()
+	if err := sch.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	sch.Start()
+
+	waitForRuns := func(min int32) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for runs.Load() < min {
+			if time.Now().After(deadline) {
+				t.Fatalf("runs = %d, want at least %d", runs.Load(), min)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	waitForRuns(1)
+
+	if err := sch.SetEnabled("noisy", false); err != nil {
+		t.Fatalf("SetEnabled(false) error = %v", err)
+	}
+	// A reload must keep the job paused.
+	if err := sch.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	paused := runs.Load()
+	time.Sleep(1500 * time.Millisecond)
+	if got := runs.Load(); got != paused {
+		t.Fatalf("paused job fired: runs went from %d to %d", paused, got)
+	}
+	stored, err := ReadJobs(storePath)
+	if err != nil || len(stored) != 1 || !stored[0].Disabled {
+		t.Fatalf("store = %+v (err %v), want the job kept as disabled", stored, err)
+	}
+	if list := sch.List(); len(list) != 1 || !list[0].NextRun.IsZero() {
+		t.Fatalf("List() = %+v, want one paused job without a next run", list)
+	}
+
+	if err := sch.SetEnabled("noisy", true); err != nil {
+		t.Fatalf("SetEnabled(true) error = %v", err)
+	}
+	waitForRuns(paused + 1)
+
+	if err := sch.SetEnabled("missing", false); err == nil {
+		t.Fatal("SetEnabled() on an unknown job returned nil")
+	}
+}
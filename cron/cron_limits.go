@@ -0,0 +1,84 @@
+package cron
+
+import (
+	"strings"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+// Overlap policies for a job whose previous run is still in flight.
+const (
+	OverlapSkip  = "skip"  // drop the new run
+	OverlapQueue = "queue" // run after the previous one finishes; at most one run waits
+	OverlapAllow = "allow" // run concurrently
+)
+
+// Limits bound how job runs execute.
+type Limits struct {
+	MaxConcurrent int    // runs executing at once across all jobs, 0 = unlimited
+	Overlap       string // OverlapSkip (default), OverlapQueue or OverlapAllow
+}
+
+// jobGate serializes runs of one job. pending counts the running and waiting
+// runs and is guarded by Scheduler.runMu.
+type jobGate struct {
+	slot    chan struct{} // held by the running run
+	pending int
+}
+
+// SetLimits configures concurrency limits for subsequent runs.
+func (s *Scheduler) SetLimits(limits Limits) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	switch overlap := strings.ToLower(strings.TrimSpace(limits.Overlap)); overlap {
+	case OverlapQueue, OverlapAllow:
+		s.overlap = overlap
+	default:
+		s.overlap = OverlapSkip
+	}
+	s.sem = nil
+	if limits.MaxConcurrent > 0 {
+		s.sem = make(chan struct{}, limits.MaxConcurrent)
+	}
+}
+
+// runGuarded runs fn for job unless the overlap policy skips it, holding a
+// global concurrency slot while fn runs.
+func (s *Scheduler) runGuarded(job Job, fn func()) {
+	s.runMu.Lock()
+	overlap, sem := s.overlap, s.sem
+	var gate *jobGate
+	if overlap != OverlapAllow {
+		gate = s.gates[job.ID]
+		if gate == nil {
+			gate = &jobGate{slot: make(chan struct{}, 1)}
+			s.gates[job.ID] = gate
+		}
+		limit := 1
+		if overlap == OverlapQueue {
+			limit = 2
+		}
+		if gate.pending >= limit {
+			s.runMu.Unlock()
+			logger.Warn("cron run skipped: previous run still in progress", "id", job.ID, "overlap", overlap)
+			return
+		}
+		gate.pending++
+	}
+	s.runMu.Unlock()
+
+	if gate != nil {
+		gate.slot <- struct{}{}
+		defer func() {
+			<-gate.slot
+			s.runMu.Lock()
+			gate.pending--
+			s.runMu.Unlock()
+		}()
+	}
+	if sem != nil {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+	}
+	fn()
+}
@@ -0,0 +1,120 @@
+package cron
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlowJobRunsDoNotOverlap(t *testing.T) {
+	cronWithSeconds = true
+	defer func() { cronWithSeconds = false }()
+
+	var running, maxRunning, runs atomic.Int32
+	factory := func(*Job) (string, error) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		runs.Add(1)
+		time.Sleep(1200 * time.Millisecond)
+		running.Add(-1)
+		return "", nil
+	}
+
+	storePath := filepath.Join(t.TempDir(), "cron.jsonl")
+	if err := WriteJobs(storePath, []Job{{ID: "fast", Kind: JobKindCron, Expr: "* * * * * *", Task: "tick"}}); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := NewScheduler(storePath, factory)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	if err := sch.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	sch.Start()
+	time.Sleep(2500 * time.Millisecond)
+	sch.Stop()
+
+	if got := maxRunning.Load(); got != 1 {
+		t.Fatalf("max concurrent runs = %d, want 1", got)
+	}
+	// Ticks every second against a 1.2s run: skipped ticks keep it to about two runs.
+	if got := runs.Load(); got < 1 || got > 3 {
+		t.Fatalf("runs = %d, want 1-3", got)
+	}
+}
+
+func TestOverlapQueueKeepsOneWaitingRun(t *testing.T) {
+	sch, err := NewScheduler("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch.SetLimits(Limits{Overlap: OverlapQueue})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	var runs atomic.Int32
+	var wg sync.WaitGroup
+	job := Job{ID: "q"}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sch.runGuarded(job, func() {
+				runs.Add(1)
+				started <- struct{}{}
+				<-release
+			})
+		}()
+		if i == 0 {
+			<-started // the first run is in flight before the others arrive
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // let the third call be rejected
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 2 {
+		t.Fatalf("runs = %d, want 2 (one running, one queued, one skipped)", got)
+	}
+}
+
+func TestMaxConcurrentLimitsRunsAcrossJobs(t *testing.T) {
+	sch, err := NewScheduler("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch.SetLimits(Limits{MaxConcurrent: 2})
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			sch.runGuarded(Job{ID: id}, func() {
+				n := running.Add(1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				running.Add(-1)
+			})
+		}(id)
+	}
+	wg.Wait()
+
+	if got := maxRunning.Load(); got != 2 {
+		t.Fatalf("max concurrent runs = %d, want 2", got)
+	}
+}
@@ -0,0 +1,88 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+const (
+	defaultRetryBackoffSeconds = 30
+	maxRetryAttempts           = 10
+)
+
+// retryBackoffUnit scales RetryPolicy.BackoffSeconds; tests shorten it.
+var retryBackoffUnit = time.Second
+
+// RetryPolicy re-runs a job's factory after a failed execution.
+type RetryPolicy struct {
+	MaxAttempts    int `json:"max_attempts"`              // total attempts per occurrence, including the first
+	BackoffSeconds int `json:"backoff_seconds,omitempty"` // wait before the first retry, doubled per retry; defaults to 30
+}
+
+// RunStatus records the outcome of a job's most recent occurrence.
+type RunStatus struct {
+	At       time.Time `json:"at"`
+	Attempts int       `json:"attempts"`
+	Err      string    `json:"error,omitempty"` // last error; empty when the occurrence succeeded
+}
+
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	seconds := defaultRetryBackoffSeconds
+	if p != nil && p.BackoffSeconds > 0 {
+		seconds = p.BackoffSeconds
+	}
+	return time.Duration(seconds) * retryBackoffUnit << (retry - 1)
+}
+
+// execute runs the factory for one occurrence of job, retrying failures per
+// the job's policy, and records the outcome. It returns the last error.
+func (s *Scheduler) execute(job Job) error {
+	attempts := job.Retry.attempts()
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		jc := job
+		_, err = s.factory(&jc)
+		s.recordRun(job.ID, attempt, err)
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		wait := job.Retry.backoff(attempt)
+		logger.Warn("cron job attempt failed, retrying", "id", job.ID, "attempt", attempt, "maxAttempts", attempts, "backoff", wait, "err", err)
+		select {
+		case <-time.After(wait):
+		case <-s.stopped:
+			return err
+		}
+	}
+	return err
+}
+
+func (s *Scheduler) recordRun(id string, attempts int, err error) {
+	status := RunStatus{At: time.Now(), Attempts: attempts}
+	if err != nil {
+		status.Err = err.Error()
+	}
+	s.runMu.Lock()
+	s.lastRuns[id] = status
+	s.runMu.Unlock()
+}
+
+// LastRun returns the outcome of the job's most recent occurrence since the
+// scheduler started.
+func (s *Scheduler) LastRun(id string) (RunStatus, bool) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	status, ok := s.lastRuns[id]
+	return status, ok
+}
@@ -0,0 +1,119 @@
+package cron
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExecuteRetriesUntilSuccess(t *testing.T) {
+	retryBackoffUnit = time.Millisecond
+	defer func() { retryBackoffUnit = time.Second }()
+
+	calls := 0
+	sch, err := NewScheduler("", func(*Job) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("provider unavailable")
+		}
+		return "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := Job{ID: "flaky", Retry: &RetryPolicy{MaxAttempts: 3, BackoffSeconds: 1}}
+	if err := sch.execute(job); err != nil {
+		t.Fatalf("execute() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("factory calls = %d, want 3", calls)
+	}
+	status, ok := sch.LastRun("flaky")
+	if !ok || status.Attempts != 3 || status.Err != "" {
+		t.Fatalf("LastRun() = %+v, %v; want 3 attempts without error", status, ok)
+	}
+}
+
+func TestExecuteStopsAfterMaxAttempts(t *testing.T) {
+	retryBackoffUnit = time.Millisecond
+	defer func() { retryBackoffUnit = time.Second }()
+
+	calls := 0
+	sch, err := NewScheduler("", func(*Job) (string, error) {
+		calls++
+		return "", errors.New("boom")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sch.execute(Job{ID: "broken", Retry: &RetryPolicy{MaxAttempts: 2}}); err == nil {
+		t.Fatal("execute() error = nil, want failure")
+	}
+	if calls != 2 {
+		t.Fatalf("factory calls = %d, want 2", calls)
+	}
+	if status, _ := sch.LastRun("broken"); status.Err != "boom" {
+		t.Fatalf("LastRun().Err = %q, want boom", status.Err)
+	}
+
+	calls = 0
+	if err := sch.execute(Job{ID: "once"}); err == nil || calls != 1 {
+		t.Fatalf("without a policy: err = %v, calls = %d; want one failed attempt", err, calls)
+	}
+}
+
+func TestAtJobRemovedAfterRetries(t *testing.T) {
+	retryBackoffUnit = time.Millisecond
+	defer func() { retryBackoffUnit = time.Second }()
+
+	done := make(chan struct{})
+	calls := 0
+	factory := func(*Job) (string, error) {
+		calls++
+		if calls == 2 {
+			close(done)
+			return "", nil
+		}
+		return "", errors.New("transient")
+	}
+	sch, err := NewScheduler(filepath.Join(t.TempDir(), "cron.jsonl"), factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch.Start()
+	defer sch.Stop()
+
+	job := Job{ID: "remind", Task: "ping", AtTime: time.Now().Add(time.Second), Retry: &RetryPolicy{MaxAttempts: 3}}
+	if _, err := sch.AddAt(job); err != nil {
+		t.Fatalf("AddAt() error = %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("at job did not succeed on retry")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := sch.Get("remind"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("at job still stored after its successful retry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAddRejectsInvalidRetryPolicy(t *testing.T) {
+	sch, err := NewScheduler(filepath.Join(t.TempDir(), "cron.jsonl"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := Job{ID: "r", Expr: "0 9 * * *", Task: "x", Retry: &RetryPolicy{MaxAttempts: maxRetryAttempts + 1}}
+	if _, err := sch.Add(job); err == nil {
+		t.Fatal("Add() error = nil, want invalid retry policy")
+	}
+}
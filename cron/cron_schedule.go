@@ -8,6 +8,10 @@ import (
 	"github.com/linanwx/nagobot/logger"
 )
 
+// cronWithSeconds makes expressions take a leading seconds field; tests enable
+// it to get sub-minute schedules.
+var cronWithSeconds = false
+
 func (s *Scheduler) scheduleLocked(job Job) (func(), error) {
 	if s.cron == nil {
 		return nil, fmt.Errorf("scheduler is not initialized")
@@ -16,14 +20,16 @@ func (s *Scheduler) scheduleLocked(job Job) (func(), error) {
 	switch job.Kind {
 	case JobKindCron:
 		registered, err := s.cron.NewJob(
-			gocron.CronJob(job.Expr, false),
+			gocron.CronJob(job.cronSpec(), cronWithSeconds),
 			gocron.NewTask(func(j Job) {
 				if s.factory == nil {
 					return
 				}
-				if _, runErr := s.factory(&j); runErr != nil {
-					logger.Warn("cron job execution failed", "id", j.ID, "err", runErr)
-				}
+				s.runGuarded(j, func() {
+					if runErr := s.execute(j); runErr != nil {
+						logger.Warn("cron job execution failed", "id", j.ID, "err", runErr)
+					}
+				})
 			}, job),
 			gocron.WithName(job.ID),
 		)
@@ -37,12 +43,14 @@ func (s *Scheduler) scheduleLocked(job Job) (func(), error) {
 			gocron.OneTimeJob(gocron.OneTimeJobStartDateTime(job.AtTime)),
 			gocron.NewTask(func(j Job) {
 				if s.factory != nil {
-					jc := j
-					if _, err := s.factory(&jc); err != nil {
-						logger.Warn("at job execution failed", "id", j.ID, "err", err)
-					}
+					s.runGuarded(j, func() {
+						if err := s.execute(j); err != nil {
+							logger.Warn("at job execution failed", "id", j.ID, "err", err)
+						}
+					})
 				}
 
+				// Removed only now, after any retries have run.
 				s.mu.Lock()
 				s.finalizeAtJobLocked(j.ID)
 				s.mu.Unlock()
@@ -31,6 +31,9 @@ func (s *Scheduler) Load() error {
 		}
 
 		s.jobs[job.ID] = job
+		if job.Disabled {
+			continue
+		}
 		cancel, err := s.scheduleLocked(job)
 		if err != nil {
 			logger.Warn("failed to schedule job from store", "id", job.ID, "kind", job.Kind, "err", err)
@@ -56,6 +59,7 @@ func (s *Scheduler) Start() {
 }
 
 func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
 	s.mu.Lock()
 	s.resetLocked()
 	s.mu.Unlock()
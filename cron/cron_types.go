@@ -15,15 +15,18 @@ const (
 )
 
 type Job struct {
-	ID                string    `json:"id"`
-	Kind              string    `json:"kind,omitempty"`
-	Expr              string    `json:"expr,omitempty"`
-	AtTime            time.Time `json:"at_time,omitempty"`
-	Task              string    `json:"task"`
-	Agent             string    `json:"agent,omitempty"`
-	WakeSession       string    `json:"wake_session,omitempty"`
-	Silent            bool      `json:"silent,omitempty"`
-	CreatedAt         time.Time `json:"created_at"`
+	ID          string       `json:"id"`
+	Kind        string       `json:"kind,omitempty"`
+	Expr        string       `json:"expr,omitempty"`
+	Timezone    string       `json:"timezone,omitempty"` // IANA zone for Expr and wall-clock at times; empty = server local
+	AtTime      time.Time    `json:"at_time,omitempty"`
+	Task        string       `json:"task"`
+	Agent       string       `json:"agent,omitempty"`
+	WakeSession string       `json:"wake_session,omitempty"`
+	Silent      bool         `json:"silent,omitempty"`
+	Disabled    bool         `json:"disabled,omitempty"` // kept in the store but not scheduled
+	Retry       *RetryPolicy `json:"retry,omitempty"`    // nil runs each occurrence once
+	CreatedAt   time.Time    `json:"created_at"`
 }
 
 type ThreadFactory func(job *Job) (string, error)
@@ -35,6 +38,15 @@ type Scheduler struct {
 	cancels   map[string]func()
 	storePath string
 	mu        sync.Mutex
+
+	runMu    sync.Mutex // guards the run limits and run status below
+	overlap  string
+	sem      chan struct{} // global concurrency slots; nil = unlimited
+	gates    map[string]*jobGate
+	lastRuns map[string]RunStatus
+
+	stopped  chan struct{} // closed by Stop to cut retry backoffs short
+	stopOnce sync.Once
 }
 
 func NewScheduler(storePath string, factory ThreadFactory) (*Scheduler, error) {
@@ -48,5 +60,9 @@ func NewScheduler(storePath string, factory ThreadFactory) (*Scheduler, error) {
 		jobs:      make(map[string]Job),
 		cancels:   make(map[string]func()),
 		storePath: strings.TrimSpace(storePath),
+		overlap:   OverlapSkip,
+		gates:     make(map[string]*jobGate),
+		lastRuns:  make(map[string]RunStatus),
+		stopped:   make(chan struct{}),
 	}, nil
 }
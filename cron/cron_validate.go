@@ -31,6 +31,7 @@ func Normalize(job Job) Job {
 	job.Task = strings.TrimSpace(job.Task)
 	job.Agent = strings.TrimSpace(job.Agent)
 	job.WakeSession = strings.TrimSpace(job.WakeSession)
+	job.Timezone = strings.TrimSpace(job.Timezone)
 	if !job.AtTime.IsZero() {
 		job.AtTime = job.AtTime.UTC()
 	}
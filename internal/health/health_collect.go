@@ -7,6 +7,9 @@ import (
 	"time"
 )
 
+// processStart approximates process start as package initialization.
+var processStart = time.Now()
+
 // Collect returns a health snapshot for the current process.
 func Collect(opts Options) Snapshot {
 	opts = opts.normalize()
@@ -15,6 +18,7 @@ func Collect(opts Options) Snapshot {
 	var mem runtime.MemStats
 	runtime.ReadMemStats(&mem)
 	zoneName, zoneOffsetSeconds := now.Zone()
+	cpuUser, cpuSystem := cpuTimes()
 
 	s := Snapshot{
 		Status:     "healthy",
@@ -27,6 +31,11 @@ func Collect(opts Options) Snapshot {
 			SysMB:        float64(mem.Sys) / 1024 / 1024,
 			NumGC:        mem.NumGC,
 		},
+		Process: ProcessInfo{
+			UptimeSeconds:    now.Sub(processStart).Seconds(),
+			CPUUserSeconds:   cpuUser.Seconds(),
+			CPUSystemSeconds: cpuSystem.Seconds(),
+		},
 		Runtime: RuntimeInfo{
 			Version: runtime.Version(),
 			OS:      runtime.GOOS,
@@ -64,7 +73,9 @@ func Collect(opts Options) Snapshot {
 		}
 	}
 
-	if opts.SessionFile != "" {
+	if opts.Session != nil {
+		s.Session = opts.Session
+	} else if opts.SessionFile != "" {
 		s.Session = inspectSessionFile(opts.SessionFile)
 	}
 	if opts.SessionsRoot != "" {
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectUptimeIncreases(t *testing.T) {
+	first := Collect(Options{})
+	time.Sleep(10 * time.Millisecond)
+	second := Collect(Options{})
+
+	if first.Process.UptimeSeconds <= 0 {
+		t.Fatalf("uptime should be positive, got %v", first.Process.UptimeSeconds)
+	}
+	if second.Process.UptimeSeconds <= first.Process.UptimeSeconds {
+		t.Fatalf("uptime did not increase: %v then %v", first.Process.UptimeSeconds, second.Process.UptimeSeconds)
+	}
+	if second.Process.CPUUserSeconds < 0 || second.Process.CPUSystemSeconds < 0 {
+		t.Fatalf("negative CPU time: %+v", second.Process)
+	}
+}
+
+func TestCollectWithoutOptions(t *testing.T) {
+	s := Collect(Options{})
+	if s.Status != "healthy" || s.Goroutines <= 0 || s.Runtime.Version == "" || s.Timestamp == "" {
+		t.Fatalf("missing base fields: %+v", s)
+	}
+	if s.Paths != nil || s.Thread != nil || s.Session != nil || s.Sessions != nil || s.Cron != nil || s.WorkspaceTree != nil {
+		t.Fatalf("optional sections should be omitted without options: %+v", s)
+	}
+}
+
+func TestCollectWithOptions(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "notes.md"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := Collect(Options{
+		Workspace:      "  " + workspace + "  ",
+		SessionsRoot:   filepath.Join(workspace, "sessions"),
+		ThreadID:       "t1",
+		SessionKey:     "main",
+		SessionFile:    filepath.Join(workspace, "sessions", "main.json"),
+		IncludeTree:    true,
+		TreeDepth:      1,
+		TreeMaxEntries: 10,
+	})
+
+	if s.Paths == nil || s.Paths.Workspace != workspace {
+		t.Fatalf("paths not populated: %+v", s.Paths)
+	}
+	if s.Thread == nil || s.Thread.ID != "t1" || s.Thread.SessionKey != "main" {
+		t.Fatalf("thread not populated: %+v", s.Thread)
+	}
+	if s.Session == nil || s.Session.Exists {
+		t.Fatalf("missing session file should be reported as not existing: %+v", s.Session)
+	}
+	if s.Cron == nil || s.Cron.Exists {
+		t.Fatalf("cron info should report a missing file: %+v", s.Cron)
+	}
+	if s.WorkspaceTree == nil || len(s.WorkspaceTree.Entries) == 0 {
+		t.Fatalf("workspace tree not populated: %+v", s.WorkspaceTree)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "time"
+
+// cpuTimes is unsupported on this platform and reports zero.
+func cpuTimes() (user, system time.Duration) {
+	return 0, 0
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+const serverShutdownTimeout = 5 * time.Second
+
+// Server exposes HTTP probes: /livez reports the process is up and /healthz
+// returns the current Snapshot as JSON.
+type Server struct {
+	addr   string
+	opts   func() Options
+	server *http.Server
+	wg     sync.WaitGroup
+}
+
+// NewServer creates a probe server on addr. opts, if non-nil, supplies the
+// Collect options for each /healthz request.
+func NewServer(addr string, opts func() Options) *Server {
+	return &Server{addr: addr, opts: opts}
+}
+
+// Handler returns the probe routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		var opts Options
+		if s.opts != nil {
+			opts = s.opts()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(Collect(opts)); err != nil {
+			logger.Warn("health probe encode error", "err", err)
+		}
+	})
+	return mux
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("health server listen failed on %s: %w", s.addr, err)
+	}
+	s.server = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	logger.Info("health server started", "addr", ln.Addr().String())
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if serveErr := s.server.Serve(ln); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logger.Error("health server error", "err", serveErr)
+		}
+	}()
+	return nil
+}
+
+// Stop gracefully shuts the server down.
+func (s *Server) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	s.wg.Wait()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Info("health server stopped")
+	return nil
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerHealthz(t *testing.T) {
+	workspace := t.TempDir()
+	srv := httptest.NewServer(NewServer("", func() Options {
+		return Options{Workspace: workspace, Provider: "anthropic"}
+	}).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var snap Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		t.Fatalf("healthz body is not a Snapshot: %v", err)
+	}
+	if snap.Status != "healthy" || snap.Provider != "anthropic" || snap.Paths == nil || snap.Paths.Workspace != workspace {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestServerLivez(t *testing.T) {
+	srv := httptest.NewServer(NewServer("", nil).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/livez")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "ok" {
+		t.Fatalf("livez = %d %q", resp.StatusCode, body)
+	}
+}
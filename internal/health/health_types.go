@@ -13,6 +13,7 @@ type Snapshot struct {
 	Model         string         `json:"model,omitempty" yaml:"model,omitempty"`
 	Goroutines    int            `json:"goroutines" yaml:"goroutines"`
 	Memory        MemoryInfo     `json:"memory" yaml:"memory"`
+	Process       ProcessInfo    `json:"process" yaml:"process"`
 	Runtime       RuntimeInfo    `json:"runtime" yaml:"runtime"`
 	Time          TimeInfo       `json:"time" yaml:"time"`
 	Timestamp     string         `json:"timestamp" yaml:"timestamp"`
@@ -34,6 +35,14 @@ type MemoryInfo struct {
 	NumGC        uint32  `json:"numGC" yaml:"num_gc"`
 }
 
+// ProcessInfo contains process uptime and CPU time in seconds. CPU times are
+// zero on platforms without getrusage.
+type ProcessInfo struct {
+	UptimeSeconds    float64 `json:"uptimeSeconds" yaml:"uptime_seconds"`
+	CPUUserSeconds   float64 `json:"cpuUserSeconds" yaml:"cpu_user_seconds"`
+	CPUSystemSeconds float64 `json:"cpuSystemSeconds" yaml:"cpu_system_seconds"`
+}
+
 // RuntimeInfo contains Go runtime metadata.
 type RuntimeInfo struct {
 	Version string `json:"version" yaml:"version"`
@@ -65,6 +74,7 @@ type Options struct {
 	AgentName   string
 	SessionKey  string
 	SessionFile string
+	Session     *SessionInfo // loaded by the caller from its session store; overrides reading SessionFile
 
 	Channels *ChannelsInfo
 
@@ -158,6 +168,7 @@ type ChannelsInfo struct {
 	UserAgents  map[string]string `json:"userAgents,omitempty" yaml:"user_agents,omitempty"`
 	Telegram    *TelegramInfo     `json:"telegram,omitempty" yaml:"telegram,omitempty"`
 	Web         *WebInfo          `json:"web,omitempty" yaml:"web,omitempty"`
+	Dropped     map[string]int64  `json:"droppedMessages,omitempty" yaml:"dropped_messages,omitempty"` // channel → messages dropped on full buffer
 }
 
 // TelegramInfo contains Telegram channel config (token masked).
@@ -168,5 +179,6 @@ type TelegramInfo struct {
 
 // WebInfo contains Web channel config.
 type WebInfo struct {
-	Addr string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Addr    string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Clients int    `json:"clients,omitempty" yaml:"clients,omitempty"` // connected websocket clients, reported by the web channel itself
 }
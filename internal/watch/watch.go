@@ -0,0 +1,99 @@
+// Package watch reports changes to template directories such as skills/ and
+// agents/ so registries can reload only when files change.
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/linanwx/nagobot/logger"
+)
+
+// DefaultDebounce coalesces the burst of events an editor save produces.
+const DefaultDebounce = 300 * time.Millisecond
+
+// Dir watches dir and its direct subdirectories (directory-based skills keep
+// SKILL.md one level down) and calls onChange once edits have been quiet for
+// debounce. Subdirectories created later are watched as they appear. The
+// returned stop function ends the watch; it is safe to call more than once.
+func Dir(dir string, debounce time.Duration, onChange func()) (func(), error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(dir); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				addSubdir(w, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		var timer *time.Timer
+		var fire <-chan time.Time
+		for {
+			select {
+			case event, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) && filepath.Dir(event.Name) == filepath.Clean(dir) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						addSubdir(w, event.Name)
+					}
+				}
+				if timer == nil {
+					timer = time.NewTimer(debounce)
+				} else {
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(debounce)
+				}
+				fire = timer.C
+			case <-fire:
+				fire = nil
+				onChange()
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("directory watch error", "dir", dir, "err", err)
+			case <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			w.Close()
+		})
+	}, nil
+}
+
+func addSubdir(w *fsnotify.Watcher, path string) {
+	if err := w.Add(path); err != nil {
+		logger.Warn("failed to watch directory", "dir", path, "err", err)
+	}
+}
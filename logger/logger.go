@@ -17,12 +17,19 @@ type Config struct {
 	Level   string
 	Stdout  bool
 	File    string
+	Format  string // "text" (default) or "json"
+
+	MaxSizeMB  int  // rotate the file once it exceeds this size, 0 = never
+	MaxBackups int  // rotated files to keep, 0 = keep all
+	Compress   bool // gzip rotated files
 }
 
 var (
 	mu      sync.RWMutex
 	base    *slog.Logger
 	enabled = true
+	logFile string        // resolved path of the active log file, "" if none
+	rotator *rotatingFile // open log file, closed on re-Init
 )
 
 // Init initializes the logger with the provided config.
@@ -30,6 +37,11 @@ func Init(cfg Config, configDir string) error {
 	mu.Lock()
 	defer mu.Unlock()
 
+	logFile = ""
+	if rotator != nil {
+		_ = rotator.Close()
+		rotator = nil
+	}
 	if !cfg.Enabled {
 		enabled = false
 		base = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
@@ -49,22 +61,37 @@ func Init(cfg Config, configDir string) error {
 		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 			return fmt.Errorf("logger: create log dir: %w", err)
 		}
-		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		f, err := openRotatingFile(path, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxBackups, cfg.Compress)
 		if err != nil {
 			initErr = fmt.Errorf("logger: open log file: %w", err)
 		} else {
 			writers = append(writers, f)
+			logFile = path
+			rotator = f
 		}
 	}
 	if len(writers) == 0 {
 		writers = append(writers, os.Stdout)
 	}
-	handler := slog.NewTextHandler(io.MultiWriter(writers...), opts)
+	var handler slog.Handler
+	if strings.EqualFold(strings.TrimSpace(cfg.Format), "json") {
+		handler = slog.NewJSONHandler(io.MultiWriter(writers...), opts)
+	} else {
+		handler = slog.NewTextHandler(io.MultiWriter(writers...), opts)
+	}
 	base = slog.New(handler)
 	enabled = true
 	return initErr
 }
 
+// FilePath returns the resolved path of the log file being written, or ""
+// when logging to a file is not configured.
+func FilePath() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logFile
+}
+
 // Debug logs a debug message.
 func Debug(msg string, args ...any) {
 	log(slog.LevelDebug, msg, args...)
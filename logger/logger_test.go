@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitJSONFormat(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(Config{Enabled: true, Level: "info", File: "nagobot.log", Format: "json"}, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Init(Config{Enabled: false}, "")
+
+	Info("thread started", "threadID", "t1", "sessionKey", "main")
+	Debug("not logged at info level")
+
+	f, err := os.Open(filepath.Join(dir, "nagobot.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v\n%s", err, scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["level"] != "INFO" || e["msg"] != "thread started" || e["threadID"] != "t1" || e["sessionKey"] != "main" || e["time"] == nil {
+		t.Fatalf("unexpected entry: %v", e)
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const rotateTimeFormat = "20060102T150405.000"
+
+// rotatingFile is an append-only log file that is renamed with a timestamp
+// suffix once it exceeds maxBytes. At most maxBackups rotated files are kept
+// (0 keeps all); with compress they are gzipped.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	compress   bool
+	f          *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens path for appending, rotating it the same way as the
+// log file. maxBytes 0 never rotates.
+func OpenRotatingFile(path string, maxBytes int64, maxBackups int, compress bool) (io.WriteCloser, error) {
+	return openRotatingFile(path, maxBytes, maxBackups, compress)
+}
+
+func openRotatingFile(path string, maxBytes int64, maxBackups int, compress bool) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups, compress: compress}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: rotate %s: %v\n", r.path, err)
+			if r.f == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate renames the active file aside, opens a fresh one, and prunes backups.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	backup := r.path + "." + time.Now().Format(rotateTimeFormat)
+	for i := 1; fileExists(backup) || fileExists(backup+".gz"); i++ {
+		backup = fmt.Sprintf("%s.%s-%d", r.path, time.Now().Format(rotateTimeFormat), i)
+	}
+	renameErr := os.Rename(r.path, backup)
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	if r.compress {
+		if err := gzipFile(backup); err != nil {
+			return err
+		}
+	}
+	return r.prune()
+}
+
+// prune removes the oldest rotated files beyond maxBackups.
+func (r *rotatingFile) prune() error {
+	if r.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > r.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backups returns rotated files oldest first. The timestamp suffix sorts
+// chronologically.
+func (r *rotatingFile) backups() ([]string, error) {
+	matches, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.path + "."
+	var backups []string
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, prefix), ".gz")
+		if len(suffix) < len(rotateTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(rotateTimeFormat, suffix[:len(rotateTimeFormat)]); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
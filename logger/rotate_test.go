@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotatesAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nagobot.log")
+	r, err := openRotatingFile(path, 100, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	line := strings.Repeat("x", 59) + "\n" // 60 bytes: each write after the first rotates
+	for i := 0; i < 6; i++ {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups after pruning, got %v", backups)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 100 {
+		t.Fatalf("active file exceeds threshold: %d bytes", info.Size())
+	}
+}
+
+func TestRotatingFileCompressesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nagobot.log")
+	r, err := openRotatingFile(path, 10, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	r.Write([]byte("first entry\n"))
+	r.Write([]byte("second entry\n"))
+
+	backups, _ := r.backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("expected one gzipped backup, got %v", backups)
+	}
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "first entry\n" {
+		t.Fatalf("unexpected backup content %q", data)
+	}
+}
+
+func TestInitRotatesLogFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(Config{Enabled: true, Level: "info", File: "nagobot.log", MaxSizeMB: 1, MaxBackups: 1}, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Init(Config{Enabled: false}, "")
+
+	if got := FilePath(); got != filepath.Join(dir, "nagobot.log") {
+		t.Fatalf("FilePath() = %q", got)
+	}
+	big := strings.Repeat("y", 64*1024)
+	for i := 0; i < 40; i++ {
+		Info("filler", "data", big)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "nagobot.log.*"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one rotated backup, got %v", matches)
+	}
+}
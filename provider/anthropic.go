@@ -15,6 +15,8 @@ import (
 
 const (
 	anthropicAPIBase = "https://api.anthropic.com"
+	// anthropicOAuthBeta enables bearer OAuth access tokens on the Messages API.
+	anthropicOAuthBeta = "oauth-2025-04-20"
 )
 
 func init() {
@@ -22,8 +24,8 @@ func init() {
 		Models:  []string{"claude-sonnet-4-5", "claude-opus-4-6"},
 		EnvKey:  "ANTHROPIC_API_KEY",
 		EnvBase: "ANTHROPIC_API_BASE",
-		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider {
-			return newAnthropicProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature)
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) Provider {
+			return newAnthropicProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature, requestTimeout, retry)
 		},
 	})
 }
@@ -36,6 +38,9 @@ type AnthropicProvider struct {
 	modelType   string
 	maxTokens   int
 	temperature float64
+	retry       RetryPolicy
+	timeout     time.Duration
+	oauth       bool // apiKey is an OAuth access token
 	client      anthropic.Client
 }
 
@@ -76,27 +81,49 @@ func anthropicThinkingBudget(maxTokens int) (int64, bool) {
 }
 
 // newAnthropicProvider creates a new Anthropic provider.
-func newAnthropicProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) *AnthropicProvider {
+func newAnthropicProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) *AnthropicProvider {
 	if modelName == "" {
 		modelName = modelType
 	}
 
-	baseURL := normalizeSDKBaseURL(apiBase, anthropicAPIBase, "/v1/messages")
-	client := anthropic.NewClient(
-		aoption.WithAPIKey(apiKey),
-		aoption.WithBaseURL(baseURL),
-		aoption.WithMaxRetries(sdkMaxRetries),
-	)
-
-	return &AnthropicProvider{
+	p := &AnthropicProvider{
 		apiKey:      apiKey,
-		apiBase:     baseURL,
+		apiBase:     normalizeSDKBaseURL(apiBase, anthropicAPIBase, "/v1/messages"),
 		modelName:   modelName,
 		modelType:   modelType,
 		maxTokens:   maxTokens,
 		temperature: temperature,
-		client:      client,
+		retry:       retry,
+		timeout:     requestTimeout,
+	}
+	p.client = p.newClient()
+	return p
+}
+
+// newClient builds the SDK client. API keys go in x-api-key; OAuth access
+// tokens are sent as a bearer Authorization header instead.
+func (p *AnthropicProvider) newClient() anthropic.Client {
+	opts := []aoption.RequestOption{
+		aoption.WithBaseURL(p.apiBase),
+		aoption.WithMaxRetries(0), // retried by withRetry
+		aoption.WithRequestTimeout(p.timeout),
+	}
+	if p.oauth {
+		opts = append(opts,
+			aoption.WithAuthToken(p.apiKey),
+			aoption.WithHeaderDel("X-Api-Key"),
+			aoption.WithHeaderAdd("anthropic-beta", anthropicOAuthBeta),
+		)
+	} else {
+		opts = append(opts, aoption.WithAPIKey(p.apiKey))
 	}
+	return anthropic.NewClient(opts...)
+}
+
+// useOAuthToken implements oauthBearer.
+func (p *AnthropicProvider) useOAuthToken() {
+	p.oauth = true
+	p.client = p.newClient()
 }
 
 func anthropicInputChars(systemPrompt string, messages []Message) int {
@@ -192,7 +219,16 @@ func toAnthropicMessages(messages []Message) (string, []anthropic.MessageParam,
 			systemPrompt = m.Content
 		case "user":
 			flushPendingToolResults()
-			msgList = append(msgList, anthropic.NewUserMessage(anthropic.NewTextBlock(m.Content)))
+			blocks := make([]anthropic.ContentBlockParamUnion, 0, 1+len(m.Images))
+			for _, img := range m.Images {
+				if img.URL != "" {
+					blocks = append(blocks, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: img.URL}))
+				} else {
+					blocks = append(blocks, anthropic.NewImageBlockBase64(img.MimeType, img.Data))
+				}
+			}
+			blocks = append(blocks, anthropic.NewTextBlock(m.Content))
+			msgList = append(msgList, anthropic.NewUserMessage(blocks...))
 		case "assistant":
 			flushPendingToolResults()
 
@@ -301,10 +337,12 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req *Request) (*Response,
 		)
 	}
 
-	messageResp, err := p.client.Messages.New(ctx, params)
+	messageResp, err := withRetry(ctx, "anthropic", p.retry, func() (*anthropic.Message, error) {
+		return p.client.Messages.New(ctx, params)
+	})
 	if err != nil {
 		logger.Error("anthropic request send error", "provider", "anthropic", "err", err)
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", asProviderError("anthropic", err))
 	}
 
 	var textParts []string
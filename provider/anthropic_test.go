@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+// newHangingServer never answers until the client gives up.
+func newHangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	return srv
+}
+
+func TestAnthropicChatTimesOut(t *testing.T) {
+	srv := newHangingServer(t)
+	p := newAnthropicProvider("key", srv.URL, "claude-sonnet-4-5", "", 1024, 0, 50*time.Millisecond, RetryPolicy{})
+
+	start := time.Now()
+	_, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	if err == nil {
+		t.Fatal("Chat() succeeded against a hanging server, want timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Chat() took %v, want it bounded by the request timeout", elapsed)
+	}
+}
+
+func TestAnthropicChatHonorsContextCancel(t *testing.T) {
+	srv := newHangingServer(t)
+	p := newAnthropicProvider("key", srv.URL, "claude-sonnet-4-5", "", 1024, 0, time.Minute, RetryPolicy{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := p.Chat(ctx, &Request{Messages: []Message{UserMessage("hi")}})
+	if err == nil {
+		t.Fatal("Chat() succeeded after cancellation, want error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Chat() took %v after cancellation", elapsed)
+	}
+}
+
+func TestToAnthropicMessagesEncodesImages(t *testing.T) {
+	msg := UserMessage("what is this?")
+	msg.Images = []ImagePart{
+		{MimeType: "image/png", Data: "iVBORw0KGgo="},
+		{URL: "https://example.com/cat.jpg"},
+	}
+	_, params, err := toAnthropicMessages([]Message{msg})
+	if err != nil {
+		t.Fatalf("toAnthropicMessages() error = %v", err)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(data)
+	for _, want := range []string{
+		`"source":{"data":"iVBORw0KGgo=","media_type":"image/png","type":"base64"}`,
+		`"source":{"url":"https://example.com/cat.jpg","type":"url"}`,
+		`{"text":"what is this?","type":"text"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("serialized message missing %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestAnthropicFactoryAuthHeaders(t *testing.T) {
+	cases := []struct {
+		name       string
+		setAuth    func(*config.ProvidersConfig)
+		wantBearer string
+		wantAPIKey string
+	}{
+		{
+			name: "oauth token",
+			setAuth: func(p *config.ProvidersConfig) {
+				p.AnthropicOAuth = &config.OAuthTokenConfig{AccessToken: "oauth-token", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+			},
+			wantBearer: "Bearer oauth-token",
+		},
+		{
+			name:       "api key",
+			setAuth:    func(p *config.ProvidersConfig) { p.Anthropic.APIKey = "sk-ant-key" },
+			wantAPIKey: "sk-ant-key",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ANTHROPIC_API_KEY", "")
+			t.Setenv("ANTHROPIC_API_BASE", "")
+			var got http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"m1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			cfg := &config.Config{}
+			cfg.Thread.Provider = "anthropic"
+			cfg.Thread.ModelType = "claude-sonnet-4-5"
+			cfg.Providers.Anthropic = &config.ProviderConfig{APIBase: srv.URL}
+			tc.setAuth(&cfg.Providers)
+
+			f, err := NewFactory(cfg)
+			if err != nil {
+				t.Fatalf("NewFactory() error = %v", err)
+			}
+			p, err := f.Create("", "")
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			resp, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hello")}})
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if resp.Content != "hi" {
+				t.Fatalf("Content = %q, want hi", resp.Content)
+			}
+
+			if auth := got.Get("Authorization"); auth != tc.wantBearer {
+				t.Fatalf("Authorization = %q, want %q", auth, tc.wantBearer)
+			}
+			if key := got.Get("X-Api-Key"); key != tc.wantAPIKey {
+				t.Fatalf("X-Api-Key = %q, want %q", key, tc.wantAPIKey)
+			}
+			hasBeta := strings.Contains(strings.Join(got.Values("Anthropic-Beta"), ","), anthropicOAuthBeta)
+			if hasBeta != (tc.wantBearer != "") {
+				t.Fatalf("anthropic-beta = %q, want OAuth beta only with a bearer token", got.Values("Anthropic-Beta"))
+			}
+		})
+	}
+}
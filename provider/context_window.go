@@ -0,0 +1,61 @@
+package provider
+
+import "strings"
+
+// modelContextWindows holds known context window sizes in tokens, keyed by
+// model type.
+var modelContextWindows = map[string]int{
+	"claude-sonnet-4-5":    200000,
+	"claude-opus-4-6":      200000,
+	"deepseek-chat":        128000,
+	"deepseek-reasoner":    128000,
+	"kimi-k2.5":            262144,
+	"moonshotai/kimi-k2.5": 262144,
+}
+
+// ContextWindowForModel returns the known context window in tokens for a model
+// type or full model name, or 0 when the model is unknown. Dated names such as
+// "claude-sonnet-4-5-20250929" and vendor-prefixed names such as
+// "anthropic/claude-sonnet-4-5" resolve to their base model.
+func ContextWindowForModel(model string) int {
+	return lookupContextWindow(modelContextWindows, model)
+}
+
+// ContextWindowFor resolves the context window for model, preferring
+// overrides, then the built-in table, then fallback.
+func ContextWindowFor(model string, overrides map[string]int, fallback int) int {
+	if tokens := lookupContextWindow(overrides, model); tokens > 0 {
+		return tokens
+	}
+	if tokens := ContextWindowForModel(model); tokens > 0 {
+		return tokens
+	}
+	return fallback
+}
+
+func lookupContextWindow(table map[string]int, model string) int {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" || len(table) == 0 {
+		return 0
+	}
+	candidates := []string{model}
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		candidates = append(candidates, model[i+1:])
+	}
+	for _, name := range candidates {
+		if tokens := table[name]; tokens > 0 {
+			return tokens
+		}
+	}
+	// Longest known prefix wins, so dated snapshots match their base model.
+	best, bestLen := 0, 0
+	for key, tokens := range table {
+		key = strings.ToLower(key)
+		for _, name := range candidates {
+			if tokens > 0 && len(key) > bestLen && strings.HasPrefix(name, key+"-") {
+				best, bestLen = tokens, len(key)
+			}
+		}
+	}
+	return best
+}
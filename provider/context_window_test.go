@@ -0,0 +1,38 @@
+package provider
+
+import "testing"
+
+func TestContextWindowForModel(t *testing.T) {
+	cases := map[string]int{
+		"claude-sonnet-4-5":          200000,
+		"claude-sonnet-4-5-20250929": 200000,
+		"anthropic/claude-opus-4-6":  200000,
+		"deepseek-chat":              128000,
+		"kimi-k2.5":                  262144,
+		"moonshotai/kimi-k2.5":       262144,
+		"  Claude-Sonnet-4-5  ":      200000,
+		"some-unknown-model":         0,
+		"":                           0,
+	}
+	for model, want := range cases {
+		if got := ContextWindowForModel(model); got != want {
+			t.Errorf("ContextWindowForModel(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestContextWindowForFallbackAndOverride(t *testing.T) {
+	if got := ContextWindowFor("unknown-model", nil, 128000); got != 128000 {
+		t.Fatalf("unknown model should use fallback, got %d", got)
+	}
+	if got := ContextWindowFor("kimi-k2.5", nil, 128000); got != 262144 {
+		t.Fatalf("known model should use table, got %d", got)
+	}
+	overrides := map[string]int{"kimi-k2.5": 100000, "my-local-model": 32000}
+	if got := ContextWindowFor("moonshotai/kimi-k2.5", overrides, 128000); got != 100000 {
+		t.Fatalf("override should win over table, got %d", got)
+	}
+	if got := ContextWindowFor("my-local-model", overrides, 128000); got != 32000 {
+		t.Fatalf("override for unknown model not applied, got %d", got)
+	}
+}
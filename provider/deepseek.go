@@ -22,8 +22,8 @@ func init() {
 		Models:  []string{"deepseek-reasoner", "deepseek-chat"},
 		EnvKey:  "DEEPSEEK_API_KEY",
 		EnvBase: "DEEPSEEK_API_BASE",
-		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider {
-			return newDeepSeekProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature)
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, _ RetryPolicy) Provider {
+			return newDeepSeekProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature, requestTimeout)
 		},
 	})
 }
@@ -40,7 +40,7 @@ type DeepSeekProvider struct {
 }
 
 // newDeepSeekProvider creates a new DeepSeek provider.
-func newDeepSeekProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) *DeepSeekProvider {
+func newDeepSeekProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration) *DeepSeekProvider {
 	if modelName == "" {
 		modelName = modelType
 	}
@@ -50,6 +50,7 @@ func newDeepSeekProvider(apiKey, apiBase, modelType, modelName string, maxTokens
 		oaioption.WithAPIKey(apiKey),
 		oaioption.WithBaseURL(baseURL),
 		oaioption.WithMaxRetries(sdkMaxRetries),
+		oaioption.WithRequestTimeout(requestTimeout),
 	)
 
 	return &DeepSeekProvider{
@@ -105,7 +106,7 @@ func (p *DeepSeekProvider) Chat(ctx context.Context, req *Request) (*Response, e
 	chatResp, err := p.client.Chat.Completions.New(ctx, chatReq, requestOpts...)
 	if err != nil {
 		logger.Error("deepseek request send error", "provider", "deepseek", "err", err)
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", asProviderError("deepseek", err))
 	}
 
 	if len(chatResp.Choices) == 0 {
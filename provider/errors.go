@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	openai "github.com/openai/openai-go/v3"
+)
+
+// ProviderError is an upstream API failure carrying the HTTP status and the
+// error details reported by the provider.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Type       string        // provider error type, e.g. rate_limit_error
+	Message    string        // provider error message
+	RetryAfter time.Duration // from Retry-After, 0 if absent
+	Err        error         // underlying SDK error
+}
+
+func (e *ProviderError) Error() string {
+	detail := e.Message
+	if e.Type != "" {
+		detail = e.Type + ": " + detail
+	}
+	return fmt.Sprintf("%s API error (%d): %s", e.Provider, e.StatusCode, strings.TrimSpace(detail))
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// IsAuth reports whether the provider rejected the credentials.
+func (e *ProviderError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+		e.Type == "authentication_error" || e.Type == "permission_error"
+}
+
+// IsRateLimit reports whether the request was throttled.
+func (e *ProviderError) IsRateLimit() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Type == "rate_limit_error"
+}
+
+// IsOverloaded reports whether the provider is temporarily unavailable.
+func (e *ProviderError) IsOverloaded() bool {
+	return e.StatusCode == 529 || e.StatusCode == http.StatusServiceUnavailable || e.Type == "overloaded_error"
+}
+
+// IsContextLength reports whether the prompt exceeded the model context window.
+func (e *ProviderError) IsContextLength() bool {
+	if e.Type == "context_length_exceeded" {
+		return true
+	}
+	if e.StatusCode != http.StatusBadRequest && e.StatusCode != http.StatusRequestEntityTooLarge {
+		return false
+	}
+	msg := strings.ToLower(e.Message)
+	for _, hint := range []string{"context length", "context_length", "context window", "prompt is too long", "maximum context", "too many tokens"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiErrorBody matches both the Anthropic envelope ({"type":"error","error":{...}})
+// and the OpenAI error object ({"message":...,"type":...,"code":...}).
+type apiErrorBody struct {
+	Type    string          `json:"type"`
+	Message string          `json:"message"`
+	Code    json.RawMessage `json:"code"`
+	Error   *struct {
+		Type    string          `json:"type"`
+		Message string          `json:"message"`
+		Code    json.RawMessage `json:"code"`
+	} `json:"error"`
+}
+
+// asProviderError converts SDK API errors into *ProviderError. Other errors
+// (network, timeout, cancellation) are returned unchanged.
+func asProviderError(providerName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		return err
+	}
+	var raw string
+	var aerr *anthropic.Error
+	var oerr *openai.Error
+	switch {
+	case errors.As(err, &aerr):
+		raw = aerr.RawJSON()
+	case errors.As(err, &oerr):
+		raw = oerr.RawJSON()
+	default:
+		return err
+	}
+
+	status, header := apiErrorStatus(err)
+	pe = &ProviderError{Provider: providerName, StatusCode: status, Err: err}
+	pe.RetryAfter, _ = parseRetryAfter(header.Get("Retry-After"), time.Now())
+
+	var body apiErrorBody
+	if json.Unmarshal([]byte(raw), &body) == nil {
+		pe.Type, pe.Message = body.Type, body.Message
+		code := body.Code
+		if body.Error != nil {
+			pe.Type, pe.Message, code = body.Error.Type, body.Error.Message, body.Error.Code
+		}
+		// OpenAI-compatible APIs often put the specific reason in code.
+		var codeStr string
+		if json.Unmarshal(code, &codeStr) == nil && codeStr != "" && (pe.Type == "" || pe.Type == "invalid_request_error") {
+			pe.Type = codeStr
+		}
+	}
+	if pe.Type == "error" {
+		pe.Type = ""
+	}
+	if pe.Message == "" {
+		pe.Message = http.StatusText(status)
+	}
+	return pe
+}
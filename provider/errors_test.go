@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newErrorServer answers every request with status, headers and body.
+func newErrorServer(t *testing.T, status int, header map[string]string, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAnthropicErrorsAreTyped(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		body      string
+		wantType  string
+		wantCheck func(*ProviderError) bool
+	}{
+		{"auth", 401, nil, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			"authentication_error", (*ProviderError).IsAuth},
+		{"rate limit", 429, map[string]string{"Retry-After": "20"}, `{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`,
+			"rate_limit_error", (*ProviderError).IsRateLimit},
+		{"context length", 400, nil, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			"invalid_request_error", (*ProviderError).IsContextLength},
+		{"overloaded", 529, nil, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			"overloaded_error", (*ProviderError).IsOverloaded},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newErrorServer(t, tc.status, tc.header, tc.body)
+			p := newAnthropicProvider("key", srv.URL, "claude-sonnet-4-5", "", 4096, 0, 5*time.Second, RetryPolicy{})
+
+			_, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+			var pe *ProviderError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Chat() error = %v, want *ProviderError", err)
+			}
+			if pe.Provider != "anthropic" || pe.StatusCode != tc.status || pe.Type != tc.wantType || pe.Message == "" {
+				t.Fatalf("ProviderError = %+v", pe)
+			}
+			if !tc.wantCheck(pe) {
+				t.Fatalf("classification failed for %+v", pe)
+			}
+			if tc.header["Retry-After"] != "" && pe.RetryAfter != 20*time.Second {
+				t.Fatalf("RetryAfter = %v, want 20s", pe.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestOpenRouterErrorsAreTyped(t *testing.T) {
+	srv := newErrorServer(t, 400, nil, `{"error":{"message":"This model's maximum context length is 131072 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`)
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 5*time.Second, RetryPolicy{})
+
+	_, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	var pe *ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Chat() error = %v, want *ProviderError", err)
+	}
+	if pe.Provider != "openrouter" || pe.StatusCode != 400 || pe.Type != "context_length_exceeded" || !pe.IsContextLength() {
+		t.Fatalf("ProviderError = %+v", pe)
+	}
+	if pe.IsAuth() || pe.IsRateLimit() {
+		t.Fatalf("ProviderError misclassified: %+v", pe)
+	}
+}
+
+func TestOpenRouterNumericCodeKeepsType(t *testing.T) {
+	srv := newErrorServer(t, 401, nil, `{"error":{"message":"No auth credentials found","code":401}}`)
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 5*time.Second, RetryPolicy{})
+
+	_, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	var pe *ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Chat() error = %v, want *ProviderError", err)
+	}
+	if pe.Message != "No auth credentials found" || !pe.IsAuth() {
+		t.Fatalf("ProviderError = %+v", pe)
+	}
+}
@@ -31,6 +31,8 @@ type Factory struct {
 	defaultModelName string
 	maxTokens        int
 	temperature      float64
+	requestTimeout   time.Duration
+	retry            RetryPolicy
 }
 
 // NewFactory builds a provider factory from config.
@@ -64,6 +66,11 @@ func NewFactory(cfg *config.Config) (*Factory, error) {
 		defaultModelName: cfg.GetModelName(),
 		maxTokens:        maxTokens,
 		temperature:      temperature,
+		requestTimeout:   time.Duration(cfg.GetProviderTimeout()) * time.Second,
+		retry: RetryPolicy{
+			MaxAttempts: cfg.GetProviderMaxAttempts(),
+			BaseDelay:   time.Duration(cfg.GetProviderRetryBaseMs()) * time.Millisecond,
+		},
 	}
 
 	for _, providerName := range SupportedProviders() {
@@ -76,15 +83,45 @@ func NewFactory(cfg *config.Config) (*Factory, error) {
 		}
 	}
 
-	if conf, ok := f.configs[defaultProv]; !ok || strings.TrimSpace(conf.APIKey) == "" {
+	if conf, ok := f.configs[defaultProv]; (!ok || strings.TrimSpace(conf.APIKey) == "") && !providerRegistry[defaultProv].KeyOptional {
 		return nil, fmt.Errorf("%s API key not configured", defaultProv)
 	}
 
 	return f, nil
 }
 
+// DefaultProfile names the provider/model from the thread config.
+const DefaultProfile = "default"
+
 // Create builds a provider instance for provider/model. Empty values fall back to defaults.
 func (f *Factory) Create(providerName, modelType string) (Provider, error) {
+	return f.create(providerName, modelType, config.ProviderProfileConfig{})
+}
+
+// CreateProfile builds the provider for a named profile in
+// providers.profiles. An empty name or DefaultProfile returns the default
+// provider.
+func (f *Factory) CreateProfile(name string) (Provider, error) {
+	if f == nil {
+		return nil, fmt.Errorf("provider factory is nil")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, DefaultProfile) {
+		return f.Create("", "")
+	}
+	profile := f.cfg.GetProviderProfile(name)
+	if profile == nil {
+		return nil, fmt.Errorf("provider profile %q not found", name)
+	}
+	if strings.TrimSpace(profile.Provider) == "" || strings.TrimSpace(profile.ModelType) == "" {
+		return nil, fmt.Errorf("provider profile %q needs provider and modelType", name)
+	}
+	return f.create(profile.Provider, profile.ModelType, *profile)
+}
+
+// create builds a provider; non-empty model name, key and base in override
+// take precedence over the factory's configuration.
+func (f *Factory) create(providerName, modelType string, override config.ProviderProfileConfig) (Provider, error) {
 	if f == nil {
 		return nil, fmt.Errorf("provider factory is nil")
 	}
@@ -112,29 +149,87 @@ func (f *Factory) Create(providerName, modelType string) (Provider, error) {
 	}
 
 	// Re-resolve API key from config to pick up OAuth token refreshes.
-	apiKey := providerAPIKey(f.cfg, providerName)
-	provCfg, hasCfg := f.configs[providerName]
+	apiKey := strings.TrimSpace(override.APIKey)
 	if apiKey == "" {
-		if !hasCfg || strings.TrimSpace(provCfg.APIKey) == "" {
-			return nil, fmt.Errorf("%s API key not configured", providerName)
-		}
-		apiKey = provCfg.APIKey
+		apiKey = providerAPIKey(f.cfg, providerName)
 	}
+	provCfg, hasCfg := f.configs[providerName]
 	reg, ok := providerRegistry[providerName]
 	if !ok {
 		return nil, fmt.Errorf("unknown provider: %s", providerName)
 	}
+	if apiKey == "" && !reg.KeyOptional {
+		if !hasCfg || strings.TrimSpace(provCfg.APIKey) == "" {
+			return nil, fmt.Errorf("%s API key not configured", providerName)
+		}
+		apiKey = provCfg.APIKey
+	}
 	if reg.Constructor == nil {
 		return nil, fmt.Errorf("provider constructor not configured: %s", providerName)
 	}
 
 	modelName := modelType
-	if providerName == f.defaultProv && modelType == f.defaultModel && strings.TrimSpace(f.defaultModelName) != "" {
+	if name := strings.TrimSpace(override.ModelName); name != "" {
+		modelName = name
+	} else if providerName == f.defaultProv && modelType == f.defaultModel && strings.TrimSpace(f.defaultModelName) != "" {
 		modelName = f.defaultModelName
 	}
 
 	apiBase := provCfg.APIBase
-	return reg.Constructor(apiKey, apiBase, modelType, modelName, f.maxTokens, f.temperature), nil
+	if base := strings.TrimSpace(override.APIBase); base != "" {
+		apiBase = base
+	}
+	p := reg.Constructor(apiKey, apiBase, modelType, modelName, f.maxTokens, f.temperature, f.requestTimeout, f.retry)
+	if b, ok := p.(oauthBearer); ok && f.isOAuthToken(providerName, apiKey) {
+		b.useOAuthToken()
+	}
+	if r, ok := p.(oauthRefreshable); ok {
+		r.setTokenRefresher(func(rejected string) string {
+			return f.refreshRejectedToken(providerName, rejected)
+		})
+	}
+	return p, nil
+}
+
+// oauthRefreshable is implemented by providers that can swap in a refreshed
+// OAuth access token when the upstream rejects the current one.
+type oauthRefreshable interface {
+	setTokenRefresher(fn func(rejected string) string)
+}
+
+// oauthBearer is implemented by providers that authenticate OAuth access
+// tokens differently from API keys.
+type oauthBearer interface {
+	useOAuthToken()
+}
+
+// isOAuthToken reports whether apiKey is the stored OAuth access token for
+// providerName rather than a static or environment API key.
+func (f *Factory) isOAuthToken(providerName, apiKey string) bool {
+	token := f.cfg.GetOAuthToken(providerName)
+	return token != nil && token.AccessToken != "" && token.AccessToken == apiKey
+}
+
+// refreshRejectedToken returns a replacement for an OAuth access token the
+// provider rejected, refreshing it if no other caller already has. It returns
+// "" when the credential is not an OAuth token or cannot be refreshed.
+func (f *Factory) refreshRejectedToken(providerName, rejected string) string {
+	if reg, ok := providerRegistry[providerName]; ok && reg.EnvKey != "" && strings.TrimSpace(os.Getenv(reg.EnvKey)) != "" {
+		return "" // env keys take precedence over OAuth
+	}
+	oauthRefreshMu.Lock()
+	defer oauthRefreshMu.Unlock()
+	token := f.cfg.GetOAuthToken(providerName)
+	if token == nil || token.AccessToken == "" {
+		return ""
+	}
+	if token.AccessToken != rejected {
+		return token.AccessToken
+	}
+	if token.RefreshToken == "" {
+		return ""
+	}
+	return oauthRefresher(f.cfg, providerName)
 }
 
 func providerAPIKey(cfg *config.Config, providerName string) string {
@@ -217,6 +312,8 @@ func providerConfigFor(cfg *config.Config, providerName string) *config.Provider
 	}
 
 	switch providerName {
+	case "openai":
+		return cfg.Providers.OpenAI
 	case "openrouter":
 		return cfg.Providers.OpenRouter
 	case "anthropic":
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+func TestFactoryBuildsNativeProviders(t *testing.T) {
+	key := &config.ProviderConfig{APIKey: "test-key"}
+	cases := []struct {
+		provider string
+		model    string
+		setKey   func(*config.ProvidersConfig)
+		wantBase string
+	}{
+		{"deepseek", "deepseek-chat", func(p *config.ProvidersConfig) { p.DeepSeek = key }, deepSeekAPIBase},
+		{"moonshot-cn", "kimi-k2.5", func(p *config.ProvidersConfig) { p.MoonshotCN = key }, moonshotCNAPIBase},
+		{"moonshot-global", "kimi-k2.5", func(p *config.ProvidersConfig) { p.MoonshotGlobal = key }, moonshotGlobalAPIBase},
+	}
+	for _, tc := range cases {
+		t.Run(tc.provider, func(t *testing.T) {
+			for _, env := range []string{"DEEPSEEK_API_BASE", "MOONSHOT_API_BASE", "MOONSHOT_GLOBAL_API_BASE"} {
+				t.Setenv(env, "")
+			}
+			cfg := &config.Config{}
+			cfg.Thread.Provider = tc.provider
+			cfg.Thread.ModelType = tc.model
+			tc.setKey(&cfg.Providers)
+
+			f, err := NewFactory(cfg)
+			if err != nil {
+				t.Fatalf("NewFactory() error = %v", err)
+			}
+			p, err := f.Create("", "")
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			var base string
+			switch v := p.(type) {
+			case *DeepSeekProvider:
+				base = v.apiBase
+			case *MoonshotProvider:
+				base = v.apiBase
+			default:
+				t.Fatalf("Create() returned %T", p)
+			}
+			if base != tc.wantBase {
+				t.Fatalf("apiBase = %q, want %q", base, tc.wantBase)
+			}
+		})
+	}
+}
+
+func TestValidateProviderModelTypeNativeProviders(t *testing.T) {
+	if err := ValidateProviderModelType("deepseek", "deepseek-reasoner"); err != nil {
+		t.Fatalf("deepseek-reasoner: %v", err)
+	}
+	if err := ValidateProviderModelType("moonshot-global", "kimi-k2.5"); err != nil {
+		t.Fatalf("moonshot-global kimi-k2.5: %v", err)
+	}
+	if err := ValidateProviderModelType("deepseek", "kimi-k2.5"); err == nil {
+		t.Fatal("deepseek accepted kimi-k2.5, want error")
+	}
+}
+
+func TestFactoryCreateProfile(t *testing.T) {
+	for _, env := range []string{"DEEPSEEK_API_KEY", "DEEPSEEK_API_BASE", "MOONSHOT_API_KEY", "MOONSHOT_API_BASE"} {
+		t.Setenv(env, "")
+	}
+	cfg := &config.Config{}
+	cfg.Thread.Provider = "deepseek"
+	cfg.Thread.ModelType = "deepseek-reasoner"
+	cfg.Providers.DeepSeek = &config.ProviderConfig{APIKey: "ds-key"}
+	cfg.Providers.Profiles = map[string]*config.ProviderProfileConfig{
+		"cheap":  {Provider: "deepseek", ModelType: "deepseek-chat"},
+		"strong": {Provider: "moonshot-cn", ModelType: "kimi-k2.5", ModelName: "kimi-k2.5-preview", APIKey: "ms-key", APIBase: "https://proxy.example/v1"},
+		"broken": {Provider: "deepseek"},
+	}
+
+	f, err := NewFactory(cfg)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+
+	cheap, err := f.CreateProfile("cheap")
+	if err != nil {
+		t.Fatalf("CreateProfile(cheap) error = %v", err)
+	}
+	strong, err := f.CreateProfile("Strong")
+	if err != nil {
+		t.Fatalf("CreateProfile(Strong) error = %v", err)
+	}
+	def, err := f.CreateProfile(DefaultProfile)
+	if err != nil {
+		t.Fatalf("CreateProfile(default) error = %v", err)
+	}
+
+	ds, ok := cheap.(*DeepSeekProvider)
+	if !ok || ds.modelType != "deepseek-chat" || ds.apiKey != "ds-key" {
+		t.Fatalf("cheap = %T %+v, want deepseek-chat with the deepseek key", cheap, cheap)
+	}
+	ms, ok := strong.(*MoonshotProvider)
+	if !ok || ms.modelName != "kimi-k2.5-preview" || ms.apiKey != "ms-key" || ms.apiBase != "https://proxy.example/v1" {
+		t.Fatalf("strong = %T %+v, want moonshot with profile key, base and model name", strong, strong)
+	}
+	if d, ok := def.(*DeepSeekProvider); !ok || d.modelType != "deepseek-reasoner" {
+		t.Fatalf("default = %T %+v, want deepseek-reasoner", def, def)
+	}
+
+	if _, err := f.CreateProfile("missing"); err == nil {
+		t.Fatal("CreateProfile(missing) succeeded, want error")
+	}
+	if _, err := f.CreateProfile("broken"); err == nil {
+		t.Fatal("CreateProfile(broken) succeeded without modelType, want error")
+	}
+}
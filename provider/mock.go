@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const mockToolPrefix = "!tool "
+
+func init() {
+	RegisterProvider("mock", ProviderRegistration{
+		Models:      []string{"mock"},
+		KeyOptional: true,
+		Constructor: func(_, _, _, _ string, _ int, _ float64, _ time.Duration, _ RetryPolicy) Provider {
+			return NewMockProvider()
+		},
+	})
+}
+
+// MockProvider is an offline provider that answers deterministically without
+// network access, for demos and end-to-end tests of the thread pipeline.
+//
+// Replies come from, in order: responses queued with Script, the first rule
+// added with On whose text appears in the latest user message, and finally
+// the built-in behavior. The built-in behavior echoes the latest user message;
+// a message of the form "!tool <name> <json args>" becomes a call to that
+// tool, and a tool result is summarized back.
+type MockProvider struct {
+	mu       sync.Mutex
+	script   []*Response
+	rules    []mockRule
+	requests []*Request
+}
+
+type mockRule struct {
+	match string
+	reply *Response
+}
+
+// NewMockProvider creates a mock provider.
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+// Script queues responses returned by the next calls, one per call.
+func (p *MockProvider) Script(responses ...*Response) *MockProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.script = append(p.script, responses...)
+	return p
+}
+
+// On replies with reply when the request ends with a user message containing
+// match (case-insensitive) and no scripted response is pending. Rules are not
+// consulted after tool results, so a rule may safely return tool calls.
+func (p *MockProvider) On(match string, reply *Response) *MockProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = append(p.rules, mockRule{match: strings.ToLower(match), reply: reply})
+	return p
+}
+
+// Requests returns copies of the requests received so far.
+func (p *MockProvider) Requests() []*Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Request(nil), p.requests...)
+}
+
+// Chat returns the next scripted, rule-based or built-in reply.
+func (p *MockProvider) Chat(_ context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		req = &Request{}
+	}
+	copied := *req
+	copied.Messages = append([]Message(nil), req.Messages...)
+
+	p.mu.Lock()
+	p.requests = append(p.requests, &copied)
+	var resp *Response
+	if len(p.script) > 0 {
+		resp, p.script = p.script[0], p.script[1:]
+	} else if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == "user" {
+		lower := strings.ToLower(req.Messages[n-1].Content)
+		for _, rule := range p.rules {
+			if strings.Contains(lower, rule.match) {
+				resp = rule.reply
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	if resp == nil {
+		resp = mockDefaultReply(req.Messages)
+	}
+	out := *resp
+	out.ToolCalls = append([]ToolCall(nil), resp.ToolCalls...)
+	if out.Usage == (Usage{}) {
+		out.Usage = mockUsage(req.Messages, out)
+	}
+	return &out, nil
+}
+
+// mockDefaultReply echoes the latest user message, turns "!tool" messages
+// into tool calls, and summarizes tool results.
+func mockDefaultReply(messages []Message) *Response {
+	if n := len(messages); n > 0 && messages[n-1].Role == "tool" {
+		var parts []string
+		for i := n - 1; i >= 0 && messages[i].Role == "tool"; i-- {
+			parts = append([]string{fmt.Sprintf("%s returned: %s", messages[i].Name, strings.TrimSpace(messages[i].Content))}, parts...)
+		}
+		return &Response{Content: "[mock] " + strings.Join(parts, "; ")}
+	}
+
+	last := strings.TrimSpace(lastUserContent(messages))
+	if rest, ok := strings.CutPrefix(last, mockToolPrefix); ok {
+		name, args, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		args = strings.TrimSpace(args)
+		if args == "" {
+			args = "{}"
+		}
+		if name != "" {
+			return &Response{ToolCalls: []ToolCall{{
+				ID:       fmt.Sprintf("mock-call-%d", len(messages)),
+				Type:     "function",
+				Function: FunctionCall{Name: name, Arguments: args},
+			}}}
+		}
+	}
+	return &Response{Content: "[mock] " + last}
+}
+
+func lastUserContent(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// mockUsage approximates token counts at four characters per token.
+func mockUsage(messages []Message, resp Response) Usage {
+	in := 0
+	for _, m := range messages {
+		in += len(m.Content)
+	}
+	out := len(resp.Content)
+	for _, call := range resp.ToolCalls {
+		out += len(call.Function.Name) + len(call.Function.Arguments)
+	}
+	u := Usage{PromptTokens: (in + 3) / 4, CompletionTokens: (out + 3) / 4}
+	u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	return u
+}
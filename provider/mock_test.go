@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+func TestMockProviderReplies(t *testing.T) {
+	p := NewMockProvider().
+		Script(&Response{Content: "scripted"}).
+		On("weather", &Response{ToolCalls: []ToolCall{{ID: "w1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: "{}"}}}})
+	chat := func(messages ...Message) *Response {
+		t.Helper()
+		resp, err := p.Chat(context.Background(), &Request{Messages: messages})
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		return resp
+	}
+
+	if resp := chat(UserMessage("what's the weather?")); resp.Content != "scripted" {
+		t.Fatalf("first reply = %q, want the scripted response", resp.Content)
+	}
+	if resp := chat(UserMessage("What's the WEATHER?")); len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "get_weather" {
+		t.Fatalf("rule reply = %+v, want get_weather call", resp)
+	}
+	if resp := chat(UserMessage("  hello  ")); resp.Content != "[mock] hello" || resp.Usage.TotalTokens == 0 {
+		t.Fatalf("echo reply = %+v, want [mock] hello with usage", resp)
+	}
+
+	resp := chat(UserMessage(`!tool read_file {"path":"a.txt"}`))
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "read_file" || resp.ToolCalls[0].Function.Arguments != `{"path":"a.txt"}` {
+		t.Fatalf("tool reply = %+v, want read_file call", resp)
+	}
+	// After the tool result, rules are skipped and the result is summarized.
+	resp = chat(
+		UserMessage("weather !"),
+		Message{Role: "assistant", ToolCalls: resp.ToolCalls},
+		Message{Role: "tool", Name: "read_file", ToolCallID: resp.ToolCalls[0].ID, Content: "contents"},
+	)
+	if resp.Content != "[mock] read_file returned: contents" || len(resp.ToolCalls) != 0 {
+		t.Fatalf("tool result reply = %+v", resp)
+	}
+	if got := len(p.Requests()); got != 5 {
+		t.Fatalf("Requests() = %d, want 5", got)
+	}
+}
+
+func TestFactoryCreatesMockWithoutKey(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Thread.Provider = "mock"
+	cfg.Thread.ModelType = "mock"
+
+	f, err := NewFactory(cfg)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	p, err := f.Create("", "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := p.(*MockProvider); !ok {
+		t.Fatalf("Create() returned %T, want *MockProvider", p)
+	}
+}
@@ -23,8 +23,8 @@ func init() {
 		Models:  []string{"kimi-k2.5"},
 		EnvKey:  "MOONSHOT_API_KEY",
 		EnvBase: "MOONSHOT_API_BASE",
-		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider {
-			return newMoonshotProvider("moonshot-cn", apiKey, apiBase, moonshotCNAPIBase, modelType, modelName, maxTokens, temperature)
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, _ RetryPolicy) Provider {
+			return newMoonshotProvider("moonshot-cn", apiKey, apiBase, moonshotCNAPIBase, modelType, modelName, maxTokens, temperature, requestTimeout)
 		},
 	})
 
@@ -32,8 +32,8 @@ func init() {
 		Models:  []string{"kimi-k2.5"},
 		EnvKey:  "MOONSHOT_GLOBAL_API_KEY",
 		EnvBase: "MOONSHOT_GLOBAL_API_BASE",
-		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider {
-			return newMoonshotProvider("moonshot-global", apiKey, apiBase, moonshotGlobalAPIBase, modelType, modelName, maxTokens, temperature)
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, _ RetryPolicy) Provider {
+			return newMoonshotProvider("moonshot-global", apiKey, apiBase, moonshotGlobalAPIBase, modelType, modelName, maxTokens, temperature, requestTimeout)
 		},
 	})
 }
@@ -58,7 +58,7 @@ func moonshotRequestTemperature(modelType string, configured float64) (float64,
 }
 
 // newMoonshotProvider creates a new Moonshot provider.
-func newMoonshotProvider(providerName, apiKey, apiBase, defaultBase, modelType, modelName string, maxTokens int, temperature float64) *MoonshotProvider {
+func newMoonshotProvider(providerName, apiKey, apiBase, defaultBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration) *MoonshotProvider {
 	if modelName == "" {
 		modelName = modelType
 	}
@@ -68,6 +68,7 @@ func newMoonshotProvider(providerName, apiKey, apiBase, defaultBase, modelType,
 		oaioption.WithAPIKey(apiKey),
 		oaioption.WithBaseURL(baseURL),
 		oaioption.WithMaxRetries(sdkMaxRetries),
+		oaioption.WithRequestTimeout(requestTimeout),
 	)
 
 	return &MoonshotProvider{
@@ -126,7 +127,7 @@ func (p *MoonshotProvider) Chat(ctx context.Context, req *Request) (*Response, e
 	chatResp, err := p.client.Chat.Completions.New(ctx, chatReq)
 	if err != nil {
 		logger.Error("moonshot request send error", "provider", p.providerName, "err", err)
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", asProviderError(p.providerName, err))
 	}
 
 	if len(chatResp.Choices) == 0 {
@@ -0,0 +1,175 @@
+// Package provider provides LLM provider implementations.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linanwx/nagobot/logger"
+	openai "github.com/openai/openai-go/v3"
+	oaioption "github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+const (
+	openAIAPIBase = "https://api.openai.com/v1"
+)
+
+func init() {
+	RegisterProvider("openai", ProviderRegistration{
+		Models:  []string{"gpt-4.1", "gpt-4.1-mini", "gpt-4o", "gpt-4o-mini"},
+		EnvKey:  "OPENAI_API_KEY",
+		EnvBase: "OPENAI_API_BASE",
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) Provider {
+			return newOpenAIProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature, requestTimeout, retry)
+		},
+	})
+}
+
+// OpenAIProvider implements the Provider interface for the OpenAI API. The
+// credential is either an API key or the access token from `nagobot oauth
+// openai`; a rejected OAuth token is refreshed once and the request retried.
+type OpenAIProvider struct {
+	apiBase     string
+	modelName   string
+	modelType   string
+	maxTokens   int
+	temperature float64
+	retry       RetryPolicy
+	client      openai.Client
+
+	mu      sync.Mutex
+	apiKey  string
+	refresh func(rejected string) string // nil when the factory cannot refresh tokens
+}
+
+// newOpenAIProvider creates a new OpenAI provider.
+func newOpenAIProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) *OpenAIProvider {
+	if modelName == "" {
+		modelName = modelType
+	}
+
+	baseURL := normalizeSDKBaseURL(apiBase, openAIAPIBase, "/chat/completions")
+	client := openai.NewClient(
+		oaioption.WithAPIKey(apiKey),
+		oaioption.WithBaseURL(baseURL),
+		oaioption.WithMaxRetries(0), // retried by withRetry
+		oaioption.WithRequestTimeout(requestTimeout),
+	)
+
+	return &OpenAIProvider{
+		apiKey:      apiKey,
+		apiBase:     baseURL,
+		modelName:   modelName,
+		modelType:   modelType,
+		maxTokens:   maxTokens,
+		temperature: temperature,
+		retry:       retry,
+		client:      client,
+	}
+}
+
+// setTokenRefresher implements oauthRefreshable.
+func (p *OpenAIProvider) setTokenRefresher(fn func(rejected string) string) {
+	p.mu.Lock()
+	p.refresh = fn
+	p.mu.Unlock()
+}
+
+func (p *OpenAIProvider) credentials() (string, func(string) string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.apiKey, p.refresh
+}
+
+// Chat sends a chat completion request to OpenAI.
+func (p *OpenAIProvider) Chat(ctx context.Context, req *Request) (*Response, error) {
+	start := time.Now()
+	messages, err := toOpenAIChatMessages(req.Messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert messages: %w", err)
+	}
+
+	logger.Info(
+		"openai request",
+		"provider", "openai",
+		"modelType", p.modelType,
+		"modelName", p.modelName,
+		"toolCount", len(req.Tools),
+		"inputChars", openRouterInputChars(req.Messages),
+	)
+
+	chatReq := openai.ChatCompletionNewParams{
+		Model:    shared.ChatModel(p.modelName),
+		Messages: messages,
+		Tools:    toOpenAIChatTools(req.Tools),
+	}
+	if p.maxTokens > 0 {
+		chatReq.MaxCompletionTokens = openai.Int(int64(p.maxTokens))
+	}
+	if p.temperature != 0 {
+		chatReq.Temperature = openai.Float(p.temperature)
+	}
+
+	apiKey, refresh := p.credentials()
+	chatResp, err := p.send(ctx, chatReq, apiKey)
+	var pe *ProviderError
+	if errors.As(err, &pe) && pe.IsAuth() && refresh != nil {
+		if fresh := refresh(apiKey); fresh != "" && fresh != apiKey {
+			logger.Info("openai token rejected, retrying with refreshed token", "provider", "openai", "status", pe.StatusCode)
+			p.mu.Lock()
+			p.apiKey = fresh
+			p.mu.Unlock()
+			chatResp, err = p.send(ctx, chatReq, fresh)
+		}
+	}
+	if err != nil {
+		logger.Error("openai request send error", "provider", "openai", "err", err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		logger.Error("openai no choices", "provider", "openai")
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := chatResp.Choices[0]
+	toolCalls := fromOpenAIChatToolCalls(choice.Message.ToolCalls)
+	logger.Info(
+		"openai response",
+		"provider", "openai",
+		"modelType", p.modelType,
+		"modelName", p.modelName,
+		"finishReason", choice.FinishReason,
+		"hasToolCalls", len(toolCalls) > 0,
+		"toolCallCount", len(toolCalls),
+		"promptTokens", chatResp.Usage.PromptTokens,
+		"completionTokens", chatResp.Usage.CompletionTokens,
+		"reasoningTokens", chatResp.Usage.CompletionTokensDetails.ReasoningTokens,
+		"totalTokens", chatResp.Usage.TotalTokens,
+		"outputChars", len(choice.Message.Content),
+		"latencyMs", time.Since(start).Milliseconds(),
+	)
+	logger.Debug("openai raw output", "rawResponse", chatResp.RawJSON())
+
+	return &Response{
+		Content:   choice.Message.Content,
+		ToolCalls: toolCalls,
+		Usage: Usage{
+			PromptTokens:     int(chatResp.Usage.PromptTokens),
+			CompletionTokens: int(chatResp.Usage.CompletionTokens),
+			TotalTokens:      int(chatResp.Usage.TotalTokens),
+		},
+	}, nil
+}
+
+// send issues one chat completion with apiKey, retrying transient failures.
+func (p *OpenAIProvider) send(ctx context.Context, chatReq openai.ChatCompletionNewParams, apiKey string) (*openai.ChatCompletion, error) {
+	resp, err := withRetry(ctx, "openai", p.retry, func() (*openai.ChatCompletion, error) {
+		return p.client.Chat.Completions.New(ctx, chatReq, oaioption.WithAPIKey(apiKey))
+	})
+	return resp, asProviderError("openai", err)
+}
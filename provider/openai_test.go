@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/config"
+)
+
+func TestOpenAIProviderRefreshesRejectedOAuthToken(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_BASE", "")
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"token expired","type":"invalid_request_error"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"c1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{}
+	cfg.Thread.Provider = "openai"
+	cfg.Thread.ModelType = "gpt-4o"
+	cfg.Providers.OpenAI = &config.ProviderConfig{APIBase: srv.URL}
+	// Not yet expired locally, so only the 401 reveals that it was revoked.
+	cfg.Providers.OpenAIOAuth = &config.OAuthTokenConfig{
+		AccessToken:  "stale-token",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}
+
+	var refreshes int
+	prev := oauthRefresher
+	oauthRefresher = func(c *config.Config, name string) string {
+		refreshes++
+		c.SetOAuthToken(name, &config.OAuthTokenConfig{AccessToken: "fresh-token", RefreshToken: "refresh"})
+		return "fresh-token"
+	}
+	t.Cleanup(func() { oauthRefresher = prev })
+
+	f, err := NewFactory(cfg)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	p, err := f.Create("", "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := p.(*OpenAIProvider); !ok {
+		t.Fatalf("Create() returned %T, want *OpenAIProvider", p)
+	}
+
+	resp, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hello")}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hi" || resp.Usage.TotalTokens != 4 {
+		t.Fatalf("resp = %+v, want content hi and 4 tokens", resp)
+	}
+	if refreshes != 1 || calls.Load() != 2 {
+		t.Fatalf("refreshes = %d, calls = %d, want 1 and 2", refreshes, calls.Load())
+	}
+
+	// The refreshed token is reused without another refresh.
+	if _, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("again")}}); err != nil {
+		t.Fatalf("second Chat() error = %v", err)
+	}
+	if refreshes != 1 || calls.Load() != 3 {
+		t.Fatalf("after second chat: refreshes = %d, calls = %d, want 1 and 3", refreshes, calls.Load())
+	}
+}
+
+func TestOpenAIProviderDoesNotRefreshStaticKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_BASE", "")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"bad key","type":"invalid_request_error"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &config.Config{}
+	cfg.Thread.Provider = "openai"
+	cfg.Thread.ModelType = "gpt-4o"
+	cfg.Providers.OpenAI = &config.ProviderConfig{APIKey: "sk-static", APIBase: srv.URL}
+
+	prev := oauthRefresher
+	oauthRefresher = func(*config.Config, string) string {
+		t.Fatal("refresher called for a static API key")
+		return ""
+	}
+	t.Cleanup(func() { oauthRefresher = prev })
+
+	f, err := NewFactory(cfg)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	p, err := f.Create("", "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, err = p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hello")}})
+	var pe *ProviderError
+	if !errors.As(err, &pe) || pe.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Chat() error = %v, want 401 ProviderError", err)
+	}
+}
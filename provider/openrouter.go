@@ -52,8 +52,8 @@ func init() {
 		Models:  []string{"moonshotai/kimi-k2.5"},
 		EnvKey:  "OPENROUTER_API_KEY",
 		EnvBase: "OPENROUTER_API_BASE",
-		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider {
-			return newOpenRouterProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature)
+		Constructor: func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) Provider {
+			return newOpenRouterProvider(apiKey, apiBase, modelType, modelName, maxTokens, temperature, requestTimeout, retry)
 		},
 	})
 }
@@ -66,11 +66,12 @@ type OpenRouterProvider struct {
 	modelType   string
 	maxTokens   int
 	temperature float64
+	retry       RetryPolicy
 	client      openai.Client
 }
 
 // newOpenRouterProvider creates a new OpenRouter provider.
-func newOpenRouterProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) *OpenRouterProvider {
+func newOpenRouterProvider(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) *OpenRouterProvider {
 	if modelName == "" {
 		modelName = modelType
 	}
@@ -81,7 +82,8 @@ func newOpenRouterProvider(apiKey, apiBase, modelType, modelName string, maxToke
 		oaioption.WithBaseURL(baseURL),
 		oaioption.WithHeader("HTTP-Referer", "https://github.com/linanwx/nagobot"),
 		oaioption.WithHeader("X-Title", "nagobot"),
-		oaioption.WithMaxRetries(sdkMaxRetries),
+		oaioption.WithMaxRetries(0), // retried by withRetry
+		oaioption.WithRequestTimeout(requestTimeout),
 	)
 
 	return &OpenRouterProvider{
@@ -91,6 +93,7 @@ func newOpenRouterProvider(apiKey, apiBase, modelType, modelName string, maxToke
 		modelType:   modelType,
 		maxTokens:   maxTokens,
 		temperature: temperature,
+		retry:       retry,
 		client:      client,
 	}
 }
@@ -112,7 +115,16 @@ func toOpenAIChatMessages(messages []Message) ([]openai.ChatCompletionMessagePar
 		case "system":
 			result = append(result, openai.SystemMessage(m.Content))
 		case "user":
-			result = append(result, openai.UserMessage(m.Content))
+			if len(m.Images) == 0 {
+				result = append(result, openai.UserMessage(m.Content))
+				continue
+			}
+			parts := make([]openai.ChatCompletionContentPartUnionParam, 0, 1+len(m.Images))
+			parts = append(parts, openai.TextContentPart(m.Content))
+			for _, img := range m.Images {
+				parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: img.DataURL()}))
+			}
+			result = append(result, openai.UserMessage(parts))
 		case "tool":
 			result = append(result, openai.ToolMessage(m.Content, m.ToolCallID))
 		case "assistant":
@@ -202,14 +214,14 @@ func fromOpenAIChatToolCalls(calls []openai.ChatCompletionMessageToolCallUnion)
 	return result
 }
 
-// Chat sends a chat completion request to OpenRouter.
-func (p *OpenRouterProvider) Chat(ctx context.Context, req *Request) (*Response, error) {
-	start := time.Now()
+// newChatParams converts req into completion params and request options, and
+// logs the outgoing request.
+func (p *OpenRouterProvider) newChatParams(req *Request, stream bool) (openai.ChatCompletionNewParams, []oaioption.RequestOption, error) {
 	inputChars := openRouterInputChars(req.Messages)
 
 	messages, err := toOpenAIChatMessages(req.Messages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert messages: %w", err)
+		return openai.ChatCompletionNewParams{}, nil, fmt.Errorf("failed to convert messages: %w", err)
 	}
 
 	thinkingEnabled := IsKimiModel(p.modelType)
@@ -219,6 +231,7 @@ func (p *OpenRouterProvider) Chat(ctx context.Context, req *Request) (*Response,
 		"modelType", p.modelType,
 		"modelName", p.modelName,
 		"thinkingEnabled", thinkingEnabled,
+		"stream", stream,
 		"toolCount", len(req.Tools),
 		"inputChars", inputChars,
 	)
@@ -234,16 +247,31 @@ func (p *OpenRouterProvider) Chat(ctx context.Context, req *Request) (*Response,
 	if p.temperature != 0 {
 		chatReq.Temperature = openai.Float(p.temperature)
 	}
+	if stream {
+		chatReq.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
 
 	requestOpts := []oaioption.RequestOption{}
 	if thinkingEnabled {
 		requestOpts = append(requestOpts, oaioption.WithJSONSet("extra_body.chat_template_kwargs.thinking", true))
 	}
+	return chatReq, requestOpts, nil
+}
 
-	chatResp, err := p.client.Chat.Completions.New(ctx, chatReq, requestOpts...)
+// Chat sends a chat completion request to OpenRouter.
+func (p *OpenRouterProvider) Chat(ctx context.Context, req *Request) (*Response, error) {
+	start := time.Now()
+	chatReq, requestOpts, err := p.newChatParams(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	chatResp, err := withRetry(ctx, "openrouter", p.retry, func() (*openai.ChatCompletion, error) {
+		return p.client.Chat.Completions.New(ctx, chatReq, requestOpts...)
+	})
 	if err != nil {
 		logger.Error("openrouter request send error", "provider", "openrouter", "err", err)
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", asProviderError("openrouter", err))
 	}
 
 	if len(chatResp.Choices) == 0 {
@@ -297,3 +325,107 @@ func (p *OpenRouterProvider) Chat(ctx context.Context, req *Request) (*Response,
 		},
 	}, nil
 }
+
+// ChatStream sends a streaming chat completion request to OpenRouter. Content
+// deltas are passed to onDelta as they arrive; tool-call fragments and Kimi
+// reasoning deltas are aggregated into the returned Response.
+func (p *OpenRouterProvider) ChatStream(ctx context.Context, req *Request, onDelta func(delta string)) (*Response, error) {
+	start := time.Now()
+	chatReq, requestOpts, err := p.newChatParams(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	type streamResult struct {
+		acc       openai.ChatCompletionAccumulator
+		reasoning string
+	}
+	result, err := withRetry(ctx, "openrouter", p.retry, func() (*streamResult, error) {
+		var res streamResult
+		var reasoning strings.Builder
+		emitted := false
+
+		stream := p.client.Chat.Completions.NewStreaming(ctx, chatReq, requestOpts...)
+		defer stream.Close()
+		for stream.Next() {
+			chunk := stream.Current()
+			if !res.acc.AddChunk(chunk) {
+				logger.Warn("openrouter stream chunk not accumulated", "provider", "openrouter", "chunkID", chunk.ID)
+				continue
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Index != 0 {
+					continue
+				}
+				reasoning.WriteString(extractReasoningText(choice.Delta.RawJSON()))
+				if choice.Delta.Content != "" && onDelta != nil {
+					emitted = true
+					onDelta(choice.Delta.Content)
+				}
+			}
+		}
+		if err := stream.Err(); err != nil {
+			if emitted {
+				// Deltas already reached the caller; a retry would repeat them.
+				return nil, &noRetryError{err: err}
+			}
+			return nil, err
+		}
+		res.reasoning = reasoning.String()
+		return &res, nil
+	})
+	if err != nil {
+		logger.Error("openrouter stream error", "provider", "openrouter", "err", err)
+		return nil, fmt.Errorf("request failed: %w", asProviderError("openrouter", err))
+	}
+	acc := result.acc
+
+	if len(acc.Choices) == 0 {
+		logger.Error("openrouter no choices", "provider", "openrouter")
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := acc.Choices[0]
+	// Only the first fragment of a tool call carries its type.
+	for i := range choice.Message.ToolCalls {
+		if choice.Message.ToolCalls[i].Type == "" {
+			choice.Message.ToolCalls[i].Type = "function"
+		}
+	}
+	toolCalls := fromOpenAIChatToolCalls(choice.Message.ToolCalls)
+	reasoningText := result.reasoning
+	finalContent := choice.Message.Content
+	if strings.TrimSpace(finalContent) == "" && len(toolCalls) == 0 && strings.TrimSpace(reasoningText) != "" {
+		logger.Warn("openrouter response content empty, using reasoning text fallback")
+		finalContent = reasoningText
+	}
+
+	logger.Info(
+		"openrouter response",
+		"provider", "openrouter",
+		"modelType", p.modelType,
+		"modelName", p.modelName,
+		"stream", true,
+		"finishReason", choice.FinishReason,
+		"hasToolCalls", len(toolCalls) > 0,
+		"toolCallCount", len(toolCalls),
+		"promptTokens", acc.Usage.PromptTokens,
+		"completionTokens", acc.Usage.CompletionTokens,
+		"reasoningTokens", acc.Usage.CompletionTokensDetails.ReasoningTokens,
+		"totalTokens", acc.Usage.TotalTokens,
+		"outputChars", len(choice.Message.Content),
+		"latencyMs", time.Since(start).Milliseconds(),
+	)
+	logger.Debug("openrouter stream output", "reasoningText", reasoningText)
+
+	return &Response{
+		Content:          finalContent,
+		ReasoningContent: reasoningText,
+		ToolCalls:        toolCalls,
+		Usage: Usage{
+			PromptTokens:     int(acc.Usage.PromptTokens),
+			CompletionTokens: int(acc.Usage.CompletionTokens),
+			TotalTokens:      int(acc.Usage.TotalTokens),
+		},
+	}, nil
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSSEServer serves chunks as one streamed chat completion and records the
+// request body.
+func newSSEServer(t *testing.T, chunks []string, body *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		if body != nil {
+			_ = json.Unmarshal(raw, body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", c)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenRouterChatStreamAggregatesDeltas(t *testing.T) {
+	var body map[string]any
+	srv := newSSEServer(t, []string{
+		`{"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","reasoning":"think "}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"reasoning":"hard"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"id":"c1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`,
+	}, &body)
+
+	p := newOpenRouterProvider("key", srv.URL, "moonshotai/kimi-k2.5", "", 0, 0, 5*time.Second, RetryPolicy{})
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), &Request{Messages: []Message{UserMessage("hi")}}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Fatalf("deltas = %q, want Hel|lo", deltas)
+	}
+	if resp.Content != "Hello" {
+		t.Fatalf("Content = %q, want Hello", resp.Content)
+	}
+	if resp.ReasoningContent != "think hard" {
+		t.Fatalf("ReasoningContent = %q, want aggregated reasoning", resp.ReasoningContent)
+	}
+	if resp.Usage.TotalTokens != 14 {
+		t.Fatalf("Usage = %+v, want total 14", resp.Usage)
+	}
+	if body["stream"] != true {
+		t.Fatalf("request stream = %v, want true", body["stream"])
+	}
+}
+
+func TestOpenRouterChatStreamAssemblesToolCalls(t *testing.T) {
+	srv := newSSEServer(t, []string{
+		`{"id":"c2","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"read_file","arguments":""}}]}}]}`,
+		`{"id":"c2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":"}}]}}]}`,
+		`{"id":"c2","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"list_dir","arguments":"{}"}}]}}]}`,
+		`{"id":"c2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"a.txt\"}"}}]}}]}`,
+		`{"id":"c2","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}, nil)
+
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 5*time.Second, RetryPolicy{})
+	resp, err := p.ChatStream(context.Background(), &Request{Messages: []Message{UserMessage("read it")}}, func(string) {})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("ToolCalls = %+v, want 2", resp.ToolCalls)
+	}
+	first, second := resp.ToolCalls[0], resp.ToolCalls[1]
+	if first.ID != "call_a" || first.Function.Name != "read_file" || first.Function.Arguments != `{"path":"a.txt"}` {
+		t.Fatalf("first tool call = %+v", first)
+	}
+	if second.ID != "call_b" || second.Function.Name != "list_dir" || second.Function.Arguments != "{}" {
+		t.Fatalf("second tool call = %+v", second)
+	}
+}
+
+func TestOpenRouterChatTimesOut(t *testing.T) {
+	srv := newHangingServer(t)
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 50*time.Millisecond, RetryPolicy{})
+
+	start := time.Now()
+	_, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	if err == nil {
+		t.Fatal("Chat() succeeded against a hanging server, want timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Chat() took %v, want it bounded by the request timeout", elapsed)
+	}
+}
+
+func TestToOpenAIChatMessagesEncodesImages(t *testing.T) {
+	msg := UserMessage("what is this?")
+	msg.Images = []ImagePart{
+		{MimeType: "image/png", Data: "iVBORw0KGgo="},
+		{URL: "https://example.com/cat.jpg"},
+	}
+	params, err := toOpenAIChatMessages([]Message{msg, UserMessage("plain")})
+	if err != nil {
+		t.Fatalf("toOpenAIChatMessages() error = %v", err)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(data)
+	for _, want := range []string{
+		`{"text":"what is this?","type":"text"}`,
+		`{"image_url":{"url":"data:image/png;base64,iVBORw0KGgo="},"type":"image_url"}`,
+		`{"image_url":{"url":"https://example.com/cat.jpg"},"type":"image_url"}`,
+		`{"content":"plain","role":"user"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("serialized message missing %s:\n%s", want, body)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Provider is the interface for LLM providers.
@@ -14,6 +15,16 @@ type Provider interface {
 	Chat(ctx context.Context, req *Request) (*Response, error)
 }
 
+// StreamingProvider is implemented by providers that can stream assistant
+// text as it is generated.
+type StreamingProvider interface {
+	Provider
+
+	// ChatStream behaves like Chat but calls onDelta with each partial chunk
+	// of assistant content. The returned Response carries the full content.
+	ChatStream(ctx context.Context, req *Request, onDelta func(delta string)) (*Response, error)
+}
+
 // Request represents a chat completion request.
 type Request struct {
 	Messages []Message
@@ -22,12 +33,30 @@ type Request struct {
 
 // Message represents a chat message in OpenAI format (internal canonical format).
 type Message struct {
-	Role             string     `json:"role"`                        // system, user, assistant, tool
-	Content          string     `json:"content,omitempty"`           // text content
-	ReasoningContent string     `json:"reasoning_content,omitempty"` // reasoning text for providers that require it
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`        // for assistant messages
-	ToolCallID       string     `json:"tool_call_id,omitempty"`      // for tool result messages
-	Name             string     `json:"name,omitempty"`              // tool name for tool results
+	Role             string      `json:"role"`                        // system, user, assistant, tool
+	Content          string      `json:"content,omitempty"`           // text content
+	ReasoningContent string      `json:"reasoning_content,omitempty"` // reasoning text for providers that require it
+	ToolCalls        []ToolCall  `json:"tool_calls,omitempty"`        // for assistant messages
+	ToolCallID       string      `json:"tool_call_id,omitempty"`      // for tool result messages
+	Name             string      `json:"name,omitempty"`              // tool name for tool results
+	Images           []ImagePart `json:"images,omitempty"`            // images attached to a user message
+}
+
+// ImagePart is an image attached to a user message, given either by URL or
+// as base64 data with its MIME type.
+type ImagePart struct {
+	URL      string `json:"url,omitempty"`
+	MimeType string `json:"mime_type,omitempty"` // e.g. image/png, required with Data
+	Data     string `json:"data,omitempty"`      // base64-encoded image bytes
+}
+
+// DataURL returns the image as a URL: URL itself, or a data: URL built from
+// Data and MimeType.
+func (p ImagePart) DataURL() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return "data:" + p.MimeType + ";base64," + p.Data
 }
 
 // ToolCall represents a tool invocation by the model.
@@ -63,6 +92,17 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// Add accumulates other into u. A missing total is derived from prompt + completion.
+func (u *Usage) Add(other Usage) {
+	total := other.TotalTokens
+	if total == 0 {
+		total = other.PromptTokens + other.CompletionTokens
+	}
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += total
+}
+
 // ToolDef defines a tool for the LLM (OpenAI function calling format).
 type ToolDef struct {
 	Type     string      `json:"type"` // "function"
@@ -77,13 +117,16 @@ type FunctionDef struct {
 }
 
 // ProviderConstructor builds a provider for the requested model/runtime settings.
-type ProviderConstructor func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64) Provider
+// requestTimeout bounds each HTTP attempt; retries start a fresh timeout.
+// retry is honored by providers that retry transient upstream errors themselves.
+type ProviderConstructor func(apiKey, apiBase, modelType, modelName string, maxTokens int, temperature float64, requestTimeout time.Duration, retry RetryPolicy) Provider
 
 // ProviderRegistration defines metadata and constructor for a provider.
 type ProviderRegistration struct {
 	Models      []string
 	EnvKey      string
 	EnvBase     string
+	KeyOptional bool // the provider runs without an API key, e.g. mock
 	Constructor ProviderConstructor
 }
 
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	"github.com/linanwx/nagobot/logger"
+	openai "github.com/openai/openai-go/v3"
+)
+
+const (
+	maxRetryDelay      = 30 * time.Second
+	maxRetryAfterDelay = 60 * time.Second
+)
+
+// RetryPolicy controls how provider calls are retried on transient errors.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first, <= 1 disables retries
+	BaseDelay   time.Duration // backoff before the first retry, doubled per attempt
+}
+
+// retryableStatus reports whether an upstream HTTP status is worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		529: // Anthropic "overloaded"
+		return true
+	default:
+		return false
+	}
+}
+
+// noRetryError marks an error that must not be retried even if its status is
+// transient, e.g. a stream that already emitted output.
+type noRetryError struct{ err error }
+
+func (e *noRetryError) Error() string { return e.err.Error() }
+func (e *noRetryError) Unwrap() error { return e.err }
+
+// apiErrorStatus extracts the HTTP status and response headers from SDK errors.
+func apiErrorStatus(err error) (int, http.Header) {
+	var aerr *anthropic.Error
+	if errors.As(err, &aerr) {
+		return aerr.StatusCode, responseHeader(aerr.Response)
+	}
+	var oerr *openai.Error
+	if errors.As(err, &oerr) {
+		return oerr.StatusCode, responseHeader(oerr.Response)
+	}
+	return 0, nil
+}
+
+func responseHeader(resp *http.Response) http.Header {
+	if resp == nil {
+		return nil
+	}
+	return resp.Header
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return min(time.Duration(secs*float64(time.Second)), maxRetryAfterDelay), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return min(d, maxRetryAfterDelay), true
+	}
+	return 0, false
+}
+
+// backoffDelay returns the jittered exponential delay before retry number
+// attempt (1-based), drawn from [d/2, d] where d = base * 2^(attempt-1).
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 1; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxRetryDelay)
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// withRetry runs fn until it succeeds, fails with a non-transient error, or
+// policy.MaxAttempts is exhausted. Retry-After is honored when present.
+func withRetry[T any](ctx context.Context, providerName string, policy RetryPolicy, fn func() (T, error)) (T, error) {
+	attempts := max(policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= attempts {
+			return result, err
+		}
+		var nr *noRetryError
+		if errors.As(err, &nr) {
+			return result, err
+		}
+		status, header := apiErrorStatus(err)
+		if !retryableStatus(status) {
+			return result, err
+		}
+
+		delay, ok := parseRetryAfter(header.Get("Retry-After"), time.Now())
+		if !ok {
+			delay = backoffDelay(policy.BaseDelay, attempt)
+		}
+		logger.Warn(
+			"provider request failed, retrying",
+			"provider", providerName,
+			"status", status,
+			"attempt", attempt,
+			"maxAttempts", attempts,
+			"delayMs", delay.Milliseconds(),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer answers the first request with status and Retry-After, then
+// serves body as a successful JSON response.
+func newFlakyServer(t *testing.T, status int, retryAfter, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if hits.Add(1) == 1 {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error":{"type":"rate_limit_error","message":"slow down"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestAnthropicChatRetriesRateLimit(t *testing.T) {
+	srv, hits := newFlakyServer(t, http.StatusTooManyRequests, "0", `{
+		"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5",
+		"content":[{"type":"text","text":"hello"}],
+		"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}`)
+	p := newAnthropicProvider("key", srv.URL, "claude-sonnet-4-5", "", 4096, 0, 5*time.Second, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	resp, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Fatalf("Content = %q, want hello", resp.Content)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("server hits = %d, want 2", got)
+	}
+}
+
+func TestOpenRouterChatRetriesOverloaded(t *testing.T) {
+	srv, hits := newFlakyServer(t, 529, "", `{
+		"id":"c1","object":"chat.completion","model":"openai/gpt-4o",
+		"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`)
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 5*time.Second, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	resp, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Fatalf("Content = %q, want hello", resp.Content)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("server hits = %d, want 2", got)
+	}
+}
+
+func TestChatDoesNotRetryClientErrors(t *testing.T) {
+	srv, hits := newFlakyServer(t, http.StatusBadRequest, "", `{}`)
+	p := newOpenRouterProvider("key", srv.URL, "openai/gpt-4o", "", 0, 0, 5*time.Second, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	if _, err := p.Chat(context.Background(), &Request{Messages: []Message{UserMessage("hi")}}); err == nil {
+		t.Fatal("Chat() succeeded, want 400 error")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("server hits = %d, want 1", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d, ok := parseRetryAfter("2", now); !ok || d != 2*time.Second {
+		t.Fatalf("seconds: got %v, %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now); !ok || d != 5*time.Second {
+		t.Fatalf("http date: got %v, %v", d, ok)
+	}
+	if d, ok := parseRetryAfter("3600", now); !ok || d != maxRetryAfterDelay {
+		t.Fatalf("cap: got %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Fatal("invalid value parsed")
+	}
+}
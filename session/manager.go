@@ -1,10 +1,7 @@
 package session
 
 import (
-	"encoding/json"
-	"errors"
-	"os"
-	"path/filepath"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -16,26 +13,51 @@ import (
 type Session struct {
 	Key       string             `json:"key"`
 	Messages  []provider.Message `json:"messages"`
+	Workspace string             `json:"workspace,omitempty"` // active named workspace, empty = default
+	Usage     provider.Usage     `json:"usage"`               // running token totals; reset only when the session is deleted
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at"`
 }
 
-// Manager manages conversation sessions.
+// Manager manages conversation sessions on top of a persistence Store.
 type Manager struct {
 	sessionsDir string
+	store       Store
 	cache       map[string]*Session
 	mu          sync.RWMutex
+
+	locksMu sync.Mutex
+	locks   map[string]*keyLock // per-key locks for read-modify-write cycles
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
 }
 
-// NewManager creates a new session manager rooted at the given sessions directory.
+// NewManager creates a new session manager rooted at the given sessions directory,
+// backed by the filesystem store.
 func NewManager(sessionsDir string) (*Manager, error) {
-	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+	store, err := NewFileStore(sessionsDir)
+	if err != nil {
 		return nil, err
 	}
+	return NewManagerWithStore(sessionsDir, store), nil
+}
+
+// NewManagerWithStore creates a session manager backed by store.
+func NewManagerWithStore(sessionsDir string, store Store) *Manager {
 	return &Manager{
 		sessionsDir: sessionsDir,
+		store:       store,
 		cache:       make(map[string]*Session),
-	}, nil
+		locks:       make(map[string]*keyLock),
+	}
+}
+
+// Store returns the underlying persistence backend.
+func (m *Manager) Store() Store {
+	return m.store
 }
 
 // Get returns a session by key, creating one if it doesn't exist.
@@ -49,7 +71,7 @@ func (m *Manager) Get(key string) (*Session, error) {
 	}
 	m.mu.RUnlock()
 
-	s, err := m.loadFromDisk(key)
+	s, err := m.load(key)
 	if err != nil {
 		return nil, err
 	}
@@ -63,11 +85,11 @@ func (m *Manager) Get(key string) (*Session, error) {
 	return s, nil
 }
 
-// Reload forces loading session state from disk and refreshes cache.
+// Reload forces loading session state from the store and refreshes cache.
 func (m *Manager) Reload(key string) (*Session, error) {
 	key = normalizeSessionKey(key)
 
-	s, err := m.loadFromDisk(key)
+	s, err := m.load(key)
 	if err != nil {
 		return nil, err
 	}
@@ -77,66 +99,176 @@ func (m *Manager) Reload(key string) (*Session, error) {
 	return s, nil
 }
 
-// Save saves a session to disk.
+// Save persists a session.
 func (m *Manager) Save(s *Session) error {
 	s.Key = normalizeSessionKey(s.Key)
 	s.UpdatedAt = time.Now()
-	data, err := json.MarshalIndent(s, "", "  ")
+	return m.store.Save(s)
+}
+
+// Update reloads a session from the store, applies fn and saves the result
+// while holding that session's lock, so concurrent writers to one key never
+// drop each other's changes. Writers to different keys do not wait for each
+// other. Nothing is saved if fn returns an error. fn must not call Update,
+// Clear, Delete or Fork for the same key.
+func (m *Manager) Update(key string, fn func(*Session) error) (*Session, error) {
+	key = normalizeSessionKey(key)
+	unlock := m.lockKey(key)
+	defer unlock()
+
+	s, err := m.Reload(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(s); err != nil {
+		return nil, err
+	}
+	if err := m.Save(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// lockKey acquires the lock for one session key and returns its release
+// func. Entries are dropped once nobody holds or waits for them.
+func (m *Manager) lockKey(key string) func() {
+	m.locksMu.Lock()
+	l := m.locks[key]
+	if l == nil {
+		l = &keyLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.locksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.locksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.locksMu.Unlock()
+	}
+}
+
+// Delete removes a session from the cache and the store. Its token usage
+// totals are discarded with it.
+func (m *Manager) Delete(key string) error {
+	key = normalizeSessionKey(key)
+	unlock := m.lockKey(key)
+	defer unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cache, key)
+	return m.store.Delete(key)
+}
+
+// Clear empties a session's messages but keeps the session, including its
+// token usage totals.
+func (m *Manager) Clear(key string) error {
+	key = normalizeSessionKey(key)
+	unlock := m.lockKey(key)
+	defer unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.load(key)
 	if err != nil {
 		return err
 	}
-	path := m.sessionPath(s.Key)
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+	s.Messages = []provider.Message{}
+	s.UpdatedAt = time.Now()
+	if err := m.store.Save(s); err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	m.cache[key] = s
+	return nil
 }
 
-func (m *Manager) sessionPath(key string) string {
-	key = normalizeSessionKey(key)
-	parts := strings.Split(key, ":")
-	cleanParts := make([]string, 0, len(parts)+1)
-	for _, p := range parts {
-		segment := sanitizePathSegment(p)
-		if segment == "" {
-			continue
-		}
-		cleanParts = append(cleanParts, segment)
+// Fork copies the history of srcKey into a new, independent session at dstKey.
+// It fails if the source session does not exist or the destination does.
+func (m *Manager) Fork(srcKey, dstKey string) (*Session, error) {
+	srcKey = normalizeSessionKey(srcKey)
+	dstKey = normalizeSessionKey(dstKey)
+	if srcKey == dstKey {
+		return nil, fmt.Errorf("source and destination session keys are the same: %s", srcKey)
 	}
-	if len(cleanParts) == 0 {
-		cleanParts = append(cleanParts, "main")
+	if m.sessionPath(srcKey) == m.sessionPath(dstKey) {
+		return nil, fmt.Errorf("session keys %q and %q map to the same file", srcKey, dstKey)
 	}
-	cleanParts = append(cleanParts, "session.json")
-	return filepath.Join(append([]string{m.sessionsDir}, cleanParts...)...)
+	unlock := m.lockKey(dstKey)
+	defer unlock()
+
+	if _, found, err := m.store.Load(dstKey); err != nil {
+		return nil, err
+	} else if found {
+		return nil, fmt.Errorf("destination session already exists: %s", dstKey)
+	}
+
+	src, found, err := m.store.Load(srcKey)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("source session not found: %s", srcKey)
+	}
+
+	now := time.Now()
+	forked := &Session{
+		Key:       dstKey,
+		Messages:  copyMessages(src.Messages),
+		Workspace: src.Workspace,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.Save(forked); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.cache[dstKey] = forked
+	m.mu.Unlock()
+	return forked, nil
+}
+
+// List returns the keys of all persisted sessions.
+func (m *Manager) List() ([]string, error) {
+	return m.store.List()
+}
+
+func (m *Manager) sessionPath(key string) string {
+	return sessionFilePath(m.sessionsDir, key)
 }
 
-// PathForKey returns the on-disk session file path for a session key.
+// PathForKey returns the on-disk session file path for a session key, or ""
+// when sessions are not stored as files.
 func (m *Manager) PathForKey(key string) string {
-	return m.sessionPath(key)
+	fs, ok := m.store.(*FileStore)
+	if !ok {
+		return ""
+	}
+	return sessionFilePath(fs.sessionsDir, key)
 }
 
-func (m *Manager) loadFromDisk(key string) (*Session, error) {
+func (m *Manager) load(key string) (*Session, error) {
 	key = normalizeSessionKey(key)
 
-	path := m.sessionPath(key)
-	data, err := os.ReadFile(path)
+	s, found, err := m.store.Load(key)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			now := time.Now()
-			return &Session{
-				Key:       key,
-				Messages:  []provider.Message{},
-				CreatedAt: now,
-				UpdatedAt: now,
-			}, nil
-		}
 		return nil, err
 	}
-
-	var s Session
-	if err := json.Unmarshal(data, &s); err != nil {
-		return nil, err
+	if !found {
+		now := time.Now()
+		return &Session{
+			Key:       key,
+			Messages:  []provider.Message{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}, nil
 	}
+
 	if strings.TrimSpace(s.Key) == "" {
 		s.Key = key
 	}
@@ -149,7 +281,22 @@ func (m *Manager) loadFromDisk(key string) (*Session, error) {
 	if s.UpdatedAt.IsZero() {
 		s.UpdatedAt = s.CreatedAt
 	}
-	return &s, nil
+	return s, nil
+}
+
+// copyMessages deep-copies messages so the result shares no slices with the input.
+func copyMessages(messages []provider.Message) []provider.Message {
+	out := make([]provider.Message, len(messages))
+	for i, msg := range messages {
+		out[i] = msg
+		if msg.ToolCalls != nil {
+			out[i].ToolCalls = append([]provider.ToolCall(nil), msg.ToolCalls...)
+		}
+		if msg.Images != nil {
+			out[i].Images = append([]provider.ImagePart(nil), msg.Images...)
+		}
+	}
+	return out
 }
 
 func normalizeSessionKey(key string) string {
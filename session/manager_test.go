@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -87,3 +88,209 @@ func TestManagerGetUsesCache(t *testing.T) {
 		t.Fatalf("Get() should return cached pointer for same key")
 	}
 }
+
+func TestManagerForkCopiesHistoryIndependently(t *testing.T) {
+	sessionsDir := filepath.Join(t.TempDir(), "sessions")
+	mgr, err := NewManager(sessionsDir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	src := &Session{
+		Key: "chat:main",
+		Messages: []provider.Message{
+			provider.UserMessage("hello"),
+			provider.AssistantMessageWithTools("", "", []provider.ToolCall{{ID: "call-1", Type: "function"}}),
+		},
+	}
+	if err := mgr.Save(src); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	forked, err := mgr.Fork("chat:main", "chat:branch")
+	if err != nil {
+		t.Fatalf("Fork() error = %v", err)
+	}
+	if forked.Key != "chat:branch" || len(forked.Messages) != 2 {
+		t.Fatalf("Fork() = key %q with %d messages, want chat:branch with 2", forked.Key, len(forked.Messages))
+	}
+
+	forked.Messages[1].ToolCalls[0].ID = "mutated"
+	if src.Messages[1].ToolCalls[0].ID != "call-1" {
+		t.Fatalf("fork should deep-copy tool calls, source was mutated")
+	}
+
+	if _, err := os.Stat(mgr.PathForKey("chat:branch")); err != nil {
+		t.Fatalf("forked session file should exist: %v", err)
+	}
+	if _, err := mgr.Fork("chat:main", "chat:branch"); err == nil {
+		t.Fatalf("Fork() into existing session should fail")
+	}
+}
+
+func TestManagerForkRequiresExistingSource(t *testing.T) {
+	mgr, err := NewManager(filepath.Join(t.TempDir(), "sessions"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	if _, err := mgr.Fork("chat:typo", "chat:branch"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Fork() from a missing source error = %v, want not found", err)
+	}
+	keys, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("failed fork left sessions %v behind", keys)
+	}
+}
+
+func TestCopyMessagesIsolatesImages(t *testing.T) {
+	msg := provider.UserMessage("look")
+	msg.Images = []provider.ImagePart{{MimeType: "image/png", Data: "AAAA"}}
+	src := []provider.Message{msg}
+
+	out := copyMessages(src)
+	out[0].Images[0].Data = "mutated"
+	out[0].Images = append(out[0].Images, provider.ImagePart{URL: "https://example.com/x.png"})
+	if src[0].Images[0].Data != "AAAA" || len(src[0].Images) != 1 {
+		t.Fatalf("copyMessages() shares images with its input: %+v", src[0].Images)
+	}
+}
+
+func TestSQLiteStoreRoundTripAndList(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer store.Close()
+	mgr := NewManagerWithStore(t.TempDir(), store)
+
+	for _, key := range []string{"chat:a", "chat:b"} {
+		if err := mgr.Save(&Session{Key: key, Messages: []provider.Message{provider.UserMessage(key)}}); err != nil {
+			t.Fatalf("Save(%q) error = %v", key, err)
+		}
+	}
+	if err := mgr.Save(&Session{Key: "chat:a", Messages: []provider.Message{provider.UserMessage("updated")}}); err != nil {
+		t.Fatalf("Save() overwrite error = %v", err)
+	}
+
+	got, err := mgr.Reload("chat:a")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Content != "updated" {
+		t.Fatalf("Reload().Messages = %#v, want single updated message", got.Messages)
+	}
+
+	keys, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("List() = %v, want 2 keys", keys)
+	}
+	if _, found, err := store.Load("chat:missing"); err != nil || found {
+		t.Fatalf("Load(missing) = found %v, err %v; want not found", found, err)
+	}
+	if path := mgr.PathForKey("chat:a"); path != "" {
+		t.Fatalf("PathForKey() = %q, want empty for a non-file store", path)
+	}
+}
+
+func TestManagerClearAndDeleteKeepCacheAndStoreConsistent(t *testing.T) {
+	mgr, err := NewManager(filepath.Join(t.TempDir(), "sessions"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, err := mgr.Get("chat:1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	sess.Messages = []provider.Message{provider.UserMessage("a"), provider.AssistantMessage("b")}
+	sess.Usage = provider.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}
+	if err := mgr.Save(sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := mgr.Clear("chat:1"); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	cached, _ := mgr.Get("chat:1")
+	if len(cached.Messages) != 0 {
+		t.Fatalf("cached session has %d messages after Clear, want 0", len(cached.Messages))
+	}
+	stored, found, err := mgr.Store().Load("chat:1")
+	if err != nil || !found {
+		t.Fatalf("Store().Load() after Clear = found %v, err %v; want found", found, err)
+	}
+	if len(stored.Messages) != 0 {
+		t.Fatalf("stored session has %d messages after Clear, want 0", len(stored.Messages))
+	}
+	if stored.Usage.TotalTokens != 12 {
+		t.Fatalf("Clear() should keep usage totals, got %+v", stored.Usage)
+	}
+
+	if err := mgr.Delete("chat:1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(mgr.PathForKey("chat:1")); !os.IsNotExist(err) {
+		t.Fatalf("session file should be removed after Delete, stat err = %v", err)
+	}
+	fresh, err := mgr.Get("chat:1")
+	if err != nil {
+		t.Fatalf("Get() after Delete error = %v", err)
+	}
+	if len(fresh.Messages) != 0 || fresh.Usage.TotalTokens != 0 {
+		t.Fatalf("Get() after Delete = %+v, want a fresh session", fresh)
+	}
+	if err := mgr.Delete("chat:missing"); err != nil {
+		t.Fatalf("Delete() of missing session error = %v", err)
+	}
+}
+
+func TestManagerUpdateSerializesConcurrentWriters(t *testing.T) {
+	mgr, err := NewManager(filepath.Join(t.TempDir(), "sessions"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.Update("chat:1", func(s *Session) error {
+				s.Messages = append(s.Messages, provider.UserMessage(string(rune('a'+i))))
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, found, err := mgr.Store().Load("chat:1")
+	if err != nil || !found {
+		t.Fatalf("Store().Load() = found %v, err %v; want found", found, err)
+	}
+	if len(stored.Messages) != writers {
+		t.Fatalf("stored %d messages, want %d; concurrent updates were lost", len(stored.Messages), writers)
+	}
+	if len(mgr.locks) != 0 {
+		t.Fatalf("%d key locks left after all updates finished", len(mgr.locks))
+	}
+
+	if _, err := mgr.Update("chat:1", func(s *Session) error {
+		s.Messages = nil
+		return os.ErrInvalid
+	}); err != os.ErrInvalid {
+		t.Fatalf("Update() error = %v, want the callback's error", err)
+	}
+	if stored, _, _ := mgr.Store().Load("chat:1"); len(stored.Messages) != writers {
+		t.Fatalf("failed Update saved anyway: %d messages", len(stored.Messages))
+	}
+}
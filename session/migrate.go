@@ -0,0 +1,65 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MigrateDir moves session files from a legacy sessions directory into
+// sessionsDir, keeping their relative layout. Files already present at the
+// destination are left in place at the old location. Directories emptied by
+// the move are removed. It returns how many files were moved; a missing
+// legacyDir is not an error.
+func MigrateDir(legacyDir, sessionsDir string) (int, error) {
+	legacyDir, sessionsDir = filepath.Clean(legacyDir), filepath.Clean(sessionsDir)
+	if legacyDir == sessionsDir {
+		return 0, nil
+	}
+	if _, err := os.Stat(legacyDir); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	moved := 0
+	var dirs []string
+	err := filepath.WalkDir(legacyDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		if d.Name() != "session.json" {
+			return nil
+		}
+		rel, err := filepath.Rel(legacyDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(sessionsDir, rel)
+		if _, err := os.Stat(dst); err == nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, dst); err != nil {
+			return err
+		}
+		moved++
+		return nil
+	})
+	if err != nil {
+		return moved, err
+	}
+
+	// Deepest first, so parents are empty by the time they are reached.
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		_ = os.Remove(dir) // fails, harmlessly, while the directory still has files
+	}
+	return moved, nil
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateDirMovesLegacyFiles(t *testing.T) {
+	root := t.TempDir()
+	legacy := filepath.Join(root, "config", "sessions")
+	sessionsDir := filepath.Join(root, "workspace", "sessions")
+
+	write := func(dir, key, body string) {
+		t.Helper()
+		path := sessionFilePath(dir, key)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(legacy, "main", `{"key":"main"}`)
+	write(legacy, "telegram:42", `{"key":"telegram:42"}`)
+	write(legacy, "web:notes", `{"key":"old"}`)
+	write(sessionsDir, "web:notes", `{"key":"new"}`)
+
+	moved, err := MigrateDir(legacy, sessionsDir)
+	if err != nil {
+		t.Fatalf("MigrateDir() error = %v", err)
+	}
+	if moved != 2 {
+		t.Fatalf("MigrateDir() moved %d files, want 2", moved)
+	}
+
+	mgr, err := NewManager(sessionsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"main", "telegram:42"} {
+		if _, found, err := mgr.Store().Load(key); err != nil || !found {
+			t.Fatalf("Load(%q) after migration = found %v, err %v", key, found, err)
+		}
+		if _, err := os.Stat(sessionFilePath(legacy, key)); !os.IsNotExist(err) {
+			t.Fatalf("legacy file for %q still present (err %v)", key, err)
+		}
+	}
+
+	// An existing session is not overwritten; the legacy copy stays behind.
+	if data, _ := os.ReadFile(sessionFilePath(sessionsDir, "web:notes")); string(data) != `{"key":"new"}` {
+		t.Fatalf("existing session overwritten: %s", data)
+	}
+	if _, err := os.Stat(sessionFilePath(legacy, "web:notes")); err != nil {
+		t.Fatalf("conflicting legacy file removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "telegram")); !os.IsNotExist(err) {
+		t.Fatalf("emptied legacy directory not removed (err %v)", err)
+	}
+
+	if moved, err := MigrateDir(filepath.Join(root, "missing"), sessionsDir); err != nil || moved != 0 {
+		t.Fatalf("MigrateDir(missing) = %d, %v; want 0, nil", moved, err)
+	}
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	key        TEXT PRIMARY KEY,
+	data       TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_updated_at ON sessions(updated_at);
+`
+
+// SQLiteStore stores sessions as JSON rows in a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the database at path.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite store: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize sqlite schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load reads a session row.
+func (s *SQLiteStore) Load(key string) (*Session, bool, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM sessions WHERE key = ?`, normalizeSessionKey(key)).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var sess Session
+	if err := json.Unmarshal([]byte(data), &sess); err != nil {
+		return nil, false, err
+	}
+	return &sess, true, nil
+}
+
+// Save upserts a session row in a single statement.
+func (s *SQLiteStore) Save(sess *Session) error {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	createdAt := sess.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO sessions (key, data, created_at, updated_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		normalizeSessionKey(sess.Key), string(data), createdAt.Unix(), sess.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// List returns session keys, most recently updated first.
+func (s *SQLiteStore) List() ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM sessions ORDER BY updated_at DESC, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Delete removes a session row.
+func (s *SQLiteStore) Delete(key string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE key = ?`, normalizeSessionKey(key))
+	return err
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store persists sessions. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the stored session for key. found is false if none exists.
+	Load(key string) (s *Session, found bool, err error)
+	// Save writes the session atomically, replacing any previous version.
+	Save(s *Session) error
+	// List returns the keys of all stored sessions.
+	List() ([]string, error)
+	// Delete removes a stored session. Deleting a missing session is not an error.
+	Delete(key string) error
+	// Close releases backend resources.
+	Close() error
+}
+
+// FileStore stores each session as <sessionsDir>/<key segments>/session.json.
+type FileStore struct {
+	sessionsDir string
+}
+
+// NewFileStore creates a filesystem store rooted at sessionsDir.
+func NewFileStore(sessionsDir string) (*FileStore, error) {
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{sessionsDir: sessionsDir}, nil
+}
+
+// Load reads a session file.
+func (f *FileStore) Load(key string) (*Session, bool, error) {
+	data, err := os.ReadFile(sessionFilePath(f.sessionsDir, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, false, err
+	}
+	return &s, true, nil
+}
+
+// Save writes a session file via temp file + rename.
+func (f *FileStore) Save(s *Session) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(sessionFilePath(f.sessionsDir, s.Key), data)
+}
+
+// List walks the sessions directory and returns the key stored in each file.
+// Unreadable files and history backups are skipped.
+func (f *FileStore) List() ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(f.sessionsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "history" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "session.json" {
+			return nil
+		}
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil
+		}
+		var s Session
+		if json.Unmarshal(data, &s) != nil {
+			return nil
+		}
+		key := strings.TrimSpace(s.Key)
+		if key == "" {
+			rel, relErr := filepath.Rel(f.sessionsDir, filepath.Dir(path))
+			if relErr != nil {
+				return nil
+			}
+			key = strings.ReplaceAll(filepath.ToSlash(rel), "/", ":")
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes the session file.
+func (f *FileStore) Delete(key string) error {
+	if err := os.Remove(sessionFilePath(f.sessionsDir, key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Close is a no-op for the filesystem store.
+func (f *FileStore) Close() error { return nil }
+
+// FilePath returns where the file store keeps key under sessionsDir. Readers
+// outside the session package use it so their paths match the store's.
+func FilePath(sessionsDir, key string) string {
+	return sessionFilePath(sessionsDir, key)
+}
+
+func sessionFilePath(sessionsDir, key string) string {
+	key = normalizeSessionKey(key)
+	parts := strings.Split(key, ":")
+	cleanParts := make([]string, 0, len(parts)+1)
+	for _, p := range parts {
+		segment := sanitizePathSegment(p)
+		if segment == "" {
+			continue
+		}
+		cleanParts = append(cleanParts, segment)
+	}
+	if len(cleanParts) == 0 {
+		cleanParts = append(cleanParts, "main")
+	}
+	cleanParts = append(cleanParts, "session.json")
+	return filepath.Join(append([]string{sessionsDir}, cleanParts...)...)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames
+// it into place so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".session-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
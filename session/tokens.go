@@ -0,0 +1,128 @@
+package session
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/tiktoken-go/tokenizer"
+)
+
+var (
+	tiktokenOnce  sync.Once
+	tiktokenCodec tokenizer.Codec
+)
+
+func getCodec() tokenizer.Codec {
+	tiktokenOnce.Do(func() {
+		enc, err := tokenizer.Get(tokenizer.O200kBase)
+		if err != nil {
+			logger.Warn("failed to init tiktoken codec, token estimates will be zero", "err", err)
+			return
+		}
+		tiktokenCodec = enc
+	})
+	return tiktokenCodec
+}
+
+// TokenEstimator approximates how many tokens a model spends on text.
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
+// TiktokenEstimator counts tokens with the o200k_base encoding. It is the
+// default and a close proxy for most supported models.
+type TiktokenEstimator struct{}
+
+// EstimateTokens implements TokenEstimator.
+func (TiktokenEstimator) EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	codec := getCodec()
+	if codec == nil {
+		return len(text) / 3 // rough fallback
+	}
+	ids, _, _ := codec.Encode(text)
+	return len(ids)
+}
+
+// CJKAwareEstimator is for tokenizers that spend about one token per CJK
+// character, where o200k_base undercounts. It returns the larger of the
+// tiktoken count and a byte-based estimate: one token per CJK rune plus one
+// per four bytes of everything else.
+type CJKAwareEstimator struct{}
+
+// EstimateTokens implements TokenEstimator.
+func (CJKAwareEstimator) EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	cjk, otherBytes := 0, 0
+	for _, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			otherBytes += utf8.RuneLen(r)
+		}
+	}
+	return max(TiktokenEstimator{}.EstimateTokens(text), cjk+(otherBytes+3)/4)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF) // CJK punctuation, full-width forms
+}
+
+// TokenEstimatorForModel picks the estimator for a model type or name. Claude
+// models use CJKAwareEstimator; everything else uses TiktokenEstimator.
+func TokenEstimatorForModel(model string) TokenEstimator {
+	if strings.Contains(strings.ToLower(model), "claude") {
+		return CJKAwareEstimator{}
+	}
+	return TiktokenEstimator{}
+}
+
+// EstimateMessageTokens approximates the prompt tokens one message consumes
+// using the default estimator.
+func EstimateMessageTokens(message provider.Message) int {
+	return EstimateMessageTokensWith(TiktokenEstimator{}, message)
+}
+
+// EstimateMessagesTokens approximates the prompt tokens of a message list
+// using the default estimator.
+func EstimateMessagesTokens(messages []provider.Message) int {
+	return EstimateMessagesTokensWith(TiktokenEstimator{}, messages)
+}
+
+// EstimateMessageTokensWith approximates the prompt tokens one message consumes.
+func EstimateMessageTokensWith(e TokenEstimator, message provider.Message) int {
+	tokens := 6 // Base per-message structure overhead.
+	tokens += e.EstimateTokens(message.Role)
+	tokens += e.EstimateTokens(message.Content)
+	tokens += e.EstimateTokens(message.ReasoningContent)
+	tokens += e.EstimateTokens(message.ToolCallID)
+	tokens += e.EstimateTokens(message.Name)
+
+	for _, call := range message.ToolCalls {
+		tokens += 8 // Tool call structure overhead.
+		tokens += e.EstimateTokens(call.ID)
+		tokens += e.EstimateTokens(call.Type)
+		tokens += e.EstimateTokens(call.Function.Name)
+		tokens += e.EstimateTokens(call.Function.Arguments)
+	}
+
+	return tokens
+}
+
+// EstimateMessagesTokensWith approximates the prompt tokens of a message list.
+func EstimateMessagesTokensWith(e TokenEstimator, messages []provider.Message) int {
+	total := 3 // Priming overhead.
+	for _, message := range messages {
+		total += EstimateMessageTokensWith(e, message)
+	}
+	return total
+}
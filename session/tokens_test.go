@@ -0,0 +1,52 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+const (
+	englishParagraph = "The quick brown fox jumps over the lazy dog. Context windows are measured in tokens, " +
+		"so a good estimate keeps long conversations from overflowing the model limit before compaction runs."
+	cjkParagraph = "今天天气很好，我们一起去公园散步吧。上下文窗口按照词元计算，所以准确的估算可以避免长对话超出模型的限制。"
+	codeBlock    = "func add(a, b int) int {\n\tif a < 0 {\n\t\treturn b\n\t}\n\treturn a + b\n}\n"
+)
+
+func TestTokenEstimatorRanges(t *testing.T) {
+	cjkRunes := len([]rune(cjkParagraph))
+	cases := []struct {
+		name     string
+		e        TokenEstimator
+		text     string
+		min, max int
+	}{
+		{"tiktoken english", TiktokenEstimator{}, englishParagraph, 30, 50},
+		{"tiktoken code", TiktokenEstimator{}, codeBlock, 20, 45},
+		{"tiktoken cjk", TiktokenEstimator{}, cjkParagraph, cjkRunes / 3, cjkRunes},
+		{"cjk-aware english", CJKAwareEstimator{}, englishParagraph, 30, 60},
+		{"cjk-aware code", CJKAwareEstimator{}, codeBlock, 20, 45},
+		{"cjk-aware cjk", CJKAwareEstimator{}, cjkParagraph, cjkRunes, cjkRunes + 10},
+	}
+	for _, tc := range cases {
+		if got := tc.e.EstimateTokens(tc.text); got < tc.min || got > tc.max {
+			t.Errorf("%s: estimate %d not in [%d, %d]", tc.name, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestTokenEstimatorForModel(t *testing.T) {
+	if _, ok := TokenEstimatorForModel("claude-sonnet-4-5").(CJKAwareEstimator); !ok {
+		t.Fatal("claude models should use the CJK-aware estimator")
+	}
+	if _, ok := TokenEstimatorForModel("kimi-k2.5").(TiktokenEstimator); !ok {
+		t.Fatal("other models should use the tiktoken estimator")
+	}
+
+	messages := []provider.Message{provider.UserMessage(strings.Repeat(cjkParagraph, 4))}
+	claude := EstimateMessagesTokensWith(TokenEstimatorForModel("claude-opus-4-6"), messages)
+	if base := EstimateMessagesTokens(messages); claude <= base {
+		t.Fatalf("claude estimate %d should exceed default %d for CJK text", claude, base)
+	}
+}
@@ -0,0 +1,81 @@
+package session
+
+import "github.com/linanwx/nagobot/provider"
+
+// TrimToTokenBudget drops the oldest messages until the estimated tokens of
+// the remainder fit within budget, using the default estimator. An assistant
+// message with tool calls is dropped together with the tool results that
+// follow it, so the result never starts with an orphaned tool result. The
+// input slice is not modified.
+func TrimToTokenBudget(messages []provider.Message, budget int) []provider.Message {
+	return TrimToTokenBudgetWith(TiktokenEstimator{}, messages, budget)
+}
+
+// TrimToTokenBudgetWith is TrimToTokenBudget counting tokens with e.
+func TrimToTokenBudgetWith(e TokenEstimator, messages []provider.Message, budget int) []provider.Message {
+	if budget <= 0 {
+		return messages
+	}
+
+	total := EstimateMessagesTokensWith(e, messages)
+	start := 0
+	for start < len(messages) && total > budget {
+		end := toolUnitEnd(messages, start)
+		for _, m := range messages[start:end] {
+			total -= EstimateMessageTokensWith(e, m)
+		}
+		start = end
+	}
+	return RepairToolPairs(messages[start:])
+}
+
+// TrimMessages keeps at most max of the most recent messages. If the cut
+// lands inside a tool-call group, the partial group's results are dropped as
+// well so no tool result outlives its assistant tool call.
+func TrimMessages(messages []provider.Message, max int) []provider.Message {
+	if max <= 0 || len(messages) <= max {
+		return RepairToolPairs(messages)
+	}
+	return RepairToolPairs(messages[len(messages)-max:])
+}
+
+// RepairToolPairs drops tool results whose tool_call_id does not belong to an
+// earlier assistant tool call in messages, such as results left at the head
+// of a history whose originating call was trimmed. Providers reject requests
+// containing such orphans. messages is returned as-is when nothing is dropped.
+func RepairToolPairs(messages []provider.Message) []provider.Message {
+	calls := make(map[string]bool)
+	var repaired []provider.Message
+	for i, m := range messages {
+		if m.Role == "assistant" {
+			for _, tc := range m.ToolCalls {
+				calls[tc.ID] = true
+			}
+		}
+		orphan := m.Role == "tool" && !calls[m.ToolCallID]
+		if orphan && repaired == nil {
+			repaired = append(make([]provider.Message, 0, len(messages)-1), messages[:i]...)
+		}
+		if repaired != nil && !orphan {
+			repaired = append(repaired, m)
+		}
+	}
+	if repaired == nil {
+		return messages
+	}
+	return repaired
+}
+
+// toolUnitEnd returns the end (exclusive) of the unit starting at i: an
+// assistant tool-call message plus its consecutive tool results, or just
+// messages[i] otherwise.
+func toolUnitEnd(messages []provider.Message, i int) int {
+	end := i + 1
+	if messages[i].Role != "assistant" || len(messages[i].ToolCalls) == 0 {
+		return end
+	}
+	for end < len(messages) && messages[end].Role == "tool" {
+		end++
+	}
+	return end
+}
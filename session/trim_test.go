@@ -0,0 +1,140 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+func toolCallMessage(id string) provider.Message {
+	return provider.AssistantMessageWithTools("", "", []provider.ToolCall{{
+		ID: id, Type: "function", Function: provider.FunctionCall{Name: "read_file", Arguments: "{}"},
+	}})
+}
+
+func TestTrimToTokenBudgetKeepsToolPairs(t *testing.T) {
+	huge := strings.Repeat("lorem ipsum dolor sit amet ", 400)
+	messages := []provider.Message{
+		provider.UserMessage("read both files"),
+		toolCallMessage("call_1"),
+		provider.ToolResultMessage("call_1", "read_file", huge),
+		toolCallMessage("call_2"),
+		provider.ToolResultMessage("call_2", "read_file", huge),
+		provider.AssistantMessage("done"),
+		provider.UserMessage("thanks"),
+		provider.AssistantMessage("you're welcome"),
+	}
+	tail := EstimateMessagesTokens(messages[3:])
+
+	trimmed := TrimToTokenBudget(messages, tail)
+	if len(trimmed) != 5 || trimmed[0].Role != "assistant" || trimmed[0].ToolCalls[0].ID != "call_2" {
+		t.Fatalf("trimmed = %d messages starting with %+v, want the call_2 pair onward", len(trimmed), trimmed[0])
+	}
+	if est := EstimateMessagesTokens(trimmed); est > tail {
+		t.Fatalf("estimate %d exceeds budget %d", est, tail)
+	}
+
+	// A budget too small for the call_2 result must drop the whole pair.
+	trimmed = TrimToTokenBudget(messages, tail-1)
+	if trimmed[0].Role == "tool" {
+		t.Fatalf("trimmed starts with orphaned tool result %+v", trimmed[0])
+	}
+	if trimmed[0].Content != "done" {
+		t.Fatalf("trimmed[0] = %+v, want the message after the dropped pair", trimmed[0])
+	}
+}
+
+func TestTrimToTokenBudgetUnderBudget(t *testing.T) {
+	messages := []provider.Message{provider.UserMessage("hi"), provider.AssistantMessage("hello")}
+	if got := TrimToTokenBudget(messages, 10000); len(got) != 2 {
+		t.Fatalf("TrimToTokenBudget() dropped messages under budget: %+v", got)
+	}
+	if got := TrimToTokenBudget(messages, 0); len(got) != 2 {
+		t.Fatalf("zero budget should disable trimming, got %+v", got)
+	}
+}
+
+func TestTrimToTokenBudgetDropsLeadingOrphans(t *testing.T) {
+	messages := []provider.Message{
+		provider.ToolResultMessage("gone", "read_file", "stale"),
+		provider.UserMessage("hi"),
+	}
+	got := TrimToTokenBudget(messages, 1)
+	for _, m := range got {
+		if m.Role == "tool" {
+			t.Fatalf("orphaned tool result kept: %+v", got)
+		}
+	}
+}
+
+func TestTrimMessagesRepairsOrphanedToolResults(t *testing.T) {
+	messages := []provider.Message{
+		provider.UserMessage("list files"),
+		provider.AssistantMessageWithTools("", "", []provider.ToolCall{
+			{ID: "call_a", Type: "function", Function: provider.FunctionCall{Name: "list_dir", Arguments: "{}"}},
+			{ID: "call_b", Type: "function", Function: provider.FunctionCall{Name: "read_file", Arguments: "{}"}},
+		}),
+		provider.ToolResultMessage("call_a", "list_dir", "a.txt"),
+		provider.ToolResultMessage("call_b", "read_file", "hello"),
+		provider.AssistantMessage("a.txt says hello"),
+		provider.UserMessage("thanks"),
+	}
+
+	// A naive messages[len-4:] starts at the call_b result, whose call was cut.
+	naive := messages[len(messages)-4:]
+	if naive[0].Role != "tool" {
+		t.Fatalf("test setup: naive slice starts with %+v, want a tool result", naive[0])
+	}
+
+	got := TrimMessages(messages, 4)
+	if len(got) != 2 || got[0].Content != "a.txt says hello" || got[1].Content != "thanks" {
+		t.Fatalf("TrimMessages() = %+v, want the partial tool group dropped", got)
+	}
+
+	// With room for the assistant call, the whole group is kept intact.
+	got = TrimMessages(messages, 5)
+	if len(got) != 5 || len(got[0].ToolCalls) != 2 || got[1].ToolCallID != "call_a" || got[2].ToolCallID != "call_b" {
+		t.Fatalf("TrimMessages() = %+v, want the call kept with both results", got)
+	}
+}
+
+func TestRepairToolPairsDropsUnknownResults(t *testing.T) {
+	messages := []provider.Message{
+		provider.ToolResultMessage("call_x", "read_file", "stale"),
+		provider.UserMessage("hi"),
+		toolCallMessage("call_1"),
+		provider.ToolResultMessage("call_1", "read_file", "ok"),
+		provider.ToolResultMessage("call_y", "read_file", "stray"),
+	}
+	got := RepairToolPairs(messages)
+	if len(got) != 3 || got[0].Role != "user" || got[2].ToolCallID != "call_1" {
+		t.Fatalf("RepairToolPairs() = %+v", got)
+	}
+	if len(messages) != 5 || messages[0].ToolCallID != "call_x" {
+		t.Fatal("RepairToolPairs() modified its input")
+	}
+
+	clean := messages[1:4]
+	if got := RepairToolPairs(clean); &got[0] != &clean[0] {
+		t.Fatal("RepairToolPairs() copied a history with nothing to repair")
+	}
+}
+
+func TestTrimToTokenBudgetWithCountsByEstimator(t *testing.T) {
+	cjk := strings.Repeat("你好世界", 200)
+	messages := []provider.Message{
+		provider.UserMessage(cjk),
+		provider.AssistantMessage("ok"),
+		provider.UserMessage("next"),
+	}
+	budget := EstimateMessagesTokens(messages)
+
+	if got := TrimToTokenBudget(messages, budget); len(got) != 3 {
+		t.Fatalf("TrimToTokenBudget() = %d messages, want all kept under the tiktoken count", len(got))
+	}
+	got := TrimToTokenBudgetWith(CJKAwareEstimator{}, messages, budget)
+	if len(got) != 2 || got[0].Content != "ok" {
+		t.Fatalf("TrimToTokenBudgetWith(CJK) = %+v, want the CJK message dropped", got)
+	}
+}
@@ -0,0 +1,64 @@
+package skills
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Param declares a named argument a skill prompt accepts as a {{name}}
+// placeholder, e.g. in frontmatter:
+//
+//	params:
+//	  - name: lang
+//	    description: Target language
+//	    required: true
+type Param struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+}
+
+// RenderSkillPrompt returns the named skill's prompt with {{key}} placeholders
+// replaced from args, falling back to declared defaults. It fails when a
+// required param has no value; placeholders without a value are left as is.
+func (r *Registry) RenderSkillPrompt(name string, args map[string]string) (string, error) {
+	s, ok := r.Get(name)
+	if !ok {
+		return "", fmt.Errorf("skill %q not found", name)
+	}
+
+	values := make(map[string]string, len(args)+len(s.Params))
+	var missing []string
+	for _, p := range s.Params {
+		if v, ok := args[p.Name]; ok && strings.TrimSpace(v) != "" {
+			continue
+		}
+		if p.Default != "" {
+			values[p.Name] = p.Default
+		} else if p.Required {
+			missing = append(missing, describeParam(p))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing required params for skill %q: %s", name, strings.Join(missing, "; "))
+	}
+	for k, v := range args {
+		if strings.TrimSpace(v) != "" {
+			values[k] = v
+		}
+	}
+
+	prompt := s.Prompt
+	for k, v := range values {
+		prompt = strings.ReplaceAll(prompt, "{{"+k+"}}", v)
+	}
+	return prompt, nil
+}
+
+func describeParam(p Param) string {
+	if p.Description == "" {
+		return p.Name
+	}
+	return p.Name + " (" + p.Description + ")"
+}
@@ -0,0 +1,45 @@
+package skills
+
+import (
+	"strings"
+	"testing"
+)
+
+func newParamsRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(&Skill{
+		Name:   "translate",
+		Prompt: "Translate the text to {{lang}} in a {{tone}} tone. Keep {{glossary}} terms.",
+		Params: []Param{
+			{Name: "lang", Description: "Target language", Required: true},
+			{Name: "tone", Default: "neutral"},
+			{Name: "glossary"},
+		},
+	})
+	return r
+}
+
+func TestRenderSkillPromptSubstitutesArgs(t *testing.T) {
+	r := newParamsRegistry()
+	got, err := r.RenderSkillPrompt("translate", map[string]string{"lang": "French", "tone": "formal"})
+	if err != nil {
+		t.Fatalf("RenderSkillPrompt() error = %v", err)
+	}
+	// Unfilled optional params stay as placeholders.
+	if want := "Translate the text to French in a formal tone. Keep {{glossary}} terms."; got != want {
+		t.Fatalf("RenderSkillPrompt() = %q, want %q", got, want)
+	}
+
+	got, err = r.RenderSkillPrompt("translate", map[string]string{"lang": "German"})
+	if err != nil || !strings.Contains(got, "in a neutral tone") {
+		t.Fatalf("RenderSkillPrompt() = %q, %v; want default tone", got, err)
+	}
+}
+
+func TestRenderSkillPromptRequiresParams(t *testing.T) {
+	r := newParamsRegistry()
+	_, err := r.RenderSkillPrompt("translate", map[string]string{"tone": "casual"})
+	if err == nil || !strings.Contains(err.Error(), "lang (Target language)") {
+		t.Fatalf("RenderSkillPrompt() error = %v, want missing lang", err)
+	}
+}
@@ -0,0 +1,73 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Requirements lists what a skill needs from the host, declared in
+// frontmatter as:
+//
+//	requires:
+//	  commands: [ffmpeg]
+//	  env: [OPENAI_API_KEY]
+//	  files: [~/.config/tool.json]
+//
+// Relative file paths are resolved against the skill directory.
+type Requirements struct {
+	Commands []string `yaml:"commands,omitempty"`
+	Env      []string `yaml:"env,omitempty"`
+	Files    []string `yaml:"files,omitempty"`
+}
+
+// CheckRequirements reports the requirements of the named skill that the host
+// does not meet, one human-readable entry each (e.g. "command: ffmpeg"). An
+// empty result means the skill can be used.
+func (r *Registry) CheckRequirements(name string) ([]string, error) {
+	s, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("skill %q not found", name)
+	}
+
+	var missing []string
+	for _, cmd := range s.Requires.Commands {
+		if cmd = strings.TrimSpace(cmd); cmd == "" {
+			continue
+		}
+		if _, err := exec.LookPath(cmd); err != nil {
+			missing = append(missing, "command: "+cmd)
+		}
+	}
+	for _, key := range s.Requires.Env {
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+		if strings.TrimSpace(os.Getenv(key)) == "" {
+			missing = append(missing, "env: "+key)
+		}
+	}
+	for _, file := range s.Requires.Files {
+		if file = strings.TrimSpace(file); file == "" {
+			continue
+		}
+		if _, err := os.Stat(requirementPath(file, s.Dir)); err != nil {
+			missing = append(missing, "file: "+file)
+		}
+	}
+	return missing, nil
+}
+
+func requirementPath(path, dir string) string {
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	if filepath.IsAbs(path) || dir == "" {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,104 @@
+package skills
+
+import (
+	"sort"
+	"strings"
+)
+
+// Search scores for a single query term, highest first.
+const (
+	scoreNameExact    = 100
+	scoreNamePrefix   = 60
+	scoreNameContains = 40
+	scoreTagExact     = 30
+	scoreTagContains  = 15
+	scoreDescContains = 10
+)
+
+// FindByTag returns the skills carrying tag, compared case-insensitively,
+// sorted by name.
+func (r *Registry) FindByTag(tag string) []*Skill {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return nil
+	}
+	var found []*Skill
+	for _, s := range r.List() {
+		for _, t := range s.Tags {
+			if strings.ToLower(strings.TrimSpace(t)) == tag {
+				found = append(found, s)
+				break
+			}
+		}
+	}
+	sortByName(found)
+	return found
+}
+
+// Search returns skills whose name, description or tags match any word of
+// query, case-insensitively. Results are ranked so name matches come before
+// tag matches, which come before description matches; ties sort by name.
+func (r *Registry) Search(query string) []*Skill {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		skill *Skill
+		score int
+	}
+	var hits []hit
+	for _, s := range r.List() {
+		score := 0
+		for _, term := range terms {
+			score += termScore(s, term)
+		}
+		if score > 0 {
+			hits = append(hits, hit{skill: s, score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].skill.Name < hits[j].skill.Name
+	})
+
+	found := make([]*Skill, len(hits))
+	for i, h := range hits {
+		found[i] = h.skill
+	}
+	return found
+}
+
+// termScore returns the best score of term against s.
+func termScore(s *Skill, term string) int {
+	name := strings.ToLower(s.Name)
+	switch {
+	case name == term:
+		return scoreNameExact
+	case strings.HasPrefix(name, term):
+		return scoreNamePrefix
+	case strings.Contains(name, term):
+		return scoreNameContains
+	}
+	best := 0
+	for _, t := range s.Tags {
+		t = strings.ToLower(t)
+		if t == term {
+			return scoreTagExact
+		}
+		if strings.Contains(t, term) {
+			best = scoreTagContains
+		}
+	}
+	if best == 0 && strings.Contains(strings.ToLower(s.Description), term) {
+		best = scoreDescContains
+	}
+	return best
+}
+
+func sortByName(list []*Skill) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+}
@@ -0,0 +1,71 @@
+package skills
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newSearchRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(&Skill{Name: "weather", Description: "Forecasts for a city", Tags: []string{"web"}})
+	r.Register(&Skill{Name: "web-research", Description: "Search and summarize pages", Tags: []string{"Web", "research"}})
+	r.Register(&Skill{Name: "manage-cron", Description: "Schedule recurring tasks", Tags: []string{"ops"}})
+	r.Register(&Skill{Name: "notes", Description: "Keep notes found on the web"})
+	return r
+}
+
+func names(list []*Skill) string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = s.Name
+	}
+	return strings.Join(out, ",")
+}
+
+func TestFindByTagIsCaseInsensitive(t *testing.T) {
+	r := newSearchRegistry()
+	if got := names(r.FindByTag("WEB")); got != "weather,web-research" {
+		t.Fatalf("FindByTag(WEB) = %s", got)
+	}
+	if got := r.FindByTag("missing"); len(got) != 0 {
+		t.Fatalf("FindByTag(missing) = %s, want none", names(got))
+	}
+}
+
+func TestSearchRanksNameThenTagThenDescription(t *testing.T) {
+	r := newSearchRegistry()
+	// web-research matches by name, weather by tag, notes by description.
+	if got := names(r.Search("Web")); got != "web-research,weather,notes" {
+		t.Fatalf("Search(Web) = %s", got)
+	}
+	if got := names(r.Search("cron")); got != "manage-cron" {
+		t.Fatalf("Search(cron) = %s", got)
+	}
+	// Each matching word adds to the score.
+	if got := names(r.Search("schedule ops")); got != "manage-cron" {
+		t.Fatalf("Search(schedule ops) = %s", got)
+	}
+	if got := r.Search("  "); len(got) != 0 {
+		t.Fatalf("Search(blank) = %s, want none", names(got))
+	}
+}
+
+func TestBuildPromptSectionGroupsLargeRegistries(t *testing.T) {
+	r := NewRegistry()
+	for i := 0; i <= promptGroupThreshold; i++ {
+		tags := []string{"ops"}
+		if i%2 == 0 {
+			tags = nil
+		}
+		r.Register(&Skill{Name: fmt.Sprintf("skill-%02d", i), Tags: tags})
+	}
+	section := r.BuildPromptSection()
+	ops, other := strings.Index(section, "### ops"), strings.Index(section, "### other")
+	if ops < 0 || other < ops {
+		t.Fatalf("section not grouped with untagged skills last:\n%s", section)
+	}
+	if !strings.Contains(section[ops:other], "skill-01") || !strings.Contains(section[other:], "skill-00") {
+		t.Fatalf("skills in the wrong group:\n%s", section)
+	}
+}
@@ -6,26 +6,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Skill represents a skill definition.
 type Skill struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	Prompt      string   `yaml:"prompt"`
-	Tags        []string `yaml:"tags,omitempty"`
-	Examples    []string `yaml:"examples,omitempty"`
-	Dir         string   `yaml:"-"` // Absolute path to skill directory (if directory-based).
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description"`
+	Prompt      string       `yaml:"prompt"`
+	Tags        []string     `yaml:"tags,omitempty"`
+	Examples    []string     `yaml:"examples,omitempty"`
+	Requires    Requirements `yaml:"requires,omitempty"`
+	Params      []Param      `yaml:"params,omitempty"`
+	Dir         string       `yaml:"-"` // Absolute path to skill directory (if directory-based).
 }
 
 // Registry holds loaded skills.
 type Registry struct {
-	skills map[string]*Skill
-	mu     sync.RWMutex
+	skills     map[string]*Skill
+	watchedDir string       // directory kept current by Watch, if any
+	reloads    atomic.Int64 // directory reloads, for tests
+	mu         sync.RWMutex
 }
 
 // NewRegistry creates a new skill registry.
@@ -80,6 +86,7 @@ func (r *Registry) LoadFromDirectory(dir string) error {
 
 // ReloadFromDirectory replaces current skills with the latest files from dir.
 func (r *Registry) ReloadFromDirectory(dir string) error {
+	r.reloads.Add(1)
 	loaded, err := loadSkillsFromDirectory(dir)
 	if err != nil {
 		return err
@@ -218,27 +225,71 @@ func loadMarkdownSkill(path string) (*Skill, error) {
 	return &skill, nil
 }
 
+// promptGroupThreshold is the skill count above which BuildPromptSection
+// groups skills by their first tag instead of listing them flat.
+const promptGroupThreshold = 12
+
 // BuildPromptSection builds a compact skill summary for the system prompt.
-// Full skill prompts are loaded on demand via the use_skill tool.
+// Full skill prompts are loaded on demand via the use_skill tool. Large
+// registries are grouped by each skill's first tag.
 func (r *Registry) BuildPromptSection() string {
 	list := r.List()
 	if len(list) == 0 {
 		return ""
 	}
+	sortByName(list)
 
 	var sb strings.Builder
 	sb.WriteString("## Skills\n\n")
 	sb.WriteString("Available skills (use the `use_skill` tool to load full instructions):\n\n")
 
+	if len(list) <= promptGroupThreshold {
+		writeSkillLines(&sb, list)
+		return sb.String()
+	}
+
+	groups := make(map[string][]*Skill)
+	var order []string
+	for _, s := range list {
+		group := "other"
+		if len(s.Tags) > 0 && strings.TrimSpace(s.Tags[0]) != "" {
+			group = strings.ToLower(strings.TrimSpace(s.Tags[0]))
+		}
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], s)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		// Untagged skills go last.
+		if (order[i] == "other") != (order[j] == "other") {
+			return order[j] == "other"
+		}
+		return order[i] < order[j]
+	})
+	for _, group := range order {
+		sb.WriteString(fmt.Sprintf("### %s\n", group))
+		writeSkillLines(&sb, groups[group])
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func writeSkillLines(sb *strings.Builder, list []*Skill) {
 	for _, s := range list {
 		sb.WriteString(fmt.Sprintf("- **%s**", s.Name))
 		if s.Description != "" {
 			sb.WriteString(fmt.Sprintf(": %s", s.Description))
 		}
+		if len(s.Params) > 0 {
+			params := make([]string, len(s.Params))
+			for i, p := range s.Params {
+				params[i] = p.Name
+			}
+			sb.WriteString(fmt.Sprintf(" (args: %s)", strings.Join(params, ", ")))
+		}
 		sb.WriteString("\n")
 	}
-
-	return sb.String()
 }
 
 // SkillNames returns the names of all registered skills.
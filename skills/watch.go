@@ -0,0 +1,45 @@
+package skills
+
+import (
+	"path/filepath"
+
+	"github.com/linanwx/nagobot/internal/watch"
+	"github.com/linanwx/nagobot/logger"
+)
+
+// Watch keeps the registry in sync with dir, reloading after file changes
+// settle. Load dir before calling it. While the watch runs, Refresh(dir) does
+// not touch the disk. The returned function stops watching.
+func (r *Registry) Watch(dir string) (func(), error) {
+	stop, err := watch.Dir(dir, watch.DefaultDebounce, func() {
+		if err := r.ReloadFromDirectory(dir); err != nil {
+			logger.Warn("failed to reload skills", "dir", dir, "err", err)
+			return
+		}
+		logger.Info("skills reloaded", "dir", dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.watchedDir = filepath.Clean(dir)
+	r.mu.Unlock()
+	return func() {
+		stop()
+		r.mu.Lock()
+		r.watchedDir = ""
+		r.mu.Unlock()
+	}, nil
+}
+
+// Refresh reloads dir unless a watcher already keeps the registry current.
+func (r *Registry) Refresh(dir string) error {
+	r.mu.RLock()
+	watched := r.watchedDir != "" && r.watchedDir == filepath.Clean(dir)
+	r.mu.RUnlock()
+	if watched {
+		return nil
+	}
+	return r.ReloadFromDirectory(dir)
+}
@@ -0,0 +1,75 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchReloadsOnNewSkill(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry()
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	stop, err := r.Watch(dir)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer stop()
+
+	skillDir := filepath.Join(dir, "translate")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Give the watcher a moment to add the new subdirectory before writing into it.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: translate\n---\nTranslate."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := r.Get("translate"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new skill was not picked up by the watcher")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRefreshSkipsDiskWhileWatching(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry()
+
+	if err := r.Refresh(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.reloads.Load(); got != 1 {
+		t.Fatalf("reloads without watcher = %d, want 1", got)
+	}
+
+	stop, err := r.Watch(dir)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := r.Refresh(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.reloads.Load(); got != 1 {
+		t.Fatalf("reloads while watching = %d, want 1", got)
+	}
+
+	stop()
+	if err := r.Refresh(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.reloads.Load(); got != 2 {
+		t.Fatalf("reloads after stop = %d, want 2", got)
+	}
+}
@@ -0,0 +1,79 @@
+package tgmd
+
+import "strings"
+
+// markdownV2Reserved are the characters Telegram's MarkdownV2 requires to be
+// escaped outside code entities.
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!\\"
+
+// EscapeMarkdownV2 escapes text for Telegram's MarkdownV2 parse mode so it
+// renders literally. Fenced code blocks and inline code spans are kept as
+// code; inside them only '`' and '\' are escaped, as the Bot API requires.
+func EscapeMarkdownV2(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + len(s)/8)
+
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, "```"):
+			end := strings.Index(s[3:], "```")
+			if end < 0 {
+				b.WriteString(escapeMarkdownV2Text(s))
+				return b.String()
+			}
+			body := s[3 : 3+end]
+			lang := ""
+			if nl := strings.IndexByte(body, '\n'); nl >= 0 && !strings.ContainsAny(body[:nl], " \t`") {
+				lang, body = body[:nl+1], body[nl+1:]
+			}
+			b.WriteString("```")
+			b.WriteString(lang)
+			b.WriteString(escapeMarkdownV2Code(body))
+			b.WriteString("```")
+			s = s[3+end+3:]
+		case s[0] == '`':
+			end := strings.IndexAny(s[1:], "`\n")
+			if end < 0 || s[1+end] != '`' || end == 0 {
+				b.WriteString("\\`")
+				s = s[1:]
+				continue
+			}
+			b.WriteByte('`')
+			b.WriteString(escapeMarkdownV2Code(s[1 : 1+end]))
+			b.WriteByte('`')
+			s = s[1+end+1:]
+		default:
+			next := strings.IndexByte(s, '`')
+			if next < 0 {
+				next = len(s)
+			}
+			b.WriteString(escapeMarkdownV2Text(s[:next]))
+			s = s[next:]
+		}
+	}
+	return b.String()
+}
+
+func escapeMarkdownV2Text(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if strings.ContainsRune(markdownV2Reserved, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func escapeMarkdownV2Code(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r == '`' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -9,6 +9,9 @@
 //   - Tables become readable list blocks
 //   - Images become links
 //   - Horizontal rules become a line of em-dashes
+//
+// EscapeMarkdownV2 covers the alternative MarkdownV2 parse mode, where text
+// is sent literally apart from code spans and blocks.
 package tgmd
 
 import (
@@ -243,3 +243,25 @@ func expect(t *testing.T, got, want string) {
 		t.Errorf("\n got: %q\nwant: %q", got, want)
 	}
 }
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"snake_case", `snake\_case`},
+		{"2 * 3 = 6", `2 \* 3 \= 6`},
+		{"[link](url)", `\[link\]\(url\)`},
+		{"a.b!c", `a\.b\!c`},
+		{"path\\to", `path\\to`},
+		{"call `do_it(*x)` now", "call `do_it(*x)` now"},
+		{"lone ` tick", "lone \\` tick"},
+		{"```go\nfmt.Println(`x`)\n```", "```go\nfmt.Println(\\`x\\`)\n```"},
+		{"```\na_b\n```", "```\na_b\n```"},
+		{"unclosed ```fence_", "unclosed \\`\\`\\`fence\\_"},
+	}
+	for _, tc := range cases {
+		if got := EscapeMarkdownV2(tc.in); got != tc.want {
+			t.Errorf("EscapeMarkdownV2(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -6,56 +6,215 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/tools"
 )
 
-// SpawnChild spawns a child thread for delegated work. Always asynchronous:
-// returns child ID immediately, and the child wakes the parent via
-// "child_completed" when done.
-func (t *Thread) SpawnChild(ctx context.Context, agentName string, task string) (string, error) {
+// SpawnChild spawns a child thread for delegated work and returns its ID.
+// By default it is asynchronous: the child wakes the parent via
+// "child_completed" when done. With opts.Wait it blocks until the child
+// reports back and returns that result. Either way the child's run is limited
+// by opts.Timeout (or a default) and a child that runs out of time reports a
+// failure instead of running on unbounded.
+func (t *Thread) SpawnChild(ctx context.Context, agentName string, task string, opts tools.SpawnOptions) (string, string, error) {
 	task = strings.TrimSpace(task)
 	if task == "" {
-		return "", fmt.Errorf("task is required")
+		return "", "", fmt.Errorf("task is required")
 	}
 	if t.mgr == nil {
-		return "", fmt.Errorf("thread has no manager, cannot spawn child")
+		return "", "", fmt.Errorf("thread has no manager, cannot spawn child")
 	}
 
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultChildMaxLifetime
+		if opts.Wait {
+			timeout = defaultChildWaitTimeout
+		}
+	}
+
+	// Nothing new is scheduled once draining starts, so a waited-on child
+	// would never run.
+	if opts.Wait && t.mgr.isDraining() {
+		return "", "", fmt.Errorf("shutting down, cannot wait on a new child")
+	}
+	if err := t.reserveChild(); err != nil {
+		return "", "", err
+	}
 	childSessionKey := t.generateChildSessionKey()
 	child, err := t.mgr.NewThread(childSessionKey, agentName)
 	if err != nil {
-		return "", fmt.Errorf("spawn child: %w", err)
+		t.mu.Lock()
+		t.spawned--
+		t.mu.Unlock()
+		return "", "", fmt.Errorf("spawn child: %w", err)
 	}
 	child.Set("TASK", task)
+	t.mu.Lock()
+	if t.children == nil {
+		t.children = make(map[string]*Thread)
+	}
+	t.children[child.id] = child
+	t.mu.Unlock()
+	child.mu.Lock()
+	child.parent = t
+	child.mu.Unlock()
+
+	// waiting is true while the parent is blocked on done. Once it gives up,
+	// a late result is delivered as a normal child_completed wake instead.
+	var waiting atomic.Bool
+	waiting.Store(opts.Wait)
+	done := make(chan string, 1)
 
 	parentThread := t
 	child.Enqueue(&WakeMessage{
 		Source:  "child_task",
 		Message: task,
+		Timeout: timeout,
+		// No Progress: Send only ever receives the child's final response.
 		Sink: Sink{
 			Label: "your response will be forwarded to parent thread",
 			Send: func(_ context.Context, response string) error {
-				var message string
-				if strings.TrimSpace(response) != "" {
-					message = fmt.Sprintf("Child %s completed:\n%s", child.id, response)
-				} else {
-					message = fmt.Sprintf("Child %s completed (no output)", child.id)
+				if waiting.CompareAndSwap(true, false) {
+					done <- response
+					return nil
 				}
 				parentThread.Enqueue(&WakeMessage{
 					Source:  "child_completed",
-					Message: message,
+					Message: childReport(child.id, response),
 				})
 				return nil
 			},
 		},
 	})
 
-	logger.Debug("child thread spawned", "parentID", t.id, "childID", child.id)
-	return child.id, nil
+	logger.Debug("child thread spawned", "parentID", t.id, "childID", child.id, "wait", opts.Wait, "timeout", timeout)
+	if !opts.Wait {
+		return child.id, "", nil
+	}
+
+	// The child enforces timeout itself; the grace period covers queueing and
+	// delivery of its final report. The parent's scheduler slot is free while
+	// it waits, so the child can run even when every slot is taken by waiters.
+	resume := releaseSlot(ctx)
+	timer := time.NewTimer(timeout + childWaitGrace)
+	defer timer.Stop()
+	var response string
+	reported := false
+	select {
+	case response = <-done:
+		reported = true
+	case <-timer.C:
+	case <-ctx.Done():
+	case <-t.mgr.drained:
+	}
+	resume()
+	if reported {
+		return child.id, childReport(child.id, response), nil
+	}
+	if !waiting.CompareAndSwap(true, false) {
+		// The result arrived while we were giving up.
+		return child.id, childReport(child.id, <-done), nil
+	}
+	if err := ctx.Err(); err != nil {
+		return child.id, "", err
+	}
+	if t.mgr.isDraining() {
+		return child.id, "", fmt.Errorf("shutting down before child %s reported back", child.id)
+	}
+	return child.id, fmt.Sprintf("Child %s timed out after %s without reporting back. It will wake this thread if it finishes later.", child.id, formatTimeout(timeout)), nil
+}
+
+// childReport formats a child's response for its parent. Failed runs,
+// including timeouts, are reported as failures.
+func childReport(childID, response string) string {
+	response = strings.TrimSpace(response)
+	switch {
+	case response == "":
+		return fmt.Sprintf("Child %s completed (no output)", childID)
+	case strings.HasPrefix(response, "[Error]"):
+		return fmt.Sprintf("Child %s failed:\n%s", childID, response)
+	default:
+		return fmt.Sprintf("Child %s completed:\n%s", childID, response)
+	}
+}
+
+// reserveChild counts a new spawn against the thread's limits, or explains
+// which limit it would exceed.
+func (t *Thread) reserveChild() error {
+	cfg := t.cfg()
+	maxActive := cfg.MaxActiveChildren
+	if maxActive <= 0 {
+		maxActive = defaultMaxActiveChildren
+	}
+	maxSpawned := cfg.MaxSpawnedChildren
+	if maxSpawned <= 0 {
+		maxSpawned = defaultMaxSpawnedChildren
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.children) >= maxActive {
+		return fmt.Errorf("this thread already has %d unfinished children (limit %d); wait for one to report back or cancel one with cancel_thread", len(t.children), maxActive)
+	}
+	if t.spawned >= maxSpawned {
+		return fmt.Errorf("this thread has reached its limit of %d spawned children; do the remaining work directly", maxSpawned)
+	}
+	t.spawned++
+	return nil
+}
+
+// childFinished drops a child that has run its task from the active set.
+func (t *Thread) childFinished(childID string) {
+	t.mu.Lock()
+	delete(t.children, childID)
+	t.mu.Unlock()
 }
 
+// CancelChild stops a child spawned by this thread: its turn in progress is
+// cancelled, including any provider request, and queued wakes are dropped.
+// The child reports no result; its status shows "cancelled".
+func (t *Thread) CancelChild(childID string) error {
+	childID = strings.TrimSpace(childID)
+	t.mu.Lock()
+	child, ok := t.children[childID]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("child %q not found among this thread's children", childID)
+	}
+	if !child.cancel() {
+		return fmt.Errorf("child %q is already cancelled", childID)
+	}
+	logger.Info("child thread cancelled", "parentID", t.id, "childID", childID)
+	return nil
+}
+
+// cancel marks t cancelled and stops its running turn. It reports false if t
+// was already cancelled.
+func (t *Thread) cancel() bool {
+	t.mu.Lock()
+	if t.cancelled {
+		t.mu.Unlock()
+		return false
+	}
+	t.cancelled = true
+	t.lastErr = "cancelled"
+	cancelRun := t.cancelRun
+	t.mu.Unlock()
+	if cancelRun != nil {
+		cancelRun()
+	}
+	return true
+}
+
+func (t *Thread) isCancelled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cancelled
+}
 
 func (t *Thread) generateChildSessionKey() string {
 	if t.cfg().Sessions == nil {
@@ -0,0 +1,315 @@
+package thread
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/tools"
+)
+
+// stallingProvider answers its scripted responses, then blocks until the
+// request context ends.
+type stallingProvider struct {
+	scriptedProvider
+}
+
+func (p *stallingProvider) Chat(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	if len(p.responses) > 0 {
+		return p.scriptedProvider.Chat(ctx, req)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSpawnChildWaitTimesOut(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	prov := &stallingProvider{scriptedProvider{responses: []*provider.Response{
+		{ToolCalls: []provider.ToolCall{{ID: "c1", Type: "function", Function: provider.FunctionCall{Name: "echo", Arguments: `"step"`}}}},
+	}}}
+	reg := tools.NewRegistry()
+	reg.Register(echoTool{})
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Tools: reg, Sessions: sessions})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.Run(ctx)
+
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	childID, result, err := parent.SpawnChild(ctx, "", "never finishes", tools.SpawnOptions{Wait: true, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("SpawnChild() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("SpawnChild() took %s, want it bounded by the timeout", elapsed)
+	}
+	if want := "Child " + childID + " failed:\n[Error] timed out after 200ms"; result != want {
+		t.Fatalf("result = %q, want %q", result, want)
+	}
+
+	info, ok := mgr.ThreadStatus(childID)
+	if !ok || info.State != "failed" || !strings.Contains(info.LastError, "timed out") {
+		t.Fatalf("ThreadStatus() = %+v, %v; want failed with timeout", info, ok)
+	}
+
+	// The finished tool round is kept in the child's session.
+	saved, err := sessions.Reload(info.SessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(saved.Messages); n != 4 || saved.Messages[2].Role != "tool" || saved.Messages[3].Content != interruptedNote {
+		t.Fatalf("saved child session = %+v, want user, tool call, tool result, interrupted note", saved.Messages)
+	}
+}
+
+func TestSpawnChildAsyncReportsTimeoutToParent(t *testing.T) {
+	mgr := NewManager(&ThreadConfig{DefaultProvider: &stallingProvider{}})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	childID, _, err := parent.SpawnChild(context.Background(), "", "slow", tools.SpawnOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("SpawnChild() error = %v", err)
+	}
+
+	var child *Thread
+	for _, th := range mgr.threads {
+		if th.id == childID {
+			child = th
+		}
+	}
+	child.RunOnce(context.Background())
+
+	select {
+	case wake := <-parent.inbox:
+		if wake.Source != "child_completed" || !strings.HasPrefix(wake.Message, "Child "+childID+" failed:") {
+			t.Fatalf("parent wake = %+v, want child failure report", wake)
+		}
+	default:
+		t.Fatal("parent was not woken after the child timed out")
+	}
+}
+
+func TestCancelChildStopsRunningChild(t *testing.T) {
+	mgr := NewManager(&ThreadConfig{DefaultProvider: &stallingProvider{}})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	childID, _, err := parent.SpawnChild(context.Background(), "", "runaway", tools.SpawnOptions{})
+	if err != nil {
+		t.Fatalf("SpawnChild() error = %v", err)
+	}
+	child := parent.children[childID]
+
+	finished := make(chan struct{})
+	go func() {
+		child.RunOnce(context.Background())
+		close(finished)
+	}()
+	// Wait until the child's turn is blocked in the provider call.
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		child.mu.Lock()
+		running := child.cancelRun != nil
+		child.mu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("child turn did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := parent.CancelChild(childID); err != nil {
+		t.Fatalf("CancelChild() error = %v", err)
+	}
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelled child kept running")
+	}
+
+	info, ok := mgr.ThreadStatus(childID)
+	if !ok || info.State != "cancelled" {
+		t.Fatalf("ThreadStatus() = %+v, %v; want cancelled", info, ok)
+	}
+	if len(parent.inbox) != 0 {
+		t.Fatal("cancelled child reported a result to the parent")
+	}
+	if err := parent.CancelChild(childID); err == nil {
+		t.Fatal("second CancelChild() error = nil, want already cancelled")
+	}
+	if err := parent.CancelChild("thread-unknown"); err == nil {
+		t.Fatal("CancelChild(unknown) error = nil")
+	}
+}
+
+func TestCancelChildDropsQueuedTask(t *testing.T) {
+	prov := &scriptedProvider{}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	childID, _, err := parent.SpawnChild(context.Background(), "", "queued", tools.SpawnOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.CancelChild(childID); err != nil {
+		t.Fatalf("CancelChild() error = %v", err)
+	}
+	parent.children[childID].RunOnce(context.Background())
+	if len(prov.requests) != 0 {
+		t.Fatalf("cancelled child made %d provider calls", len(prov.requests))
+	}
+}
+
+func TestSpawnChildEnforcesLimits(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(&ThreadConfig{Sessions: sessions, MaxActiveChildren: 2, MaxSpawnedChildren: 3})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spawn := func() (string, error) {
+		id, _, err := parent.SpawnChild(context.Background(), "", "work", tools.SpawnOptions{})
+		return id, err
+	}
+
+	first, _ := spawn()
+	if _, err := spawn(); err != nil {
+		t.Fatalf("second spawn error = %v", err)
+	}
+	if _, err := spawn(); err == nil || !strings.Contains(err.Error(), "unfinished children (limit 2)") {
+		t.Fatalf("third spawn error = %v, want active limit", err)
+	}
+
+	parent.childFinished(first)
+	third, err := spawn()
+	if err != nil {
+		t.Fatalf("spawn after a child finished error = %v", err)
+	}
+	parent.childFinished(third)
+	if _, err := spawn(); err == nil || !strings.Contains(err.Error(), "limit of 3 spawned children") {
+		t.Fatalf("fourth spawn error = %v, want lifetime limit", err)
+	}
+}
+
+func TestCompletedChildrenAreForgotten(t *testing.T) {
+	const n = 20
+	responses := make([]*provider.Response, n)
+	for i := range responses {
+		responses[i] = &provider.Response{Content: "done"}
+	}
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: &scriptedProvider{responses: responses}, Sessions: sessions, MaxSpawnedChildren: n})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < n; i++ {
+		id, _, err := parent.SpawnChild(context.Background(), "", "quick", tools.SpawnOptions{})
+		if err != nil {
+			t.Fatalf("spawn %d error = %v", i, err)
+		}
+		parent.children[id].RunOnce(context.Background())
+		<-parent.inbox // drain the child_completed report
+	}
+	if got := len(parent.children); got != 0 {
+		t.Fatalf("children map holds %d entries after all finished, want 0", got)
+	}
+}
+
+func TestWaitingParentFreesSchedulerSlot(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	prov := &scriptedProvider{responses: []*provider.Response{
+		{ToolCalls: []provider.ToolCall{{ID: "c1", Type: "function", Function: provider.FunctionCall{Name: "spawn_thread", Arguments: `{"task":"sub","wait":true}`}}}},
+		{Content: "child done"},
+		{Content: "parent done"},
+	}}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Sessions: sessions})
+	mgr.maxConcurrency = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.Run(ctx)
+
+	replies := make(chan string, 1)
+	mgr.Wake("cli:main", &WakeMessage{Source: "user_message", Message: "delegate", Sink: Sink{
+		Send: func(_ context.Context, response string) error {
+			replies <- response
+			return nil
+		},
+	}})
+	select {
+	case got := <-replies:
+		if got != "parent done" {
+			t.Fatalf("reply = %q, want parent done", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the child never ran while its parent held the only slot")
+	}
+	toolResult := prov.requests[2].Messages[len(prov.requests[2].Messages)-1].Content
+	if !strings.Contains(toolResult, "child done") {
+		t.Fatalf("parent tool result = %q, want the child's answer", toolResult)
+	}
+}
+
+func TestSpawnChildWaitStopsWhenDraining(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	// The manager loop is not running, so the child is never scheduled.
+	mgr := NewManager(&ThreadConfig{DefaultProvider: &scriptedProvider{}, Sessions: sessions})
+	parent, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := parent.SpawnChild(context.Background(), "", "sub", tools.SpawnOptions{Wait: true, Timeout: time.Minute})
+		errs <- err
+	}()
+	for spawned := false; !spawned; time.Sleep(time.Millisecond) {
+		parent.mu.Lock()
+		spawned = len(parent.children) > 0
+		parent.mu.Unlock()
+	}
+	if err := mgr.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	select {
+	case err := <-errs:
+		if err == nil || !strings.Contains(err.Error(), "shutting down") {
+			t.Fatalf("SpawnChild() error = %v, want shutting down", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SpawnChild() kept waiting after Drain")
+	}
+
+	if _, _, err := parent.SpawnChild(context.Background(), "", "late", tools.SpawnOptions{Wait: true}); err == nil {
+		t.Fatal("SpawnChild() after Drain succeeded, want an error")
+	}
+}
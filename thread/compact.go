@@ -0,0 +1,159 @@
+package thread
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+const (
+	defaultCompactKeepRecent = 4
+	compactNotePrefix        = "[Compacted conversation summary]\n"
+	compactMaxPartChars      = 2000
+)
+
+const compactSystemPrompt = `You compact conversation history for an AI assistant.
+Summarize the transcript you are given into concise notes that let the assistant continue the conversation seamlessly.
+Keep critical facts, user preferences, decisions, IDs, file paths, commands, and unresolved tasks. Drop chit-chat and redundant tool output.
+Reply with the summary only.`
+
+// CompactSession summarizes everything before the last keepRecent user turns
+// into a single assistant note generated by p, and keeps those recent turns
+// verbatim. It is a no-op when there is nothing older to compact.
+func CompactSession(ctx context.Context, p provider.Provider, sess *session.Session, keepRecent int) error {
+	if p == nil || sess == nil {
+		return fmt.Errorf("provider and session are required")
+	}
+	if keepRecent <= 0 {
+		keepRecent = defaultCompactKeepRecent
+	}
+
+	cut := recentTurnsStart(sess.Messages, keepRecent)
+	if cut <= 0 {
+		return nil
+	}
+	older := sess.Messages[:cut]
+
+	resp, err := p.Chat(ctx, &provider.Request{Messages: []provider.Message{
+		provider.SystemMessage(compactSystemPrompt),
+		provider.UserMessage(renderTranscript(older)),
+	}})
+	if err != nil {
+		return fmt.Errorf("compaction request failed: %w", err)
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return fmt.Errorf("compaction returned an empty summary")
+	}
+
+	compacted := make([]provider.Message, 0, 1+len(sess.Messages)-cut)
+	compacted = append(compacted, provider.AssistantMessage(compactNotePrefix+summary))
+	compacted = append(compacted, sess.Messages[cut:]...)
+	sess.Messages = compacted
+	sess.Usage.Add(resp.Usage)
+	return nil
+}
+
+// recentTurnsStart returns the index of the user message that starts the
+// keepRecent-th most recent turn, or 0 if the history has no more turns than that.
+func recentTurnsStart(messages []provider.Message, keepRecent int) int {
+	turns := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		turns++
+		if turns == keepRecent {
+			return i
+		}
+	}
+	return 0
+}
+
+// renderTranscript flattens messages into plain text for the summarizer.
+func renderTranscript(messages []provider.Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		switch {
+		case m.Role == "tool":
+			fmt.Fprintf(&sb, "[tool result %s]\n%s\n\n", m.Name, clipText(m.Content))
+		case len(m.ToolCalls) > 0:
+			if strings.TrimSpace(m.Content) != "" {
+				fmt.Fprintf(&sb, "[assistant]\n%s\n", clipText(m.Content))
+			}
+			for _, call := range m.ToolCalls {
+				fmt.Fprintf(&sb, "[assistant called %s] %s\n", call.Function.Name, clipText(call.Function.Arguments))
+			}
+			sb.WriteString("\n")
+		default:
+			fmt.Fprintf(&sb, "[%s]\n%s\n\n", m.Role, clipText(m.Content))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func clipText(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= compactMaxPartChars {
+		return s
+	}
+	n := compactMaxPartChars
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…(truncated)"
+}
+
+// autoCompact compacts the stored session and refreshes sess from the result
+// when requestTokens reaches the context warn threshold and auto-compaction is
+// enabled. It reports whether the session changed.
+func (t *Thread) autoCompact(ctx context.Context, sess *session.Session, requestTokens int) bool {
+	cfg := t.cfg()
+	windowTokens, warnRatio := t.contextBudget()
+	if !cfg.AutoCompact || sess == nil || cfg.Sessions == nil || windowTokens <= 0 {
+		return false
+	}
+	threshold := int(float64(windowTokens) * warnRatio)
+	if threshold <= 0 {
+		threshold = windowTokens
+	}
+	if requestTokens < threshold {
+		return false
+	}
+
+	keepRecent := cfg.CompactKeepRecent
+	if keepRecent <= 0 {
+		keepRecent = defaultCompactKeepRecent
+	}
+	if recentTurnsStart(sess.Messages, keepRecent) <= 0 {
+		return false
+	}
+
+	// Compact the stored session under its lock, not the copy loaded for this
+	// turn, so turns saved meanwhile are neither lost nor overwritten.
+	before := len(sess.Messages)
+	compacted, err := cfg.Sessions.Update(t.sessionKey, func(s *session.Session) error {
+		before = len(s.Messages)
+		return CompactSession(ctx, t.provider, s, keepRecent)
+	})
+	if err != nil {
+		logger.Warn("auto compaction failed", "threadID", t.id, "sessionKey", t.sessionKey, "err", err)
+		return false
+	}
+	*sess = *compacted
+	logger.Info(
+		"session auto-compacted",
+		"threadID", t.id,
+		"sessionKey", t.sessionKey,
+		"requestEstimatedTokens", requestTokens,
+		"thresholdTokens", threshold,
+		"messagesBefore", before,
+		"messagesAfter", len(sess.Messages),
+	)
+	return true
+}
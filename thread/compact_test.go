@@ -0,0 +1,159 @@
+package thread
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+func turnHistory(turns int) []provider.Message {
+	var msgs []provider.Message
+	for i := 0; i < turns; i++ {
+		n := string(rune('a' + i))
+		msgs = append(msgs,
+			provider.UserMessage("question "+n),
+			provider.AssistantMessageWithTools("", "", []provider.ToolCall{{ID: "call_" + n, Type: "function", Function: provider.FunctionCall{Name: "echo", Arguments: "{}"}}}),
+			provider.ToolResultMessage("call_"+n, "echo", "{}"),
+			provider.AssistantMessage("answer "+n),
+		)
+	}
+	return msgs
+}
+
+func TestCompactSessionKeepsRecentTurns(t *testing.T) {
+	prov := &scriptedProvider{responses: []*provider.Response{{Content: "summary of a and b", Usage: provider.Usage{TotalTokens: 7}}}}
+	sess := &session.Session{Key: "s", Messages: turnHistory(4)}
+	recent := append([]provider.Message(nil), sess.Messages[8:]...)
+
+	if err := CompactSession(context.Background(), prov, sess, 2); err != nil {
+		t.Fatalf("CompactSession() error = %v", err)
+	}
+	if len(sess.Messages) != 1+len(recent) {
+		t.Fatalf("len(Messages) = %d, want %d", len(sess.Messages), 1+len(recent))
+	}
+	note := sess.Messages[0]
+	if note.Role != "assistant" || !strings.HasPrefix(note.Content, compactNotePrefix) || !strings.HasSuffix(note.Content, "summary of a and b") {
+		t.Fatalf("note = %+v", note)
+	}
+	for i, m := range recent {
+		got := sess.Messages[1+i]
+		if got.Role != m.Role || got.Content != m.Content || got.ToolCallID != m.ToolCallID {
+			t.Fatalf("recent[%d] = %+v, want %+v", i, got, m)
+		}
+	}
+	if sess.Usage.TotalTokens != 7 {
+		t.Fatalf("Usage = %+v, want compaction usage recorded", sess.Usage)
+	}
+
+	transcript := prov.requests[0].Messages[1].Content
+	if !strings.Contains(transcript, "question a") || strings.Contains(transcript, "question c") {
+		t.Fatalf("transcript = %q, want only the older turns", transcript)
+	}
+}
+
+func TestCompactSessionNoopWhenShort(t *testing.T) {
+	prov := &scriptedProvider{}
+	sess := &session.Session{Key: "s", Messages: turnHistory(2)}
+	if err := CompactSession(context.Background(), prov, sess, 2); err != nil {
+		t.Fatalf("CompactSession() error = %v", err)
+	}
+	if len(sess.Messages) != 8 || len(prov.requests) != 0 {
+		t.Fatalf("short session compacted: %d messages, %d requests", len(sess.Messages), len(prov.requests))
+	}
+}
+
+func TestRunAutoCompactsOverThreshold(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	sess, _ := sessions.Get("cli:main")
+	sess.Messages = turnHistory(5)
+	if err := sessions.Save(sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	prov := &scriptedProvider{responses: []*provider.Response{{Content: "older summary"}, {Content: "reply"}}}
+	mgr := NewManager(&ThreadConfig{
+		DefaultProvider:     prov,
+		Sessions:            sessions,
+		ContextWindowTokens: 100,
+		ContextWarnRatio:    0.5,
+		AutoCompact:         true,
+		CompactKeepRecent:   1,
+	})
+	th, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+	if _, err := th.run(context.Background(), "next", "next", nil, nil, nil); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	turnReq := prov.requests[1].Messages
+	if !strings.HasPrefix(turnReq[1].Content, compactNotePrefix) {
+		t.Fatalf("turn request[1] = %+v, want compacted note", turnReq[1])
+	}
+	saved, err := sessions.Reload("cli:main")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(saved.Messages) >= 20 {
+		t.Fatalf("saved %d messages, want fewer than the original 20", len(saved.Messages))
+	}
+	if last := saved.Messages[len(saved.Messages)-1]; last.Content != "reply" {
+		t.Fatalf("last saved message = %+v, want reply", last)
+	}
+	if saved.Messages[1].Content != "question e" {
+		t.Fatalf("first kept message = %+v, want most recent turn", saved.Messages[1])
+	}
+}
+
+func TestAutoCompactKeepsTurnsSavedSinceLoad(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	stale := &session.Session{Key: "cli:main", Messages: turnHistory(4)}
+	if err := sessions.Save(stale); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// Another turn lands after this turn loaded its copy.
+	if _, err := sessions.Update("cli:main", func(s *session.Session) error {
+		s.Messages = append(s.Messages, provider.UserMessage("question e"), provider.AssistantMessage("answer e"))
+		return nil
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	prov := &scriptedProvider{responses: []*provider.Response{{Content: "older summary"}}}
+	mgr := NewManager(&ThreadConfig{
+		DefaultProvider:     prov,
+		Sessions:            sessions,
+		ContextWindowTokens: 100,
+		ContextWarnRatio:    0.5,
+		AutoCompact:         true,
+		CompactKeepRecent:   1,
+	})
+	th, err := mgr.NewThread("cli:main", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+	if !th.autoCompact(context.Background(), stale, 100) {
+		t.Fatal("autoCompact() = false, want compaction")
+	}
+
+	saved, err := sessions.Reload("cli:main")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(saved.Messages) != 3 || saved.Messages[1].Content != "question e" {
+		t.Fatalf("saved = %+v, want the note and the later turn", saved.Messages)
+	}
+	if len(stale.Messages) != len(saved.Messages) {
+		t.Fatalf("turn copy has %d messages, want it refreshed to %d", len(stale.Messages), len(saved.Messages))
+	}
+}
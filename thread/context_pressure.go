@@ -2,31 +2,18 @@ package thread
 
 import (
 	"fmt"
+	"os"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
-	"github.com/tiktoken-go/tokenizer"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/tools"
 )
 
-var (
-	tiktokenOnce sync.Once
-	tiktokenCodec tokenizer.Codec
-)
-
-func getCodec() tokenizer.Codec {
-	tiktokenOnce.Do(func() {
-		enc, err := tokenizer.Get(tokenizer.O200kBase)
-		if err != nil {
-			logger.Warn("failed to init tiktoken codec, token estimates will be zero", "err", err)
-			return
-		}
-		tiktokenCodec = enc
-	})
-	return tiktokenCodec
-}
-
+// sessionFilePath returns the thread's session file, when sessions are
+// stored as files.
 func (t *Thread) sessionFilePath() (string, bool) {
 	cfg := t.cfg()
 	if cfg.Sessions == nil {
@@ -36,15 +23,68 @@ func (t *Thread) sessionFilePath() (string, bool) {
 	if key == "" {
 		return "", false
 	}
-	return cfg.Sessions.PathForKey(key), true
+	path := cfg.Sessions.PathForKey(key)
+	return path, path != ""
+}
+
+// sessionHealth reports the thread's stored session as read through the
+// session store, so it works for every storage backend.
+func (t *Thread) sessionHealth(path string) *tools.HealthSessionInfo {
+	cfg := t.cfg()
+	if cfg.Sessions == nil || strings.TrimSpace(t.sessionKey) == "" {
+		return nil
+	}
+	info := &tools.HealthSessionInfo{Path: path}
+	s, found, err := cfg.Sessions.Store().Load(t.sessionKey)
+	if err != nil {
+		info.ParseError = err.Error()
+		return info
+	}
+	info.Exists = found
+	if found {
+		info.MessagesCount = len(s.Messages)
+		if !s.UpdatedAt.IsZero() {
+			info.UpdatedAt = s.UpdatedAt.Format(time.RFC3339)
+		}
+	}
+	if stat, err := os.Stat(path); path != "" && err == nil {
+		info.FileSizeBytes = stat.Size()
+	}
+	return info
 }
 
+// contextBudget returns the context window of the thread's model, from the
+// config overrides or the provider table, falling back to ContextWindowTokens.
 func (t *Thread) contextBudget() (tokens int, warnRatio float64) {
 	cfg := t.cfg()
-	return cfg.ContextWindowTokens, cfg.ContextWarnRatio
+	return provider.ContextWindowFor(t.modelName, cfg.ModelContextWindows, cfg.ContextWindowTokens), cfg.ContextWarnRatio
+}
+
+// trimHistory returns the session history to send with this turn: orphaned
+// tool results are dropped and, when HistoryTokenRatio is set, the oldest
+// messages too so the history fits that share of the context window. The
+// stored session is left untouched.
+func (t *Thread) trimHistory(history []provider.Message) []provider.Message {
+	cfg := t.cfg()
+	windowTokens, _ := t.contextBudget()
+	if cfg.HistoryTokenRatio <= 0 || windowTokens <= 0 {
+		return session.RepairToolPairs(history)
+	}
+	budget := int(float64(windowTokens) * cfg.HistoryTokenRatio)
+	trimmed := session.TrimToTokenBudgetWith(session.TokenEstimatorForModel(t.modelName), history, budget)
+	if len(trimmed) < len(history) {
+		logger.Info(
+			"session history trimmed to token budget",
+			"threadID", t.id,
+			"sessionKey", t.sessionKey,
+			"budgetTokens", budget,
+			"droppedMessages", len(history)-len(trimmed),
+		)
+	}
+	return trimmed
 }
 
-func (t *Thread) buildCompressionNotice(requestTokens, contextWindowTokens int, usageRatio float64, sessionPath string) string {
+func (t *Thread) buildCompressionNotice(requestTokens, contextWindowTokens int, usageRatio float64) string {
 	return fmt.Sprintf(`[Context Pressure Notice]
 Estimated request tokens are high for this thread.
 
@@ -52,53 +92,13 @@ Estimated request tokens are high for this thread.
 - configured_context_window_tokens: %d
 - estimated_usage_ratio: %.2f
 - session_key: %s
-- session_file: %s
-
-You MUST load and execute skill "compress-context" NOW, before responding to the user. Then you can respond to the user request. Follow the skill instructions to compact the session file safely. Keep critical facts, decisions, IDs, and unresolved tasks.`, requestTokens, contextWindowTokens, usageRatio, t.sessionKey, sessionPath)
-}
-
-func estimateTextTokens(text string) int {
-	if text == "" {
-		return 0
-	}
-	codec := getCodec()
-	if codec == nil {
-		return len(text) / 3 // rough fallback
-	}
-	ids, _, _ := codec.Encode(text)
-	return len(ids)
-}
-
-func estimateMessageTokens(message provider.Message) int {
-	tokens := 6 // Base per-message structure overhead.
-	tokens += estimateTextTokens(message.Role)
-	tokens += estimateTextTokens(message.Content)
-	tokens += estimateTextTokens(message.ReasoningContent)
-	tokens += estimateTextTokens(message.ToolCallID)
-	tokens += estimateTextTokens(message.Name)
-
-	for _, call := range message.ToolCalls {
-		tokens += 8 // Tool call structure overhead.
-		tokens += estimateTextTokens(call.ID)
-		tokens += estimateTextTokens(call.Type)
-		tokens += estimateTextTokens(call.Function.Name)
-		tokens += estimateTextTokens(call.Function.Arguments)
-	}
-
-	return tokens
-}
 
-func estimateMessagesTokens(messages []provider.Message) int {
-	total := 3 // Priming overhead.
-	for _, message := range messages {
-		total += estimateMessageTokens(message)
-	}
-	return total
+You MUST load and execute skill "compress-context" NOW, before responding to the user. Then you can respond to the user request. Follow the skill instructions to compact the session safely. Keep critical facts, decisions, IDs, and unresolved tasks.`, requestTokens, contextWindowTokens, usageRatio, t.sessionKey)
 }
 
 func (t *Thread) contextPressureHook() turnHook {
 	return func(ctx turnContext) []string {
-		if strings.TrimSpace(ctx.SessionPath) == "" {
+		if t.cfg().Sessions == nil || strings.TrimSpace(ctx.SessionKey) == "" {
 			return nil
 		}
 		if ctx.ContextWindowTokens <= 0 {
@@ -118,14 +118,12 @@ func (t *Thread) contextPressureHook() turnHook {
 			ctx.RequestEstimatedTokens,
 			ctx.ContextWindowTokens,
 			usageRatio,
-			ctx.SessionPath,
 		)
 
 		logger.Info(
 			"context threshold reached, compression reminder injected into current turn",
 			"threadID", ctx.ThreadID,
 			"sessionKey", ctx.SessionKey,
-			"sessionPath", ctx.SessionPath,
 			"requestEstimatedTokens", ctx.RequestEstimatedTokens,
 			"contextWindowTokens", ctx.ContextWindowTokens,
 			"thresholdTokens", threshold,
@@ -9,7 +9,6 @@ type turnContext struct {
 	ThreadID string
 
 	SessionKey  string
-	SessionPath string
 	UserMessage string
 
 	SessionEstimatedTokens int
@@ -2,11 +2,13 @@ package thread
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/session"
 	"github.com/linanwx/nagobot/tools"
 )
 
@@ -17,8 +19,15 @@ type Manager struct {
 	threads        map[string]*Thread
 	maxConcurrency int
 	signal         chan struct{} // aggregated notification from all threads
+
+	draining bool           // set by Drain; no new turns start
+	drained  chan struct{}  // closed when draining starts
+	active   sync.WaitGroup // turns started by scheduleReady
 }
 
+// slotKey carries the scheduler slot of a running turn in its context.
+type slotKey struct{}
+
 // NewManager creates a thread manager.
 func NewManager(cfg *ThreadConfig) *Manager {
 	if cfg == nil {
@@ -29,6 +38,7 @@ func NewManager(cfg *ThreadConfig) *Manager {
 		threads:        make(map[string]*Thread),
 		maxConcurrency: defaultMaxConcurrency,
 		signal:         make(chan struct{}, 1),
+		drained:        make(chan struct{}),
 	}
 }
 
@@ -69,16 +79,22 @@ func (m *Manager) scheduleReady(ctx context.Context, sem chan struct{}) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.draining {
+		return
+	}
 	for _, t := range m.threads {
 		if t.state == threadIdle && t.hasMessages() {
 			t.state = threadRunning
+			m.active.Add(1)
 
 			go func(thread *Thread) {
+				defer m.active.Done()
+
 				// Acquire concurrency slot (may block).
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				thread.RunOnce(ctx)
+				thread.RunOnce(context.WithValue(ctx, slotKey{}, sem))
 
 				m.mu.Lock()
 				thread.lastActiveAt = time.Now()
@@ -94,6 +110,52 @@ func (m *Manager) scheduleReady(ctx context.Context, sem chan struct{}) {
 	}
 }
 
+// Drain stops starting new turns and waits for running ones to finish and
+// save their sessions. It returns ctx.Err() if turns are still running when
+// ctx ends; cancelling the context passed to Run then interrupts them, and a
+// second Drain waits for their partial turns to be saved. Messages still
+// queued are not run.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.draining {
+		m.draining = true
+		close(m.drained)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isDraining reports whether Drain has been called.
+func (m *Manager) isDraining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
+// releaseSlot gives up the scheduler slot held by the turn running under ctx,
+// for turns that block on other threads, and returns a func that takes it
+// back. Turns not started by the scheduler hold no slot; for them both are
+// no-ops.
+func releaseSlot(ctx context.Context) (reacquire func()) {
+	sem, _ := ctx.Value(slotKey{}).(chan struct{})
+	if sem == nil {
+		return func() {}
+	}
+	<-sem
+	return func() { sem <- struct{}{} }
+}
+
 // notify sends a non-blocking signal to the manager's run loop.
 func (m *Manager) notify() {
 	select {
@@ -141,11 +203,24 @@ func (m *Manager) NewThread(sessionKey, agentName string) (*Thread, error) {
 		lastActiveAt: time.Now(),
 	}
 	a, err := m.cfg.Agents.New(agentName)
+	if err != nil && agentName != "" {
+		// A misconfigured assignment should not drop the user's message.
+		logger.Warn("agent not found, using default", "agent", agentName, "sessionKey", sessionKey, "err", err)
+		a, err = m.cfg.Agents.New("")
+	}
 	if err != nil {
 		return nil, err
 	}
 	t.Agent = a
-	t.provider = m.cfg.DefaultProvider
+	t.provider, t.providerName, t.modelName = m.cfg.DefaultProvider, m.cfg.ProviderName, m.cfg.ModelName
+	if a.Profile != "" && m.cfg.Profiles != nil {
+		if p, err := m.cfg.Profiles(a.Profile); err != nil {
+			logger.Warn("agent provider profile unavailable, using default", "agent", a.Name, "profile", a.Profile, "err", err)
+		} else if p != nil && p.Provider != nil {
+			t.provider, t.providerName, t.modelName = p.Provider, p.ProviderName, p.ModelName
+			t.profile = a.Profile
+		}
+	}
 	if m.cfg.DefaultSinkFor != nil {
 		t.defaultSink = m.cfg.DefaultSinkFor(sessionKey)
 	}
@@ -160,6 +235,61 @@ func (m *Manager) SetDefaultSinkFor(fn func(string) Sink) {
 	m.cfg.DefaultSinkFor = fn
 }
 
+// SetChannelDroppedFn sets the source of per-channel dropped message counts for health output.
+func (m *Manager) SetChannelDroppedFn(fn func() map[string]int64) {
+	m.cfg.ChannelDroppedFn = fn
+}
+
+// SessionUsage returns the persisted token totals for a session.
+func (m *Manager) SessionUsage(sessionKey string) (prompt, completion, total int) {
+	if m.cfg.Sessions == nil {
+		return 0, 0, 0
+	}
+	sess, err := m.cfg.Sessions.Reload(sessionKey)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return sess.Usage.PromptTokens, sess.Usage.CompletionTokens, sess.Usage.TotalTokens
+}
+
+// ResetSession deletes a session's history and token totals. Its thread
+// starts from an empty session on the next turn.
+func (m *Manager) ResetSession(sessionKey string) error {
+	if m.cfg.Sessions == nil {
+		return errors.New("sessions are not enabled")
+	}
+	return m.cfg.Sessions.Delete(sessionKey)
+}
+
+// SessionStore returns the backend sessions are persisted to, or nil when
+// sessions are not enabled.
+func (m *Manager) SessionStore() session.Store {
+	if m.cfg.Sessions == nil {
+		return nil
+	}
+	return m.cfg.Sessions.Store()
+}
+
+// SessionModel returns the provider and model serving a session: those of its
+// thread if one exists, otherwise the defaults.
+func (m *Manager) SessionModel(sessionKey string) (providerName, modelName string) {
+	m.mu.Lock()
+	t := m.threads[sessionKey]
+	m.mu.Unlock()
+	if t != nil && t.providerName != "" {
+		return t.providerName, t.modelName
+	}
+	return m.cfg.ProviderName, m.cfg.ModelName
+}
+
+// SetToolApprovalFunc sets the approval callback for sensitive tool calls.
+// Call before threads are created; each thread clones the shared registry.
+func (m *Manager) SetToolApprovalFunc(fn tools.ApprovalFunc) {
+	if m.cfg.Tools != nil {
+		m.cfg.Tools.SetApprovalFunc(fn)
+	}
+}
+
 // RegisterTool adds a tool to the shared tool registry.
 func (m *Manager) RegisterTool(t tools.Tool) {
 	if m.cfg.Tools != nil {
@@ -204,6 +334,16 @@ func threadInfo(t *Thread) tools.ThreadInfo {
 			info.State = "idle"
 		}
 	}
+	t.mu.Lock()
+	info.LastError = t.lastErr
+	cancelled := t.cancelled
+	t.mu.Unlock()
+	switch {
+	case cancelled:
+		info.State = "cancelled"
+	case info.State == "idle" && info.LastError != "":
+		info.State = "failed"
+	}
 	info.Pending = len(t.inbox)
 	return info
 }
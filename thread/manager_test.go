@@ -0,0 +1,114 @@
+package thread
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+// gatedProvider signals each call on started and answers once release is
+// closed, or fails when the request context ends first.
+type gatedProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *gatedProvider) Chat(ctx context.Context, _ *provider.Request) (*provider.Response, error) {
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+		return &provider.Response{Content: "done"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newDrainTest(t *testing.T) (*Manager, *gatedProvider, *session.Manager, context.CancelFunc) {
+	t.Helper()
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	prov := &gatedProvider{started: make(chan struct{}, 4), release: make(chan struct{})}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Sessions: sessions})
+	ctx, cancel := context.WithCancel(context.Background())
+	go mgr.Run(ctx)
+	t.Cleanup(cancel)
+	return mgr, prov, sessions, cancel
+}
+
+func waitStarted(t *testing.T, prov *gatedProvider) {
+	t.Helper()
+	select {
+	case <-prov.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn never reached the provider")
+	}
+}
+
+func TestDrainLetsRunningTurnFinishAndSave(t *testing.T) {
+	mgr, prov, sessions, _ := newDrainTest(t)
+	mgr.Wake("telegram:1", &WakeMessage{Source: "telegram", Message: "hello"})
+	waitStarted(t, prov)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drained := make(chan error, 1)
+	go func() { drained <- mgr.Drain(ctx) }()
+	for draining := false; !draining; {
+		mgr.mu.Lock()
+		draining = mgr.draining
+		mgr.mu.Unlock()
+	}
+
+	// A message arriving during the drain is not started.
+	mgr.Wake("telegram:2", &WakeMessage{Source: "telegram", Message: "late"})
+	time.Sleep(20 * time.Millisecond)
+	close(prov.release)
+
+	if err := <-drained; err != nil {
+		t.Fatalf("Drain() error = %v, want nil", err)
+	}
+	sess, err := sessions.Reload("telegram:1")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if n := len(sess.Messages); n != 2 || sess.Messages[1].Content != "done" {
+		t.Fatalf("saved session = %+v, want the finished turn", sess.Messages)
+	}
+	select {
+	case <-prov.started:
+		t.Fatal("a turn started after Drain")
+	default:
+	}
+}
+
+func TestDrainTimesOutThenCancelledTurnIsSaved(t *testing.T) {
+	mgr, prov, sessions, cancelRun := newDrainTest(t)
+	mgr.Wake("telegram:1", &WakeMessage{Source: "telegram", Message: "hello"})
+	waitStarted(t, prov)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := mgr.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() error = %v, want deadline exceeded", err)
+	}
+
+	cancelRun()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := mgr.Drain(ctx); err != nil {
+		t.Fatalf("Drain() after cancel error = %v", err)
+	}
+	sess, err := sessions.Reload("telegram:1")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if n := len(sess.Messages); n != 2 || sess.Messages[1].Content != shutdownNote {
+		t.Fatalf("saved session = %+v, want the message and the shutdown note", sess.Messages)
+	}
+}
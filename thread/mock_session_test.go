@@ -0,0 +1,176 @@
+package thread
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/tools"
+)
+
+func TestMultiTurnSessionWithMockProvider(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	reg := tools.NewRegistry()
+	reg.Register(echoTool{})
+	prov := provider.NewMockProvider().On("remember", &provider.Response{Content: "Noted."})
+
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Tools: reg, Sessions: sessions})
+	th, err := mgr.NewThread("web:demo", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	turns := []struct{ in, want string }{
+		{"hello", "[mock] hello"},
+		{"please remember my name is Ada", "Noted."},
+		{`!tool echo {"name":"Ada"}`, `[mock] echo returned: {"name":"Ada"}`},
+	}
+	for _, turn := range turns {
+		out, err := th.run(context.Background(), turn.in, turn.in, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("run(%q) error = %v", turn.in, err)
+		}
+		if out != turn.want {
+			t.Fatalf("run(%q) = %q, want %q", turn.in, out, turn.want)
+		}
+	}
+
+	// The tool turn took two calls, and each turn sees the earlier ones.
+	requests := prov.Requests()
+	if len(requests) != len(turns)+1 {
+		t.Fatalf("provider calls = %d, want %d", len(requests), len(turns)+1)
+	}
+	last := requests[len(requests)-1]
+	if final := last.Messages[len(last.Messages)-1]; final.Role != "tool" || final.Name != "echo" {
+		t.Fatalf("last request ends with %+v, want the echo tool result", final)
+	}
+	var users int
+	for _, m := range last.Messages {
+		if m.Role == "user" {
+			users++
+		}
+	}
+	if users < len(turns) {
+		t.Fatalf("final request has %d user messages, want history of %d turns", users, len(turns))
+	}
+
+	sess, err := sessions.Reload("web:demo")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	var roles []string
+	for _, m := range sess.Messages {
+		roles = append(roles, m.Role)
+	}
+	want := []string{"user", "assistant", "user", "assistant", "user", "assistant"}
+	if len(roles) != len(want) {
+		t.Fatalf("session roles = %v, want %v", roles, want)
+	}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Fatalf("session roles = %v, want %v", roles, want)
+		}
+	}
+	if got := sess.Messages[len(sess.Messages)-1].Content; got != turns[len(turns)-1].want {
+		t.Fatalf("saved reply = %q, want %q", got, turns[len(turns)-1].want)
+	}
+	if sess.Usage.TotalTokens == 0 {
+		t.Fatal("session usage not recorded")
+	}
+}
+
+// overlapProvider wraps the mock provider, holding each call briefly and
+// recording the most calls seen in flight at once.
+type overlapProvider struct {
+	*provider.MockProvider
+	active, peak atomic.Int32
+}
+
+func (p *overlapProvider) Chat(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	n := p.active.Add(1)
+	defer p.active.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return p.MockProvider.Chat(ctx, req)
+}
+
+func TestConcurrentWakesOnOneSessionPersistEveryTurnInOrder(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	prov := &overlapProvider{MockProvider: provider.NewMockProvider()}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Sessions: sessions})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.Run(ctx)
+
+	const n = 5
+	done := make(chan struct{}, n)
+	sink := Sink{Send: func(context.Context, string) error {
+		done <- struct{}{}
+		return nil
+	}}
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mgr.Wake("telegram:42", &WakeMessage{Source: "telegram", Message: fmt.Sprintf("message %d", i), Sink: sink})
+		}()
+	}
+	wg.Wait()
+	for range n {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for turns")
+		}
+	}
+
+	if peak := prov.peak.Load(); peak != 1 {
+		t.Fatalf("provider saw %d concurrent calls for one session, want 1", peak)
+	}
+	sess, err := sessions.Reload("telegram:42")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(sess.Messages) != 2*n {
+		t.Fatalf("session has %d messages, want %d", len(sess.Messages), 2*n)
+	}
+	// Turns are stored in the order they ran, each reply right after its message.
+	requests := prov.Requests()
+	seen := make(map[int]bool)
+	for i := 0; i < len(sess.Messages); i += 2 {
+		user, reply := sess.Messages[i], sess.Messages[i+1]
+		if user.Role != "user" || reply.Role != "assistant" || reply.Content != "[mock] "+user.Content {
+			t.Fatalf("turn %d = %+v, %+v; want a user message and its echo", i/2, user, reply)
+		}
+		last := requests[i/2].Messages[len(requests[i/2].Messages)-1]
+		if last.Content != user.Content {
+			t.Fatalf("turn %d stored %q, but call %d was for %q", i/2, user.Content, i/2, last.Content)
+		}
+		for j := range n {
+			if strings.Contains(user.Content, fmt.Sprintf("message %d", j)) {
+				seen[j] = true
+			}
+		}
+	}
+	if len(seen) != n {
+		t.Fatalf("stored turns cover messages %v, want all %d", seen, n)
+	}
+}
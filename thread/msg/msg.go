@@ -1,12 +1,27 @@
 // Package msg defines the WakeMessage type shared between thread and tools.
 package msg
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/linanwx/nagobot/provider"
+)
 
 // Sink defines how thread output is delivered.
 type Sink struct {
 	Label string
 	Send  func(ctx context.Context, response string) error
+	// Stream optionally receives partial response text while the turn runs.
+	// Send is still called with the full response at the end.
+	Stream func(ctx context.Context, delta string) error
+	// Progress optionally receives progress lines from long-running tools.
+	// Only sinks that reach a user set it; Send stays reserved for results.
+	Progress func(ctx context.Context, line string) error
+	// Done, if set on a wake's own sink, is called once that wake's turn is
+	// over, after any Send, even when nothing was sent or the turn never ran.
+	// err is the turn's error, nil when it succeeded.
+	Done func(ctx context.Context, err error)
 }
 
 // IsZero reports whether the sink has no delivery function.
@@ -16,15 +31,28 @@ func (s Sink) IsZero() bool { return s.Send == nil }
 type ThreadInfo struct {
 	ID         string `json:"id"`
 	SessionKey string `json:"sessionKey"`
-	State      string `json:"state"`   // "running", "pending", "idle"
+	State      string `json:"state"` // "running", "pending", "idle", "failed", "cancelled"
 	Pending    int    `json:"pending"`
+	LastError  string `json:"lastError,omitempty"` // error of the most recent turn, if it failed
+}
+
+// Origin identifies the user and channel a wake message came from.
+type Origin struct {
+	Channel  string `json:"channel"`  // "telegram", "feishu", "web", "cli", etc.
+	UserID   string `json:"userID"`   // Channel-specific user identifier.
+	Username string `json:"username"` // Human-readable username, if known.
+	IsAdmin  bool   `json:"isAdmin"`  // Whether the user is the configured admin.
+	ReplyTo  string `json:"replyTo"`  // Channel-specific chat address replies are sent to.
 }
 
 // WakeMessage is an item in a thread's wake queue.
 type WakeMessage struct {
-	Source    string            // Wake source: "telegram", "cron", "child_completed", etc.
-	Message  string            // Wake payload text.
-	Sink     Sink              // Per-wake sink. Zero value = no per-wake delivery.
-	AgentName string           // Optional agent name override for this wake.
-	Vars     map[string]string // Optional vars override for this wake.
+	Source    string               // Wake source: "telegram", "cron", "child_completed", etc.
+	Message   string               // Wake payload text.
+	Sink      Sink                 // Per-wake sink. Zero value = no per-wake delivery.
+	AgentName string               // Optional agent name override for this wake.
+	Vars      map[string]string    // Optional vars override for this wake.
+	Origin    *Origin              // Optional user origin; nil for system or stateless wakes.
+	Images    []provider.ImagePart // Optional images sent with the message; not persisted in the session.
+	Timeout   time.Duration        // Optional limit for the turn; 0 = no limit.
 }
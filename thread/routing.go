@@ -0,0 +1,104 @@
+package thread
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+// Tier is a model routing tier.
+type Tier string
+
+const (
+	TierFast   Tier = "fast"
+	TierStrong Tier = "strong"
+)
+
+const defaultFastMaxChars = 80
+
+// defaultStrongKeywords escalate a turn to the strong tier regardless of length.
+var defaultStrongKeywords = []string{
+	"implement", "refactor", "debug", "analyze", "analyse", "explain", "design", "review",
+	"plan", "write", "fix", "code", "why",
+	"实现", "重构", "调试", "分析", "解释", "设计", "为什么", "怎么",
+}
+
+// Classifier decides which tier should serve a user message.
+type Classifier interface {
+	Classify(message string) Tier
+}
+
+// HeuristicClassifier routes short messages without task keywords to the fast
+// tier and everything else to the strong tier.
+type HeuristicClassifier struct {
+	MaxFastChars   int      // defaults to 80 runes
+	StrongKeywords []string // defaults to defaultStrongKeywords
+}
+
+// Classify implements Classifier.
+func (c *HeuristicClassifier) Classify(message string) Tier {
+	message = strings.TrimSpace(message)
+	maxChars := c.MaxFastChars
+	if maxChars <= 0 {
+		maxChars = defaultFastMaxChars
+	}
+	if utf8.RuneCountInString(message) > maxChars {
+		return TierStrong
+	}
+	if strings.Contains(message, "```") || strings.Count(message, "\n") >= 2 {
+		return TierStrong
+	}
+
+	keywords := c.StrongKeywords
+	if keywords == nil {
+		keywords = defaultStrongKeywords
+	}
+	lower := strings.ToLower(message)
+	for _, kw := range keywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" && strings.Contains(lower, kw) {
+			return TierStrong
+		}
+	}
+	return TierFast
+}
+
+// RouteTiers holds the providers serving each tier.
+type RouteTiers struct {
+	Fast        provider.Provider
+	FastModel   string
+	Strong      provider.Provider
+	StrongModel string
+}
+
+// Router selects a provider per turn using a pluggable Classifier.
+type Router struct {
+	Classifier Classifier
+	Default    RouteTiers
+	Agents     map[string]RouteTiers // agent name → tiers; missing tiers use Default
+}
+
+// Route returns the provider, tier and model name for a turn. A nil provider
+// means the caller should keep its own provider.
+func (r *Router) Route(agentName, message string) (provider.Provider, Tier, string) {
+	classifier := r.Classifier
+	if classifier == nil {
+		classifier = &HeuristicClassifier{}
+	}
+	tier := classifier.Classify(message)
+
+	tiers := r.Default
+	if override, ok := r.Agents[agentName]; ok {
+		if override.Fast != nil {
+			tiers.Fast, tiers.FastModel = override.Fast, override.FastModel
+		}
+		if override.Strong != nil {
+			tiers.Strong, tiers.StrongModel = override.Strong, override.StrongModel
+		}
+	}
+
+	if tier == TierFast && tiers.Fast != nil {
+		return tiers.Fast, TierFast, tiers.FastModel
+	}
+	return tiers.Strong, TierStrong, tiers.StrongModel
+}
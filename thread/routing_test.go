@@ -0,0 +1,152 @@
+package thread
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+func TestHeuristicClassifier(t *testing.T) {
+	c := &HeuristicClassifier{}
+	tests := []struct {
+		message string
+		want    Tier
+	}{
+		{"thanks!", TierFast},
+		{"ok 好的", TierFast},
+		{"please refactor the parser", TierStrong},
+		{"为什么会这样", TierStrong},
+		{"see this:\n```go\nfmt.Println()\n```", TierStrong},
+		{string(make([]rune, defaultFastMaxChars+1)), TierStrong},
+	}
+	for _, tt := range tests {
+		if got := c.Classify(tt.message); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+
+	custom := &HeuristicClassifier{MaxFastChars: 5, StrongKeywords: []string{"deploy"}}
+	if got := custom.Classify("hi"); got != TierFast {
+		t.Errorf("custom Classify(hi) = %q, want fast", got)
+	}
+	if got := custom.Classify("deploy"); got != TierStrong {
+		t.Errorf("custom Classify(deploy) = %q, want strong", got)
+	}
+	if got := custom.Classify("hello there"); got != TierStrong {
+		t.Errorf("custom Classify(long) = %q, want strong", got)
+	}
+}
+
+type fixedClassifier Tier
+
+func (c fixedClassifier) Classify(string) Tier { return Tier(c) }
+
+func TestRouterSelectsTierAndAgentOverride(t *testing.T) {
+	fast := &scriptedProvider{}
+	strong := &scriptedProvider{}
+	agentFast := &scriptedProvider{}
+	router := &Router{
+		Classifier: fixedClassifier(TierFast),
+		Default:    RouteTiers{Fast: fast, FastModel: "cheap", Strong: strong, StrongModel: "big"},
+		Agents: map[string]RouteTiers{
+			"coder": {Fast: agentFast, FastModel: "agent-cheap"},
+		},
+	}
+
+	if p, tier, model := router.Route("", "hi"); p != provider.Provider(fast) || tier != TierFast || model != "cheap" {
+		t.Fatalf("Route(default, fast) = %v/%q/%q, want fast/cheap", p, tier, model)
+	}
+	if p, _, model := router.Route("coder", "hi"); p != provider.Provider(agentFast) || model != "agent-cheap" {
+		t.Fatalf("Route(coder, fast) = %v/%q, want agent override", p, model)
+	}
+
+	router.Classifier = fixedClassifier(TierStrong)
+	if p, tier, model := router.Route("coder", "hi"); p != provider.Provider(strong) || tier != TierStrong || model != "big" {
+		t.Fatalf("Route(coder, strong) = %v/%q/%q, want default strong", p, tier, model)
+	}
+
+	router.Default.Fast = nil
+	router.Classifier = fixedClassifier(TierFast)
+	if p, tier, _ := router.Route("", "hi"); p != provider.Provider(strong) || tier != TierStrong {
+		t.Fatalf("Route() without fast tier = %v/%q, want strong", p, tier)
+	}
+}
+
+func TestRunOnceRoutesOnRawMessage(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	fast := &scriptedProvider{responses: []*provider.Response{{Content: "hey"}}}
+	strong := &scriptedProvider{}
+	mgr := NewManager(&ThreadConfig{
+		DefaultProvider: strong,
+		Sessions:        sessions,
+		Router: &Router{
+			Classifier: &HeuristicClassifier{},
+			Default:    RouteTiers{Fast: fast, FastModel: "cheap", Strong: strong, StrongModel: "big"},
+		},
+	})
+	th, err := mgr.NewThread("telegram:1", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	// The wake header alone is long enough to classify as strong.
+	th.Enqueue(&WakeMessage{Source: "telegram", Message: "hi"})
+	th.RunOnce(context.Background())
+
+	if len(fast.requests) != 1 || len(strong.requests) != 0 {
+		t.Fatalf("fast calls = %d, strong calls = %d, want the short message routed fast", len(fast.requests), len(strong.requests))
+	}
+}
+
+func TestRunOnceKeepsAgentProfileOverRouter(t *testing.T) {
+	workspace := t.TempDir()
+	agentsDir := filepath.Join(workspace, "agents")
+	if err := os.MkdirAll(agentsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: chat\nprofile: strong\n---\nYou are chat."
+	if err := os.WriteFile(filepath.Join(agentsDir, "chat.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+
+	pinned := &scriptedProvider{responses: []*provider.Response{{Content: "hey"}}}
+	fast := &scriptedProvider{}
+	mgr := NewManager(&ThreadConfig{
+		DefaultProvider: &scriptedProvider{},
+		Sessions:        sessions,
+		Agents:          agent.NewRegistry(workspace),
+		Profiles: func(string) (*ProviderProfile, error) {
+			return &ProviderProfile{Provider: pinned, ProviderName: "anthropic", ModelName: "claude-opus-4-6"}, nil
+		},
+		Router: &Router{
+			Classifier: fixedClassifier(TierFast),
+			Default:    RouteTiers{Fast: fast, FastModel: "cheap"},
+		},
+	})
+	th, err := mgr.NewThread("telegram:1", "chat")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	th.Enqueue(&WakeMessage{Source: "telegram", Message: "hi"})
+	th.RunOnce(context.Background())
+
+	if len(pinned.requests) != 1 || len(fast.requests) != 0 {
+		t.Fatalf("profile calls = %d, routed calls = %d, want the profile provider", len(pinned.requests), len(fast.requests))
+	}
+	if th.modelName != "claude-opus-4-6" {
+		t.Fatalf("modelName = %q, want the profile model", th.modelName)
+	}
+}
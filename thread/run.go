@@ -2,19 +2,23 @@ package thread
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
 	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/thread/msg"
 	"github.com/linanwx/nagobot/tools"
 )
 
 // run executes one thread turn. Called by RunOnce; callers must not invoke
-// this directly.
-func (t *Thread) run(ctx context.Context, userMessage string) (string, error) {
+// this directly. images are sent with this turn only and are not saved to the
+// session. routeText is the sender's own text, without the wake header, and
+// is what model routing classifies. origin may be nil for system or stateless wakes. stream, if set,
+// receives partial assistant text as it is generated.
+func (t *Thread) run(ctx context.Context, userMessage, routeText string, images []provider.ImagePart, origin *msg.Origin, stream func(ctx context.Context, delta string) error) (string, error) {
 	userMessage = strings.TrimSpace(userMessage)
 	if userMessage == "" {
 		return "", nil
@@ -26,12 +30,25 @@ func (t *Thread) run(ctx context.Context, userMessage string) (string, error) {
 	activeAgent := t.Agent
 	t.mu.Unlock()
 
-	skillsSection := t.buildSkillsSection()
+	sess := t.loadSession()
+
+	// Tools and skills follow the session's active workspace.
+	workspaceName, workspace := defaultWorkspaceName, cfg.Workspace
+	if t.mgr != nil {
+		workspaceName, workspace = t.mgr.resolveWorkspace(sess)
+	}
+	runTools := t.tools
+	var skillsSection string
+	if workspaceName == defaultWorkspaceName {
+		skillsSection = t.buildSkillsSection()
+	} else {
+		runTools, skillsSection = t.workspaceTools(workspace)
+	}
 
 	systemPrompt := ""
 	if activeAgent != nil {
 		activeAgent.Set("TIME", time.Now())
-		activeAgent.Set("TOOLS", t.tools.Names())
+		activeAgent.Set("TOOLS", runTools.Names())
 		activeAgent.Set("SKILLS", skillsSection)
 		systemPrompt = activeAgent.Build()
 	}
@@ -42,21 +59,35 @@ func (t *Thread) run(ctx context.Context, userMessage string) (string, error) {
 	messages := make([]provider.Message, 0, 2)
 	messages = append(messages, provider.SystemMessage(systemPrompt))
 
-	sess := t.loadSession()
 	if sess != nil {
 		messages = append(messages, sess.Messages...)
 	}
 
 	turnUserMessages := make([]provider.Message, 0, 4)
 	userMsg := provider.UserMessage(userMessage)
-	messages = append(messages, userMsg)
 	turnUserMessages = append(turnUserMessages, userMsg)
+	userMsg.Images = images
+	messages = append(messages, userMsg)
 
+	estimator := session.TokenEstimatorForModel(t.modelName)
 	sessionEstimatedTokens := 0
 	if sess != nil {
-		sessionEstimatedTokens = estimateMessagesTokens(sess.Messages)
+		sessionEstimatedTokens = session.EstimateMessagesTokensWith(estimator, sess.Messages)
+	}
+	requestEstimatedTokens := session.EstimateMessagesTokensWith(estimator, messages)
+	if t.autoCompact(ctx, sess, requestEstimatedTokens) {
+		messages = append(messages[:1], sess.Messages...)
+		messages = append(messages, userMsg)
+		sessionEstimatedTokens = session.EstimateMessagesTokensWith(estimator, sess.Messages)
+		requestEstimatedTokens = session.EstimateMessagesTokensWith(estimator, messages)
+	}
+	if sess != nil {
+		if history := t.trimHistory(sess.Messages); len(history) < len(sess.Messages) {
+			messages = append(messages[:1], history...)
+			messages = append(messages, userMsg)
+			requestEstimatedTokens = session.EstimateMessagesTokensWith(estimator, messages)
+		}
 	}
-	requestEstimatedTokens := estimateMessagesTokens(messages)
 	contextWindowTokens, contextWarnRatio := t.contextBudget()
 	logger.Debug(
 		"context estimate",
@@ -68,11 +99,9 @@ func (t *Thread) run(ctx context.Context, userMessage string) (string, error) {
 		"contextWarnRatio", contextWarnRatio,
 	)
 
-	sessionPath, _ := t.sessionFilePath()
 	hookInjections := t.runHooks(turnContext{
 		ThreadID:               t.id,
 		SessionKey:             t.sessionKey,
-		SessionPath:            sessionPath,
 		UserMessage:            userMessage,
 		SessionEstimatedTokens: sessionEstimatedTokens,
 		RequestEstimatedTokens: requestEstimatedTokens,
@@ -91,35 +120,88 @@ func (t *Thread) run(ctx context.Context, userMessage string) (string, error) {
 
 	runCtx := tools.WithRuntimeContext(ctx, tools.RuntimeContext{
 		SessionKey: t.sessionKey,
-		Workspace:  cfg.Workspace,
+		Workspace:  workspace,
+		Origin:     origin,
 	})
-	runner := NewRunner(t.provider, t.tools)
+	runProvider := t.provider
+	// An agent pinned to a provider profile keeps it; routing would swap the
+	// model out from under the budget and health figures derived from it.
+	if cfg.Router != nil && t.profile == "" {
+		agentName := ""
+		if activeAgent != nil {
+			agentName = activeAgent.Name
+		}
+		routed, tier, model := cfg.Router.Route(agentName, routeText)
+		if routed != nil {
+			runProvider = routed
+		}
+		logger.Info(
+			"turn routed",
+			"threadID", t.id,
+			"sessionKey", t.sessionKey,
+			"agent", agentName,
+			"tier", tier,
+			"model", model,
+		)
+	}
+	runner := NewRunner(runProvider, runTools)
+	if cfg.OnRunnerEvent != nil {
+		runner.OnEvent(func(ev RunnerEvent) { cfg.OnRunnerEvent(t.sessionKey, ev) })
+	}
+	if stream != nil {
+		runner.OnDelta(func(delta string) {
+			if err := stream(ctx, delta); err != nil {
+				logger.Debug("stream delivery error", "threadID", t.id, "sessionKey", t.sessionKey, "err", err)
+			}
+		})
+	}
 	response, err := runner.RunWithMessages(runCtx, messages)
 	if err != nil {
+		// A turn cut short by its deadline or by shutdown keeps the work it finished.
+		if sess != nil && ctx.Err() != nil {
+			note := interruptedNote
+			if errors.Is(ctx.Err(), context.Canceled) {
+				note = shutdownNote
+			}
+			partial := append(append([]provider.Message(nil), turnUserMessages...), runner.Progress()...)
+			partial = append(partial, provider.AssistantMessage(note))
+			t.saveTurn(partial, runner.Usage())
+		}
 		return "", err
 	}
 
 	if sess != nil {
-		latestSession, reloadErr := t.reloadSessionForSave()
-		if reloadErr != nil {
-			logger.Warn(
-				"failed to reload session before save; skipping save to avoid overwriting external changes",
-				"key", t.sessionKey,
-				"err", reloadErr,
-			)
-		} else {
-			latestSession.Messages = append(latestSession.Messages, turnUserMessages...)
-			latestSession.Messages = append(latestSession.Messages, provider.AssistantMessage(response))
-
-			if saveErr := cfg.Sessions.Save(latestSession); saveErr != nil {
-				logger.Warn("failed to save session", "key", t.sessionKey, "err", saveErr)
-			}
-		}
+		t.saveTurn(append(turnUserMessages, provider.AssistantMessage(response)), runner.Usage())
 	}
 
 	return response, nil
 }
 
+// interruptedNote closes the session record of a turn that hit its deadline.
+const interruptedNote = "[Turn interrupted: time limit reached before a final answer]"
+
+// shutdownNote closes the session record of a turn cancelled by shutdown.
+const shutdownNote = "[Turn interrupted: service shut down before a final answer]"
+
+// saveTurn appends a turn's messages to the latest stored session. The
+// append runs under the session's lock so a concurrent reset or workspace
+// switch is neither lost nor undone.
+func (t *Thread) saveTurn(turn []provider.Message, usage provider.Usage) {
+	cfg := t.cfg()
+	if cfg.Sessions == nil || strings.TrimSpace(t.sessionKey) == "" {
+		logger.Warn("session manager unavailable; skipping save", "key", t.sessionKey)
+		return
+	}
+	_, err := cfg.Sessions.Update(t.sessionKey, func(latest *session.Session) error {
+		latest.Messages = append(latest.Messages, turn...)
+		latest.Usage.Add(usage)
+		return nil
+	})
+	if err != nil {
+		logger.Warn("failed to save session", "key", t.sessionKey, "err", err)
+	}
+}
+
 func (t *Thread) buildTools() *tools.Registry {
 	cfg := t.cfg()
 	reg := tools.NewRegistry()
@@ -131,9 +213,10 @@ func (t *Thread) buildTools() *tools.Registry {
 		Workspace:    cfg.Workspace,
 		SessionsRoot: cfg.SessionsDir,
 		SkillsRoot:   cfg.SkillsDir,
-		ProviderName: cfg.ProviderName,
-		ModelName:    cfg.ModelName,
+		ProviderName: t.providerName,
+		ModelName:    t.modelName,
 		Channels:     cfg.HealthChannels,
+		DroppedFn:    cfg.ChannelDroppedFn,
 		ThreadsListFn: func() []tools.ThreadInfo {
 			return t.mgr.ListThreads()
 		},
@@ -150,11 +233,13 @@ func (t *Thread) buildTools() *tools.Registry {
 				AgentName:   agentName,
 				SessionKey:  t.sessionKey,
 				SessionFile: sessionPath,
+				Session:     t.sessionHealth(sessionPath),
 			}
 		},
 	})
 
 	reg.Register(tools.NewSpawnThreadTool(t))
+	reg.Register(tools.NewCancelThreadTool(t))
 
 	return reg
 }
@@ -173,21 +258,13 @@ func (t *Thread) loadSession() *session.Session {
 	return loadedSession
 }
 
-func (t *Thread) reloadSessionForSave() (*session.Session, error) {
-	cfg := t.cfg()
-	if cfg.Sessions == nil || strings.TrimSpace(t.sessionKey) == "" {
-		return nil, fmt.Errorf("session manager unavailable")
-	}
-	return cfg.Sessions.Reload(t.sessionKey)
-}
-
 func (t *Thread) buildSkillsSection() string {
 	cfg := t.cfg()
 	if cfg.Skills == nil || strings.TrimSpace(cfg.SkillsDir) == "" {
 		return ""
 	}
 
-	if err := cfg.Skills.ReloadFromDirectory(cfg.SkillsDir); err != nil {
+	if err := cfg.Skills.Refresh(cfg.SkillsDir); err != nil {
 		logger.Warn("failed to reload skills", "dir", cfg.SkillsDir, "err", err)
 	}
 	return cfg.Skills.BuildPromptSection()
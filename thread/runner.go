@@ -5,16 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
 	"github.com/linanwx/nagobot/tools"
 )
 
+// eventResultMaxChars bounds the tool result carried by a RunnerEvent.
+const eventResultMaxChars = 500
+
+// Runner event kinds.
+const (
+	EventToolCalled    = "tool_called"
+	EventToolCompleted = "tool_completed"
+	EventAgentError    = "agent_error"
+)
+
+// RunnerEvent reports a step of the agent loop to observers such as metrics
+// or a UI.
+type RunnerEvent struct {
+	Kind      string        // EventToolCalled, EventToolCompleted or EventAgentError
+	Tool      string        // tool name, empty for EventAgentError
+	CallID    string        // tool call ID, empty for EventAgentError
+	Arguments string        // raw JSON arguments, for tool events
+	Result    string        // tool result truncated to eventResultMaxChars, for EventToolCompleted
+	IsError   bool          // the tool returned an "Error:" result
+	Duration  time.Duration // tool run time, for EventToolCompleted
+	Err       error         // provider failure, for EventAgentError
+}
+
 // Runner is a generic agent loop executor.
 type Runner struct {
 	provider provider.Provider
 	tools    *tools.Registry
+	usage    provider.Usage
+	onDelta  func(delta string)
+	onEvent  func(RunnerEvent)
+	progress []provider.Message // tool-call rounds completed by the last run
 }
 
 // NewRunner creates a new Runner.
@@ -25,31 +54,159 @@ func NewRunner(p provider.Provider, t *tools.Registry) *Runner {
 	}
 }
 
+// OnDelta sets a callback that receives partial assistant text. It only takes
+// effect when the provider implements provider.StreamingProvider.
+func (r *Runner) OnDelta(fn func(delta string)) {
+	r.onDelta = fn
+}
+
+// OnEvent sets a callback that receives tool-call and error events. It is
+// called synchronously from the loop, so it should return quickly.
+func (r *Runner) OnEvent(fn func(RunnerEvent)) {
+	r.onEvent = fn
+}
+
+func (r *Runner) emit(ev RunnerEvent) {
+	if r.onEvent != nil {
+		r.onEvent(ev)
+	}
+}
+
 // RunWithMessages executes the agent loop with pre-built messages.
 func (r *Runner) RunWithMessages(ctx context.Context, messages []provider.Message) (string, error) {
+	r.progress = nil
 	toolDefs := r.tools.Defs()
+	seenToolCallIDs := make(map[string]bool)
+	for _, m := range messages {
+		for _, tc := range m.ToolCalls {
+			seenToolCallIDs[tc.ID] = true
+		}
+	}
 
 	for {
-		resp, err := r.provider.Chat(ctx, &provider.Request{
+		resp, err := r.chat(ctx, &provider.Request{
 			Messages: messages,
 			Tools:    toolDefs,
 		})
 		if err != nil {
-			return "", fmt.Errorf("provider error: %w", err)
+			err = fmt.Errorf("provider error: %w", err)
+			r.emit(RunnerEvent{Kind: EventAgentError, Err: err})
+			return "", err
 		}
+		r.usage.Add(resp.Usage)
 
 		if !resp.HasToolCalls() {
 			return resp.Content, nil
 		}
 
-		messages = append(messages, provider.AssistantMessageWithTools(resp.Content, resp.ReasoningContent, resp.ToolCalls))
+		resp.ToolCalls = normalizeToolCallIDs(resp.ToolCalls, seenToolCallIDs)
+		round := []provider.Message{provider.AssistantMessageWithTools(resp.Content, resp.ReasoningContent, resp.ToolCalls)}
 
 		for _, tc := range resp.ToolCalls {
+			r.emit(RunnerEvent{Kind: EventToolCalled, Tool: tc.Function.Name, CallID: tc.ID, Arguments: tc.Function.Arguments})
+			start := time.Now()
 			result := r.tools.Run(ctx, tc.Function.Name, json.RawMessage(tc.Function.Arguments))
-			if strings.HasPrefix(result, "Error:") {
+			isError := strings.HasPrefix(result, "Error:")
+			if isError {
 				logger.Error("tool error", "tool", tc.Function.Name, "err", result)
 			}
-			messages = append(messages, provider.ToolResultMessage(tc.ID, tc.Function.Name, result))
+			r.emit(RunnerEvent{
+				Kind:      EventToolCompleted,
+				Tool:      tc.Function.Name,
+				CallID:    tc.ID,
+				Arguments: tc.Function.Arguments,
+				Result:    truncateEventResult(result),
+				IsError:   isError,
+				Duration:  time.Since(start),
+			})
+			round = append(round, provider.ToolResultMessage(tc.ID, tc.Function.Name, result))
+		}
+		messages = append(messages, round...)
+		r.progress = append(r.progress, round...)
+	}
+}
+
+func truncateEventResult(s string) string {
+	if len(s) <= eventResultMaxChars {
+		return s
+	}
+	n := eventResultMaxChars
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// Progress returns the assistant tool calls and their results from completed
+// rounds of the last run, so an interrupted run can still be recorded.
+func (r *Runner) Progress() []provider.Message {
+	return r.progress
+}
+
+// chat performs one provider call, streaming when both the provider and the
+// runner support it.
+func (r *Runner) chat(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	sp, ok := r.provider.(provider.StreamingProvider)
+	if !ok || r.onDelta == nil {
+		return r.provider.Chat(ctx, req)
+	}
+
+	var streamed strings.Builder
+	resp, err := sp.ChatStream(ctx, req, func(delta string) {
+		if delta == "" {
+			return
+		}
+		streamed.WriteString(delta)
+		r.onDelta(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Keep the full text even if the provider only reported it as deltas.
+	if resp.Content == "" {
+		resp.Content = streamed.String()
+	}
+	return resp, nil
+}
+
+// Usage returns the token usage accumulated across all provider calls made by
+// this runner.
+func (r *Runner) Usage() provider.Usage {
+	return r.usage
+}
+
+// normalizeToolCallIDs replaces empty or duplicate tool call IDs with synthetic
+// unique ones. The same slice is used for both the assistant message and the
+// tool results, so pairing stays consistent. seen is updated in place.
+func normalizeToolCallIDs(calls []provider.ToolCall, seen map[string]bool) []provider.ToolCall {
+	out := make([]provider.ToolCall, len(calls))
+	for i, tc := range calls {
+		id := strings.TrimSpace(tc.ID)
+		if id == "" || seen[id] {
+			repaired := syntheticToolCallID(seen)
+			logger.Warn(
+				"repaired invalid tool call id",
+				"tool", tc.Function.Name,
+				"originalID", tc.ID,
+				"newID", repaired,
+			)
+			id = repaired
+		}
+		seen[id] = true
+		tc.ID = id
+		out[i] = tc
+	}
+	return out
+}
+
+func syntheticToolCallID(seen map[string]bool) string {
+	for i := len(seen); ; i++ {
+		id := fmt.Sprintf("call_nagobot_%d", i)
+		if suffix := RandomHex(4); suffix != "" {
+			id += "_" + suffix
+		}
+		if !seen[id] {
+			return id
 		}
 	}
 }
@@ -0,0 +1,228 @@
+package thread
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/tools"
+)
+
+type scriptedProvider struct {
+	responses []*provider.Response
+	requests  []*provider.Request
+}
+
+func (p *scriptedProvider) Chat(_ context.Context, req *provider.Request) (*provider.Response, error) {
+	copied := *req
+	copied.Messages = append([]provider.Message(nil), req.Messages...)
+	p.requests = append(p.requests, &copied)
+	resp := p.responses[0]
+	p.responses = p.responses[1:]
+	return resp, nil
+}
+
+type echoTool struct{}
+
+func (echoTool) Def() provider.ToolDef {
+	return provider.ToolDef{Type: "function", Function: provider.FunctionDef{Name: "echo"}}
+}
+
+func (echoTool) Run(_ context.Context, args json.RawMessage) string { return string(args) }
+
+func TestRunnerRepairsDuplicateToolCallIDs(t *testing.T) {
+	call := func(id, args string) provider.ToolCall {
+		return provider.ToolCall{ID: id, Type: "function", Function: provider.FunctionCall{Name: "echo", Arguments: args}}
+	}
+	prov := &scriptedProvider{responses: []*provider.Response{
+		{ToolCalls: []provider.ToolCall{call("dup", `"a"`), call("dup", `"b"`), call("", `"c"`)}},
+		{Content: "done"},
+	}}
+	reg := tools.NewRegistry()
+	reg.Register(echoTool{})
+
+	out, err := NewRunner(prov, reg).RunWithMessages(context.Background(), []provider.Message{provider.UserMessage("go")})
+	if err != nil {
+		t.Fatalf("RunWithMessages() error = %v", err)
+	}
+	if out != "done" {
+		t.Fatalf("RunWithMessages() = %q, want done", out)
+	}
+
+	second := prov.requests[1].Messages
+	if len(second) != 5 {
+		t.Fatalf("second request has %d messages, want 5", len(second))
+	}
+	assistant := second[1]
+	seen := make(map[string]bool)
+	for i, tc := range assistant.ToolCalls {
+		if tc.ID == "" || seen[tc.ID] {
+			t.Fatalf("tool call %d has empty or duplicate id %q", i, tc.ID)
+		}
+		seen[tc.ID] = true
+		result := second[2+i]
+		if result.Role != "tool" || result.ToolCallID != tc.ID {
+			t.Fatalf("tool result %d paired with %q, want %q", i, result.ToolCallID, tc.ID)
+		}
+	}
+	if assistant.ToolCalls[0].ID != "dup" {
+		t.Fatalf("first valid id should be kept, got %q", assistant.ToolCalls[0].ID)
+	}
+}
+
+func TestRunnerAccumulatesUsageAcrossCalls(t *testing.T) {
+	prov := &scriptedProvider{responses: []*provider.Response{
+		{
+			ToolCalls: []provider.ToolCall{{ID: "c1", Type: "function", Function: provider.FunctionCall{Name: "echo", Arguments: `"x"`}}},
+			Usage:     provider.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+		},
+		{Content: "done", Usage: provider.Usage{PromptTokens: 120, CompletionTokens: 5}},
+	}}
+	reg := tools.NewRegistry()
+	reg.Register(echoTool{})
+
+	runner := NewRunner(prov, reg)
+	if _, err := runner.RunWithMessages(context.Background(), []provider.Message{provider.UserMessage("go")}); err != nil {
+		t.Fatalf("RunWithMessages() error = %v", err)
+	}
+	want := provider.Usage{PromptTokens: 220, CompletionTokens: 15, TotalTokens: 235}
+	if got := runner.Usage(); got != want {
+		t.Fatalf("Usage() = %+v, want %+v", got, want)
+	}
+}
+
+type streamingProvider struct {
+	scriptedProvider
+	deltas [][]string
+}
+
+func (p *streamingProvider) ChatStream(ctx context.Context, req *provider.Request, onDelta func(string)) (*provider.Response, error) {
+	chunks := p.deltas[0]
+	p.deltas = p.deltas[1:]
+	for _, d := range chunks {
+		onDelta(d)
+	}
+	return p.Chat(ctx, req)
+}
+
+func TestRunStreamsDeltasAndSavesFullText(t *testing.T) {
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	prov := &streamingProvider{
+		scriptedProvider: scriptedProvider{responses: []*provider.Response{{}}},
+		deltas:           [][]string{{"Hel", "lo, ", "world"}},
+	}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov, Sessions: sessions})
+	th, err := mgr.NewThread("web:work", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	var got []string
+	out, err := th.run(context.Background(), "hi", "hi", nil, nil, func(_ context.Context, delta string) error {
+		got = append(got, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if strings.Join(got, "|") != "Hel|lo, |world" {
+		t.Fatalf("streamed deltas = %q", got)
+	}
+	if out != "Hello, world" {
+		t.Fatalf("run() = %q, want the concatenated deltas", out)
+	}
+
+	sess, err := sessions.Reload("web:work")
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	last := sess.Messages[len(sess.Messages)-1]
+	if last.Role != "assistant" || last.Content != "Hello, world" {
+		t.Fatalf("saved message = %+v, want full assistant text", last)
+	}
+}
+
+func TestRunnerWithoutDeltaHandlerUsesChat(t *testing.T) {
+	prov := &streamingProvider{scriptedProvider: scriptedProvider{responses: []*provider.Response{{Content: "plain"}}}}
+	out, err := NewRunner(prov, tools.NewRegistry()).RunWithMessages(context.Background(), []provider.Message{provider.UserMessage("go")})
+	if err != nil {
+		t.Fatalf("RunWithMessages() error = %v", err)
+	}
+	if out != "plain" {
+		t.Fatalf("RunWithMessages() = %q, want plain", out)
+	}
+}
+
+type failAfterProvider struct {
+	scriptedProvider
+}
+
+func (p *failAfterProvider) Chat(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	if len(p.responses) == 0 {
+		return nil, errors.New("upstream unavailable")
+	}
+	return p.scriptedProvider.Chat(ctx, req)
+}
+
+func TestRunnerEmitsToolAndErrorEvents(t *testing.T) {
+	args := `"` + strings.Repeat("x", eventResultMaxChars) + `"`
+	prov := &failAfterProvider{scriptedProvider{responses: []*provider.Response{
+		{ToolCalls: []provider.ToolCall{{ID: "c1", Type: "function", Function: provider.FunctionCall{Name: "echo", Arguments: args}}}},
+	}}}
+	reg := tools.NewRegistry()
+	reg.Register(echoTool{})
+
+	var events []RunnerEvent
+	runner := NewRunner(prov, reg)
+	runner.OnEvent(func(ev RunnerEvent) { events = append(events, ev) })
+	if _, err := runner.RunWithMessages(context.Background(), []provider.Message{provider.UserMessage("go")}); err == nil {
+		t.Fatal("RunWithMessages() error = nil, want provider error")
+	}
+
+	var kinds []string
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []string{EventToolCalled, EventToolCompleted, EventAgentError}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("event kinds = %v, want %v", kinds, want)
+	}
+	called, completed := events[0], events[1]
+	if called.Tool != "echo" || called.CallID != "c1" || called.Arguments != args {
+		t.Fatalf("tool_called event = %+v", called)
+	}
+	if completed.IsError || !strings.HasSuffix(completed.Result, "…") || len(completed.Result) > eventResultMaxChars+len("…") {
+		t.Fatalf("tool_completed result not truncated: %d bytes", len(completed.Result))
+	}
+	if events[2].Err == nil || !strings.Contains(events[2].Err.Error(), "upstream unavailable") {
+		t.Fatalf("agent_error event err = %v", events[2].Err)
+	}
+}
+
+func TestRunOnceReportsTurnOutcomeToDone(t *testing.T) {
+	prov := &failAfterProvider{scriptedProvider{responses: []*provider.Response{{Content: "ok"}}}}
+	mgr := NewManager(&ThreadConfig{DefaultProvider: prov})
+	th, err := mgr.NewThread("cron:daily", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	var outcomes []error
+	// No Send, as for a silent cron job: Done is still called.
+	sink := Sink{Done: func(_ context.Context, err error) { outcomes = append(outcomes, err) }}
+	for i := 0; i < 2; i++ {
+		th.Enqueue(&WakeMessage{Source: "cron", Message: "run", Sink: sink})
+		th.RunOnce(context.Background())
+	}
+
+	if len(outcomes) != 2 || outcomes[0] != nil || outcomes[1] == nil {
+		t.Fatalf("outcomes = %v, want success then the provider error", outcomes)
+	}
+}
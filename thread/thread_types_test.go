@@ -1,7 +1,15 @@
 package thread
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
 )
 
 func TestNewThread(t *testing.T) {
@@ -59,3 +67,138 @@ func TestThreadSet(t *testing.T) {
 		t.Fatal("agent should be initialized")
 	}
 }
+
+func TestContextBudgetUsesModelWindow(t *testing.T) {
+	cases := []struct {
+		cfg  ThreadConfig
+		want int
+	}{
+		{ThreadConfig{ModelName: "kimi-k2.5", ContextWindowTokens: 128000}, 262144},
+		{ThreadConfig{ModelName: "unknown-model", ContextWindowTokens: 64000}, 64000},
+		{ThreadConfig{ModelName: "kimi-k2.5", ContextWindowTokens: 128000, ModelContextWindows: map[string]int{"kimi-k2.5": 50000}}, 50000},
+	}
+	for _, tc := range cases {
+		cfg := tc.cfg
+		th, err := NewManager(&cfg).NewThread("test:budget", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := th.contextBudget(); got != tc.want {
+			t.Errorf("contextBudget() for %q = %d, want %d", cfg.ModelName, got, tc.want)
+		}
+	}
+}
+
+func TestTrimHistoryCountsWithModelEstimator(t *testing.T) {
+	history := []provider.Message{
+		provider.UserMessage(strings.Repeat("你好世界", 200)),
+		provider.AssistantMessage("ok"),
+		provider.UserMessage("next"),
+	}
+	window := session.EstimateMessagesTokens(history)
+	for model, want := range map[string]int{"gpt-4o": 3, "claude-opus-4-6": 2} {
+		th, err := NewManager(&ThreadConfig{
+			ModelName:           model,
+			ModelContextWindows: map[string]int{model: window},
+			HistoryTokenRatio:   1,
+		}).NewThread("test:trim", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := th.trimHistory(history); len(got) != want {
+			t.Errorf("trimHistory() for %q kept %d messages, want %d", model, len(got), want)
+		}
+	}
+}
+
+func TestNewThreadUsesAgentProviderProfile(t *testing.T) {
+	workspace := t.TempDir()
+	agentsDir := filepath.Join(workspace, "agents")
+	if err := os.MkdirAll(agentsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, profile := range map[string]string{"chat": "strong", "cron": "cheap", "plain": ""} {
+		content := "---\nname: " + name + "\nprofile: " + profile + "\n---\nYou are " + name + "."
+		if err := os.WriteFile(filepath.Join(agentsDir, name+".md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defaultProvider := &scriptedProvider{}
+	profiles := map[string]*ProviderProfile{
+		"strong": {Provider: &scriptedProvider{}, ProviderName: "anthropic", ModelName: "claude-opus-4-6"},
+		"cheap":  {Provider: &scriptedProvider{}, ProviderName: "deepseek", ModelName: "deepseek-chat"},
+	}
+	mgr := NewManager(&ThreadConfig{
+		DefaultProvider: defaultProvider,
+		ProviderName:    "deepseek",
+		ModelName:       "deepseek-reasoner",
+		Agents:          agent.NewRegistry(workspace),
+		Profiles: func(name string) (*ProviderProfile, error) {
+			if p, ok := profiles[name]; ok {
+				return p, nil
+			}
+			return nil, fmt.Errorf("unknown profile %q", name)
+		},
+	})
+
+	chat, err := mgr.NewThread("test:chat", "chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, err := mgr.NewThread("test:cron", "cron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := mgr.NewThread("test:plain", "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chat.provider != profiles["strong"].Provider || chat.modelName != "claude-opus-4-6" {
+		t.Fatalf("chat thread uses %p/%s, want the strong profile", chat.provider, chat.modelName)
+	}
+	if cron.provider != profiles["cheap"].Provider || cron.providerName != "deepseek" || cron.modelName != "deepseek-chat" {
+		t.Fatalf("cron thread uses %p/%s/%s, want the cheap profile", cron.provider, cron.providerName, cron.modelName)
+	}
+	if chat.provider == cron.provider {
+		t.Fatal("agents with different profiles share a provider")
+	}
+	if plain.provider != defaultProvider || plain.modelName != "deepseek-reasoner" {
+		t.Fatalf("plain thread uses %p/%s, want the default provider", plain.provider, plain.modelName)
+	}
+}
+
+func TestSessionDiagnosticsWithSQLiteStore(t *testing.T) {
+	store, err := session.NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer store.Close()
+	sessions := session.NewManagerWithStore(t.TempDir(), store)
+	if err := sessions.Save(&session.Session{Key: "telegram:1", Messages: []provider.Message{provider.UserMessage("hi")}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	mgr := NewManager(&ThreadConfig{Sessions: sessions})
+	th, err := mgr.NewThread("telegram:1", "")
+	if err != nil {
+		t.Fatalf("NewThread() error = %v", err)
+	}
+
+	if path, ok := th.sessionFilePath(); ok || path != "" {
+		t.Fatalf("sessionFilePath() = %q, %v; want none for a non-file store", path, ok)
+	}
+	if info := th.sessionHealth(""); info == nil || !info.Exists || info.MessagesCount != 1 {
+		t.Fatalf("sessionHealth() = %+v, want the stored session", info)
+	}
+
+	notices := th.contextPressureHook()(turnContext{
+		SessionKey:             "telegram:1",
+		RequestEstimatedTokens: 900,
+		ContextWindowTokens:    1000,
+		ContextWarnRatio:       0.8,
+	})
+	if len(notices) != 1 || !strings.Contains(notices[0], "session_key: telegram:1") {
+		t.Fatalf("pressure notices = %q, want one naming the session key", notices)
+	}
+}
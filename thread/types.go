@@ -18,6 +18,9 @@ type Sink = msg.Sink
 // WakeMessage is an alias for msg.WakeMessage.
 type WakeMessage = msg.WakeMessage
 
+// Origin is an alias for msg.Origin.
+type Origin = msg.Origin
+
 // threadState represents the runtime state of a thread.
 type threadState int
 
@@ -31,6 +34,12 @@ const (
 	defaultInboxSize      = 64
 	defaultThreadTTL      = 30 * time.Minute
 	gcInterval            = 5 * time.Minute
+
+	defaultChildWaitTimeout   = 5 * time.Minute  // child run limit when the parent waits
+	defaultChildMaxLifetime   = 30 * time.Minute // child run limit when the parent does not wait
+	childWaitGrace            = 5 * time.Second
+	defaultMaxActiveChildren  = 8
+	defaultMaxSpawnedChildren = 100
 )
 
 // ThreadConfig contains shared dependencies for creating threads.
@@ -42,13 +51,32 @@ type ThreadConfig struct {
 	Skills              *skills.Registry
 	Agents              *agent.AgentRegistry
 	Workspace           string
+	Workspaces          map[string]string // named workspaces (name → path), including the default
 	SkillsDir           string
 	SessionsDir         string
-	ContextWindowTokens int
+	ContextWindowTokens int            // fallback when the model's window is unknown
+	ModelContextWindows map[string]int // per-model overrides of the built-in context window table
 	ContextWarnRatio    float64
+	HistoryTokenRatio   float64 // share of the context window session history may fill, 0 = no trimming
+	AutoCompact         bool    // summarize older session turns when the warn ratio is reached
+	CompactKeepRecent   int     // user turns kept verbatim by auto-compaction, 0 = default (4)
+	MaxActiveChildren   int     // unfinished children per thread, 0 = default (8)
+	MaxSpawnedChildren  int     // children a thread may spawn in its lifetime, 0 = default (100)
+	ToolProgress        bool    // forward progress from long-running tools to the wake sink's Progress
 	Sessions            *session.Manager
 	DefaultSinkFor      func(sessionKey string) Sink
 	HealthChannels      *tools.HealthChannelsInfo
+	ChannelDroppedFn    func() map[string]int64
+	Router              *Router                                     // optional per-turn model routing; nil uses DefaultProvider; skipped for threads on an agent profile
+	Profiles            func(name string) (*ProviderProfile, error) // resolves agent provider profiles; nil runs every agent on DefaultProvider
+	OnRunnerEvent       func(sessionKey string, ev RunnerEvent)     // optional observer of tool calls and provider errors in every turn
+}
+
+// ProviderProfile is a provider resolved from a named config profile.
+type ProviderProfile struct {
+	Provider     provider.Provider
+	ProviderName string
+	ModelName    string
 }
 
 // Thread is a single execution unit with an agent, wake queue, and optional session.
@@ -57,9 +85,12 @@ type Thread struct {
 	mgr *Manager
 	*agent.Agent
 
-	sessionKey string
-	provider   provider.Provider
-	tools      *tools.Registry
+	sessionKey   string
+	provider     provider.Provider
+	providerName string // provider and model behind provider, for budgets and health
+	modelName    string
+	profile      string // agent provider profile in use; such threads are not routed
+	tools        *tools.Registry
 
 	// State machine fields.
 	state  threadState
@@ -70,6 +101,13 @@ type Thread struct {
 	hooks        []turnHook
 	defaultSink  Sink      // Fallback sink when WakeMessage.Sink is nil.
 	lastActiveAt time.Time // Last time this thread completed work.
+	lastErr      string    // Error of the most recent turn; empty if it succeeded.
+
+	parent    *Thread            // Thread that spawned this one, if any.
+	children  map[string]*Thread // Unfinished children spawned by this thread, by ID.
+	spawned   int                // Children spawned over the thread's lifetime.
+	cancelRun func()             // Cancels the turn in progress, if any.
+	cancelled bool               // Set by cancel; later wakes are dropped.
 }
 
 // cfg returns the shared config from the manager.
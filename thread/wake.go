@@ -2,11 +2,14 @@ package thread
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/tools"
 )
 
 // Enqueue adds a wake message to the thread's inbox and notifies the manager.
@@ -31,6 +34,23 @@ func (t *Thread) hasMessages() bool {
 func (t *Thread) RunOnce(ctx context.Context) {
 	select {
 	case msg := <-t.inbox:
+		var turnErr error
+		if done := msg.Sink.Done; done != nil {
+			defer func() { done(ctx, turnErr) }()
+		}
+		if msg.Source == "child_task" {
+			t.mu.Lock()
+			parent := t.parent
+			t.mu.Unlock()
+			if parent != nil {
+				defer parent.childFinished(t.id)
+			}
+		}
+		if t.isCancelled() {
+			logger.Debug("dropping wake for cancelled thread", "threadID", t.id, "source", msg.Source)
+			turnErr = context.Canceled
+			return
+		}
 		if name := strings.TrimSpace(msg.AgentName); name != "" {
 			a, err := t.cfg().Agents.New(name)
 			if err != nil {
@@ -60,11 +80,47 @@ func (t *Thread) RunOnce(ctx context.Context) {
 		}
 
 		userMessage := buildWakePayload(msg.Source, msg.Message, t.id, t.sessionKey, deliveryLabel)
-		response, err := t.run(ctx, userMessage)
+		runCtx, cancelRun := context.WithCancel(ctx)
+		defer cancelRun()
+		t.mu.Lock()
+		t.cancelRun = cancelRun
+		t.mu.Unlock()
+		if msg.Timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(runCtx, msg.Timeout)
+			defer cancel()
+		}
+		if t.cfg().ToolProgress && sink.Progress != nil {
+			runCtx = tools.WithProgress(runCtx, func(line string) {
+				if sinkErr := sink.Progress(ctx, line); sinkErr != nil {
+					logger.Warn("tool progress delivery error", "threadID", t.id, "sessionKey", t.sessionKey, "err", sinkErr)
+				}
+			})
+		}
+		response, err := t.run(runCtx, userMessage, msg.Message, msg.Images, msg.Origin, sink.Stream)
+		if err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = &TimeoutError{After: msg.Timeout}
+		}
+		t.mu.Lock()
+		t.cancelRun = nil
+		t.mu.Unlock()
+		turnErr = err
+		if t.isCancelled() {
+			// Whoever cancelled already knows; don't deliver a result.
+			logger.Info("thread run cancelled", "threadID", t.id, "sessionKey", t.sessionKey, "source", msg.Source)
+			turnErr = context.Canceled
+			return
+		}
 		if err != nil {
 			logger.Error("thread run error", "threadID", t.id, "sessionKey", t.sessionKey, "source", msg.Source, "err", err)
-			response = fmt.Sprintf("[Error] %v", err)
+			response = formatRunError(err)
+		}
+		t.mu.Lock()
+		t.lastErr = ""
+		if err != nil {
+			t.lastErr = err.Error()
 		}
+		t.mu.Unlock()
 
 		if !sink.IsZero() && strings.TrimSpace(response) != "" {
 			if sinkErr := sink.Send(ctx, response); sinkErr != nil {
@@ -76,6 +132,46 @@ func (t *Thread) RunOnce(ctx context.Context) {
 	}
 }
 
+// TimeoutError reports a turn stopped by its WakeMessage.Timeout.
+type TimeoutError struct {
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return "timed out after " + formatTimeout(e.After)
+}
+
+func formatTimeout(d time.Duration) string {
+	if d >= time.Second {
+		d = d.Round(time.Second)
+	}
+	return d.String()
+}
+
+// formatRunError turns a failed turn into a message for the user. Provider
+// API errors are explained by category; anything else is shown verbatim.
+func formatRunError(err error) string {
+	var pe *provider.ProviderError
+	if !errors.As(err, &pe) {
+		return fmt.Sprintf("[Error] %v", err)
+	}
+	switch {
+	case pe.IsAuth():
+		return fmt.Sprintf("[Error] %s rejected the API credentials (HTTP %d). Check the provider API key or re-run OAuth login.", pe.Provider, pe.StatusCode)
+	case pe.IsRateLimit():
+		if pe.RetryAfter >= time.Second {
+			return fmt.Sprintf("[Error] %s rate limit reached. Please try again in %s.", pe.Provider, pe.RetryAfter.Round(time.Second))
+		}
+		return fmt.Sprintf("[Error] %s rate limit reached. Please try again shortly.", pe.Provider)
+	case pe.IsContextLength():
+		return fmt.Sprintf("[Error] The conversation is too long for the %s model's context window. Compress or clear the session and try again.", pe.Provider)
+	case pe.IsOverloaded():
+		return fmt.Sprintf("[Error] %s is temporarily overloaded (HTTP %d). Please try again shortly.", pe.Provider, pe.StatusCode)
+	default:
+		return fmt.Sprintf("[Error] %v", pe)
+	}
+}
+
 // buildWakePayload constructs the user message from a wake source and message.
 func buildWakePayload(source, message, threadID, sessionKey, deliveryLabel string) string {
 	source = strings.TrimSpace(source)
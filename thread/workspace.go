@@ -0,0 +1,130 @@
+package thread
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/linanwx/nagobot/session"
+	"github.com/linanwx/nagobot/skills"
+	"github.com/linanwx/nagobot/tools"
+)
+
+const defaultWorkspaceName = "default"
+
+// Workspaces returns the configured named workspaces (name → path).
+func (m *Manager) Workspaces() map[string]string {
+	out := make(map[string]string, len(m.cfg.Workspaces)+1)
+	for name, path := range m.cfg.Workspaces {
+		out[name] = path
+	}
+	if _, ok := out[defaultWorkspaceName]; !ok && m.cfg.Workspace != "" {
+		out[defaultWorkspaceName] = m.cfg.Workspace
+	}
+	return out
+}
+
+// ActiveWorkspace returns the active workspace name for a session.
+func (m *Manager) ActiveWorkspace(sessionKey string) string {
+	if m.cfg.Sessions == nil {
+		return defaultWorkspaceName
+	}
+	sess, err := m.cfg.Sessions.Reload(sessionKey)
+	if err != nil {
+		return defaultWorkspaceName
+	}
+	name, _ := m.resolveWorkspace(sess)
+	return name
+}
+
+// SwitchWorkspace makes name the active workspace for a session and returns
+// its path. The workspace must exist and be writable. The switch is recorded
+// on the session and takes effect from the next turn.
+func (m *Manager) SwitchWorkspace(sessionKey, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	path, ok := m.Workspaces()[name]
+	if !ok {
+		return "", fmt.Errorf("unknown workspace %q (available: %s)", name, strings.Join(sortedWorkspaceNames(m.Workspaces()), ", "))
+	}
+	if err := validateWorkspaceDir(path); err != nil {
+		return "", err
+	}
+	if m.cfg.Sessions == nil {
+		return "", fmt.Errorf("session manager unavailable")
+	}
+
+	_, err := m.cfg.Sessions.Update(sessionKey, func(sess *session.Session) error {
+		sess.Workspace = name
+		if name == defaultWorkspaceName {
+			sess.Workspace = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// resolveWorkspace maps the session's recorded workspace to a name and path.
+// Unknown or missing workspaces fall back to the default.
+func (m *Manager) resolveWorkspace(sess *session.Session) (string, string) {
+	if sess != nil && sess.Workspace != "" && sess.Workspace != defaultWorkspaceName {
+		if path, ok := m.cfg.Workspaces[sess.Workspace]; ok {
+			return sess.Workspace, path
+		}
+	}
+	return defaultWorkspaceName, m.cfg.Workspace
+}
+
+// workspaceTools returns the tool registry for a turn in a non-default
+// workspace: skill tools are re-rooted at that workspace's skills directory.
+// The prompt skills section is returned alongside.
+func (t *Thread) workspaceTools(workspace string) (*tools.Registry, string) {
+	skillsDir := filepath.Join(workspace, "skills")
+	reg := skills.NewRegistry()
+	if err := reg.LoadFromDirectory(skillsDir); err != nil {
+		return t.tools, t.buildSkillsSection()
+	}
+
+	runTools := t.tools.Clone()
+	useSkill := tools.NewUseSkillTool(reg, false)
+	if existing, ok := t.tools.Get("use_skill"); ok {
+		if base, ok := existing.(*tools.UseSkillTool); ok {
+			useSkill = base.WithProvider(reg)
+		}
+	}
+	runTools.Register(useSkill)
+	runTools.Register(tools.NewListSkillsTool(reg))
+	runTools.Register(tools.NewSkillFilesTool(skillsDir))
+	return runTools, reg.BuildPromptSection()
+}
+
+// validateWorkspaceDir checks that path is an existing, writable directory.
+func validateWorkspaceDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("workspace %s is not accessible: %w", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("workspace %s is not a directory", path)
+	}
+	probe, err := os.CreateTemp(path, ".nagobot-write-*")
+	if err != nil {
+		return fmt.Errorf("workspace %s is not writable: %w", path, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+func sortedWorkspaceNames(workspaces map[string]string) []string {
+	names := make([]string, 0, len(workspaces))
+	for name := range workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
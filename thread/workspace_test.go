@@ -0,0 +1,50 @@
+package thread
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/linanwx/nagobot/session"
+)
+
+func TestManagerSwitchWorkspacePersistsOnSession(t *testing.T) {
+	root := t.TempDir()
+	sessions, err := session.NewManager(filepath.Join(root, "sessions"))
+	if err != nil {
+		t.Fatalf("session.NewManager() error = %v", err)
+	}
+	projectDir := t.TempDir()
+	mgr := NewManager(&ThreadConfig{
+		Workspace: root,
+		Workspaces: map[string]string{
+			"default": root,
+			"project": projectDir,
+			"missing": filepath.Join(root, "does-not-exist"),
+		},
+		Sessions: sessions,
+	})
+
+	if got := mgr.ActiveWorkspace("chat:1"); got != "default" {
+		t.Fatalf("ActiveWorkspace() = %q, want default", got)
+	}
+	if _, err := mgr.SwitchWorkspace("chat:1", "missing"); err == nil {
+		t.Fatal("SwitchWorkspace(missing) should fail for a nonexistent directory")
+	}
+	if _, err := mgr.SwitchWorkspace("chat:1", "unknown"); err == nil {
+		t.Fatal("SwitchWorkspace(unknown) should fail for an unconfigured name")
+	}
+
+	path, err := mgr.SwitchWorkspace("chat:1", "project")
+	if err != nil {
+		t.Fatalf("SwitchWorkspace(project) error = %v", err)
+	}
+	if path != projectDir {
+		t.Fatalf("SwitchWorkspace() path = %q, want %q", path, projectDir)
+	}
+	if got := mgr.ActiveWorkspace("chat:1"); got != "project" {
+		t.Fatalf("ActiveWorkspace() after switch = %q, want project", got)
+	}
+	if got := mgr.ActiveWorkspace("chat:2"); got != "default" {
+		t.Fatalf("ActiveWorkspace() for other session = %q, want default", got)
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linanwx/nagobot/agent"
+	"github.com/linanwx/nagobot/provider"
+)
+
+// AgentsTool lists the agent templates available to spawn_thread and shows
+// their prompts.
+type AgentsTool struct {
+	registry *agent.AgentRegistry
+}
+
+// NewAgentsTool creates an agents tool backed by registry.
+func NewAgentsTool(registry *agent.AgentRegistry) *AgentsTool {
+	return &AgentsTool{registry: registry}
+}
+
+// Def returns the tool definition.
+func (t *AgentsTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "agents",
+			Description: "Discover agents to delegate to with spawn_thread. list: agent names with short descriptions; describe: the full prompt of one agent.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"action": map[string]any{
+						"type": "string",
+						"enum": []string{"list", "describe"},
+					},
+					"name": map[string]any{
+						"type":        "string",
+						"description": "Agent name. Required for describe.",
+					},
+				},
+				"required": []string{"action"},
+			},
+		},
+	}
+}
+
+type agentsArgs struct {
+	Action string `json:"action"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Run executes the tool.
+func (t *AgentsTool) Run(_ context.Context, args json.RawMessage) string {
+	var a agentsArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if t.registry == nil {
+		return "Error: agent registry not available"
+	}
+
+	switch strings.TrimSpace(a.Action) {
+	case "list":
+		defs := t.registry.List()
+		if len(defs) == 0 {
+			return "No agents found."
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%d agent(s):\n", len(defs))
+		for _, def := range defs {
+			sb.WriteString("- " + def.Name)
+			if def.Description != "" {
+				sb.WriteString(": " + def.Description)
+			}
+			sb.WriteString("\n")
+		}
+		return strings.TrimRight(sb.String(), "\n")
+	case "describe":
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			return "Error: name is required for describe"
+		}
+		def, prompt, err := t.registry.Prompt(name)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return fmt.Sprintf("name: %s\ndescription: %s\npath: %s\nprompt:\n%s", def.Name, def.Description, def.Path, prompt)
+	default:
+		return fmt.Sprintf("Error: unknown action %q (expected list or describe)", a.Action)
+	}
+}
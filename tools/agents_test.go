@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/agent"
+)
+
+func newTestAgentsTool(t *testing.T) *AgentsTool {
+	t.Helper()
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"coder.md":      "---\nname: coder\ndescription: Writes and reviews code\n---\nYou are a careful programmer.\n\n{{TASK}}",
+		"researcher.md": "# Digs through sources and cites them\n\nSearch widely before answering.",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewAgentsTool(agent.NewRegistry(workspace))
+}
+
+func TestAgentsToolList(t *testing.T) {
+	tool := newTestAgentsTool(t)
+	args, _ := json.Marshal(agentsArgs{Action: "list"})
+	out := tool.Run(context.Background(), args)
+
+	want := "2 agent(s):\n- coder: Writes and reviews code\n- researcher: Digs through sources and cites them"
+	if out != want {
+		t.Fatalf("Run() = %q, want %q", out, want)
+	}
+}
+
+func TestAgentsToolDescribe(t *testing.T) {
+	tool := newTestAgentsTool(t)
+	args, _ := json.Marshal(agentsArgs{Action: "describe", Name: "Coder"})
+	out := tool.Run(context.Background(), args)
+	if !strings.Contains(out, "prompt:\nYou are a careful programmer.\n\n{{TASK}}") || strings.Contains(out, "---") {
+		t.Fatalf("Run() = %q", out)
+	}
+
+	args, _ = json.Marshal(agentsArgs{Action: "describe", Name: "ghost"})
+	if out := tool.Run(context.Background(), args); !strings.HasPrefix(out, "Error:") {
+		t.Fatalf("Run() = %q, want not found error", out)
+	}
+}
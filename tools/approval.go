@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ApprovalFunc decides whether a sensitive tool call may run.
+type ApprovalFunc func(toolName string, args json.RawMessage) bool
+
+// SensitiveTool is implemented by tools whose calls may need approval before
+// running when the registry has an ApprovalFunc.
+type SensitiveTool interface {
+	Tool
+	// Sensitive reports whether this particular call needs approval.
+	Sensitive(ctx context.Context, args json.RawMessage) bool
+}
+
+// refusingTool is implemented by sensitive tools that can tell before running
+// that Run would refuse a call, e.g. under restrictToWorkspace. The registry
+// checks it first so nobody is asked to approve a call that cannot run.
+type refusingTool interface {
+	// refusal returns the error Run would report for these args, or "".
+	refusal(ctx context.Context, args json.RawMessage) string
+}
+
+// SetApprovalFunc sets the callback consulted before running sensitive tool
+// calls. Nil disables approval. Clones made afterwards share the callback.
+func (r *Registry) SetApprovalFunc(fn ApprovalFunc) {
+	r.approve = fn
+}
+
+// Sensitive reports that every exec call needs approval.
+func (t *ExecTool) Sensitive(context.Context, json.RawMessage) bool {
+	return true
+}
+
+func (t *ExecTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a execArgs
+	if json.Unmarshal(args, &a) != nil {
+		return "" // Run reports malformed args
+	}
+	if reason := t.policy.check(a.Command); reason != "" {
+		return "Error: command blocked by exec policy: " + reason
+	}
+	workspace := workspaceFor(ctx, t.workspace)
+	dir := workspace
+	if a.Workdir != "" {
+		dir = expandPath(a.Workdir)
+	}
+	return t.workdirRefusal(workspace, dir)
+}
+
+// Sensitive reports whether the write targets a path outside the workspace.
+func (t *WriteFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a writeFileArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *WriteFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a writeFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the append targets a path outside the workspace.
+func (t *AppendFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a appendFileArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *AppendFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a appendFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the edit targets a path outside the workspace.
+// Previews write nothing and never need approval.
+func (t *EditFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a editFileArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return true
+	}
+	return !a.Preview && writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *EditFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a editFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the edits target a path outside the workspace.
+func (t *MultiEditTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a multiEditArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *MultiEditTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a multiEditArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the line edit targets a path outside the workspace.
+func (t *EditLinesTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a editLinesArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *EditLinesTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a editLinesArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the replacement targets a path outside the workspace.
+func (t *ReplaceAllTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a replaceAllArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *ReplaceAllTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a replaceAllArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the move touches a path outside the workspace.
+func (t *MoveFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a moveFileArgs
+	return json.Unmarshal(args, &a) != nil ||
+		writesOutsideWorkspace(ctx, t.workspace, a.Source) ||
+		writesOutsideWorkspace(ctx, t.workspace, a.Destination)
+}
+
+func (t *MoveFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a moveFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Source, a.Destination)
+}
+
+// Sensitive reports whether the delete targets a path outside the workspace.
+func (t *DeleteFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a deleteFileArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Path)
+}
+
+func (t *DeleteFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a deleteFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+}
+
+// Sensitive reports whether the copy writes to a path outside the workspace.
+func (t *CopyFileTool) Sensitive(ctx context.Context, args json.RawMessage) bool {
+	var a copyFileArgs
+	return json.Unmarshal(args, &a) != nil || writesOutsideWorkspace(ctx, t.workspace, a.Destination)
+}
+
+func (t *CopyFileTool) refusal(ctx context.Context, args json.RawMessage) string {
+	var a copyFileArgs
+	_ = json.Unmarshal(args, &a) // Run reports malformed args
+	return pathsRefusal(ctx, t.workspace, t.restrictToWorkspace, a.Source, a.Destination)
+}
+
+// pathsRefusal returns the restrictToWorkspace error for the first of paths
+// outside the workspace, or "".
+func pathsRefusal(ctx context.Context, fallback string, restrict bool, paths ...string) string {
+	for _, path := range paths {
+		if _, errMsg := resolveFileToolPath(ctx, fallback, restrict, path); errMsg != "" {
+			return errMsg
+		}
+	}
+	return ""
+}
+
+// writesOutsideWorkspace reports whether path resolves outside the run's
+// workspace, following symlinks. Without a workspace every path counts as
+// outside.
+func writesOutsideWorkspace(ctx context.Context, fallback, path string) bool {
+	workspace := workspaceFor(ctx, fallback)
+	if workspace == "" {
+		return true
+	}
+	return checkWithinWorkspace(workspace, path, absOrOriginal(resolveToolPath(path, workspace))) != ""
+}
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newApprovalRegistry(workspace string, approve ApprovalFunc) (*Registry, *[]string) {
+	r := NewRegistry()
+	r.Register(&WriteFileTool{workspace: workspace})
+	var asked []string
+	r.SetApprovalFunc(func(name string, args json.RawMessage) bool {
+		asked = append(asked, name)
+		return approve(name, args)
+	})
+	return r, &asked
+}
+
+func TestApprovalDeniedBlocksWriteOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	target := filepath.Join(t.TempDir(), "outside.txt")
+	r, asked := newApprovalRegistry(workspace, func(string, json.RawMessage) bool { return false })
+
+	args, _ := json.Marshal(writeFileArgs{Path: target, Content: "x"})
+	out := r.Run(context.Background(), "write_file", args)
+	if !strings.Contains(out, "requires admin approval") {
+		t.Fatalf("expected approval error, got %q", out)
+	}
+	if len(*asked) != 1 {
+		t.Fatalf("expected one approval request, got %v", *asked)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("denied write created the file: %v", err)
+	}
+}
+
+func TestApprovalAllowedRunsWriteOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	target := filepath.Join(t.TempDir(), "outside.txt")
+	r, asked := newApprovalRegistry(workspace, func(string, json.RawMessage) bool { return true })
+
+	args, _ := json.Marshal(writeFileArgs{Path: target, Content: "x"})
+	r.Run(context.Background(), "write_file", args)
+	if len(*asked) != 1 {
+		t.Fatalf("expected one approval request, got %v", *asked)
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "x" {
+		t.Fatalf("approved write did not run: %q, %v", data, err)
+	}
+}
+
+func TestApprovalSkipsWriteInsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	r, asked := newApprovalRegistry(workspace, func(string, json.RawMessage) bool { return false })
+
+	args, _ := json.Marshal(writeFileArgs{Path: "inside.txt", Content: "x"})
+	r.Run(context.Background(), "write_file", args)
+	if len(*asked) != 0 {
+		t.Fatalf("write inside the workspace asked for approval: %v", *asked)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "inside.txt")); err != nil {
+		t.Fatalf("write inside the workspace did not run: %v", err)
+	}
+}
+
+func TestApprovalFollowsSymlinksOutOfWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(workspace, "link")); err != nil {
+		t.Fatal(err)
+	}
+	r, asked := newApprovalRegistry(workspace, func(string, json.RawMessage) bool { return false })
+	r.Register(&MoveFileTool{workspace: workspace})
+	if err := os.WriteFile(filepath.Join(workspace, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args, _ := json.Marshal(writeFileArgs{Path: "link/escape.txt", Content: "x"})
+	if out := r.Run(context.Background(), "write_file", args); !strings.Contains(out, "requires admin approval") {
+		t.Fatalf("write through symlink = %q, want approval error", out)
+	}
+	args, _ = json.Marshal(moveFileArgs{Source: "a.txt", Destination: "link/a.txt"})
+	if out := r.Run(context.Background(), "move_file", args); !strings.Contains(out, "requires admin approval") {
+		t.Fatalf("move through symlink = %q, want approval error", out)
+	}
+	if len(*asked) != 2 {
+		t.Fatalf("approval requests = %v, want write_file and move_file", *asked)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Fatalf("denied calls wrote outside the workspace: %v", entries)
+	}
+}
+
+func TestRestrictedCallsAreRefusedBeforeApproval(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r, asked := newApprovalRegistry(workspace, func(string, json.RawMessage) bool { return true })
+	r.Register(&WriteFileTool{workspace: workspace, restrictToWorkspace: true})
+	r.Register(&ExecTool{workspace: workspace, restrictToWorkspace: true})
+
+	args, _ := json.Marshal(writeFileArgs{Path: "../outside.txt", Content: "x"})
+	if out := r.Run(context.Background(), "write_file", args); !strings.Contains(out, "is outside workspace") {
+		t.Fatalf("restricted write = %q, want outside-workspace error", out)
+	}
+	args, _ = json.Marshal(execArgs{Command: "true", Workdir: root})
+	if out := r.Run(context.Background(), "exec", args); !strings.Contains(out, "is outside workspace") {
+		t.Fatalf("restricted exec = %q, want outside-workspace error", out)
+	}
+	if len(*asked) != 0 {
+		t.Fatalf("refused calls asked for approval: %v", *asked)
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside.txt")); !os.IsNotExist(err) {
+		t.Fatalf("restricted write created the file: %v", err)
+	}
+}
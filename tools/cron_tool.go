@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/cron"
+	"github.com/linanwx/nagobot/provider"
+)
+
+const cronListTaskMaxChars = 80
+
+// CronTool inspects and edits scheduled jobs in the running scheduler.
+type CronTool struct {
+	scheduler *cron.Scheduler
+}
+
+// NewCronTool creates a cron tool backed by scheduler.
+func NewCronTool(scheduler *cron.Scheduler) *CronTool {
+	return &CronTool{scheduler: scheduler}
+}
+
+// Def returns the tool definition.
+func (t *CronTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "cron",
+			Description: "Inspect and manage scheduled jobs. Actions: list (all jobs with next run time), " +
+				"describe (full config of one job), add_cron (recurring, 5-field cron expression), " +
+				"add_at (one-time, RFC3339 time), remove, pause (stop firing but keep the job), resume. " +
+				"Adding with an existing id replaces that job.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"action": map[string]any{
+						"type": "string",
+						"enum": []string{"list", "describe", "add_cron", "add_at", "remove", "pause", "resume"},
+					},
+					"id": map[string]any{
+						"type":        "string",
+						"description": "Job ID. Required for every action except list.",
+					},
+					"expr": map[string]any{
+						"type":        "string",
+						"description": "Cron expression for add_cron, e.g. \"0 9 * * 1-5\".",
+					},
+					"at": map[string]any{
+						"type":        "string",
+						"description": "Execution time for add_at: RFC3339 (\"2026-02-07T18:30:00+08:00\") or wall-clock time in timezone (\"2026-02-07T18:30:00\").",
+					},
+					"timezone": map[string]any{
+						"type":        "string",
+						"description": "Optional IANA timezone, e.g. \"Asia/Shanghai\", for expr and wall-clock at times. Defaults to the server's local time.",
+					},
+					"task": map[string]any{
+						"type":        "string",
+						"description": "Instructions for the job thread. Required for add_cron and add_at.",
+					},
+					"agent": map[string]any{
+						"type":        "string",
+						"description": "Optional agent template name.",
+					},
+					"wake_session": map[string]any{
+						"type":        "string",
+						"description": "Session that receives the result. Defaults to main.",
+					},
+					"silent": map[string]any{
+						"type":        "boolean",
+						"description": "Suppress result delivery.",
+					},
+					"max_attempts": map[string]any{
+						"type":        "integer",
+						"description": "Attempts per run when the job's agent turn fails (e.g. a provider error or timeout), including the first (max 10). Defaults to 1.",
+					},
+					"retry_backoff_seconds": map[string]any{
+						"type":        "integer",
+						"description": "Wait before the first retry, doubled for each further retry. Defaults to 30.",
+					},
+				},
+				"required": []string{"action"},
+			},
+		},
+	}
+}
+
+type cronArgs struct {
+	Action      string `json:"action"`
+	ID          string `json:"id,omitempty"`
+	Expr        string `json:"expr,omitempty"`
+	At          string `json:"at,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+	Task        string `json:"task,omitempty"`
+	Agent       string `json:"agent,omitempty"`
+	WakeSession string `json:"wake_session,omitempty"`
+	Silent      bool   `json:"silent,omitempty"`
+	MaxAttempts int    `json:"max_attempts,omitempty"`
+	Backoff     int    `json:"retry_backoff_seconds,omitempty"`
+}
+
+// Run executes the tool.
+func (t *CronTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a cronArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if t.scheduler == nil {
+		return "Error: cron scheduler not available"
+	}
+
+	action := strings.TrimSpace(a.Action)
+	id := strings.TrimSpace(a.ID)
+	if action != "list" && id == "" {
+		return fmt.Sprintf("Error: id is required for %s", action)
+	}
+	now := time.Now()
+
+	// Anyone may look; CLI and system runs are local operators, channel users
+	// must be the admin to change jobs.
+	switch action {
+	case "add_cron", "add_at", "remove", "pause", "resume":
+		if rt := RuntimeContextFrom(ctx); rt.Origin != nil && !rt.Origin.IsAdmin && rt.Origin.Channel != "cli" {
+			return "Error: only the admin can change cron jobs"
+		}
+	}
+
+	switch action {
+	case "list":
+		return t.list(now)
+	case "describe":
+		job, ok := t.scheduler.Get(id)
+		if !ok {
+			return fmt.Sprintf("Error: job not found: %s", id)
+		}
+		out := describeCronJob(job, now)
+		if status, ok := t.scheduler.LastRun(id); ok {
+			result := "ok"
+			if status.Err != "" {
+				result = "failed: " + status.Err
+			}
+			out += fmt.Sprintf("\nlast_run: %s, attempts: %d, %s", status.At.Format(time.RFC3339), status.Attempts, result)
+		}
+		return out
+	case "add_cron":
+		if strings.TrimSpace(a.Expr) == "" {
+			return "Error: expr is required for add_cron"
+		}
+		return t.add(t.scheduler.Add, a.job(id), now)
+	case "add_at":
+		at, err := cron.ParseAtTime(a.At, a.Timezone)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		job := a.job(id)
+		job.AtTime = at
+		return t.add(t.scheduler.AddAt, job, now)
+	case "remove":
+		removed, err := t.scheduler.Remove(id)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		if !removed {
+			return fmt.Sprintf("Error: job not found: %s", id)
+		}
+		return fmt.Sprintf("Removed job: %s", id)
+	case "pause", "resume":
+		if err := t.scheduler.SetEnabled(id, action == "resume"); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		if action == "pause" {
+			return fmt.Sprintf("Paused job: %s", id)
+		}
+		job, _ := t.scheduler.Get(id)
+		return fmt.Sprintf("Resumed job: %s, next: %s", id, formatNextRun(job.NextRunAfter(now), now))
+	default:
+		return fmt.Sprintf("Error: unknown action %q (use list, describe, add_cron, add_at, remove, pause or resume)", a.Action)
+	}
+}
+
+func (a cronArgs) job(id string) cron.Job {
+	var retry *cron.RetryPolicy
+	if a.MaxAttempts > 1 {
+		retry = &cron.RetryPolicy{MaxAttempts: a.MaxAttempts, BackoffSeconds: a.Backoff}
+	}
+	return cron.Job{
+		ID:          id,
+		Expr:        a.Expr,
+		Timezone:    a.Timezone,
+		Task:        a.Task,
+		Agent:       a.Agent,
+		WakeSession: a.WakeSession,
+		Silent:      a.Silent,
+		Retry:       retry,
+	}
+}
+
+func (t *CronTool) add(add func(cron.Job) (bool, error), job cron.Job, now time.Time) string {
+	updated, err := add(job)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	action := "created"
+	if updated {
+		action = "updated"
+	}
+	saved, _ := t.scheduler.Get(strings.TrimSpace(job.ID))
+	return fmt.Sprintf("Job %s.\n%s", action, describeCronJob(saved, now))
+}
+
+func (t *CronTool) list(now time.Time) string {
+	jobs := t.scheduler.List()
+	if len(jobs) == 0 {
+		return "No scheduled jobs."
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d scheduled job(s):\n", len(jobs))
+	for _, job := range jobs {
+		kind := job.Kind
+		if job.Disabled {
+			kind += ", paused"
+		}
+		fmt.Fprintf(&sb, "- %s [%s] %s, next: %s, task: %s\n", job.ID, kind, cronSchedule(job.Job), formatNextRun(job.NextRun, now), clipCronTask(job.Task))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func describeCronJob(job cron.Job, now time.Time) string {
+	wakeSession := job.WakeSession
+	if wakeSession == "" {
+		wakeSession = "main"
+	}
+	timezone := job.Timezone
+	if timezone == "" {
+		timezone = "local"
+	}
+	retry := "none"
+	if job.Retry != nil && job.Retry.MaxAttempts > 1 {
+		retry = fmt.Sprintf("%d attempts", job.Retry.MaxAttempts)
+		if job.Retry.BackoffSeconds > 0 {
+			retry += fmt.Sprintf(", %ds backoff", job.Retry.BackoffSeconds)
+		}
+	}
+	return fmt.Sprintf(
+		"id: %s\nkind: %s\nenabled: %t\nschedule: %s\ntimezone: %s\nnext_run: %s\nagent: %s\nwake_session: %s\nsilent: %t\nretry: %s\ncreated_at: %s\ntask:\n%s",
+		job.ID, job.Kind, !job.Disabled, cronSchedule(job), timezone, formatNextRun(job.NextRunAfter(now), now), job.Agent, wakeSession, job.Silent, retry,
+		job.CreatedAt.Format(time.RFC3339), job.Task,
+	)
+}
+
+func clipCronTask(task string) string {
+	if len(task) <= cronListTaskMaxChars {
+		return task
+	}
+	n := cronListTaskMaxChars
+	for n > 0 && !utf8.RuneStart(task[n]) {
+		n--
+	}
+	return task[:n] + "…"
+}
+
+func cronSchedule(job cron.Job) string {
+	if job.Kind == cron.JobKindAt {
+		return job.AtTime.Format(time.RFC3339)
+	}
+	if job.Timezone != "" {
+		return job.Expr + " (" + job.Timezone + ")"
+	}
+	return job.Expr
+}
+
+// formatNextRun renders a next activation, or "none" when the job will not fire again.
+func formatNextRun(next, now time.Time) string {
+	if next.IsZero() || !next.After(now) {
+		return "none"
+	}
+	return next.Format(time.RFC3339)
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linanwx/nagobot/cron"
+)
+
+func runCronTool(t *testing.T, tool *CronTool, a cronArgs) string {
+	t.Helper()
+	args, _ := json.Marshal(a)
+	return tool.Run(context.Background(), args)
+}
+
+func newTestCronTool(t *testing.T) (*CronTool, string) {
+	t.Helper()
+	storePath := filepath.Join(t.TempDir(), "cron.jsonl")
+	sch, err := cron.NewScheduler(storePath, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	t.Cleanup(sch.Stop)
+	return NewCronTool(sch), storePath
+}
+
+func TestCronToolAddListRemove(t *testing.T) {
+	tool, storePath := newTestCronTool(t)
+
+	out := runCronTool(t, tool, cronArgs{Action: "add_cron", ID: "daily", Expr: "0 9 * * *", Task: "morning briefing"})
+	if !strings.HasPrefix(out, "Job created.") || !strings.Contains(out, "schedule: 0 9 * * *") {
+		t.Fatalf("add_cron = %q", out)
+	}
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	out = runCronTool(t, tool, cronArgs{Action: "add_at", ID: "once", At: at.Format(time.RFC3339), Task: "one-off", Silent: true})
+	if !strings.HasPrefix(out, "Job created.") {
+		t.Fatalf("add_at = %q", out)
+	}
+
+	out = runCronTool(t, tool, cronArgs{Action: "list"})
+	if !strings.HasPrefix(out, "2 scheduled job(s):") {
+		t.Fatalf("list = %q", out)
+	}
+	if !strings.Contains(out, "- once [at] "+at.UTC().Format(time.RFC3339)+", next: "+at.UTC().Format(time.RFC3339)) {
+		t.Errorf("list missing at job next run:\n%s", out)
+	}
+	next := cron.Job{Kind: cron.JobKindCron, Expr: "0 9 * * *"}.NextRun()
+	if !strings.Contains(out, "- daily [cron] 0 9 * * *, next: "+next.Format(time.RFC3339)) {
+		t.Errorf("list missing cron job next run:\n%s", out)
+	}
+
+	stored, err := cron.ReadJobs(storePath)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("store has %d jobs (err %v), want 2", len(stored), err)
+	}
+
+	if out := runCronTool(t, tool, cronArgs{Action: "remove", ID: "daily"}); out != "Removed job: daily" {
+		t.Fatalf("remove = %q", out)
+	}
+	if out := runCronTool(t, tool, cronArgs{Action: "describe", ID: "daily"}); !strings.Contains(out, "job not found") {
+		t.Fatalf("describe after remove = %q", out)
+	}
+	if stored, _ := cron.ReadJobs(storePath); len(stored) != 1 || stored[0].ID != "once" {
+		t.Fatalf("store after remove = %+v, want only once", stored)
+	}
+}
+
+func TestCronToolRejectsInvalidSchedules(t *testing.T) {
+	tool, _ := newTestCronTool(t)
+
+	if out := runCronTool(t, tool, cronArgs{Action: "add_cron", ID: "bad", Expr: "every day", Task: "x"}); !strings.Contains(out, "invalid cron expression") {
+		t.Errorf("bad expr = %q", out)
+	}
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	if out := runCronTool(t, tool, cronArgs{Action: "add_at", ID: "late", At: past, Task: "x"}); !strings.Contains(out, "in the past") {
+		t.Errorf("past at = %q", out)
+	}
+	if out := runCronTool(t, tool, cronArgs{Action: "list"}); out != "No scheduled jobs." {
+		t.Errorf("list = %q, want no jobs", out)
+	}
+}
+
+func TestCronToolPauseResume(t *testing.T) {
+	tool, storePath := newTestCronTool(t)
+	runCronTool(t, tool, cronArgs{Action: "add_cron", ID: "noisy", Expr: "*/5 * * * *", Task: "poll"})
+
+	if out := runCronTool(t, tool, cronArgs{Action: "pause", ID: "noisy"}); out != "Paused job: noisy" {
+		t.Fatalf("pause = %q", out)
+	}
+	if out := runCronTool(t, tool, cronArgs{Action: "list"}); !strings.Contains(out, "- noisy [cron, paused] */5 * * * *, next: none") {
+		t.Fatalf("list after pause = %q", out)
+	}
+	if stored, _ := cron.ReadJobs(storePath); len(stored) != 1 || !stored[0].Disabled {
+		t.Fatalf("store after pause = %+v, want the job kept as disabled", stored)
+	}
+	if out := runCronTool(t, tool, cronArgs{Action: "resume", ID: "noisy"}); !strings.HasPrefix(out, "Resumed job: noisy, next: ") || strings.HasSuffix(out, "none") {
+		t.Fatalf("resume = %q", out)
+	}
+	if out := runCronTool(t, tool, cronArgs{Action: "pause", ID: "missing"}); !strings.Contains(out, "job not found") {
+		t.Fatalf("pause missing = %q", out)
+	}
+}
+
+func TestCronToolChangesRequireAdmin(t *testing.T) {
+	tool, storePath := newTestCronTool(t)
+	runCronTool(t, tool, cronArgs{Action: "add_cron", ID: "daily", Expr: "0 9 * * *", Task: "briefing"})
+
+	run := func(origin *Origin, a cronArgs) string {
+		args, _ := json.Marshal(a)
+		ctx := WithRuntimeContext(context.Background(), RuntimeContext{Origin: origin})
+		return tool.Run(ctx, args)
+	}
+	user := &Origin{Channel: "telegram", UserID: "42"}
+	for _, a := range []cronArgs{
+		{Action: "add_cron", ID: "spam", Expr: "* * * * *", Task: "spam"},
+		{Action: "add_at", ID: "once", At: time.Now().Add(time.Hour).Format(time.RFC3339), Task: "once"},
+		{Action: "remove", ID: "daily"},
+		{Action: "pause", ID: "daily"},
+		{Action: "resume", ID: "daily"},
+	} {
+		if out := run(user, a); !strings.Contains(out, "only the admin") {
+			t.Fatalf("%s as non-admin = %q, want rejection", a.Action, out)
+		}
+	}
+	if stored, _ := cron.ReadJobs(storePath); len(stored) != 1 || stored[0].ID != "daily" || stored[0].Disabled {
+		t.Fatalf("store after rejected changes = %+v, want it untouched", stored)
+	}
+	if out := run(user, cronArgs{Action: "list"}); !strings.Contains(out, "daily") {
+		t.Fatalf("list as non-admin = %q", out)
+	}
+
+	admin := &Origin{Channel: "telegram", UserID: "1", IsAdmin: true}
+	if out := run(admin, cronArgs{Action: "pause", ID: "daily"}); out != "Paused job: daily" {
+		t.Fatalf("pause as admin = %q", out)
+	}
+	if out := run(&Origin{Channel: "cli"}, cronArgs{Action: "resume", ID: "daily"}); !strings.HasPrefix(out, "Resumed job: daily") {
+		t.Fatalf("resume from cli = %q", out)
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+// unifiedDiff renders a single-hunk unified diff between oldText and newText.
+// The changed region is found by trimming common leading and trailing lines,
+// which is exact for one contiguous replacement such as edit_file performs.
+func unifiedDiff(name, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	oldLines := splitDiffLines(oldText)
+	newLines := splitDiffLines(newText)
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	start := prefix - diffContextLines
+	if start < 0 {
+		start = 0
+	}
+	oldEnd := len(oldLines) - suffix
+	newEnd := len(newLines) - suffix
+	tail := suffix
+	if tail > diffContextLines {
+		tail = diffContextLines
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+		diffRange(start, oldEnd+tail-start),
+		diffRange(start, newEnd+tail-start),
+	)
+	for _, line := range oldLines[start:prefix] {
+		writeDiffLine(&sb, " ", line)
+	}
+	for _, line := range oldLines[prefix:oldEnd] {
+		writeDiffLine(&sb, "-", line)
+	}
+	for _, line := range newLines[prefix:newEnd] {
+		writeDiffLine(&sb, "+", line)
+	}
+	for _, line := range oldLines[oldEnd : oldEnd+tail] {
+		writeDiffLine(&sb, " ", line)
+	}
+	return sb.String()
+}
+
+// splitDiffLines splits text into lines that keep their trailing newline.
+func splitDiffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffRange formats a hunk range; start is 0-based.
+func diffRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeDiffLine(sb *strings.Builder, marker, line string) {
+	sb.WriteString(marker)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
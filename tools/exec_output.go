@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// execProgressInterval is how often a running command's latest output line is
+// forwarded to the progress callback.
+var execProgressInterval = 10 * time.Second
+
+type progressKey struct{}
+
+// ProgressFunc receives short progress updates from long-running tools.
+type ProgressFunc func(line string)
+
+// WithProgress attaches a progress callback to ctx. Tools that support it
+// report intermediate output while they run.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFrom(ctx context.Context) ProgressFunc {
+	if ctx == nil {
+		return nil
+	}
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// tailBuffer is an io.Writer that keeps only the last max bytes written.
+// It is safe for concurrent use.
+type tailBuffer struct {
+	mu      sync.Mutex
+	max     int
+	buf     []byte
+	total   int64
+	changed bool // written to since the last lastLine call
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total += int64(len(p))
+	b.changed = true
+	if len(p) >= b.max {
+		b.buf = append(b.buf[:0], p[len(p)-b.max:]...)
+		return len(p), nil
+	}
+	if over := len(b.buf) + len(p) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// String returns the retained output. When earlier output was dropped, a
+// truncation notice is prepended and any partial leading rune is skipped.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.total <= int64(len(b.buf)) {
+		return string(b.buf)
+	}
+	tail := b.buf
+	for i := 0; i < utf8.UTFMax && len(tail) > 0 && !utf8.RuneStart(tail[0]); i++ {
+		tail = tail[1:]
+	}
+	return fmt.Sprintf(
+		"[Truncated] Output exceeded %d bytes (%d total); showing the last %d. Narrow the scope or redirect output to a file.\n%s",
+		b.max, b.total, len(tail), tail,
+	)
+}
+
+// lastLine returns the last non-empty line written since the previous call,
+// or "" when nothing new has arrived.
+func (b *tailBuffer) lastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.changed {
+		return ""
+	}
+	b.changed = false
+	lines := bytes.Split(bytes.TrimRight(b.buf, "\r\n"), []byte("\n"))
+	return strings.TrimSpace(string(bytes.ToValidUTF8(lines[len(lines)-1], nil)))
+}
+
+// reportProgress forwards out's latest line to fn every execProgressInterval
+// until done is closed.
+func reportProgress(fn ProgressFunc, command string, out *tailBuffer, done <-chan struct{}) {
+	ticker := time.NewTicker(execProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if line := out.lastLine(); line != "" {
+				fn(fmt.Sprintf("[exec] %s\n%s", clipProgress(command), clipProgress(line)))
+			}
+		}
+	}
+}
+
+func clipProgress(s string) string {
+	const maxLen = 200
+	if len(s) <= maxLen {
+		return s
+	}
+	n := maxLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
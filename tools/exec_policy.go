@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/linanwx/nagobot/logger"
+)
+
+// ExecPolicy restricts which shell commands the exec tool may run.
+type ExecPolicy struct {
+	Deny         []string // commands rejected in any pipeline segment, e.g. "rm -rf"; flag order and spelling don't matter
+	DenyPatterns []string // regular expressions rejected anywhere in the command
+	Allow        []string // when non-empty, only these binaries may start a segment
+}
+
+// execPolicy is the compiled form of ExecPolicy.
+type execPolicy struct {
+	deny         []commandWords
+	denyPatterns []*regexp.Regexp
+	allow        map[string]bool
+}
+
+// compile prepares the policy for matching. Invalid patterns are logged and
+// skipped. It returns nil when the policy has no rules.
+func (p ExecPolicy) compile() *execPolicy {
+	c := &execPolicy{}
+	for _, prefix := range p.Deny {
+		if fields := shellFields(prefix); len(fields) > 0 {
+			c.deny = append(c.deny, parseCommandWords(prefix, fields))
+		}
+	}
+	for _, pattern := range p.DenyPatterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Warn("invalid exec deny pattern, skipping", "pattern", pattern, "err", err)
+			continue
+		}
+		c.denyPatterns = append(c.denyPatterns, re)
+	}
+	for _, bin := range p.Allow {
+		if bin = strings.TrimSpace(bin); bin != "" {
+			if c.allow == nil {
+				c.allow = make(map[string]bool)
+			}
+			c.allow[filepath.Base(bin)] = true
+		}
+	}
+	if len(c.deny) == 0 && len(c.denyPatterns) == 0 && c.allow == nil {
+		return nil
+	}
+	return c
+}
+
+// check returns a non-empty reason when command violates the policy.
+func (c *execPolicy) check(command string) string {
+	if c == nil {
+		return ""
+	}
+	for _, re := range c.denyPatterns {
+		if re.MatchString(command) {
+			return fmt.Sprintf("command matches denied pattern %q", re.String())
+		}
+	}
+	if c.allow != nil && (strings.Contains(command, "$(") || strings.Contains(command, "`")) {
+		return "command substitution is not allowed when an exec allowlist is configured"
+	}
+
+	for _, segment := range splitShellSegments(command) {
+		fields := shellFields(segment)
+		for len(fields) > 0 && isEnvAssignment(fields[0]) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = filepath.Base(fields[0])
+
+		words := parseCommandWords(segment, fields)
+		if (len(c.deny) > 0 || len(c.denyPatterns) > 0) && words.nestedShell() {
+			return fmt.Sprintf("%q runs a nested shell command, which cannot be checked against the exec deny list", segment)
+		}
+		for _, denied := range c.deny {
+			if words.matches(denied) {
+				return fmt.Sprintf("%q matches denied command %q", segment, denied.text)
+			}
+		}
+		if c.allow != nil && !c.allow[fields[0]] {
+			return fmt.Sprintf("%q is not in the exec allowlist", fields[0])
+		}
+	}
+	return ""
+}
+
+// commandWords is a command split into its binary, its flags and its other
+// arguments, so "rm -rf", "rm -r -f" and "rm --recursive --force" compare
+// equal.
+type commandWords struct {
+	text  string
+	bin   string
+	flags map[string]bool
+	args  []string
+}
+
+// longFlagAliases maps common long options to their short form.
+var longFlagAliases = map[string]string{
+	"--recursive": "r",
+	"--force":     "f",
+}
+
+// nestedShells are binaries that run their argument as another command line.
+var nestedShells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+}
+
+func parseCommandWords(text string, fields []string) commandWords {
+	w := commandWords{text: text, bin: filepath.Base(fields[0]), flags: make(map[string]bool)}
+	endOfFlags := false
+	for _, field := range fields[1:] {
+		switch {
+		case endOfFlags || field == "-" || !strings.HasPrefix(field, "-"):
+			w.args = append(w.args, field)
+		case field == "--":
+			endOfFlags = true
+		case strings.HasPrefix(field, "--"):
+			if short, ok := longFlagAliases[field]; ok {
+				w.flags[short] = true
+			} else {
+				w.flags[field] = true
+			}
+		default:
+			for _, r := range field[1:] {
+				if r == 'R' {
+					r = 'r'
+				}
+				w.flags[string(r)] = true
+			}
+		}
+	}
+	return w
+}
+
+// matches reports whether w runs the denied binary with at least its flags
+// and starts with its other arguments.
+func (w commandWords) matches(denied commandWords) bool {
+	if w.bin != denied.bin || len(w.args) < len(denied.args) {
+		return false
+	}
+	for flag := range denied.flags {
+		if !w.flags[flag] {
+			return false
+		}
+	}
+	for i, arg := range denied.args {
+		if w.args[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+func (w commandWords) nestedShell() bool {
+	return w.bin == "eval" || (nestedShells[w.bin] && w.flags["c"])
+}
+
+func isEnvAssignment(field string) bool {
+	eq := strings.IndexByte(field, '=')
+	return eq > 0 && !strings.ContainsAny(field[:eq], "/-.")
+}
+
+// splitShellSegments splits a command line on pipes, ;, && , || , & and
+// newlines, ignoring separators inside quotes.
+func splitShellSegments(command string) []string {
+	var segments []string
+	var cur strings.Builder
+	var quote rune
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			segments = append(segments, s)
+		}
+		cur.Reset()
+	}
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			cur.WriteRune(r)
+		case r == '|' || r == ';' || r == '&' || r == '\n' || r == '(' || r == ')':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return segments
+}
+
+// shellFields splits a segment into words, removing quotes.
+func shellFields(segment string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range segment {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inField = false
+			}
+		default:
+			cur.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,14 +16,22 @@ import (
 
 const (
 	execDefaultTimeoutSeconds = 60
-	execOutputMaxChars        = 50000
+	execOutputMaxBytes        = 50000
+	execWaitDelay             = 2 * time.Second // how long to wait for output pipes after the command is killed
 )
 
+// defaultExecEnvAllowlist is the host environment kept in sanitized mode when
+// no allowlist is configured.
+var defaultExecEnvAllowlist = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TMPDIR", "TZ"}
+
 // ExecTool executes shell commands.
 type ExecTool struct {
 	workspace           string
 	defaultTimeout      int
 	restrictToWorkspace bool
+	policy              *execPolicy
+	sanitizeEnv         bool     // start from an allowlisted host environment instead of the full one
+	envAllowlist        []string // host variables kept when sanitizeEnv is set; empty uses defaultExecEnvAllowlist
 }
 
 // Def returns the tool definition.
@@ -47,6 +56,11 @@ func (t *ExecTool) Def() provider.ToolDef {
 						"type":        "integer",
 						"description": "Optional timeout in seconds. Defaults to 60.",
 					},
+					"env": map[string]any{
+						"type":                 "object",
+						"description":          "Optional environment variables to set for the command, e.g. {\"FOO\": \"bar\"}.",
+						"additionalProperties": map[string]any{"type": "string"},
+					},
 				},
 				"required": []string{"command"},
 			},
@@ -56,9 +70,10 @@ func (t *ExecTool) Def() provider.ToolDef {
 
 // execArgs are the arguments for exec.
 type execArgs struct {
-	Command string `json:"command"`
-	Workdir string `json:"workdir,omitempty"`
-	Timeout int    `json:"timeout,omitempty"`
+	Command string            `json:"command"`
+	Workdir string            `json:"workdir,omitempty"`
+	Timeout int               `json:"timeout,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
 }
 
 // Run executes the tool.
@@ -68,6 +83,15 @@ func (t *ExecTool) Run(ctx context.Context, args json.RawMessage) string {
 		return errMsg
 	}
 
+	if reason := t.policy.check(a.Command); reason != "" {
+		return "Error: command blocked by exec policy: " + reason
+	}
+
+	env, err := t.environ(a.Env)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
 	timeout := a.Timeout
 	if timeout <= 0 {
 		if t.defaultTimeout > 0 {
@@ -80,58 +104,123 @@ func (t *ExecTool) Run(ctx context.Context, args json.RawMessage) string {
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
+	workspace := workspaceFor(ctx, t.workspace)
 	cmd := exec.CommandContext(execCtx, "sh", "-c", a.Command)
+	cmd.Env = env
 	if a.Workdir != "" {
 		cmd.Dir = expandPath(a.Workdir)
-	} else if t.workspace != "" {
-		cmd.Dir = t.workspace
+	} else if workspace != "" {
+		cmd.Dir = workspace
 	}
 
-	if t.restrictToWorkspace && t.workspace != "" {
-		effectiveDir := cmd.Dir
-		if effectiveDir == "" {
-			var err error
-			effectiveDir, err = os.Getwd()
-			if err != nil {
-				return fmt.Sprintf("Error: cannot determine working directory: %v", err)
-			}
-		}
-		absDir, err := filepath.Abs(effectiveDir)
-		if err != nil {
-			return fmt.Sprintf("Error: cannot resolve working directory %q: %v", effectiveDir, err)
-		}
-		absDir, err = filepath.EvalSymlinks(absDir)
-		if err != nil {
-			return fmt.Sprintf("Error: cannot resolve symlinks for %q: %v", absDir, err)
-		}
-		absWorkspace, err := filepath.Abs(t.workspace)
-		if err != nil {
-			return fmt.Sprintf("Error: cannot resolve workspace %q: %v", t.workspace, err)
-		}
-		absWorkspace, err = filepath.EvalSymlinks(absWorkspace)
-		if err != nil {
-			return fmt.Sprintf("Error: cannot resolve symlinks for workspace %q: %v", absWorkspace, err)
-		}
-		sep := string(filepath.Separator)
-		if absDir != absWorkspace && !strings.HasPrefix(absDir+sep, absWorkspace+sep) {
-			return fmt.Sprintf("Error: working directory %q is outside workspace %q (restrictToWorkspace is enabled)", effectiveDir, t.workspace)
-		}
+	if errMsg := t.workdirRefusal(workspace, cmd.Dir); errMsg != "" {
+		return errMsg
 	}
 
-	output, err := cmd.CombinedOutput()
+	output := newTailBuffer(execOutputMaxBytes)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = execWaitDelay
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Sprintf("Command failed: %v\nOutput:\n", err)
+	}
+	if progress := progressFrom(ctx); progress != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go reportProgress(progress, a.Command, output, done)
+	}
+	err = cmd.Wait()
+
 	if execCtx.Err() == context.DeadlineExceeded {
-		return fmt.Sprintf("Error: command timed out after %d seconds\nPartial output:\n%s", timeout, string(output))
+		return fmt.Sprintf("Error: command timed out after %d seconds\nPartial output:\n%s", timeout, output.String())
 	}
 
 	if err != nil {
-		return fmt.Sprintf("Command failed: %v\nOutput:\n%s", err, string(output))
+		return fmt.Sprintf("Command failed: %v\nOutput:\n%s", err, output.String())
 	}
 
-	result := string(output)
+	result := output.String()
 	if result == "" {
 		return "(no output)"
 	}
-	result, _ = truncateWithNotice(result, execOutputMaxChars)
 
 	return result
 }
+
+// workdirRefusal enforces restrictToWorkspace for the directory a command
+// runs in ("" means the current directory). It returns the error to report,
+// or "" when the command may run there.
+func (t *ExecTool) workdirRefusal(workspace, dir string) string {
+	if !t.restrictToWorkspace || workspace == "" {
+		return ""
+	}
+	effectiveDir := dir
+	if effectiveDir == "" {
+		var err error
+		effectiveDir, err = os.Getwd()
+		if err != nil {
+			return fmt.Sprintf("Error: cannot determine working directory: %v", err)
+		}
+	}
+	absDir, err := filepath.Abs(effectiveDir)
+	if err != nil {
+		return fmt.Sprintf("Error: cannot resolve working directory %q: %v", effectiveDir, err)
+	}
+	absDir, err = filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return fmt.Sprintf("Error: cannot resolve symlinks for %q: %v", absDir, err)
+	}
+	absWorkspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return fmt.Sprintf("Error: cannot resolve workspace %q: %v", workspace, err)
+	}
+	absWorkspace, err = filepath.EvalSymlinks(absWorkspace)
+	if err != nil {
+		return fmt.Sprintf("Error: cannot resolve symlinks for workspace %q: %v", absWorkspace, err)
+	}
+	sep := string(filepath.Separator)
+	if absDir != absWorkspace && !strings.HasPrefix(absDir+sep, absWorkspace+sep) {
+		return fmt.Sprintf("Error: working directory %q is outside workspace %q (restrictToWorkspace is enabled)", effectiveDir, workspace)
+	}
+	return ""
+}
+
+// environ builds the command environment: the host environment (or only its
+// allowlisted variables in sanitized mode) overlaid with extra. It returns nil,
+// meaning inherit everything, when there is nothing to change.
+func (t *ExecTool) environ(extra map[string]string) ([]string, error) {
+	for key := range extra {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return nil, fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+	if !t.sanitizeEnv && len(extra) == 0 {
+		return nil, nil
+	}
+
+	var env []string
+	if t.sanitizeEnv {
+		allow := t.envAllowlist
+		if len(allow) == 0 {
+			allow = defaultExecEnvAllowlist
+		}
+		for _, key := range allow {
+			if value, ok := os.LookupEnv(key); ok {
+				env = append(env, key+"="+value)
+			}
+		}
+	} else {
+		env = os.Environ()
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+extra[key])
+	}
+	return env, nil
+}
@@ -0,0 +1,410 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+// MoveFileTool renames or moves a file or directory.
+type MoveFileTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *MoveFileTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "move_file",
+			Description: "Move or rename a file or directory. Relative paths are resolved from workspace root. " +
+				"Creates the destination's parent directories if needed. Refuses to replace an existing file unless overwrite is set.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"source": map[string]any{
+						"type":        "string",
+						"description": "The file or directory to move.",
+					},
+					"destination": map[string]any{
+						"type":        "string",
+						"description": "The new path.",
+					},
+					"overwrite": map[string]any{
+						"type":        "boolean",
+						"description": "Replace an existing destination file. Defaults to false. Directories are never replaced.",
+					},
+				},
+				"required": []string{"source", "destination"},
+			},
+		},
+	}
+}
+
+// moveFileArgs are the arguments for move_file.
+type moveFileArgs struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Overwrite   bool   `json:"overwrite,omitempty"`
+}
+
+// Run executes the tool.
+func (t *MoveFileTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a moveFileArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if strings.TrimSpace(a.Source) == "" || strings.TrimSpace(a.Destination) == "" {
+		return "Error: source and destination are required"
+	}
+
+	src, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Source)
+	if errMsg != "" {
+		return errMsg
+	}
+	dst, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Destination)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedSrc, resolvedDst := absOrOriginal(src), absOrOriginal(dst)
+
+	if _, err := os.Lstat(src); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: source not found: %s", formatResolvedPath(a.Source, resolvedSrc))
+		}
+		return fmt.Sprintf("Error: failed to stat source: %s: %v", formatResolvedPath(a.Source, resolvedSrc), err)
+	}
+	if errMsg := guardWorkspaceRoot(workspaceFor(ctx, t.workspace), a.Source, resolvedSrc, "move"); errMsg != "" {
+		return errMsg
+	}
+	if resolvedSrc == resolvedDst {
+		return fmt.Sprintf("Error: source and destination are the same: %s", resolvedSrc)
+	}
+	if info, err := os.Lstat(dst); err == nil {
+		if info.IsDir() {
+			return fmt.Sprintf("Error: destination is an existing directory: %s", formatResolvedPath(a.Destination, resolvedDst))
+		}
+		if !a.Overwrite {
+			return fmt.Sprintf("Error: destination already exists: %s; set overwrite to replace it", formatResolvedPath(a.Destination, resolvedDst))
+		}
+	}
+
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Sprintf("Error: failed to create parent directory: %s: %v", formatResolvedPath(dir, absOrOriginal(dir)), err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Sprintf("Error: failed to move %s to %s: %v", formatResolvedPath(a.Source, resolvedSrc), formatResolvedPath(a.Destination, resolvedDst), err)
+	}
+	return fmt.Sprintf("Moved %s to %s", formatResolvedPath(a.Source, resolvedSrc), formatResolvedPath(a.Destination, resolvedDst))
+}
+
+// DeleteFileTool deletes a file, or a directory when recursive is set.
+type DeleteFileTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *DeleteFileTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "delete_file",
+			Description: "Delete a file or an empty directory. Set recursive to delete a directory and everything in it. " +
+				"Relative paths are resolved from workspace root. The workspace root itself cannot be deleted.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "The file or directory to delete.",
+					},
+					"recursive": map[string]any{
+						"type":        "boolean",
+						"description": "Delete a non-empty directory with all its contents. Defaults to false.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}
+}
+
+// deleteFileArgs are the arguments for delete_file.
+type deleteFileArgs struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+// Run executes the tool.
+func (t *DeleteFileTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a deleteFileArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if strings.TrimSpace(a.Path) == "" {
+		return "Error: path is required"
+	}
+
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedPath := absOrOriginal(path)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: path not found: %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to stat path: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	if errMsg := guardWorkspaceRoot(workspaceFor(ctx, t.workspace), a.Path, resolvedPath, "delete"); errMsg != "" {
+		return errMsg
+	}
+
+	if info.IsDir() && a.Recursive {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Sprintf("Error: failed to delete directory: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+		}
+		return fmt.Sprintf("Deleted directory %s", formatResolvedPath(a.Path, resolvedPath))
+	}
+	if err := os.Remove(path); err != nil {
+		if info.IsDir() {
+			return fmt.Sprintf("Error: directory is not empty: %s; set recursive to delete it and its contents", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to delete: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("Deleted empty directory %s", formatResolvedPath(a.Path, resolvedPath))
+	}
+	return fmt.Sprintf("Deleted %s", formatResolvedPath(a.Path, resolvedPath))
+}
+
+// guardWorkspaceRoot refuses to move or delete the workspace root or any
+// directory containing it. A symlink is judged by itself, not its target,
+// since removing or renaming it leaves the target alone.
+func guardWorkspaceRoot(workspace, input, path, verb string) string {
+	if workspace == "" {
+		return ""
+	}
+	realWorkspace := absOrOriginal(workspace)
+	if resolved, err := filepath.EvalSymlinks(realWorkspace); err == nil {
+		realWorkspace = resolved
+	}
+	realPath := path
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		realPath = filepath.Join(resolved, filepath.Base(path))
+	}
+	if isWithinDir(realPath, realWorkspace) {
+		return fmt.Sprintf("Error: refusing to %s %q: it is or contains the workspace root %q", verb, input, workspace)
+	}
+	return ""
+}
+
+// CopyFileTool copies a file.
+type CopyFileTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *CopyFileTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "copy_file",
+			Description: "Copy a file, keeping its permissions. Relative paths are resolved from workspace root. " +
+				"Creates the destination's parent directories if needed. Refuses to replace an existing file unless overwrite is set.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"source": map[string]any{
+						"type":        "string",
+						"description": "The file to copy.",
+					},
+					"destination": map[string]any{
+						"type":        "string",
+						"description": "The path of the copy.",
+					},
+					"overwrite": map[string]any{
+						"type":        "boolean",
+						"description": "Replace an existing destination file. Defaults to false.",
+					},
+				},
+				"required": []string{"source", "destination"},
+			},
+		},
+	}
+}
+
+// copyFileArgs are the arguments for copy_file.
+type copyFileArgs struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Overwrite   bool   `json:"overwrite,omitempty"`
+}
+
+// Run executes the tool.
+func (t *CopyFileTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a copyFileArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if strings.TrimSpace(a.Source) == "" || strings.TrimSpace(a.Destination) == "" {
+		return "Error: source and destination are required"
+	}
+
+	src, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Source)
+	if errMsg != "" {
+		return errMsg
+	}
+	dst, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Destination)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedSrc, resolvedDst := absOrOriginal(src), absOrOriginal(dst)
+
+	info, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: source not found: %s", formatResolvedPath(a.Source, resolvedSrc))
+		}
+		return fmt.Sprintf("Error: failed to stat source: %s: %v", formatResolvedPath(a.Source, resolvedSrc), err)
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("Error: source is a directory; only files can be copied: %s", formatResolvedPath(a.Source, resolvedSrc))
+	}
+	if dstInfo, err := os.Stat(dst); err == nil {
+		if os.SameFile(info, dstInfo) {
+			return fmt.Sprintf("Error: source and destination are the same file: %s", resolvedSrc)
+		}
+		if dstInfo.IsDir() {
+			return fmt.Sprintf("Error: destination is an existing directory: %s", formatResolvedPath(a.Destination, resolvedDst))
+		}
+		if !a.Overwrite {
+			return fmt.Sprintf("Error: destination already exists: %s; set overwrite to replace it", formatResolvedPath(a.Destination, resolvedDst))
+		}
+	}
+
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Sprintf("Error: failed to create parent directory: %s: %v", formatResolvedPath(dir, absOrOriginal(dir)), err)
+	}
+	n, err := copyFile(src, dst, info.Mode().Perm())
+	if err != nil {
+		return fmt.Sprintf("Error: failed to copy %s to %s: %v", formatResolvedPath(a.Source, resolvedSrc), formatResolvedPath(a.Destination, resolvedDst), err)
+	}
+	return fmt.Sprintf("Copied %d bytes from %s to %s", n, formatResolvedPath(a.Source, resolvedSrc), formatResolvedPath(a.Destination, resolvedDst))
+}
+
+// copyFile copies src to dst, truncating dst, and applies perm to it.
+func copyFile(src, dst string, perm os.FileMode) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Chmod(dst, perm)
+}
+
+// StatFileTool reports metadata about a path without reading it.
+type StatFileTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *StatFileTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "stat_file",
+			Description: "Show a file or directory's size, permissions, modification time and type as JSON, without reading it. " +
+				"Use this to check how large a file is before reading it. Relative paths are resolved from workspace root.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "The file or directory to inspect.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}
+}
+
+// statFileArgs are the arguments for stat_file.
+type statFileArgs struct {
+	Path string `json:"path"`
+}
+
+// statFileResult is the JSON returned by stat_file.
+type statFileResult struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Mode     string `json:"mode"`
+	Modified string `json:"modified"`
+	IsDir    bool   `json:"is_dir"`
+	Symlink  string `json:"symlink,omitempty"` // link target when path is a symlink
+}
+
+// Run executes the tool.
+func (t *StatFileTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a statFileArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedPath := absOrOriginal(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: path not found: %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to stat path: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	result := statFileResult{
+		Path:     resolvedPath,
+		Size:     info.Size(),
+		Mode:     info.Mode().String(),
+		Modified: info.ModTime().UTC().Format(time.RFC3339),
+		IsDir:    info.IsDir(),
+	}
+	if linkInfo, err := os.Lstat(path); err == nil && linkInfo.Mode()&os.ModeSymlink != 0 {
+		result.Symlink, _ = os.Readlink(path)
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("Error: failed to encode result: %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMoveFileToolMovesAndRefusesOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{"a.txt": "a", "b.txt": "b"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := &MoveFileTool{workspace: dir}
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+
+	if out := run(`{"source":"a.txt","destination":"b.txt"}`); !strings.Contains(out, "already exists") {
+		t.Fatalf("Run() = %q, want overwrite refusal", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "b.txt")); string(data) != "b" {
+		t.Fatalf("destination changed without overwrite: %q", data)
+	}
+
+	if out := run(`{"source":"a.txt","destination":"sub/dir/a.txt"}`); !strings.HasPrefix(out, "Moved ") {
+		t.Fatalf("Run() = %q, want move", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("source still present after move (err %v)", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "dir", "a.txt")); string(data) != "a" {
+		t.Fatalf("moved file = %q, want %q", data, "a")
+	}
+
+	if out := run(`{"source":"sub/dir/a.txt","destination":"b.txt","overwrite":true}`); !strings.HasPrefix(out, "Moved ") {
+		t.Fatalf("Run(overwrite) = %q, want move", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "b.txt")); string(data) != "a" {
+		t.Fatalf("overwritten file = %q, want %q", data, "a")
+	}
+
+	if out := run(`{"source":"b.txt","destination":"sub","overwrite":true}`); !strings.Contains(out, "existing directory") {
+		t.Fatalf("Run(onto directory) = %q, want refusal", out)
+	}
+	if out := run(`{"source":".","destination":"../elsewhere"}`); !strings.Contains(out, "workspace root") {
+		t.Fatalf("Run(workspace root) = %q, want refusal", out)
+	}
+}
+
+func TestDeleteFileToolGuardsDirectoriesAndWorkspaceRoot(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(filepath.Join(dir, "build", "out"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"notes.txt", filepath.Join("build", "out", "app")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := &DeleteFileTool{workspace: dir}
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+
+	if out := run(`{"path":" ","recursive":true}`); out != "Error: path is required" {
+		t.Fatalf("Run(empty path) = %q, want required error", out)
+	}
+	for _, args := range []string{`{"path":"."}`, `{"path":"..","recursive":true}`, `{"path":"` + dir + `/","recursive":true}`} {
+		if out := run(args); !strings.Contains(out, "workspace root") {
+			t.Fatalf("Run(%s) = %q, want workspace root refusal", args, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("workspace contents touched: %v", err)
+	}
+
+	if out := run(`{"path":"build"}`); !strings.Contains(out, "set recursive") {
+		t.Fatalf("Run(non-empty dir) = %q, want recursive hint", out)
+	}
+	if out := run(`{"path":"notes.txt"}`); !strings.HasPrefix(out, "Deleted ") {
+		t.Fatalf("Run(file) = %q, want delete", out)
+	}
+	if out := run(`{"path":"build","recursive":true}`); !strings.HasPrefix(out, "Deleted directory") {
+		t.Fatalf("Run(recursive) = %q, want delete", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "build")); !os.IsNotExist(err) {
+		t.Fatalf("directory still present (err %v)", err)
+	}
+	if out := run(`{"path":"missing.txt"}`); !strings.HasPrefix(out, "Error: path not found") {
+		t.Fatalf("Run(missing) = %q, want not found", out)
+	}
+}
+
+func TestMoveAndDeleteHonorWorkspaceJail(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "outside.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg := NewRegistry()
+	reg.RegisterDefaultTools(workspace, DefaultToolsConfig{RestrictToWorkspace: true})
+
+	for _, c := range []struct{ tool, args string }{
+		{"move_file", `{"source":"../outside.txt","destination":"in.txt"}`},
+		{"delete_file", `{"path":"../outside.txt"}`},
+	} {
+		if out := reg.Run(context.Background(), c.tool, json.RawMessage(c.args)); !strings.Contains(out, "is outside workspace") {
+			t.Fatalf("%s = %q, want outside-workspace error", c.tool, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside.txt")); err != nil {
+		t.Fatalf("outside file touched: %v", err)
+	}
+}
+
+func TestCopyFileToolCopiesAndRespectsOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "old.sh"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := &CopyFileTool{workspace: dir}
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+
+	if out := run(`{"source":"run.sh","destination":"backup/run.sh"}`); !strings.HasPrefix(out, "Copied 18 bytes") {
+		t.Fatalf("Run() = %q, want copy", out)
+	}
+	copied := filepath.Join(dir, "backup", "run.sh")
+	if data, _ := os.ReadFile(copied); string(data) != "#!/bin/sh\necho hi\n" {
+		t.Fatalf("copy = %q", data)
+	}
+	if info, err := os.Stat(copied); err != nil || info.Mode().Perm() != 0755 {
+		t.Fatalf("copy mode = %v, %v; want 0755", info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run.sh")); err != nil {
+		t.Fatalf("source removed by copy: %v", err)
+	}
+
+	if out := run(`{"source":"run.sh","destination":"old.sh"}`); !strings.Contains(out, "already exists") {
+		t.Fatalf("Run() = %q, want overwrite refusal", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.sh")); string(data) != "old" {
+		t.Fatalf("destination changed without overwrite: %q", data)
+	}
+	if out := run(`{"source":"run.sh","destination":"old.sh","overwrite":true}`); !strings.HasPrefix(out, "Copied ") {
+		t.Fatalf("Run(overwrite) = %q, want copy", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.sh")); string(data) != "#!/bin/sh\necho hi\n" {
+		t.Fatalf("overwritten file = %q", data)
+	}
+
+	if out := run(`{"source":"run.sh","destination":"./run.sh","overwrite":true}`); !strings.Contains(out, "same file") {
+		t.Fatalf("Run(onto itself) = %q, want refusal", out)
+	}
+	if out := run(`{"source":"backup","destination":"backup2"}`); !strings.Contains(out, "source is a directory") {
+		t.Fatalf("Run(directory) = %q, want refusal", out)
+	}
+}
+
+func TestStatFileToolReportsMetadata(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	tool := &StatFileTool{workspace: dir}
+
+	var got statFileResult
+	out := tool.Run(context.Background(), json.RawMessage(`{"path":"notes.txt"}`))
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Run() = %q, want JSON: %v", out, err)
+	}
+	want := statFileResult{Path: path, Size: 5, Mode: "-rw-r-----", Modified: "2024-05-06T07:08:09Z"}
+	if got != want {
+		t.Fatalf("Run() = %+v, want %+v", got, want)
+	}
+
+	out = tool.Run(context.Background(), json.RawMessage(`{"path":"."}`))
+	if err := json.Unmarshal([]byte(out), &got); err != nil || !got.IsDir {
+		t.Fatalf("Run(dir) = %q, want a directory", out)
+	}
+	if out := tool.Run(context.Background(), json.RawMessage(`{"path":"missing"}`)); !strings.HasPrefix(out, "Error: path not found") {
+		t.Fatalf("Run(missing) = %q, want not found", out)
+	}
+}
@@ -1,12 +1,14 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
@@ -24,11 +26,33 @@ func formatResolvedPath(input, resolved string) string {
 	return fmt.Sprintf("%s (resolved: %s)", input, resolved)
 }
 
-const readFileDefaultLimit = 100
+// resolveFileToolPath resolves a file tool path against the active workspace
+// and, when restricted, rejects paths that land outside it.
+func resolveFileToolPath(ctx context.Context, defaultWorkspace string, restrict bool, input string) (string, string) {
+	workspace := workspaceFor(ctx, defaultWorkspace)
+	path := resolveToolPath(input, workspace)
+	if restrict && workspace != "" {
+		if errMsg := checkWithinWorkspace(workspace, input, absOrOriginal(path)); errMsg != "" {
+			return "", errMsg
+		}
+	}
+	return path, ""
+}
+
+const (
+	readFileDefaultLimit = 100
+	binarySniffBytes     = 8192
+)
 
 // ReadFileTool reads the contents of a file with line-based pagination.
 type ReadFileTool struct {
-	workspace string
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// NewReadFileTool creates a read_file tool rooted at workspace.
+func NewReadFileTool(workspace string) *ReadFileTool {
+	return &ReadFileTool{workspace: workspace}
 }
 
 // Def returns the tool definition.
@@ -39,7 +63,7 @@ func (t *ReadFileTool) Def() provider.ToolDef {
 			Name: "read_file",
 			Description: "Read lines from a file. Returns up to 100 lines starting from offset (default 1). " +
 				"If the file has more lines than the limit, a notice is appended showing total line count " +
-				"so you can make follow-up calls with offset to read the rest.",
+				"so you can make follow-up calls with offset to read the rest. Binary files are rejected unless force is set.",
 			Parameters: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -55,6 +79,10 @@ func (t *ReadFileTool) Def() provider.ToolDef {
 						"type":        "integer",
 						"description": "Maximum number of lines to return. Defaults to 100.",
 					},
+					"force": map[string]any{
+						"type":        "boolean",
+						"description": "Read the file even if it appears to be binary. Defaults to false.",
+					},
 				},
 				"required": []string{"path"},
 			},
@@ -67,6 +95,7 @@ type readFileArgs struct {
 	Path   string `json:"path"`
 	Offset int    `json:"offset,omitempty"`
 	Limit  int    `json:"limit,omitempty"`
+	Force  bool   `json:"force,omitempty"`
 }
 
 // Run executes the tool.
@@ -76,7 +105,10 @@ func (t *ReadFileTool) Run(ctx context.Context, args json.RawMessage) string {
 		return errMsg
 	}
 
-	path := resolveToolPath(a.Path, t.workspace)
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
 	resolvedPath := absOrOriginal(path)
 	logger.Debug("read_file resolved path", "inputPath", a.Path, "resolvedPath", resolvedPath)
 
@@ -99,6 +131,9 @@ func (t *ReadFileTool) Run(ctx context.Context, args json.RawMessage) string {
 	if len(content) == 0 {
 		return fmt.Sprintf("Error: file exists but is empty: %s", resolvedPath)
 	}
+	if !a.Force && looksBinary(content) {
+		return fmt.Sprintf("Error: file appears to be binary (%d bytes); use a different tool: %s", len(content), resolvedPath)
+	}
 
 	allLines := strings.Split(string(content), "\n")
 	totalLines := len(allLines)
@@ -133,9 +168,23 @@ func (t *ReadFileTool) Run(ctx context.Context, args json.RawMessage) string {
 	return sb.String()
 }
 
+// looksBinary sniffs the first binarySniffBytes of content for NUL bytes or
+// invalid UTF-8. A multi-byte rune cut off by the sniff window is allowed.
+func looksBinary(content []byte) bool {
+	sample := content
+	if len(sample) > binarySniffBytes {
+		sample = sample[:binarySniffBytes]
+		for i := 0; i < utf8.UTFMax-1 && len(sample) > 0 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(sample)
+}
+
 // WriteFileTool writes content to a file.
 type WriteFileTool struct {
-	workspace string
+	workspace           string
+	restrictToWorkspace bool
 }
 
 // Def returns the tool definition.
@@ -176,7 +225,10 @@ func (t *WriteFileTool) Run(ctx context.Context, args json.RawMessage) string {
 		return errMsg
 	}
 
-	path := resolveToolPath(a.Path, t.workspace)
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
 	resolvedPath := absOrOriginal(path)
 
 	// Create parent directories
@@ -196,7 +248,8 @@ func (t *WriteFileTool) Run(ctx context.Context, args json.RawMessage) string {
 
 // AppendFileTool appends content to a file.
 type AppendFileTool struct {
-	workspace string
+	workspace           string
+	restrictToWorkspace bool
 }
 
 // Def returns the tool definition.
@@ -236,7 +289,10 @@ func (t *AppendFileTool) Run(ctx context.Context, args json.RawMessage) string {
 		return errMsg
 	}
 
-	path := resolveToolPath(a.Path, t.workspace)
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
 	resolvedPath := absOrOriginal(path)
 
 	dir := filepath.Dir(path)
@@ -260,7 +316,8 @@ func (t *AppendFileTool) Run(ctx context.Context, args json.RawMessage) string {
 
 // EditFileTool edits a file by replacing text.
 type EditFileTool struct {
-	workspace string
+	workspace           string
+	restrictToWorkspace bool
 }
 
 // Def returns the tool definition.
@@ -269,7 +326,7 @@ func (t *EditFileTool) Def() provider.ToolDef {
 		Type: "function",
 		Function: provider.FunctionDef{
 			Name:        "edit_file",
-			Description: "Edit a file by replacing specific text. Relative paths are resolved from workspace root. The old_text must match exactly (including whitespace).",
+			Description: "Edit a file by replacing specific text. Relative paths are resolved from workspace root. The old_text must match exactly (including whitespace). Set preview to see a unified diff without writing.",
 			Parameters: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -285,6 +342,10 @@ func (t *EditFileTool) Def() provider.ToolDef {
 						"type":        "string",
 						"description": "The text to replace with.",
 					},
+					"preview": map[string]any{
+						"type":        "boolean",
+						"description": "If true, return a unified diff of the change without modifying the file.",
+					},
 				},
 				"required": []string{"path", "old_text", "new_text"},
 			},
@@ -297,6 +358,7 @@ type editFileArgs struct {
 	Path    string `json:"path"`
 	OldText string `json:"old_text"`
 	NewText string `json:"new_text"`
+	Preview bool   `json:"preview,omitempty"`
 }
 
 // Run executes the tool.
@@ -306,7 +368,10 @@ func (t *EditFileTool) Run(ctx context.Context, args json.RawMessage) string {
 		return errMsg
 	}
 
-	path := resolveToolPath(a.Path, t.workspace)
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
 	resolvedPath := absOrOriginal(path)
 
 	content, err := os.ReadFile(path)
@@ -327,9 +392,351 @@ func (t *EditFileTool) Run(ctx context.Context, args json.RawMessage) string {
 	}
 
 	newContent := strings.Replace(contentStr, a.OldText, a.NewText, 1)
+	if a.Preview {
+		diff := unifiedDiff(a.Path, contentStr, newContent)
+		if diff == "" {
+			return fmt.Sprintf("Preview: no changes (old_text equals new_text) for %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Preview only, file not modified: %s\n\n%s", formatResolvedPath(a.Path, resolvedPath), diff)
+	}
 	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
 		return fmt.Sprintf("Error: failed to write file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
 	}
 
 	return fmt.Sprintf("Successfully edited %s", formatResolvedPath(a.Path, resolvedPath))
 }
+
+// MultiEditTool applies several text replacements to one file atomically.
+type MultiEditTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *MultiEditTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "multi_edit",
+			Description: "Apply several edits to one file in a single call. Edits are applied in order; each old_text must " +
+				"match exactly once in the file as modified by the previous edits. If any edit fails, no changes are written.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "The path to the file to edit.",
+					},
+					"edits": map[string]any{
+						"type":        "array",
+						"description": "Edits to apply in order.",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"old_text": map[string]any{
+									"type":        "string",
+									"description": "The exact text to find and replace.",
+								},
+								"new_text": map[string]any{
+									"type":        "string",
+									"description": "The text to replace with.",
+								},
+							},
+							"required": []string{"old_text", "new_text"},
+						},
+					},
+				},
+				"required": []string{"path", "edits"},
+			},
+		},
+	}
+}
+
+// multiEditArgs are the arguments for multi_edit.
+type multiEditArgs struct {
+	Path  string `json:"path"`
+	Edits []struct {
+		OldText string `json:"old_text"`
+		NewText string `json:"new_text"`
+	} `json:"edits"`
+}
+
+// Run executes the tool.
+func (t *MultiEditTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a multiEditArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if len(a.Edits) == 0 {
+		return "Error: edits must contain at least one edit"
+	}
+
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedPath := absOrOriginal(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: file not found: %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to stat file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to read file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+
+	contentStr := string(content)
+	for i, e := range a.Edits {
+		if e.OldText == "" {
+			return fmt.Sprintf("Error: edit %d has empty old_text; no changes written (path: %s)", i+1, formatResolvedPath(a.Path, resolvedPath))
+		}
+		count := strings.Count(contentStr, e.OldText)
+		if count == 0 {
+			return fmt.Sprintf("Error: edit %d: text not found in file: %q; no changes written (path: %s)", i+1, e.OldText, formatResolvedPath(a.Path, resolvedPath))
+		}
+		if count > 1 {
+			return fmt.Sprintf("Error: edit %d: text appears %d times in file; match must be unique. Provide more context; no changes written (path: %s)", i+1, count, formatResolvedPath(a.Path, resolvedPath))
+		}
+		contentStr = strings.Replace(contentStr, e.OldText, e.NewText, 1)
+	}
+
+	if err := os.WriteFile(path, []byte(contentStr), info.Mode().Perm()); err != nil {
+		return fmt.Sprintf("Error: failed to write file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+
+	return fmt.Sprintf("Successfully applied %d edits to %s", len(a.Edits), formatResolvedPath(a.Path, resolvedPath))
+}
+
+// ReplaceAllTool replaces every occurrence of a text in a file, guarded by
+// the number of occurrences the caller expects.
+type ReplaceAllTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *ReplaceAllTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "replace_all",
+			Description: "Replace every occurrence of old_text in a file with new_text. Relative paths are resolved from workspace root. " +
+				"expected_count must equal the actual number of occurrences, otherwise nothing is written and the real count is returned. " +
+				"Use edit_file for a single occurrence.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "The path to the file to edit.",
+					},
+					"old_text": map[string]any{
+						"type":        "string",
+						"description": "The exact text to replace.",
+					},
+					"new_text": map[string]any{
+						"type":        "string",
+						"description": "The text to replace with.",
+					},
+					"expected_count": map[string]any{
+						"type":        "integer",
+						"description": "How many occurrences you expect to replace.",
+					},
+				},
+				"required": []string{"path", "old_text", "new_text", "expected_count"},
+			},
+		},
+	}
+}
+
+// replaceAllArgs are the arguments for replace_all.
+type replaceAllArgs struct {
+	Path          string `json:"path"`
+	OldText       string `json:"old_text"`
+	NewText       string `json:"new_text"`
+	ExpectedCount *int   `json:"expected_count"`
+}
+
+// Run executes the tool.
+func (t *ReplaceAllTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a replaceAllArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if a.OldText == "" {
+		return "Error: old_text must not be empty"
+	}
+	if a.ExpectedCount == nil || *a.ExpectedCount < 1 {
+		return "Error: expected_count is required and must be at least 1"
+	}
+
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedPath := absOrOriginal(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: file not found: %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to stat file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to read file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+
+	contentStr := string(content)
+	count := strings.Count(contentStr, a.OldText)
+	if count != *a.ExpectedCount {
+		return fmt.Sprintf("Error: text appears %d times in file, but expected_count is %d; no changes written (path: %s)",
+			count, *a.ExpectedCount, formatResolvedPath(a.Path, resolvedPath))
+	}
+
+	newContent := strings.ReplaceAll(contentStr, a.OldText, a.NewText)
+	if err := os.WriteFile(path, []byte(newContent), info.Mode().Perm()); err != nil {
+		return fmt.Sprintf("Error: failed to write file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	return fmt.Sprintf("Successfully replaced %d occurrences in %s", count, formatResolvedPath(a.Path, resolvedPath))
+}
+
+// editLinesContext is how many lines around an edit_lines change are shown.
+const editLinesContext = 3
+
+// EditLinesTool replaces a range of lines in a file.
+type EditLinesTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *EditLinesTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "edit_lines",
+			Description: "Replace lines start_line through end_line (1-based, inclusive) of a file with content, using the line numbers read_file shows. " +
+				"Use this when edit_file cannot match the text exactly. Empty content deletes the lines. " +
+				"To append, set start_line and end_line to one past the last line. Returns the edited region with line numbers.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "The path to the file to edit.",
+					},
+					"start_line": map[string]any{
+						"type":        "integer",
+						"description": "First line to replace (1-based).",
+					},
+					"end_line": map[string]any{
+						"type":        "integer",
+						"description": "Last line to replace (inclusive).",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": "The replacement lines.",
+					},
+				},
+				"required": []string{"path", "start_line", "end_line", "content"},
+			},
+		},
+	}
+}
+
+// editLinesArgs are the arguments for edit_lines.
+type editLinesArgs struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+}
+
+// Run executes the tool.
+func (t *EditLinesTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a editLinesArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	path, errMsg := resolveFileToolPath(ctx, t.workspace, t.restrictToWorkspace, a.Path)
+	if errMsg != "" {
+		return errMsg
+	}
+	resolvedPath := absOrOriginal(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: file not found: %s", formatResolvedPath(a.Path, resolvedPath))
+		}
+		return fmt.Sprintf("Error: failed to stat file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("Error: path is a directory, not a file: %s", formatResolvedPath(a.Path, resolvedPath))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to read file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+
+	lines := splitFileLines(string(content))
+	total := len(lines)
+	appending := a.StartLine == total+1 && a.EndLine == total+1
+	if !appending && (a.StartLine < 1 || a.EndLine < a.StartLine || a.EndLine > total) {
+		return fmt.Sprintf("Error: line range %d-%d is out of bounds; file has %d lines (use start_line=end_line=%d to append): %s",
+			a.StartLine, a.EndLine, total, total+1, formatResolvedPath(a.Path, resolvedPath))
+	}
+	replacement := splitFileLines(a.Content)
+	if appending && len(replacement) == 0 {
+		return "Error: content is empty; nothing to append"
+	}
+
+	end := a.EndLine
+	if appending {
+		end = total
+	}
+	edited := make([]string, 0, total+len(replacement))
+	edited = append(edited, lines[:a.StartLine-1]...)
+	edited = append(edited, replacement...)
+	edited = append(edited, lines[end:]...)
+
+	newContent := strings.Join(edited, "\n")
+	if len(edited) > 0 && (len(content) == 0 || strings.HasSuffix(string(content), "\n")) {
+		newContent += "\n"
+	}
+	if err := os.WriteFile(path, []byte(newContent), info.Mode().Perm()); err != nil {
+		return fmt.Sprintf("Error: failed to write file: %s: %v", formatResolvedPath(a.Path, resolvedPath), err)
+	}
+
+	var sb strings.Builder
+	if appending {
+		fmt.Fprintf(&sb, "Successfully appended %d lines to %s", len(replacement), formatResolvedPath(a.Path, resolvedPath))
+	} else {
+		fmt.Fprintf(&sb, "Successfully replaced lines %d-%d with %d lines in %s", a.StartLine, a.EndLine, len(replacement), formatResolvedPath(a.Path, resolvedPath))
+	}
+	from := max(a.StartLine-editLinesContext, 1)
+	to := min(a.StartLine+len(replacement)-1+editLinesContext, len(edited))
+	if from <= to {
+		fmt.Fprintf(&sb, "\n\n[Lines %d-%d of %d after the edit]\n", from, to, len(edited))
+		for i := from; i <= to; i++ {
+			fmt.Fprintf(&sb, "%d\t%s\n", i, edited[i-1])
+		}
+	}
+	return sb.String()
+}
+
+// splitFileLines splits text into lines. A trailing newline ends the last
+// line rather than starting an empty one.
+func splitFileLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
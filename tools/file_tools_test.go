@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFileToolRejectsBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	png := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), make([]byte, 32)...)
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), png, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	tool := NewReadFileTool(dir)
+
+	out := tool.Run(context.Background(), json.RawMessage(`{"path":"image.png"}`))
+	if !strings.HasPrefix(out, "Error: file appears to be binary (") {
+		t.Fatalf("Run() = %q, want binary file error", out)
+	}
+	if !strings.Contains(out, "(48 bytes)") {
+		t.Fatalf("Run() = %q, want byte count in error", out)
+	}
+
+	forced := tool.Run(context.Background(), json.RawMessage(`{"path":"image.png","force":true}`))
+	if strings.HasPrefix(forced, "Error:") {
+		t.Fatalf("Run(force) = %q, want content", forced)
+	}
+}
+
+func TestReadFileToolReadsUTF8Text(t *testing.T) {
+	dir := t.TempDir()
+	content := "第一行 hello\nsecond ✓\nthird"
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	out := NewReadFileTool(dir).Run(context.Background(), json.RawMessage(`{"path":"notes.txt","offset":2,"limit":1}`))
+	want := "[Showing lines 2-2 of 3 total. Use offset=3 to read more.]\n\n2\tsecond ✓\n"
+	if out != want {
+		t.Fatalf("Run() = %q, want %q", out, want)
+	}
+}
+
+func TestLooksBinaryAllowsRuneCutAtSniffBoundary(t *testing.T) {
+	text := []byte(strings.Repeat("a", binarySniffBytes-1) + "中文")
+	if looksBinary(text) {
+		t.Fatal("looksBinary() = true for UTF-8 text split at the sniff boundary")
+	}
+	if !looksBinary([]byte{0xff, 0xfe, 'a'}) {
+		t.Fatal("looksBinary() = false for invalid UTF-8")
+	}
+}
+
+func TestMultiEditToolAppliesAllEdits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("func a() {}\nfunc b() {}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	out := (&MultiEditTool{workspace: dir}).Run(context.Background(), json.RawMessage(`{
+		"path": "main.go",
+		"edits": [
+			{"old_text": "func a()", "new_text": "func alpha()"},
+			{"old_text": "func alpha() {}", "new_text": "func alpha() { b() }"},
+			{"old_text": "func b()", "new_text": "func beta()"}
+		]
+	}`))
+	if !strings.HasPrefix(out, "Successfully applied 3 edits") {
+		t.Fatalf("Run() = %q, want success", out)
+	}
+	got, _ := os.ReadFile(path)
+	if want := "func alpha() { b() }\nfunc beta() {}\n"; string(got) != want {
+		t.Fatalf("file = %q, want %q", got, want)
+	}
+}
+
+func TestMultiEditToolIsAllOrNothing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	original := "one\ntwo\ntwo\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	tool := &MultiEditTool{workspace: dir}
+
+	tests := []struct {
+		name  string
+		args  string
+		error string
+	}{
+		{
+			name:  "missing text",
+			args:  `{"path":"notes.txt","edits":[{"old_text":"one","new_text":"1"},{"old_text":"three","new_text":"3"}]}`,
+			error: "Error: edit 2: text not found",
+		},
+		{
+			name:  "ambiguous text",
+			args:  `{"path":"notes.txt","edits":[{"old_text":"one","new_text":"1"},{"old_text":"two","new_text":"2"}]}`,
+			error: "Error: edit 2: text appears 2 times",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tool.Run(context.Background(), json.RawMessage(tt.args))
+			if !strings.HasPrefix(out, tt.error) {
+				t.Fatalf("Run() = %q, want prefix %q", out, tt.error)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != original {
+				t.Fatalf("file changed to %q after failed edit, want %q", got, original)
+			}
+		})
+	}
+}
+
+func TestEditFileToolPreviewDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	original := "a: 1\nb: 2\nc: 3\nd: 4\ne: 5\nf: 6\ng: 7\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	tool := &EditFileTool{workspace: dir}
+
+	out := tool.Run(context.Background(), json.RawMessage(`{"path":"config.yaml","old_text":"d: 4","new_text":"d: 40","preview":true}`))
+	if !strings.HasPrefix(out, "Preview only, file not modified") {
+		t.Fatalf("Run(preview) = %q, want preview", out)
+	}
+	wantDiff := "--- a/config.yaml\n+++ b/config.yaml\n@@ -1,7 +1,7 @@\n a: 1\n b: 2\n c: 3\n-d: 4\n+d: 40\n e: 5\n f: 6\n g: 7\n"
+	if !strings.HasSuffix(out, wantDiff) {
+		t.Fatalf("Run(preview) diff = %q, want suffix %q", out, wantDiff)
+	}
+	if got, _ := os.ReadFile(path); string(got) != original {
+		t.Fatalf("file changed in preview mode: %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("x\nx\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	dup := tool.Run(context.Background(), json.RawMessage(`{"path":"config.yaml","old_text":"x","new_text":"y","preview":true}`))
+	if !strings.Contains(dup, "appears 2 times") {
+		t.Fatalf("Run(preview) on ambiguous text = %q, want uniqueness error", dup)
+	}
+}
+
+func TestUnifiedDiffTrimsContext(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	newText := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+	want := "--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"
+	if got := unifiedDiff("f", oldText, newText); got != want {
+		t.Fatalf("unifiedDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestFileToolsRestrictToWorkspace(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{workspace, outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	reg := NewRegistry()
+	reg.RegisterDefaultTools(workspace, DefaultToolsConfig{RestrictToWorkspace: true})
+	run := func(name, args string) string {
+		t.Helper()
+		return reg.Run(context.Background(), name, json.RawMessage(args))
+	}
+
+	escapes := []struct{ tool, args string }{
+		{"read_file", `{"path":"../outside/secret.txt"}`},
+		{"read_file", `{"path":"../../etc/passwd"}`},
+		{"read_file", `{"path":"` + filepath.Join(outside, "secret.txt") + `"}`},
+		{"read_file", `{"path":"link/secret.txt"}`},
+		{"write_file", `{"path":"link/new.txt","content":"x"}`},
+		{"append_file", `{"path":"../outside/secret.txt","content":"x"}`},
+		{"edit_file", `{"path":"link/secret.txt","old_text":"secret","new_text":"x"}`},
+		{"multi_edit", `{"path":"link/secret.txt","edits":[{"old_text":"secret","new_text":"x"}]}`},
+	}
+	for _, c := range escapes {
+		if out := run(c.tool, c.args); !strings.Contains(out, "is outside workspace") {
+			t.Errorf("%s %s = %q, want outside-workspace error", c.tool, c.args, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("write through symlink created a file outside the workspace (err %v)", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "secret.txt")); string(data) != "secret" {
+		t.Fatalf("outside file modified: %q", data)
+	}
+
+	if out := run("read_file", `{"path":"notes.txt"}`); !strings.Contains(out, "notes") {
+		t.Fatalf("read_file inside workspace = %q", out)
+	}
+	if out := run("write_file", `{"path":"sub/dir/new.txt","content":"ok"}`); strings.HasPrefix(out, "Error:") {
+		t.Fatalf("write_file to a new directory inside workspace = %q", out)
+	}
+}
+
+func TestEditLinesTool(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.txt")
+	write := func() {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\nfive\nsix\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := &EditLinesTool{workspace: dir}
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+	read := func() string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	write()
+	out := run(`{"path":"list.txt","start_line":3,"end_line":4,"content":"THREE\n  3.5\nFOUR"}`)
+	if got, want := read(), "one\ntwo\nTHREE\n  3.5\nFOUR\nfive\nsix\n"; got != want {
+		t.Fatalf("file = %q, want %q", got, want)
+	}
+	wantOut := "[Lines 1-7 of 7 after the edit]\n1\tone\n2\ttwo\n3\tTHREE\n4\t  3.5\n5\tFOUR\n6\tfive\n7\tsix\n"
+	if !strings.HasPrefix(out, "Successfully replaced lines 3-4 with 3 lines") || !strings.HasSuffix(out, wantOut) {
+		t.Fatalf("Run() = %q, want summary and context %q", out, wantOut)
+	}
+
+	write()
+	for _, args := range []string{
+		`{"path":"list.txt","start_line":0,"end_line":1,"content":"x"}`,
+		`{"path":"list.txt","start_line":5,"end_line":7,"content":"x"}`,
+		`{"path":"list.txt","start_line":4,"end_line":3,"content":"x"}`,
+		`{"path":"list.txt","start_line":9,"end_line":9,"content":"x"}`,
+	} {
+		if out := run(args); !strings.Contains(out, "out of bounds; file has 6 lines") {
+			t.Fatalf("Run(%s) = %q, want out-of-bounds error", args, out)
+		}
+	}
+	if got := read(); got != "one\ntwo\nthree\nfour\nfive\nsix\n" {
+		t.Fatalf("file changed by a rejected edit: %q", got)
+	}
+
+	out = run(`{"path":"list.txt","start_line":7,"end_line":7,"content":"seven\neight\n"}`)
+	if got, want := read(), "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"; got != want {
+		t.Fatalf("file after append = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(out, "Successfully appended 2 lines") || !strings.HasSuffix(out, "4\tfour\n5\tfive\n6\tsix\n7\tseven\n8\teight\n") {
+		t.Fatalf("Run(append) = %q", out)
+	}
+
+	run(`{"path":"list.txt","start_line":2,"end_line":7,"content":""}`)
+	if got, want := read(), "one\neight\n"; got != want {
+		t.Fatalf("file after delete = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceAllToolChecksExpectedCount(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	original := "host=old\nbackup=old\n# old comment\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := &ReplaceAllTool{workspace: dir}
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+
+	out := run(`{"path":"config.ini","old_text":"old","new_text":"new","expected_count":2}`)
+	if !strings.Contains(out, "text appears 3 times in file, but expected_count is 2") {
+		t.Fatalf("Run(mismatch) = %q, want real count", out)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Fatalf("file changed on count mismatch: %q", data)
+	}
+	if out := run(`{"path":"config.ini","old_text":"old","new_text":"new"}`); !strings.Contains(out, "expected_count is required") {
+		t.Fatalf("Run(no count) = %q, want required error", out)
+	}
+
+	out = run(`{"path":"config.ini","old_text":"old","new_text":"new","expected_count":3}`)
+	if !strings.HasPrefix(out, "Successfully replaced 3 occurrences") {
+		t.Fatalf("Run(match) = %q", out)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "host=new\nbackup=new\n# new comment\n" {
+		t.Fatalf("file = %q", data)
+	}
+}
@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+const (
+	grepDefaultMaxMatches = 100
+	grepMaxMatchesLimit   = 1000
+	grepMaxLineChars      = 300
+	grepMaxFileBytes      = 2 << 20
+	grepBinarySniffBytes  = 8000
+)
+
+// errGrepLimit stops the directory walk once max_matches is reached.
+var errGrepLimit = errors.New("grep match limit reached")
+
+// GrepTool searches file contents under the workspace with a regular expression.
+type GrepTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *GrepTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "grep",
+			Description: "Search file contents with a regular expression (RE2 syntax). Returns matching lines as " +
+				"file:line:content, like ripgrep. Skips .git, node_modules, vendor and binary files. " +
+				"Use this instead of reading files one by one to find where something is defined or used.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"pattern": map[string]any{
+						"type":        "string",
+						"description": "Regular expression to search for.",
+					},
+					"path": map[string]any{
+						"type":        "string",
+						"description": "Optional file or directory to search. Defaults to workspace.",
+					},
+					"glob": map[string]any{
+						"type":        "string",
+						"description": "Optional file name filter, e.g. \"*.go\". Matched against the base name.",
+					},
+					"max_matches": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of matching lines to return. Defaults to 100, max 1000.",
+					},
+				},
+				"required": []string{"pattern"},
+			},
+		},
+	}
+}
+
+// grepArgs are the arguments for grep.
+type grepArgs struct {
+	Pattern    string `json:"pattern"`
+	Path       string `json:"path,omitempty"`
+	Glob       string `json:"glob,omitempty"`
+	MaxMatches int    `json:"max_matches,omitempty"`
+}
+
+// Run executes the tool.
+func (t *GrepTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a grepArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if a.Pattern == "" {
+		return "Error: pattern is required"
+	}
+	re, err := regexp.Compile(a.Pattern)
+	if err != nil {
+		return fmt.Sprintf("Error: invalid pattern: %v", err)
+	}
+	if a.Glob != "" {
+		if _, err := filepath.Match(a.Glob, ""); err != nil {
+			return fmt.Sprintf("Error: invalid glob %q: %v", a.Glob, err)
+		}
+	}
+
+	maxMatches := a.MaxMatches
+	if maxMatches <= 0 {
+		maxMatches = grepDefaultMaxMatches
+	}
+	if maxMatches > grepMaxMatchesLimit {
+		maxMatches = grepMaxMatchesLimit
+	}
+
+	workspace := workspaceFor(ctx, t.workspace)
+	root := workspace
+	if a.Path != "" {
+		root = resolveToolPath(a.Path, workspace)
+	}
+	if root == "" {
+		return "Error: no path given and workspace is not set"
+	}
+	root = absOrOriginal(root)
+
+	if t.restrictToWorkspace && workspace != "" {
+		if errMsg := checkWithinWorkspace(workspace, a.Path, root); errMsg != "" {
+			return errMsg
+		}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: path not found: %s", formatResolvedPath(a.Path, root))
+		}
+		return fmt.Sprintf("Error: cannot access path: %s: %v", formatResolvedPath(a.Path, root), err)
+	}
+
+	var out strings.Builder
+	matches := 0
+	searchFile := func(path string) error {
+		if a.Glob != "" {
+			if ok, _ := filepath.Match(a.Glob, filepath.Base(path)); !ok {
+				return nil
+			}
+		}
+		n, limited := grepFile(path, grepDisplayPath(workspace, path), re, maxMatches-matches, &out)
+		matches += n
+		if limited {
+			return errGrepLimit
+		}
+		return nil
+	}
+
+	if !info.IsDir() {
+		err = searchFile(root)
+	} else {
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if walkErr != nil {
+				// Unreadable entries are skipped rather than failing the search.
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && shouldSkipWalkDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			return searchFile(path)
+		})
+	}
+
+	truncated := errors.Is(err, errGrepLimit)
+	if err != nil && !truncated {
+		return fmt.Sprintf("Error: search failed: %v", err)
+	}
+	if matches == 0 {
+		return fmt.Sprintf("No matches for %q in %s", a.Pattern, formatResolvedPath(a.Path, root))
+	}
+
+	result := strings.TrimRight(out.String(), "\n")
+	if truncated {
+		result += fmt.Sprintf(
+			"\n[Truncated] Stopped after %d matches. Narrow the pattern, path or glob to see the rest.",
+			maxMatches,
+		)
+	}
+	result, _ = truncateWithNotice(result, toolResultMaxChars)
+	return result
+}
+
+// checkWithinWorkspace enforces restrictToWorkspace for root, following
+// symlinks. root need not exist yet; its deepest existing ancestor decides.
+func checkWithinWorkspace(workspace, input, root string) string {
+	absWorkspace := absOrOriginal(workspace)
+	if realWorkspace, err := filepath.EvalSymlinks(absWorkspace); err == nil {
+		absWorkspace = realWorkspace
+	}
+	realRoot, ok := evalSymlinksPrefix(root)
+	if !ok || !isWithinDir(absWorkspace, realRoot) {
+		return fmt.Sprintf("Error: path %q is outside workspace %q (restrictToWorkspace is enabled)", input, workspace)
+	}
+	return ""
+}
+
+// evalSymlinksPrefix resolves symlinks in the longest existing prefix of path
+// and appends the rest, so a file about to be created is judged by where it
+// would really land. It fails on a dangling symlink, whose target is unknown.
+func evalSymlinksPrefix(path string) (string, bool) {
+	var rest []string
+	for p := path; ; p = filepath.Dir(p) {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), true
+		}
+		if _, err := os.Lstat(p); err == nil {
+			return "", false
+		}
+		if filepath.Dir(p) == p {
+			return path, true
+		}
+		rest = append([]string{filepath.Base(p)}, rest...)
+	}
+}
+
+// grepDisplayPath renders path relative to the workspace when possible.
+func grepDisplayPath(workspace, path string) string {
+	if workspace != "" {
+		ws := absOrOriginal(workspace)
+		if isWithinDir(ws, path) {
+			if rel, err := filepath.Rel(ws, path); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return path
+}
+
+// grepFile appends up to limit matching lines of path to out. It reports the
+// number of matches written and whether the limit was hit.
+func grepFile(path, display string, re *regexp.Regexp, limit int, out *strings.Builder) (int, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err != nil || info.Size() > grepMaxFileBytes {
+		return 0, false
+	}
+
+	reader := bufio.NewReaderSize(f, grepBinarySniffBytes)
+	if head, _ := reader.Peek(grepBinarySniffBytes); bytes.IndexByte(head, 0) >= 0 {
+		return 0, false
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), grepMaxFileBytes)
+	matches := 0
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if !re.MatchString(line) {
+			continue
+		}
+		if matches >= limit {
+			return matches, true
+		}
+		fmt.Fprintf(out, "%s:%d:%s\n", display, lineNo, clipGrepLine(line))
+		matches++
+	}
+	return matches, false
+}
+
+// clipGrepLine shortens a long matching line, cutting on a rune boundary.
+func clipGrepLine(line string) string {
+	if len(line) <= grepMaxLineChars {
+		return line
+	}
+	n := grepMaxLineChars
+	for n > 0 && !utf8.RuneStart(line[n]) {
+		n--
+	}
+	return line[:n] + "..."
+}
+
+// shouldSkipWalkDir reports directories skipped by workspace walks, mirroring
+// the health tree walk.
+func shouldSkipWalkDir(name string) bool {
+	switch name {
+	case ".git", "node_modules", "vendor", ".tmp":
+		return true
+	default:
+		return false
+	}
+}
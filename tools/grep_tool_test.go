@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func runGrep(t *testing.T, tool *GrepTool, args string) string {
+	t.Helper()
+	return tool.Run(context.Background(), json.RawMessage(args))
+}
+
+func TestGrepToolReportsMatches(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":             "package main\n\nfunc main() {}\n",
+		"sub/util.go":         "package sub\n\nfunc Helper() {}\nfunc main2() {}\n",
+		"sub/notes.txt":       "func main in prose\n",
+		"node_modules/x/x.go": "func main() {}\n",
+		"bin/data.bin":        "func main\x00\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := &GrepTool{workspace: dir}
+
+	out := runGrep(t, tool, `{"pattern":"^func main","glob":"*.go"}`)
+	want := "main.go:3:func main() {}\nsub/util.go:4:func main2() {}"
+	if out != want {
+		t.Fatalf("Run() = %q, want %q", out, want)
+	}
+
+	out = runGrep(t, tool, `{"pattern":"func main","max_matches":1}`)
+	if !strings.HasPrefix(out, "main.go:3:") || !strings.Contains(out, "[Truncated] Stopped after 1 matches.") {
+		t.Fatalf("Run(max_matches=1) = %q, want one match and a truncation notice", out)
+	}
+
+	if out := runGrep(t, tool, `{"pattern":"nothing here"}`); !strings.HasPrefix(out, `No matches for "nothing here"`) {
+		t.Fatalf("Run() = %q, want no matches", out)
+	}
+	if out := runGrep(t, tool, `{"pattern":"("}`); !strings.HasPrefix(out, "Error: invalid pattern") {
+		t.Fatalf("Run() = %q, want invalid pattern error", out)
+	}
+}
+
+func TestGrepToolClipsLongLinesOnRuneBoundary(t *testing.T) {
+	dir := t.TempDir()
+	line := "x" + strings.Repeat("中", grepMaxLineChars)
+	if err := os.WriteFile(filepath.Join(dir, "wide.txt"), []byte(line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runGrep(t, &GrepTool{workspace: dir}, `{"pattern":"x"}`)
+	content := strings.TrimPrefix(out, "wide.txt:1:")
+	if content == out || !strings.HasSuffix(content, "...") {
+		t.Fatalf("Run() = %q, want a clipped match", out)
+	}
+	if !utf8.ValidString(content) {
+		t.Fatalf("clipped line is not valid UTF-8: %q", content)
+	}
+	if n := len(strings.TrimSuffix(content, "...")); n > grepMaxLineChars || n < grepMaxLineChars-3 {
+		t.Fatalf("clipped line is %d bytes, want just under %d", n, grepMaxLineChars)
+	}
+}
+
+func TestGrepToolRestrictToWorkspace(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("token=abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(workspace, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	tool := &GrepTool{workspace: workspace, restrictToWorkspace: true}
+
+	for _, path := range []string{"..", "../secret.txt", filepath.Join(root, "secret.txt"), "escape"} {
+		args, _ := json.Marshal(grepArgs{Pattern: "token", Path: path})
+		out := tool.Run(context.Background(), args)
+		if !strings.Contains(out, "is outside workspace") {
+			t.Fatalf("Run(path=%q) = %q, want outside-workspace error", path, out)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+const (
+	hashDefaultAlgorithm = "sha256"
+	hashDirMaxEntries    = 100000
+)
+
+// errHashWalkLimit stops a directory walk once hashDirMaxEntries is reached.
+var errHashWalkLimit = errors.New("directory entry limit reached")
+
+// HashTool computes file checksums and directory size summaries.
+type HashTool struct {
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// Def returns the tool definition.
+func (t *HashTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "hash",
+			Description: "For a file: compute its checksum (sha256 by default) and size. " +
+				"For a directory: report total size, file count and directory count of the subtree " +
+				"(skips .git, node_modules, vendor). Use for integrity checks and detecting changes without exec.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "File or directory path.",
+					},
+					"algorithm": map[string]any{
+						"type":        "string",
+						"enum":        []string{"sha256", "sha512", "sha1", "md5"},
+						"description": "Checksum algorithm for files. Defaults to sha256.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}
+}
+
+// hashArgs are the arguments for hash.
+type hashArgs struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// Run executes the tool.
+func (t *HashTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a hashArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if strings.TrimSpace(a.Path) == "" {
+		return "Error: path is required"
+	}
+
+	algorithm := strings.ToLower(strings.TrimSpace(a.Algorithm))
+	if algorithm == "" {
+		algorithm = hashDefaultAlgorithm
+	}
+	newHash := hashConstructor(algorithm)
+	if newHash == nil {
+		return fmt.Sprintf("Error: unsupported algorithm %q (use sha256, sha512, sha1 or md5)", a.Algorithm)
+	}
+
+	workspace := workspaceFor(ctx, t.workspace)
+	path := absOrOriginal(resolveToolPath(a.Path, workspace))
+	if t.restrictToWorkspace && workspace != "" {
+		if errMsg := checkWithinWorkspace(workspace, a.Path, path); errMsg != "" {
+			return errMsg
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: path not found: %s", formatResolvedPath(a.Path, path))
+		}
+		return fmt.Sprintf("Error: cannot access path: %s: %v", formatResolvedPath(a.Path, path), err)
+	}
+	if info.IsDir() {
+		return summarizeDir(ctx, a.Path, path)
+	}
+	return hashFile(a.Path, path, algorithm, newHash)
+}
+
+func hashConstructor(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New
+	case "sha512":
+		return sha512.New
+	case "sha1":
+		return sha1.New
+	case "md5":
+		return md5.New
+	default:
+		return nil
+	}
+}
+
+func hashFile(input, path, algorithm string, newHash func() hash.Hash) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to open file: %s: %v", formatResolvedPath(input, path), err)
+	}
+	defer f.Close()
+
+	h := newHash()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to read file: %s: %v", formatResolvedPath(input, path), err)
+	}
+	return fmt.Sprintf("%s: %s\nsize: %d bytes\npath: %s", algorithm, hex.EncodeToString(h.Sum(nil)), n, path)
+}
+
+// summarizeDir walks the subtree, bounded by hashDirMaxEntries.
+func summarizeDir(ctx context.Context, input, root string) string {
+	var files, dirs, totalBytes int64
+	entries := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if walkErr != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if entries++; entries > hashDirMaxEntries {
+			return errHashWalkLimit
+		}
+		if d.IsDir() {
+			if shouldSkipWalkDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			dirs++
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files++
+			totalBytes += info.Size()
+		}
+		return nil
+	})
+
+	truncated := errors.Is(err, errHashWalkLimit)
+	if err != nil && !truncated {
+		return fmt.Sprintf("Error: failed to walk directory: %s: %v", formatResolvedPath(input, root), err)
+	}
+
+	result := fmt.Sprintf("directory: %s\nfiles: %d\ndirectories: %d\nsize: %d bytes", root, files, dirs, totalBytes)
+	if truncated {
+		result += fmt.Sprintf("\n[Truncated] Stopped after %d entries; totals are partial. Use a narrower path.", hashDirMaxEntries)
+	}
+	return result
+}
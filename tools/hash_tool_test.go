@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashToolKnownDigests(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "abc.txt"), []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := &HashTool{workspace: dir}
+
+	for algorithm, digest := range map[string]string{
+		"":       "sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"sha256": "sha256: ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"sha512": "sha512: ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+		"SHA1":   "sha1: a9993e364706816aba3e25717850c26c9cd0d89d",
+		"md5":    "md5: 900150983cd24fb0d6963f7d28e17f72",
+	} {
+		args, _ := json.Marshal(hashArgs{Path: "abc.txt", Algorithm: algorithm})
+		out := tool.Run(context.Background(), args)
+		if !strings.HasPrefix(out, digest+"\nsize: 3 bytes\n") {
+			t.Fatalf("Run(%q) = %q, want %q", algorithm, out, digest)
+		}
+	}
+
+	if out := tool.Run(context.Background(), json.RawMessage(`{"path":"abc.txt","algorithm":"crc32"}`)); !strings.HasPrefix(out, "Error: unsupported algorithm") {
+		t.Fatalf("Run(crc32) = %q, want unsupported algorithm error", out)
+	}
+	if out := tool.Run(context.Background(), json.RawMessage(`{"path":"missing.txt"}`)); !strings.HasPrefix(out, "Error: path not found") {
+		t.Fatalf("Run(missing) = %q, want path not found", out)
+	}
+}
+
+func TestHashToolSummarizesDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{"a.txt": "12345", "sub/b.txt": "123", ".git/HEAD": "ref"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := (&HashTool{workspace: dir}).Run(context.Background(), json.RawMessage(`{"path":"."}`))
+	if !strings.Contains(out, "files: 2\ndirectories: 1\nsize: 8 bytes") {
+		t.Fatalf("Run(dir) = %q, want two files in one directory, .git skipped", out)
+	}
+}
+
+func TestHashToolRestrictToWorkspace(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "outside.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "outside.txt"), filepath.Join(workspace, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	tool := &HashTool{workspace: workspace, restrictToWorkspace: true}
+
+	for _, path := range []string{"../outside.txt", filepath.Join(root, "outside.txt"), "link.txt"} {
+		args, _ := json.Marshal(hashArgs{Path: path})
+		if out := tool.Run(context.Background(), args); !strings.Contains(out, "is outside workspace") {
+			t.Fatalf("Run(%q) = %q, want outside-workspace error", path, out)
+		}
+	}
+}
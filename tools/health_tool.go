@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	healthsnap "github.com/linanwx/nagobot/internal/health"
@@ -15,7 +17,8 @@ import (
 type HealthRuntimeContext struct {
 	ThreadID    string
 	SessionKey  string
-	SessionFile string
+	SessionFile string // empty unless sessions are stored as files
+	Session     *HealthSessionInfo
 	AgentName   string
 }
 
@@ -25,6 +28,9 @@ type HealthContextProvider func() HealthRuntimeContext
 // HealthChannelsInfo holds channel config for health output.
 type HealthChannelsInfo = healthsnap.ChannelsInfo
 
+// HealthSessionInfo holds diagnostics for the current session.
+type HealthSessionInfo = healthsnap.SessionInfo
+
 // HealthTelegramInfo holds Telegram config for health output.
 type HealthTelegramInfo = healthsnap.TelegramInfo
 
@@ -41,6 +47,24 @@ type HealthTool struct {
 	Channels      *HealthChannelsInfo
 	CtxFn         HealthContextProvider
 	ThreadsListFn func() []ThreadInfo
+	DroppedFn     func() map[string]int64
+}
+
+// channelsInfo merges static channel config with live dropped-message counts.
+func (t *HealthTool) channelsInfo() *HealthChannelsInfo {
+	if t.DroppedFn == nil {
+		return t.Channels
+	}
+	dropped := t.DroppedFn()
+	if len(dropped) == 0 {
+		return t.Channels
+	}
+	info := HealthChannelsInfo{}
+	if t.Channels != nil {
+		info = *t.Channels
+	}
+	info.Dropped = dropped
+	return &info
 }
 
 // Def returns the tool definition.
@@ -55,8 +79,13 @@ func (t *HealthTool) Def() provider.ToolDef {
 				"properties": map[string]any{
 					"format": map[string]any{
 						"type":        "string",
-						"description": "Output format. Defaults to 'yaml'.",
-						"enum":        []string{"yaml", "json"},
+						"description": "Output format. Defaults to 'yaml'. 'compact' is single-line JSON for automated consumers.",
+						"enum":        []string{"yaml", "json", "compact"},
+					},
+					"fields": map[string]any{
+						"type":        "array",
+						"description": "Optional top-level sections to include, e.g. [\"memory\", \"goroutines\"]. Defaults to all.",
+						"items":       map[string]any{"type": "string"},
 					},
 				},
 			},
@@ -65,7 +94,8 @@ func (t *HealthTool) Def() provider.ToolDef {
 }
 
 type healthArgs struct {
-	Format string `json:"format,omitempty"`
+	Format string   `json:"format,omitempty"`
+	Fields []string `json:"fields,omitempty"`
 }
 
 // Run executes the tool.
@@ -97,7 +127,8 @@ func (t *HealthTool) Run(ctx context.Context, args json.RawMessage) string {
 		AgentName:      runtimeCtx.AgentName,
 		SessionKey:     runtimeCtx.SessionKey,
 		SessionFile:    runtimeCtx.SessionFile,
-		Channels:       t.Channels,
+		Session:        runtimeCtx.Session,
+		Channels:       t.channelsInfo(),
 		IncludeTree:    true,
 		TreeDepth:      treeDepth,
 		TreeMaxEntries: treeMaxEntries,
@@ -107,17 +138,77 @@ func (t *HealthTool) Run(ctx context.Context, args json.RawMessage) string {
 		snapshot.AllThreads = t.ThreadsListFn()
 	}
 
-	if strings.EqualFold(a.Format, "json") {
-		data, err := json.MarshalIndent(snapshot, "", "  ")
+	format := strings.ToLower(strings.TrimSpace(a.Format))
+	tag := "yaml"
+	if format == "json" || format == "compact" {
+		tag = "json"
+	}
+	var out any = snapshot
+	if len(a.Fields) > 0 {
+		selected, err := selectSnapshotFields(snapshot, a.Fields, tag)
 		if err != nil {
-			return fmt.Sprintf("Error: failed to serialize health snapshot: %v", err)
+			return fmt.Sprintf("Error: %v", err)
 		}
-		return string(data)
+		out = selected
 	}
 
-	data, err := yaml.Marshal(snapshot)
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(out, "", "  ")
+	case "compact":
+		data, err = json.Marshal(out)
+	default:
+		data, err = yaml.Marshal(out)
+	}
 	if err != nil {
 		return fmt.Sprintf("Error: failed to serialize health snapshot: %v", err)
 	}
 	return string(data)
 }
+
+// selectSnapshotFields returns the requested top-level sections of s keyed by
+// their tag names. Fields match either their JSON or YAML name, ignoring case.
+func selectSnapshotFields(s healthsnap.Snapshot, fields []string, tag string) (map[string]any, error) {
+	want := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			want[f] = true
+		}
+	}
+
+	v := reflect.ValueOf(s)
+	typ := v.Type()
+	out := make(map[string]any, len(want))
+	var known []string
+	for i := 0; i < typ.NumField(); i++ {
+		jsonName := tagName(typ.Field(i).Tag.Get("json"))
+		yamlName := tagName(typ.Field(i).Tag.Get("yaml"))
+		known = append(known, jsonName)
+		if !want[strings.ToLower(jsonName)] && !want[strings.ToLower(yamlName)] {
+			continue
+		}
+		delete(want, strings.ToLower(jsonName))
+		delete(want, strings.ToLower(yamlName))
+		name := jsonName
+		if tag == "yaml" {
+			name = yamlName
+		}
+		out[name] = v.Field(i).Interface()
+	}
+	if len(want) > 0 {
+		unknown := make([]string, 0, len(want))
+		for f := range want {
+			unknown = append(unknown, f)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown health fields %s (available: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return out, nil
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
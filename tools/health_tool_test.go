@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func runHealth(t *testing.T, args string) string {
+	t.Helper()
+	tool := &HealthTool{Workspace: t.TempDir()}
+	return tool.Run(context.Background(), json.RawMessage(args))
+}
+
+func TestHealthCompactFormat(t *testing.T) {
+	out := runHealth(t, `{"format":"compact"}`)
+	if strings.Contains(out, "\n") {
+		t.Fatalf("compact output spans multiple lines: %q", out)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("compact output is not valid JSON: %v\n%s", err, out)
+	}
+	if decoded["status"] != "healthy" {
+		t.Fatalf("unexpected status: %v", decoded["status"])
+	}
+}
+
+func TestHealthFieldSelection(t *testing.T) {
+	out := runHealth(t, `{"format":"compact","fields":["memory","Goroutines"]}`)
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if len(decoded) != 2 || decoded["memory"] == nil || decoded["goroutines"] == nil {
+		t.Fatalf("expected only memory and goroutines, got %s", out)
+	}
+
+	yamlOut := runHealth(t, `{"fields":["workspace_tree"]}`)
+	if !strings.HasPrefix(yamlOut, "workspace_tree:") || strings.Contains(yamlOut, "goroutines:") {
+		t.Fatalf("unexpected yaml selection: %s", yamlOut)
+	}
+
+	if out := runHealth(t, `{"fields":["bogus"]}`); !strings.HasPrefix(out, "Error: unknown health fields bogus") {
+		t.Fatalf("expected unknown field error, got %q", out)
+	}
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linanwx/nagobot/logger"
+	"github.com/linanwx/nagobot/provider"
+)
+
+const (
+	logsDefaultLines = 100
+	logsMaxLines     = 1000
+)
+
+// logLevelRank orders slog text-handler levels for minimum-level filtering.
+var logLevelRank = map[string]int{"DEBUG": 0, "INFO": 1, "WARN": 2, "ERROR": 3}
+
+// LogsTool tails nagobot's own log file. It only reads the configured log
+// path, never a caller-supplied one.
+type LogsTool struct {
+	path string // overrides logger.FilePath(), for tests
+}
+
+// Def returns the tool definition.
+func (t *LogsTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "logs",
+			Description: "Read the tail of nagobot's own log file, optionally filtered by minimum level and substring. Use to investigate errors and recent activity.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"lines": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("Number of matching lines to return from the end. Defaults to %d, max %d.", logsDefaultLines, logsMaxLines),
+					},
+					"level": map[string]any{
+						"type":        "string",
+						"enum":        []string{"debug", "info", "warn", "error"},
+						"description": "Minimum level to include. Defaults to all levels.",
+					},
+					"grep": map[string]any{
+						"type":        "string",
+						"description": "Only include lines containing this substring (case-insensitive).",
+					},
+				},
+			},
+		},
+	}
+}
+
+// logsArgs are the arguments for logs.
+type logsArgs struct {
+	Lines int    `json:"lines,omitempty"`
+	Level string `json:"level,omitempty"`
+	Grep  string `json:"grep,omitempty"`
+}
+
+// Run executes the tool.
+func (t *LogsTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a logsArgs
+	if len(args) > 0 {
+		if errMsg := parseArgs(args, &a); errMsg != "" {
+			return errMsg
+		}
+	}
+
+	path := t.path
+	if path == "" {
+		path = logger.FilePath()
+	}
+	if path == "" {
+		return "Error: no log file is configured (set logging.file in config)"
+	}
+
+	limit := a.Lines
+	if limit <= 0 {
+		limit = logsDefaultLines
+	}
+	if limit > logsMaxLines {
+		limit = logsMaxLines
+	}
+	minRank := -1
+	if level := strings.ToUpper(strings.TrimSpace(a.Level)); level != "" {
+		if level == "WARNING" {
+			level = "WARN"
+		}
+		rank, ok := logLevelRank[level]
+		if !ok {
+			return fmt.Sprintf("Error: unknown level %q (use debug, info, warn, or error)", a.Level)
+		}
+		minRank = rank
+	}
+	grep := strings.ToLower(a.Grep)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("Error: cannot open log file: %v", err)
+	}
+	defer f.Close()
+
+	// Keep the last limit matches in a ring.
+	ring := make([]string, limit)
+	matched := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if minRank >= 0 && logLineRank(line) < minRank {
+			continue
+		}
+		if grep != "" && !strings.Contains(strings.ToLower(line), grep) {
+			continue
+		}
+		ring[matched%limit] = line
+		matched++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Sprintf("Error: failed to read log file: %v", err)
+	}
+	if matched == 0 {
+		return "(no matching log lines)"
+	}
+
+	// Walk back from the newest match until the line or size budget is spent.
+	n, size := 0, 0
+	for n < min(matched, limit) {
+		line := ring[(matched-1-n)%limit]
+		if n > 0 && size+len(line)+1 > toolResultMaxChars {
+			break
+		}
+		size += len(line) + 1
+		n++
+	}
+	out := make([]string, 0, n)
+	for i := matched - n; i < matched; i++ {
+		out = append(out, ring[i%limit])
+	}
+	result := strings.Join(out, "\n")
+	if matched > n {
+		result = fmt.Sprintf("[Truncated] Showing the last %d of %d matching lines.\n", n, matched) + result
+	}
+	return result
+}
+
+// logLineRank returns the rank of the level field in a slog text or JSON
+// line, or -1 when it has none (e.g. a continuation line).
+func logLineRank(line string) int {
+	var level string
+	if idx := strings.Index(line, "level="); idx >= 0 {
+		level = line[idx+len("level="):]
+		if end := strings.IndexByte(level, ' '); end >= 0 {
+			level = level[:end]
+		}
+	} else if idx := strings.Index(line, `"level":"`); idx >= 0 {
+		level = line[idx+len(`"level":"`):]
+		if end := strings.IndexByte(level, '"'); end >= 0 {
+			level = level[:end]
+		}
+	}
+	if rank, ok := logLevelRank[level]; ok {
+		return rank
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleLog = `time=2026-01-01T10:00:00Z level=DEBUG msg="polling telegram"
+time=2026-01-01T10:00:01Z level=INFO msg="thread started" threadID=t1
+time=2026-01-01T10:00:02Z level=WARN msg="sink delivery slow" channel=telegram
+time=2026-01-01T10:00:03Z level=ERROR msg="thread run error" err="request failed"
+time=2026-01-01T10:00:04Z level=INFO msg="thread finished" threadID=t1
+`
+
+func runLogs(t *testing.T, args string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "nagobot.log")
+	if err := os.WriteFile(path, []byte(sampleLog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return (&LogsTool{path: path}).Run(context.Background(), json.RawMessage(args))
+}
+
+func TestLogsToolTail(t *testing.T) {
+	out := runLogs(t, `{"lines":2}`)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "[Truncated] Showing the last 2 of 5") {
+		t.Fatalf("unexpected tail: %q", out)
+	}
+	if !strings.Contains(lines[1], "thread run error") || !strings.Contains(lines[2], "thread finished") {
+		t.Fatalf("tail should keep the newest lines in order: %q", out)
+	}
+}
+
+func TestLogsToolLevelFilter(t *testing.T) {
+	out := runLogs(t, `{"level":"warn"}`)
+	if strings.Contains(out, "level=INFO") || strings.Contains(out, "level=DEBUG") {
+		t.Fatalf("lower levels were not filtered: %q", out)
+	}
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "level=ERROR") {
+		t.Fatalf("warn and error lines missing: %q", out)
+	}
+}
+
+func TestLogsToolGrep(t *testing.T) {
+	out := runLogs(t, `{"grep":"THREADID=t1","level":"info"}`)
+	if strings.Count(out, "\n") != 1 || !strings.Contains(out, "thread started") || !strings.Contains(out, "thread finished") {
+		t.Fatalf("unexpected grep result: %q", out)
+	}
+	if out := runLogs(t, `{"grep":"nothing like this"}`); out != "(no matching log lines)" {
+		t.Fatalf("unexpected empty result: %q", out)
+	}
+}
+
+func TestLogsToolWithoutLogFile(t *testing.T) {
+	if out := (&LogsTool{}).Run(context.Background(), nil); !strings.HasPrefix(out, "Error: no log file") {
+		t.Fatalf("expected missing log file error, got %q", out)
+	}
+}
+
+func TestLogsToolLevelFilterJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nagobot.log")
+	content := `{"time":"2026-01-01T10:00:00Z","level":"INFO","msg":"thread started"}
+{"time":"2026-01-01T10:00:01Z","level":"ERROR","msg":"thread run error"}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := (&LogsTool{path: path}).Run(context.Background(), json.RawMessage(`{"level":"error"}`))
+	if !strings.Contains(out, "thread run error") || strings.Contains(out, "thread started") {
+		t.Fatalf("unexpected JSON level filtering: %q", out)
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"path/filepath"
 	"strings"
+
+	"github.com/linanwx/nagobot/thread/msg"
 )
 
 type runtimeContextKey struct{}
 
+// Origin is an alias for msg.Origin.
+type Origin = msg.Origin
+
 // RuntimeContext carries lightweight per-run metadata for tools.
 type RuntimeContext struct {
 	SessionKey string
 	Workspace  string
+	Origin     *Origin // nil when the run has no user origin (e.g. one-shot CLI)
 }
 
 // WithRuntimeContext injects tool runtime metadata into context.
@@ -44,3 +50,12 @@ func RuntimeContextFrom(ctx context.Context) RuntimeContext {
 	}
 	return rt
 }
+
+// workspaceFor returns the run's active workspace from ctx, falling back to
+// the tool's configured workspace. Sessions may switch workspaces at runtime.
+func workspaceFor(ctx context.Context, fallback string) string {
+	if ws := RuntimeContextFrom(ctx).Workspace; ws != "" {
+		return ws
+	}
+	return fallback
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+// FileSender delivers a local file through a named channel.
+type FileSender interface {
+	SendFile(ctx context.Context, channelName, path, caption, replyTo string) error
+}
+
+// SendFileTool sends a workspace file to the user through a channel.
+type SendFileTool struct {
+	sender              FileSender
+	workspace           string
+	restrictToWorkspace bool
+}
+
+// NewSendFileTool creates a send_file tool.
+func NewSendFileTool(sender FileSender, workspace string, restrictToWorkspace bool) *SendFileTool {
+	return &SendFileTool{sender: sender, workspace: workspace, restrictToWorkspace: restrictToWorkspace}
+}
+
+// Def returns the tool definition.
+func (t *SendFileTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "send_file",
+			Description: "Send a file (report, chart, archive...) to the user as an attachment. " +
+				"By default it goes to the conversation the current message came from.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{
+						"type":        "string",
+						"description": "File path, relative to the workspace or absolute.",
+					},
+					"caption": map[string]any{
+						"type":        "string",
+						"description": "Optional text sent with the file.",
+					},
+					"channel": map[string]any{
+						"type":        "string",
+						"description": "Optional channel name (telegram, feishu). Defaults to the current conversation's channel.",
+					},
+					"to": map[string]any{
+						"type":        "string",
+						"description": "Optional channel-specific chat address. Defaults to the current conversation.",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+	}
+}
+
+type sendFileArgs struct {
+	Path    string `json:"path"`
+	Caption string `json:"caption,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	To      string `json:"to,omitempty"`
+}
+
+// Run executes the tool.
+func (t *SendFileTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a sendFileArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if t.sender == nil {
+		return "Error: file sender not configured"
+	}
+	if strings.TrimSpace(a.Path) == "" {
+		return "Error: path is required"
+	}
+
+	channelName := strings.TrimSpace(a.Channel)
+	to := strings.TrimSpace(a.To)
+	if origin := RuntimeContextFrom(ctx).Origin; origin != nil {
+		if channelName == "" {
+			channelName = origin.Channel
+		}
+		if to == "" && channelName == origin.Channel {
+			to = origin.ReplyTo
+		}
+	}
+	if channelName == "" || to == "" {
+		return "Error: no conversation to reply to; specify channel and to"
+	}
+
+	workspace := workspaceFor(ctx, t.workspace)
+	path := absOrOriginal(resolveToolPath(a.Path, workspace))
+	if t.restrictToWorkspace && workspace != "" {
+		if errMsg := checkWithinWorkspace(workspace, a.Path, path); errMsg != "" {
+			return errMsg
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: file not found: %s", formatResolvedPath(a.Path, path))
+		}
+		return fmt.Sprintf("Error: cannot access file: %s: %v", formatResolvedPath(a.Path, path), err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Sprintf("Error: not a regular file: %s", formatResolvedPath(a.Path, path))
+	}
+
+	if err := t.sender.SendFile(ctx, channelName, path, strings.TrimSpace(a.Caption), to); err != nil {
+		return fmt.Sprintf("Error: failed to send file: %v", err)
+	}
+	return fmt.Sprintf("File sent via %s: %s (%d bytes)", channelName, path, info.Size())
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeFileSender struct {
+	calls []string
+}
+
+func (f *fakeFileSender) SendFile(_ context.Context, channelName, path, caption, replyTo string) error {
+	f.calls = append(f.calls, strings.Join([]string{channelName, path, caption, replyTo}, "|"))
+	return nil
+}
+
+func TestSendFileDefaultsToCurrentConversation(t *testing.T) {
+	workspace := t.TempDir()
+	file := filepath.Join(workspace, "chart.png")
+	if err := os.WriteFile(file, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sender := &fakeFileSender{}
+	tool := NewSendFileTool(sender, workspace, true)
+	ctx := WithRuntimeContext(context.Background(), RuntimeContext{
+		Origin: &Origin{Channel: "feishu", UserID: "ou_1", ReplyTo: "group:oc_1"},
+	})
+
+	args, _ := json.Marshal(sendFileArgs{Path: "chart.png", Caption: "latest"})
+	out := tool.Run(ctx, args)
+	if !strings.HasPrefix(out, "File sent via feishu") {
+		t.Fatalf("Run() = %q", out)
+	}
+	want := "feishu|" + file + "|latest|group:oc_1"
+	if len(sender.calls) != 1 || sender.calls[0] != want {
+		t.Fatalf("calls = %v, want [%s]", sender.calls, want)
+	}
+}
+
+func TestSendFileRequiresTargetWithoutOrigin(t *testing.T) {
+	workspace := t.TempDir()
+	sender := &fakeFileSender{}
+	tool := NewSendFileTool(sender, workspace, true)
+
+	args, _ := json.Marshal(sendFileArgs{Path: "chart.png", Channel: "telegram"})
+	if out := tool.Run(context.Background(), args); !strings.Contains(out, "specify channel and to") {
+		t.Fatalf("Run() = %q, want missing target error", out)
+	}
+	if len(sender.calls) != 0 {
+		t.Fatalf("unexpected send: %v", sender.calls)
+	}
+}
+
+func TestSendFileRejectsPathOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sender := &fakeFileSender{}
+	args, _ := json.Marshal(sendFileArgs{Path: outside, Channel: "telegram", To: "42"})
+
+	if out := NewSendFileTool(sender, workspace, true).Run(context.Background(), args); !strings.Contains(out, "outside workspace") {
+		t.Fatalf("Run() = %q, want workspace restriction error", out)
+	}
+	if len(sender.calls) != 0 {
+		t.Fatalf("unexpected send: %v", sender.calls)
+	}
+	if out := NewSendFileTool(sender, workspace, false).Run(context.Background(), args); !strings.HasPrefix(out, "File sent") {
+		t.Fatalf("unrestricted Run() = %q", out)
+	}
+}
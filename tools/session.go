@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/session"
+)
+
+// SessionForker copies a session's history into a new session key.
+type SessionForker interface {
+	Fork(srcKey, dstKey string) (*session.Session, error)
+}
+
+// ForkSessionTool branches a conversation into an independent session.
+type ForkSessionTool struct {
+	forker SessionForker
+}
+
+// NewForkSessionTool creates a fork_session tool.
+func NewForkSessionTool(forker SessionForker) *ForkSessionTool {
+	return &ForkSessionTool{forker: forker}
+}
+
+// Def returns the tool definition.
+func (t *ForkSessionTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "fork_session",
+			Description: "Fork a session's conversation history into a new, independent session key. Use it to explore an alternative without changing the original session. " +
+				"Reading from or writing to a session other than the current one requires admin.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"source_key": map[string]any{
+						"type":        "string",
+						"description": "Session key to copy from. Defaults to the current session.",
+					},
+					"target_key": map[string]any{
+						"type":        "string",
+						"description": "New session key to create. Must not already exist.",
+					},
+				},
+				"required": []string{"target_key"},
+			},
+		},
+	}
+}
+
+type forkSessionArgs struct {
+	SourceKey string `json:"source_key"`
+	TargetKey string `json:"target_key"`
+}
+
+// Run executes the tool.
+func (t *ForkSessionTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a forkSessionArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	if t.forker == nil {
+		return "Error: session manager not configured"
+	}
+
+	sourceKey := strings.TrimSpace(a.SourceKey)
+	if sourceKey == "" {
+		sourceKey = RuntimeContextFrom(ctx).SessionKey
+	}
+	if sourceKey == "" {
+		return "Error: source_key is required (no current session)"
+	}
+	targetKey := strings.TrimSpace(a.TargetKey)
+	if targetKey == "" {
+		return "Error: target_key is required"
+	}
+
+	// Forking copies history somewhere else to read, so both ends count.
+	if rt := RuntimeContextFrom(ctx); !mayUseSession(rt, sourceKey) || !mayUseSession(rt, targetKey) {
+		return "Error: only the admin can fork other sessions"
+	}
+
+	forked, err := t.forker.Fork(sourceKey, targetKey)
+	if err != nil {
+		return fmt.Sprintf("Error: fork failed: %v", err)
+	}
+
+	return fmt.Sprintf("Session forked: %s -> %s (%d messages copied)", sourceKey, forked.Key, len(forked.Messages))
+}
+
+// SessionEditor clears or deletes sessions.
+type SessionEditor interface {
+	Clear(key string) error
+	Delete(key string) error
+}
+
+// SessionTool clears or deletes a session's stored history.
+type SessionTool struct {
+	editor SessionEditor
+}
+
+// NewSessionTool creates a session tool.
+func NewSessionTool(editor SessionEditor) *SessionTool {
+	return &SessionTool{editor: editor}
+}
+
+// Def returns the tool definition.
+func (t *SessionTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "session",
+			Description: "Manage stored conversation history. action=clear empties a session's messages (keeps token usage totals); " +
+				"action=delete removes the session entirely. Use clear when the user asks to reset or forget the conversation. " +
+				"The current turn is still recorded afterwards. Acting on a session other than the current one requires admin.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"action": map[string]any{
+						"type":        "string",
+						"enum":        []string{"clear", "delete"},
+						"description": "Operation to perform.",
+					},
+					"key": map[string]any{
+						"type":        "string",
+						"description": "Session key. Defaults to the current session.",
+					},
+				},
+				"required": []string{"action"},
+			},
+		},
+	}
+}
+
+type sessionArgs struct {
+	Action string `json:"action"`
+	Key    string `json:"key,omitempty"`
+}
+
+// Run executes the tool.
+func (t *SessionTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a sessionArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	if t.editor == nil {
+		return "Error: session manager not configured"
+	}
+
+	rt := RuntimeContextFrom(ctx)
+	key := strings.TrimSpace(a.Key)
+	if key == "" {
+		key = rt.SessionKey
+	}
+	if key == "" {
+		return "Error: key is required (no current session)"
+	}
+	if !mayUseSession(rt, key) {
+		return "Error: only the admin can modify other sessions"
+	}
+
+	switch strings.TrimSpace(a.Action) {
+	case "clear":
+		if err := t.editor.Clear(key); err != nil {
+			return fmt.Sprintf("Error: clear failed: %v", err)
+		}
+		return fmt.Sprintf("Session cleared: %s", key)
+	case "delete":
+		if err := t.editor.Delete(key); err != nil {
+			return fmt.Sprintf("Error: delete failed: %v", err)
+		}
+		return fmt.Sprintf("Session deleted: %s", key)
+	default:
+		return fmt.Sprintf("Error: unknown action %q (use clear or delete)", a.Action)
+	}
+}
+
+// mayUseSession reports whether the run may act on the session key: its own
+// session always, any other only for the admin or the local CLI. Runs without
+// a user origin (cron, system wakes) cannot vouch for an admin, so they are
+// limited to their own session too.
+func mayUseSession(rt RuntimeContext, key string) bool {
+	return key == rt.SessionKey || rt.Origin != nil && (rt.Origin.IsAdmin || rt.Origin.Channel == "cli")
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/session"
+)
+
+type recordingSessionEditor struct {
+	cleared, deleted []string
+}
+
+func (e *recordingSessionEditor) Clear(key string) error {
+	e.cleared = append(e.cleared, key)
+	return nil
+}
+
+func (e *recordingSessionEditor) Delete(key string) error {
+	e.deleted = append(e.deleted, key)
+	return nil
+}
+
+func TestSessionToolOtherSessionsRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin *Origin
+		allow  bool
+	}{
+		{"no origin", nil, false},
+		{"user", &Origin{Channel: "telegram", UserID: "2"}, false},
+		{"admin", &Origin{Channel: "telegram", UserID: "1", IsAdmin: true}, true},
+		{"cli", &Origin{Channel: "cli"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := &recordingSessionEditor{}
+			tool := NewSessionTool(editor)
+			ctx := WithRuntimeContext(context.Background(), RuntimeContext{SessionKey: "telegram:2", Origin: tt.origin})
+
+			out := tool.Run(ctx, []byte(`{"action":"delete","key":"telegram:1"}`))
+			if tt.allow != (len(editor.deleted) == 1) {
+				t.Fatalf("Run() = %q, deleted %v; want allowed=%v", out, editor.deleted, tt.allow)
+			}
+			if !tt.allow && !strings.HasPrefix(out, "Error:") {
+				t.Fatalf("Run() = %q, want an error", out)
+			}
+
+			// The current session is always the caller's own.
+			if out := tool.Run(ctx, []byte(`{"action":"clear"}`)); len(editor.cleared) != 1 || editor.cleared[0] != "telegram:2" {
+				t.Fatalf("clear own session = %q, cleared %v", out, editor.cleared)
+			}
+		})
+	}
+}
+
+type recordingForker struct {
+	forks [][2]string
+}
+
+func (f *recordingForker) Fork(srcKey, dstKey string) (*session.Session, error) {
+	f.forks = append(f.forks, [2]string{srcKey, dstKey})
+	return &session.Session{Key: dstKey}, nil
+}
+
+func TestForkSessionToolOtherSessionsRequireAdmin(t *testing.T) {
+	user := &Origin{Channel: "telegram", UserID: "2"}
+	admin := &Origin{Channel: "telegram", UserID: "1", IsAdmin: true}
+	tests := []struct {
+		name   string
+		origin *Origin
+		args   string
+		allow  bool
+	}{
+		{"user reads main", user, `{"source_key":"main","target_key":"telegram:2"}`, false},
+		{"user leaks into web", user, `{"source_key":"main","target_key":"web:foo"}`, false},
+		{"user copies own out", user, `{"target_key":"web:foo"}`, false},
+		{"no origin", nil, `{"source_key":"main","target_key":"web:foo"}`, false},
+		{"admin", admin, `{"source_key":"main","target_key":"web:foo"}`, true},
+		{"cli", &Origin{Channel: "cli"}, `{"source_key":"main","target_key":"web:foo"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forker := &recordingForker{}
+			ctx := WithRuntimeContext(context.Background(), RuntimeContext{SessionKey: "telegram:2", Origin: tt.origin})
+
+			out := NewForkSessionTool(forker).Run(ctx, []byte(tt.args))
+			if tt.allow != (len(forker.forks) == 1) {
+				t.Fatalf("Run() = %q, forks %v; want allowed=%v", out, forker.forks, tt.allow)
+			}
+			if !tt.allow && !strings.Contains(out, "only the admin") {
+				t.Fatalf("Run() = %q, want an admin error", out)
+			}
+		})
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/linanwx/nagobot/provider"
+	"github.com/linanwx/nagobot/skills"
 )
 
 // SkillProvider retrieves skill prompts.
@@ -15,14 +20,34 @@ type SkillProvider interface {
 	SkillNames() []string
 }
 
+// RequirementChecker is implemented by skill providers that can verify a
+// skill's declared host requirements.
+type RequirementChecker interface {
+	// CheckRequirements returns the unmet requirements, one entry each.
+	CheckRequirements(name string) ([]string, error)
+}
+
+// SkillRenderer is implemented by skill providers that can fill {{key}}
+// placeholders in a skill prompt from named arguments.
+type SkillRenderer interface {
+	RenderSkillPrompt(name string, args map[string]string) (string, error)
+}
+
 // UseSkillTool loads the full prompt for a named skill.
 type UseSkillTool struct {
 	provider SkillProvider
+	strict   bool // refuse to load skills with unmet requirements
 }
 
-// NewUseSkillTool creates a new use_skill tool.
-func NewUseSkillTool(provider SkillProvider) *UseSkillTool {
-	return &UseSkillTool{provider: provider}
+// NewUseSkillTool creates a new use_skill tool. In strict mode a skill whose
+// requirements are unmet is not loaded; otherwise it is loaded with a warning.
+func NewUseSkillTool(provider SkillProvider, strict bool) *UseSkillTool {
+	return &UseSkillTool{provider: provider, strict: strict}
+}
+
+// WithProvider returns a copy of the tool that loads skills from provider.
+func (t *UseSkillTool) WithProvider(provider SkillProvider) *UseSkillTool {
+	return &UseSkillTool{provider: provider, strict: t.strict}
 }
 
 // Def returns the tool definition.
@@ -39,6 +64,11 @@ func (t *UseSkillTool) Def() provider.ToolDef {
 						"type":        "string",
 						"description": "The skill name to load (for example: 'research').",
 					},
+					"args": map[string]any{
+						"type":                 "object",
+						"description":          "Optional named arguments filling the skill's {{key}} placeholders, e.g. {\"lang\": \"French\"}.",
+						"additionalProperties": map[string]any{"type": "string"},
+					},
 				},
 				"required": []string{"name"},
 			},
@@ -48,7 +78,8 @@ func (t *UseSkillTool) Def() provider.ToolDef {
 
 // useSkillArgs are the arguments for use_skill.
 type useSkillArgs struct {
-	Name string `json:"name"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
 }
 
 // Run executes the tool.
@@ -64,10 +95,273 @@ func (t *UseSkillTool) Run(ctx context.Context, args json.RawMessage) string {
 		return fmt.Sprintf("Error: skill %q not found. Available skills: %s", a.Name, strings.Join(names, ", "))
 	}
 
+	if renderer, ok := t.provider.(SkillRenderer); ok {
+		values := make(map[string]string, len(a.Args))
+		for k, v := range a.Args {
+			values[k] = fmt.Sprint(v)
+		}
+		rendered, err := renderer.RenderSkillPrompt(a.Name, values)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		prompt = rendered
+	}
+
+	var missing []string
+	if checker, ok := t.provider.(RequirementChecker); ok {
+		missing, _ = checker.CheckRequirements(a.Name)
+	}
+	if len(missing) > 0 && t.strict {
+		return fmt.Sprintf("Error: skill %q cannot be loaded because its requirements are not met:\n- %s\nInstall or configure them, or tell the user what is missing.", a.Name, strings.Join(missing, "\n- "))
+	}
+
 	rt := RuntimeContextFrom(ctx)
 	if strings.TrimSpace(rt.Workspace) != "" {
 		prompt = strings.ReplaceAll(prompt, "{{WORKSPACE}}", rt.Workspace)
 	}
 
+	if len(missing) > 0 {
+		prompt = fmt.Sprintf("WARNING: this skill's requirements are not met on this host:\n- %s\nSteps that depend on them will fail. Install or configure them first, or tell the user what is missing.\n\n---\n\n%s", strings.Join(missing, "\n- "), prompt)
+	}
 	return prompt
 }
+
+// SkillCatalog searches registered skills.
+type SkillCatalog interface {
+	List() []*skills.Skill
+	FindByTag(tag string) []*skills.Skill
+	Search(query string) []*skills.Skill
+}
+
+// ListSkillsTool lets the agent discover skills by keyword or tag.
+type ListSkillsTool struct {
+	catalog SkillCatalog
+}
+
+// NewListSkillsTool creates a new list_skills tool.
+func NewListSkillsTool(catalog SkillCatalog) *ListSkillsTool {
+	return &ListSkillsTool{catalog: catalog}
+}
+
+// Def returns the tool definition.
+func (t *ListSkillsTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "list_skills",
+			Description: "Find skills by keyword or tag. Returns matching skill names, tags and descriptions, best matches first; load one with use_skill.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Keywords matched against skill names, descriptions and tags. Omit to list all skills.",
+					},
+					"tag": map[string]any{
+						"type":        "string",
+						"description": "Only return skills with this tag.",
+					},
+				},
+			},
+		},
+	}
+}
+
+type listSkillsArgs struct {
+	Query string `json:"query,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// Run executes the tool.
+func (t *ListSkillsTool) Run(_ context.Context, args json.RawMessage) string {
+	var a listSkillsArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	query, tag := strings.TrimSpace(a.Query), strings.TrimSpace(a.Tag)
+	var found []*skills.Skill
+	switch {
+	case query != "":
+		found = t.catalog.Search(query)
+	case tag != "":
+		found = t.catalog.FindByTag(tag)
+	default:
+		found = t.catalog.List()
+		sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	}
+	if query != "" && tag != "" {
+		tagged := make([]*skills.Skill, 0, len(found))
+		for _, s := range found {
+			if hasTag(s, tag) {
+				tagged = append(tagged, s)
+			}
+		}
+		found = tagged
+	}
+	if len(found) == 0 {
+		return "No matching skills."
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d skill(s):\n", len(found))
+	for _, s := range found {
+		sb.WriteString("- " + s.Name)
+		if len(s.Tags) > 0 {
+			sb.WriteString(" [" + strings.Join(s.Tags, ", ") + "]")
+		}
+		if s.Description != "" {
+			sb.WriteString(": " + s.Description)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func hasTag(s *skills.Skill, tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkillFilesTool lists and reads raw skill files, including frontmatter.
+// It bypasses registry parsing so broken skills can still be inspected.
+type SkillFilesTool struct {
+	skillsDir string
+}
+
+// NewSkillFilesTool creates a new skill_files tool rooted at skillsDir.
+func NewSkillFilesTool(skillsDir string) *SkillFilesTool {
+	return &SkillFilesTool{skillsDir: skillsDir}
+}
+
+// Def returns the tool definition.
+func (t *SkillFilesTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "skill_files",
+			Description: "List skill files in the skills directory or read the raw content (including frontmatter) of one. Useful to debug skills that fail to load.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"action": map[string]any{
+						"type":        "string",
+						"enum":        []string{"list", "read"},
+						"description": "list: show skill files; read: return raw file content.",
+					},
+					"name": map[string]any{
+						"type":        "string",
+						"description": "For read: a skill directory name (reads its SKILL.md) or a file path relative to the skills directory.",
+					},
+				},
+				"required": []string{"action"},
+			},
+		},
+	}
+}
+
+type skillFilesArgs struct {
+	Action string `json:"action"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Run executes the tool.
+func (t *SkillFilesTool) Run(_ context.Context, args json.RawMessage) string {
+	var a skillFilesArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	root := strings.TrimSpace(t.skillsDir)
+	if root == "" {
+		return "Error: skills directory not configured"
+	}
+	root = absOrOriginal(root)
+
+	switch strings.TrimSpace(a.Action) {
+	case "list":
+		return t.list(root)
+	case "read":
+		return t.read(root, strings.TrimSpace(a.Name))
+	default:
+		return fmt.Sprintf("Error: unknown action %q (expected list or read)", a.Action)
+	}
+}
+
+func (t *SkillFilesTool) list(root string) string {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(d.Name())) {
+		case ".md", ".yaml", ".yml":
+			rel, relErr := filepath.Rel(root, path)
+			if relErr == nil {
+				files = append(files, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("No skills directory at %s", root)
+		}
+		return fmt.Sprintf("Error: failed to list skills directory: %v", err)
+	}
+	if len(files) == 0 {
+		return fmt.Sprintf("No skill files found in %s", root)
+	}
+
+	sort.Strings(files)
+	return fmt.Sprintf("Skill files in %s:\n%s", root, strings.Join(files, "\n"))
+}
+
+func (t *SkillFilesTool) read(root, name string) string {
+	if name == "" {
+		return "Error: name is required for read"
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "SKILL.md")
+	}
+	if !isWithinDir(root, path) {
+		return fmt.Sprintf("Error: path escapes skills directory: %s", name)
+	}
+	if realRoot, err := filepath.EvalSymlinks(root); err == nil {
+		if realPath, err := filepath.EvalSymlinks(path); err == nil && !isWithinDir(realRoot, realPath) {
+			return fmt.Sprintf("Error: path escapes skills directory: %s", name)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Error: skill file not found: %s", formatResolvedPath(name, path))
+		}
+		return fmt.Sprintf("Error: failed to read skill file: %s: %v", formatResolvedPath(name, path), err)
+	}
+
+	result, _ := truncateWithNotice(string(content), toolResultMaxChars)
+	return result
+}
+
+// isWithinDir reports whether path is root or lies beneath it.
+func isWithinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
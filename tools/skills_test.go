@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linanwx/nagobot/skills"
+)
+
+func newRequirementsRegistry(t *testing.T) *skills.Registry {
+	t.Helper()
+	dir := t.TempDir()
+	skillDir := filepath.Join(dir, "transcode")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: transcode\ndescription: Convert video\nrequires:\n  commands: [nagobot-missing-cmd-xyz]\n  env: [NAGOBOT_MISSING_ENV_XYZ]\n  files: [model.bin]\n---\nRun the transcoder."
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg := skills.NewRegistry()
+	if err := reg.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+func TestUseSkillWarnsAboutMissingRequirements(t *testing.T) {
+	tool := NewUseSkillTool(newRequirementsRegistry(t), false)
+	args, _ := json.Marshal(useSkillArgs{Name: "transcode"})
+	out := tool.Run(context.Background(), args)
+
+	if !strings.HasPrefix(out, "WARNING:") {
+		t.Fatalf("Run() = %q, want a leading warning", out)
+	}
+	for _, want := range []string{"command: nagobot-missing-cmd-xyz", "env: NAGOBOT_MISSING_ENV_XYZ", "file: model.bin", "Run the transcoder."} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Run() = %q, missing %q", out, want)
+		}
+	}
+}
+
+func TestUseSkillStrictRefusesMissingRequirements(t *testing.T) {
+	tool := NewUseSkillTool(newRequirementsRegistry(t), true)
+	args, _ := json.Marshal(useSkillArgs{Name: "transcode"})
+	out := tool.Run(context.Background(), args)
+
+	if !strings.HasPrefix(out, "Error:") || !strings.Contains(out, "nagobot-missing-cmd-xyz") {
+		t.Fatalf("Run() = %q, want a refusal naming the command", out)
+	}
+	if strings.Contains(out, "Run the transcoder.") {
+		t.Fatalf("strict mode returned the skill prompt: %q", out)
+	}
+}
+
+func TestListSkillsFiltersByQueryAndTag(t *testing.T) {
+	reg := skills.NewRegistry()
+	reg.Register(&skills.Skill{Name: "web-research", Description: "Search pages", Tags: []string{"web"}})
+	reg.Register(&skills.Skill{Name: "research-notes", Description: "Organize findings", Tags: []string{"notes"}})
+	tool := NewListSkillsTool(reg)
+
+	args, _ := json.Marshal(listSkillsArgs{Query: "research", Tag: "web"})
+	out := tool.Run(context.Background(), args)
+	if !strings.Contains(out, "- web-research [web]: Search pages") || strings.Contains(out, "research-notes") {
+		t.Fatalf("Run() = %q", out)
+	}
+
+	args, _ = json.Marshal(listSkillsArgs{Query: "nothing-matches"})
+	if out := tool.Run(context.Background(), args); out != "No matching skills." {
+		t.Fatalf("Run() = %q", out)
+	}
+}
+
+func TestUseSkillFillsArgs(t *testing.T) {
+	reg := skills.NewRegistry()
+	reg.Register(&skills.Skill{
+		Name:   "translate",
+		Prompt: "Translate to {{lang}}.",
+		Params: []skills.Param{{Name: "lang", Required: true}},
+	})
+	tool := NewUseSkillTool(reg, false)
+
+	args, _ := json.Marshal(useSkillArgs{Name: "translate", Args: map[string]any{"lang": "Japanese"}})
+	if out := tool.Run(context.Background(), args); out != "Translate to Japanese." {
+		t.Fatalf("Run() = %q", out)
+	}
+
+	args, _ = json.Marshal(useSkillArgs{Name: "translate"})
+	if out := tool.Run(context.Background(), args); !strings.HasPrefix(out, "Error:") || !strings.Contains(out, "lang") {
+		t.Fatalf("Run() = %q, want missing param error", out)
+	}
+}
+
+func TestSkillFilesToolListsReadsAndStaysInDir(t *testing.T) {
+	root := t.TempDir()
+	skillsDir := filepath.Join(root, "skills")
+	for name, body := range map[string]string{
+		"broken/SKILL.md":    "---\nname: [broken\n---\nbody",
+		"broken/config.yaml": "key: value",
+		"broken/script.sh":   "echo hi",
+		".hidden/SKILL.md":   "hidden",
+		"notes.md":           "top level",
+		"../secret.md":       "secret",
+	} {
+		path := filepath.Join(skillsDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "secret.md"), filepath.Join(skillsDir, "link.md")); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewSkillFilesTool(skillsDir)
+	run := func(args string) string {
+		return tool.Run(context.Background(), json.RawMessage(args))
+	}
+
+	want := "Skill files in " + skillsDir + ":\nbroken/SKILL.md\nbroken/config.yaml\nlink.md\nnotes.md"
+	if out := run(`{"action":"list"}`); out != want {
+		t.Fatalf("list = %q, want %q", out, want)
+	}
+	if out := run(`{"action":"read","name":"broken"}`); out != "---\nname: [broken\n---\nbody" {
+		t.Fatalf("read skill dir = %q, want raw SKILL.md", out)
+	}
+	if out := run(`{"action":"read","name":"broken/config.yaml"}`); out != "key: value" {
+		t.Fatalf("read file = %q", out)
+	}
+	if out := run(`{"action":"read","name":"missing"}`); !strings.HasPrefix(out, "Error: skill file not found") {
+		t.Fatalf("read missing = %q", out)
+	}
+	for _, name := range []string{"../secret.md", "broken/../../secret.md", "link.md"} {
+		args, _ := json.Marshal(skillFilesArgs{Action: "read", Name: name})
+		if out := tool.Run(context.Background(), args); !strings.HasPrefix(out, "Error: path escapes skills directory") {
+			t.Fatalf("read %q = %q, want escape error", name, out)
+		}
+	}
+}
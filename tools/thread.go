@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/linanwx/nagobot/provider"
 	"github.com/linanwx/nagobot/thread/msg"
@@ -12,7 +13,15 @@ import (
 
 // ThreadSpawner is implemented by thread.Thread to spawn child threads.
 type ThreadSpawner interface {
-	SpawnChild(ctx context.Context, agentName string, task string) (string, error)
+	// SpawnChild starts a child and returns its ID. With opts.Wait it also
+	// returns the child's result once it finishes or times out.
+	SpawnChild(ctx context.Context, agentName string, task string, opts SpawnOptions) (id string, result string, err error)
+}
+
+// SpawnOptions controls how a child thread runs.
+type SpawnOptions struct {
+	Wait    bool          // block until the child finishes and return its result
+	Timeout time.Duration // limit for the child's run; 0 uses the spawner's default
 }
 
 // ThreadInfo is an alias for msg.ThreadInfo.
@@ -39,8 +48,9 @@ func (t *SpawnThreadTool) Def() provider.ToolDef {
 	return provider.ToolDef{
 		Type: "function",
 		Function: provider.FunctionDef{
-			Name:        "spawn_thread",
-			Description: "Spawn a child thread for a delegated task. Always asynchronous: returns a child ID immediately. The child will wake the parent thread with a message when done.",
+			Name: "spawn_thread",
+			Description: "Spawn a child thread for a delegated task. By default asynchronous: returns a child ID immediately and the child wakes the parent thread with a message when done. " +
+				"Set wait=true to block until the child finishes and get its result directly; use it for short tasks whose result you need right away.",
 			Parameters: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -52,6 +62,14 @@ func (t *SpawnThreadTool) Def() provider.ToolDef {
 						"type":        "string",
 						"description": "Task description for the child thread, including specific instructions and task background context. Recommended length: 100-800 words.",
 					},
+					"wait": map[string]any{
+						"type":        "boolean",
+						"description": "Block until the child finishes and return its result. Defaults to false.",
+					},
+					"timeout": map[string]any{
+						"type":        "integer",
+						"description": "Optional limit for the child's run, in seconds. Defaults to 300 with wait, 1800 without. A child that runs out of time is stopped and reported as failed.",
+					},
 				},
 				"required": []string{"task"},
 			},
@@ -60,8 +78,10 @@ func (t *SpawnThreadTool) Def() provider.ToolDef {
 }
 
 type spawnThreadArgs struct {
-	Agent string `json:"agent"`
-	Task  string `json:"task"`
+	Agent   string `json:"agent"`
+	Task    string `json:"task"`
+	Wait    bool   `json:"wait,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
 }
 
 // Run executes the tool.
@@ -75,14 +95,76 @@ func (t *SpawnThreadTool) Run(ctx context.Context, args json.RawMessage) string
 		return "Error: thread spawner not configured"
 	}
 
-	childID, err := t.spawner.SpawnChild(ctx, strings.TrimSpace(a.Agent), a.Task)
+	if a.Timeout < 0 {
+		return "Error: timeout must be non-negative"
+	}
+	opts := SpawnOptions{Wait: a.Wait, Timeout: time.Duration(a.Timeout) * time.Second}
+	childID, result, err := t.spawner.SpawnChild(ctx, strings.TrimSpace(a.Agent), a.Task, opts)
 	if err != nil {
 		return fmt.Sprintf("Error spawning thread: %v", err)
 	}
+	if a.Wait {
+		return result
+	}
 
 	return fmt.Sprintf("Thread spawned with ID: %s\nThe child will wake this thread with a 'child_completed' message when done.", childID)
 }
 
+// ThreadCanceller is implemented by thread.Thread to cancel its children.
+type ThreadCanceller interface {
+	CancelChild(childID string) error
+}
+
+// CancelThreadTool stops a running or queued child thread.
+type CancelThreadTool struct {
+	canceller ThreadCanceller
+}
+
+// NewCancelThreadTool creates a new cancel_thread tool.
+func NewCancelThreadTool(canceller ThreadCanceller) *CancelThreadTool {
+	return &CancelThreadTool{canceller: canceller}
+}
+
+// Def returns the tool definition.
+func (t *CancelThreadTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "cancel_thread",
+			Description: "Cancel a child thread spawned by this thread. Its current work stops immediately and it will not report back.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"thread_id": map[string]any{
+						"type":        "string",
+						"description": "The thread ID returned by spawn_thread.",
+					},
+				},
+				"required": []string{"thread_id"},
+			},
+		},
+	}
+}
+
+// Run executes the tool.
+func (t *CancelThreadTool) Run(_ context.Context, args json.RawMessage) string {
+	var a checkThreadArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+	if t.canceller == nil {
+		return "Error: thread canceller not configured"
+	}
+	id := strings.TrimSpace(a.ThreadID)
+	if id == "" {
+		return "Error: thread_id is required"
+	}
+	if err := t.canceller.CancelChild(id); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("Thread %s cancelled.", id)
+}
+
 // CheckThreadTool checks the status of a spawned thread.
 type CheckThreadTool struct {
 	checker ThreadChecker
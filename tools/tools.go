@@ -43,14 +43,21 @@ func parseArgs[T any](args json.RawMessage, target *T) string {
 type Registry struct {
 	tools   map[string]Tool
 	logsDir string
+	approve ApprovalFunc
 }
 
 // DefaultToolsConfig provides defaults for built-in tools.
 type DefaultToolsConfig struct {
 	ExecTimeout         int
 	WebSearchMaxResults int
+	WebSearchAPIKey     string // Brave Search API key; empty uses DuckDuckGo
 	RestrictToWorkspace bool
+	ExecPolicy          ExecPolicy
+	ExecSanitizeEnv     bool     // run exec with only allowlisted host environment variables
+	ExecEnvAllowlist    []string // host variables kept when ExecSanitizeEnv is set
 	Skills              SkillProvider
+	SkillsDir           string
+	SkillsStrict        bool // refuse to load skills with unmet requirements
 }
 
 // NewRegistry creates a new tool registry.
@@ -69,6 +76,7 @@ func (r *Registry) SetLogsDir(dir string) {
 func (r *Registry) Clone() *Registry {
 	cloned := NewRegistry()
 	cloned.logsDir = r.logsDir
+	cloned.approve = r.approve
 	for name, tool := range r.tools {
 		cloned.tools[name] = tool
 	}
@@ -107,6 +115,18 @@ func (r *Registry) Run(ctx context.Context, name string, args json.RawMessage) s
 		return fmt.Sprintf("Error: unknown tool '%s'", name)
 	}
 
+	if rt, ok := t.(refusingTool); ok && r.approve != nil {
+		if errMsg := rt.refusal(ctx, args); errMsg != "" {
+			logger.Debug("tool call finished", "tool", name, "ok", false, "latencyMs", time.Since(start).Milliseconds())
+			return errMsg
+		}
+	}
+	if st, ok := t.(SensitiveTool); ok && r.approve != nil && st.Sensitive(ctx, args) && !r.approve(name, args) {
+		logger.Warn("tool call not approved", "tool", name)
+		logger.Debug("tool call finished", "tool", name, "ok", false, "latencyMs", time.Since(start).Milliseconds())
+		return fmt.Sprintf("Error: %s was blocked because it requires admin approval and was not approved. Do not retry the same call; tell the user what you wanted to do instead.", name)
+	}
+
 	result := t.Run(ctx, args)
 	latency := time.Since(start)
 	originalChars := len(result)
@@ -141,16 +161,33 @@ func (r *Registry) Names() []string {
 
 // RegisterDefaultTools registers the default file tools.
 func (r *Registry) RegisterDefaultTools(workspace string, cfg DefaultToolsConfig) {
-	r.Register(&ReadFileTool{workspace: workspace})
-	r.Register(&WriteFileTool{workspace: workspace})
-	r.Register(&AppendFileTool{workspace: workspace})
-	r.Register(&EditFileTool{workspace: workspace})
-	r.Register(&ExecTool{workspace: workspace, defaultTimeout: cfg.ExecTimeout, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&ReadFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&WriteFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&AppendFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&EditFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&MultiEditTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&EditLinesTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&ReplaceAllTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&MoveFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&DeleteFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&CopyFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&StatFileTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&ExecTool{workspace: workspace, defaultTimeout: cfg.ExecTimeout, restrictToWorkspace: cfg.RestrictToWorkspace, policy: cfg.ExecPolicy.compile(), sanitizeEnv: cfg.ExecSanitizeEnv, envAllowlist: cfg.ExecEnvAllowlist})
+	r.Register(&GrepTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
+	r.Register(&HashTool{workspace: workspace, restrictToWorkspace: cfg.RestrictToWorkspace})
 	r.Register(&HealthTool{Workspace: workspace})
-	r.Register(&WebSearchTool{defaultMaxResults: cfg.WebSearchMaxResults})
+	r.Register(&LogsTool{})
+	r.Register(&WebSearchTool{defaultMaxResults: cfg.WebSearchMaxResults, braveAPIKey: cfg.WebSearchAPIKey})
 	r.Register(&WebFetchTool{})
+	r.Register(NewWhoAmITool())
 	if cfg.Skills != nil {
-		r.Register(NewUseSkillTool(cfg.Skills))
+		r.Register(NewUseSkillTool(cfg.Skills, cfg.SkillsStrict))
+		if catalog, ok := cfg.Skills.(SkillCatalog); ok {
+			r.Register(NewListSkillsTool(catalog))
+		}
+	}
+	if cfg.SkillsDir != "" {
+		r.Register(NewSkillFilesTool(cfg.SkillsDir))
 	}
 }
 
@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/linanwx/nagobot/logger"
 	"github.com/linanwx/nagobot/provider"
 )
 
@@ -21,11 +24,20 @@ const (
 	webFetchHTTPTimeout        = 30 * time.Second
 	webFetchMaxReadBytes       = 500000
 	webFetchMaxContentChars    = 10000
+	duckDuckGoSearchURL        = "https://html.duckduckgo.com/html/"
+	braveSearchURL             = "https://api.search.brave.com/res/v1/web/search"
+	braveMaxCount              = 20
 )
 
-// WebSearchTool searches the web using DuckDuckGo.
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// WebSearchTool searches the web using the Brave Search API when an API key is
+// configured, falling back to the DuckDuckGo HTML endpoint.
 type WebSearchTool struct {
 	defaultMaxResults int
+	braveAPIKey       string
+	braveURL          string // overrides braveSearchURL in tests
+	duckDuckGoURL     string // overrides duckDuckGoSearchURL in tests
 }
 
 // Def returns the tool definition.
@@ -34,7 +46,7 @@ func (t *WebSearchTool) Def() provider.ToolDef {
 		Type: "function",
 		Function: provider.FunctionDef{
 			Name:        "web_search",
-			Description: "Search the web using DuckDuckGo and return results. Use for finding current information, documentation, etc.",
+			Description: "Search the web and return results. Use for finding current information, documentation, etc.",
 			Parameters: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -46,6 +58,16 @@ func (t *WebSearchTool) Def() provider.ToolDef {
 						"type":        "integer",
 						"description": "Maximum number of results to return. Defaults to 5.",
 					},
+					"region": map[string]any{
+						"type": "string",
+						"description": "Optional region/locale as <country>-<language>, e.g. \"cn-zh\", \"uk-en\", \"us-en\", \"jp-jp\", \"de-de\", " +
+							"or \"wt-wt\" for no region. Pick one matching the user's language. Defaults to no region.",
+					},
+					"safe_search": map[string]any{
+						"type":        "string",
+						"enum":        []string{"strict", "moderate", "off"},
+						"description": "Optional safe search level. Defaults to the search engine's default (moderate).",
+					},
 				},
 				"required": []string{"query"},
 			},
@@ -57,6 +79,8 @@ func (t *WebSearchTool) Def() provider.ToolDef {
 type webSearchArgs struct {
 	Query      string `json:"query"`
 	MaxResults int    `json:"max_results,omitempty"`
+	Region     string `json:"region,omitempty"`
+	SafeSearch string `json:"safe_search,omitempty"`
 }
 
 // Run executes the tool.
@@ -74,38 +98,173 @@ func (t *WebSearchTool) Run(ctx context.Context, args json.RawMessage) string {
 		}
 	}
 
-	searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", url.QueryEscape(a.Query))
-
 	client := &http.Client{Timeout: webSearchHTTPTimeout}
+	backend := "duckduckgo"
+	var results []searchResult
+	var err error
+	if t.braveAPIKey != "" {
+		backend = "brave"
+		results, err = t.searchBrave(ctx, client, a)
+		if err != nil {
+			logger.Warn("brave search failed, falling back to duckduckgo", "err", err)
+			backend = "duckduckgo"
+		}
+	}
+	if backend == "duckduckgo" {
+		results, err = t.searchDuckDuckGo(ctx, client, a)
+	}
+	logger.Debug("web_search backend", "backend", backend, "query", a.Query, "results", len(results))
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(results) == 0 {
+		return "No search results found."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Search results for: %s\n\n", a.Query))
+	for i, r := range results {
+		sb.WriteString(fmt.Sprintf("%d. %s\n   %s\n   %s\n\n", i+1, r.Title, r.URL, r.Snippet))
+	}
+
+	return sb.String()
+}
+
+func (t *WebSearchTool) searchDuckDuckGo(ctx context.Context, client *http.Client, a webSearchArgs) ([]searchResult, error) {
+	base := t.duckDuckGoURL
+	if base == "" {
+		base = duckDuckGoSearchURL
+	}
+	searchURL, err := buildDuckDuckGoURL(base, a.Query, a.Region, a.SafeSearch)
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
-		return fmt.Sprintf("Error: failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Sprintf("Error: search request failed: %v", err)
+		return nil, fmt.Errorf("search request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Sprintf("Error: failed to read response: %v", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	return parseDuckDuckGoResults(string(body), a.MaxResults), nil
+}
 
-	results := parseDuckDuckGoResults(string(body), a.MaxResults)
-	if len(results) == 0 {
-		return "No search results found."
+// braveSearchResponse is the subset of the Brave web search response we use.
+type braveSearchResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+func (t *WebSearchTool) searchBrave(ctx context.Context, client *http.Client, a webSearchArgs) ([]searchResult, error) {
+	base := t.braveURL
+	if base == "" {
+		base = braveSearchURL
+	}
+	count := a.MaxResults
+	if count > braveMaxCount {
+		count = braveMaxCount
+	}
+	params := url.Values{}
+	params.Set("q", a.Query)
+	params.Set("count", fmt.Sprintf("%d", count))
+	// Brave takes a country code; use the country half of a DuckDuckGo-style region.
+	if country, _, ok := strings.Cut(strings.ToLower(strings.TrimSpace(a.Region)), "-"); ok && country != "" && country != "wt" {
+		params.Set("country", country)
+	}
+	switch safe := strings.ToLower(strings.TrimSpace(a.SafeSearch)); safe {
+	case "":
+	case "strict", "moderate", "off":
+		params.Set("safesearch", safe)
+	default:
+		return nil, fmt.Errorf("invalid safe_search %q (use strict, moderate or off)", a.SafeSearch)
 	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Search results for: %s\n\n", a.Query))
-	for i, r := range results {
-		sb.WriteString(fmt.Sprintf("%d. %s\n   %s\n   %s\n\n", i+1, r.Title, r.URL, r.Snippet))
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", t.braveAPIKey)
 
-	return sb.String()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("brave request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webFetchMaxReadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read brave response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("brave returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed braveSearchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse brave response: %w", err)
+	}
+
+	results := make([]searchResult, 0, len(parsed.Web.Results))
+	for _, r := range parsed.Web.Results {
+		if r.Title == "" || r.URL == "" {
+			continue
+		}
+		results = append(results, searchResult{
+			Title:   stripHTMLTags(r.Title),
+			URL:     r.URL,
+			Snippet: stripHTMLTags(r.Description),
+		})
+		if len(results) >= a.MaxResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// stripHTMLTags removes inline markup such as <strong> highlights.
+func stripHTMLTags(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(s, "")))
+}
+
+// buildDuckDuckGoURL builds the HTML search URL. region maps to kl and
+// safeSearch (strict, moderate, off) maps to kp; empty values are omitted.
+func buildDuckDuckGoURL(base, query, region, safeSearch string) (string, error) {
+	params := url.Values{}
+	params.Set("q", query)
+
+	if region = strings.ToLower(strings.TrimSpace(region)); region != "" {
+		params.Set("kl", region)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(safeSearch)) {
+	case "":
+	case "strict":
+		params.Set("kp", "1")
+	case "moderate":
+		params.Set("kp", "-1")
+	case "off":
+		params.Set("kp", "-2")
+	default:
+		return "", fmt.Errorf("invalid safe_search %q (use strict, moderate or off)", safeSearch)
+	}
+
+	return base + "?" + params.Encode(), nil
 }
 
 // searchResult represents a single search result.
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBuildDuckDuckGoURLMapsRegionAndSafeSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     string
+		safeSearch string
+		wantKL     string
+		wantKP     string
+	}{
+		{name: "defaults omit locale params"},
+		{name: "region only", region: "cn-zh", wantKL: "cn-zh"},
+		{name: "region is normalized", region: " UK-EN ", wantKL: "uk-en"},
+		{name: "strict", safeSearch: "strict", wantKP: "1"},
+		{name: "moderate", safeSearch: "moderate", wantKP: "-1"},
+		{name: "off with region", region: "jp-jp", safeSearch: "off", wantKL: "jp-jp", wantKP: "-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := buildDuckDuckGoURL(duckDuckGoSearchURL, "golang 协程", tt.region, tt.safeSearch)
+			if err != nil {
+				t.Fatalf("buildDuckDuckGoURL() error = %v", err)
+			}
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatalf("url.Parse(%q) error = %v", raw, err)
+			}
+			if got := u.Scheme + "://" + u.Host + u.Path; got != duckDuckGoSearchURL {
+				t.Fatalf("base = %q, want %q", got, duckDuckGoSearchURL)
+			}
+			q := u.Query()
+			if got := q.Get("q"); got != "golang 协程" {
+				t.Fatalf("q = %q, want %q", got, "golang 协程")
+			}
+			if got, ok := q["kl"]; tt.wantKL == "" && ok {
+				t.Fatalf("kl = %v, want absent", got)
+			}
+			if got := q.Get("kl"); got != tt.wantKL {
+				t.Fatalf("kl = %q, want %q", got, tt.wantKL)
+			}
+			if got, ok := q["kp"]; tt.wantKP == "" && ok {
+				t.Fatalf("kp = %v, want absent", got)
+			}
+			if got := q.Get("kp"); got != tt.wantKP {
+				t.Fatalf("kp = %q, want %q", got, tt.wantKP)
+			}
+		})
+	}
+}
+
+func TestBuildDuckDuckGoURLRejectsUnknownSafeSearch(t *testing.T) {
+	if _, err := buildDuckDuckGoURL(duckDuckGoSearchURL, "q", "", "maximum"); err == nil {
+		t.Fatal("buildDuckDuckGoURL() should reject an unknown safe_search value")
+	}
+}
+
+const testDuckDuckGoHTML = `<html><body>
+<div class="result">
+  <a class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fexample.com%2Fddg&rut=x">DDG Result</a>
+  <a class="result__snippet">from duckduckgo</a>
+</div>
+</body></html>`
+
+func TestWebSearchToolUsesBraveWhenKeyConfigured(t *testing.T) {
+	var gotToken, gotQuery, gotCount string
+	brave := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("X-Subscription-Token")
+		gotQuery = r.URL.Query().Get("q")
+		gotCount = r.URL.Query().Get("count")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"web":{"results":[
+			{"title":"Brave <strong>Result</strong>","url":"https://example.com/brave","description":"from &amp; brave"},
+			{"title":"Second","url":"https://example.com/second","description":"more"}
+		]}}`)
+	}))
+	defer brave.Close()
+	ddg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("duckduckgo should not be queried when brave succeeds")
+	}))
+	defer ddg.Close()
+
+	tool := &WebSearchTool{braveAPIKey: "test-key", braveURL: brave.URL, duckDuckGoURL: ddg.URL}
+	out := tool.Run(context.Background(), json.RawMessage(`{"query":"nagobot","max_results":1}`))
+
+	if gotToken != "test-key" {
+		t.Fatalf("X-Subscription-Token = %q, want test-key", gotToken)
+	}
+	if gotQuery != "nagobot" || gotCount != "1" {
+		t.Fatalf("brave query params q=%q count=%q, want nagobot/1", gotQuery, gotCount)
+	}
+	if !strings.Contains(out, "1. Brave Result\n   https://example.com/brave\n   from & brave") {
+		t.Fatalf("Run() output missing brave result:\n%s", out)
+	}
+	if strings.Contains(out, "Second") {
+		t.Fatalf("Run() should respect max_results:\n%s", out)
+	}
+}
+
+func TestWebSearchToolFallsBackToDuckDuckGo(t *testing.T) {
+	braveCalls := 0
+	brave := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		braveCalls++
+		http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer brave.Close()
+	ddg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testDuckDuckGoHTML)
+	}))
+	defer ddg.Close()
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		wantBrave int
+	}{
+		{name: "no api key", apiKey: "", wantBrave: 0},
+		{name: "brave error", apiKey: "test-key", wantBrave: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			braveCalls = 0
+			tool := &WebSearchTool{braveAPIKey: tt.apiKey, braveURL: brave.URL, duckDuckGoURL: ddg.URL}
+			out := tool.Run(context.Background(), json.RawMessage(`{"query":"nagobot"}`))
+			if braveCalls != tt.wantBrave {
+				t.Fatalf("brave calls = %d, want %d", braveCalls, tt.wantBrave)
+			}
+			if !strings.Contains(out, "DDG Result") || !strings.Contains(out, "https://example.com/ddg") {
+				t.Fatalf("Run() output missing duckduckgo result:\n%s", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+// WhoAmITool reports the identity of the user who triggered the current run.
+type WhoAmITool struct{}
+
+// NewWhoAmITool creates a whoami tool.
+func NewWhoAmITool() *WhoAmITool {
+	return &WhoAmITool{}
+}
+
+// Def returns the tool definition.
+func (t *WhoAmITool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name:        "whoami",
+			Description: "Get the identity of the current user: user ID, username, channel, whether they are the configured admin, and the session key.",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+		},
+	}
+}
+
+type whoAmIResult struct {
+	UserID     string `json:"userID"`
+	Username   string `json:"username,omitempty"`
+	Channel    string `json:"channel"`
+	IsAdmin    bool   `json:"isAdmin"`
+	SessionKey string `json:"sessionKey,omitempty"`
+}
+
+// Run executes the tool.
+func (t *WhoAmITool) Run(ctx context.Context, _ json.RawMessage) string {
+	rt := RuntimeContextFrom(ctx)
+
+	result := whoAmIResult{
+		UserID:     "unknown",
+		Channel:    "stateless",
+		SessionKey: rt.SessionKey,
+	}
+	if rt.Origin != nil {
+		result.Channel = rt.Origin.Channel
+		result.Username = rt.Origin.Username
+		result.IsAdmin = rt.Origin.IsAdmin
+		if rt.Origin.UserID != "" {
+			result.UserID = rt.Origin.UserID
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("Error: failed to serialize identity: %v", err)
+	}
+	return string(data)
+}
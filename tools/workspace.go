@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/linanwx/nagobot/provider"
+)
+
+// WorkspaceSwitcher lists named workspaces and switches a session between them.
+type WorkspaceSwitcher interface {
+	Workspaces() map[string]string
+	ActiveWorkspace(sessionKey string) string
+	SwitchWorkspace(sessionKey, name string) (string, error)
+}
+
+// SwitchWorkspaceTool lets an admin move the current session to another named workspace.
+type SwitchWorkspaceTool struct {
+	switcher WorkspaceSwitcher
+}
+
+// NewSwitchWorkspaceTool creates a switch_workspace tool.
+func NewSwitchWorkspaceTool(switcher WorkspaceSwitcher) *SwitchWorkspaceTool {
+	return &SwitchWorkspaceTool{switcher: switcher}
+}
+
+// Def returns the tool definition.
+func (t *SwitchWorkspaceTool) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Type: "function",
+		Function: provider.FunctionDef{
+			Name: "switch_workspace",
+			Description: "List configured workspaces, or switch the current session to another named workspace. " +
+				"After switching, file, exec and grep tools resolve relative paths against the new workspace, " +
+				"and skills are loaded from its skills directory, starting with the next message. Admin only.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{
+						"type":        "string",
+						"description": "Workspace name to switch to. Omit to list workspaces and show the active one.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// switchWorkspaceArgs are the arguments for switch_workspace.
+type switchWorkspaceArgs struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Run executes the tool.
+func (t *SwitchWorkspaceTool) Run(ctx context.Context, args json.RawMessage) string {
+	var a switchWorkspaceArgs
+	if errMsg := parseArgs(args, &a); errMsg != "" {
+		return errMsg
+	}
+
+	rt := RuntimeContextFrom(ctx)
+	if rt.SessionKey == "" {
+		return "Error: switch_workspace requires a session"
+	}
+
+	name := strings.TrimSpace(a.Name)
+	if name == "" {
+		return t.list(rt.SessionKey)
+	}
+
+	// CLI and system runs are local operators; channel users must be the admin.
+	if rt.Origin != nil && !rt.Origin.IsAdmin && rt.Origin.Channel != "cli" {
+		return "Error: only the admin can switch workspaces"
+	}
+
+	path, err := t.switcher.SwitchWorkspace(rt.SessionKey, name)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return fmt.Sprintf("Session %q switched to workspace %q (%s). Takes effect from the next message.", rt.SessionKey, name, path)
+}
+
+func (t *SwitchWorkspaceTool) list(sessionKey string) string {
+	workspaces := t.switcher.Workspaces()
+	active := t.switcher.ActiveWorkspace(sessionKey)
+
+	names := make([]string, 0, len(workspaces))
+	for name := range workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Fprintf(&sb, "%s %s: %s\n", marker, name, workspaces[name])
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}